@@ -0,0 +1,323 @@
+package backup
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+	"todolist-api/config"
+	"todolist-api/constants"
+	"todolist-api/data/repositories/backup"
+	"todolist-api/infra/archive"
+	"todolist-api/infra/context/repository"
+	"todolist-api/infra/db"
+	"todolist-api/infra/logger"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/spf13/cobra"
+)
+
+// restoreBatch the rows of a table held in memory before they are inserted
+const restoreBatch = 500
+
+var (
+	backupCMD = &cobra.Command{
+		Use:   "backup",
+		Short: "Back up the database to a file",
+		Long: "Stream the activity groups, the todos, the webhooks with their events and deliveries and the change feed " +
+			"into a versioned, compressed and checksummed archive, independent of the SQL dialect. " +
+			"The archive holds the secrets of the webhooks.",
+		Args: cobra.NoArgs,
+		RunE: runBackup,
+	}
+
+	restoreCMD = &cobra.Command{
+		Use:   "restore",
+		Short: "Restore a backup into an empty database",
+		Long: "Restore a backup into an empty database migrated to the schema of the backup, keeping the ids and the timestamps. " +
+			"Everything is restored in one transaction, committed once the checksum of the whole backup matched. " +
+			"With --verify, the backup is only checked and the database is left alone.",
+		Args: cobra.NoArgs,
+		RunE: runRestore,
+	}
+)
+
+func init() {
+	backupCMD.Flags().String("out", "", "file written, replaced once the backup is complete")
+	_ = backupCMD.MarkFlagRequired("out")
+
+	restoreCMD.Flags().String("in", "", "file of the backup")
+	restoreCMD.Flags().Bool("verify", false, "check the backup without restoring it")
+	_ = restoreCMD.MarkFlagRequired("in")
+}
+
+// open the database and the repository of the backups
+func open() (*db.DB, backup.BackupRepositoryInterface, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := logger.Configure(cfg.Log); err != nil {
+		return nil, nil, err
+	}
+
+	conn, err := db.Open(&cfg.DB)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return conn, repository.NewRepoCtx(conn, nil).BackupRepository, nil
+}
+
+func runBackup(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	ctx := cmd.Context()
+	out, _ := cmd.Flags().GetString("out")
+
+	conn, repo, err := open()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// one snapshot for every table
+	tx, err := conn.Master().BeginTxx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	schema, err := repo.GetSchemaVersion(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	var trailer archive.Trailer
+	err = create(out, func(w io.Writer) error {
+		aw, err := archive.NewWriter(w, archive.Header{
+			Schema:    schema,
+			CreatedAt: time.Now().UTC(),
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, table := range repo.Tables() {
+			err := repo.DumpTable(ctx, tx, table, func(row archive.Row) error {
+				return aw.Write(table, row)
+			})
+			if err != nil {
+				return fmt.Errorf("back up %s: %w", table, err)
+			}
+		}
+
+		trailer, err = aw.Close()
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return summary(cmd.OutOrStdout(), fmt.Sprintf("backed up schema %d to %s", schema, out), repo.Tables(), trailer)
+}
+
+func runRestore(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	ctx := cmd.Context()
+	in, _ := cmd.Flags().GetString("in")
+	verify, _ := cmd.Flags().GetBool("verify")
+
+	file, err := os.Open(in)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	r, err := archive.NewReader(file)
+	if err != nil {
+		return err
+	}
+
+	if verify {
+		for {
+			if _, _, err := r.Next(); err != nil {
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				return err
+			}
+		}
+
+		title := fmt.Sprintf("backup of schema %d taken at %s is valid", r.Header.Schema, r.Header.CreatedAt.Format(time.RFC3339))
+		return summary(cmd.OutOrStdout(), title, tablesOf(r.Trailer().Rows), *r.Trailer())
+	}
+
+	conn, repo, err := open()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if err := restoreAll(ctx, conn, repo, r); err != nil {
+		return err
+	}
+
+	title := fmt.Sprintf("restored schema %d from %s", r.Header.Schema, in)
+	return summary(cmd.OutOrStdout(), title, repo.Tables(), *r.Trailer())
+}
+
+// restoreAll restore r in one transaction, committed once every row is
+// inserted and the trailer matched, rolled back on any failure
+func restoreAll(ctx context.Context, conn *db.DB, repo backup.BackupRepositoryInterface, r *archive.Reader) error {
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := restore(ctx, tx, repo, r); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+// restore insert the rows of r in tx, once the database is checked to be of
+// the schema of the backup and empty
+func restore(ctx context.Context, tx *sqlx.Tx, repo backup.BackupRepositoryInterface, r *archive.Reader) error {
+	schema, err := repo.GetSchemaVersion(ctx, tx)
+	if err != nil {
+		return err
+	}
+	if schema != r.Header.Schema {
+		return fmt.Errorf("%w: backup of schema %d, database of schema %d", constants.ErrArchiveSchema, r.Header.Schema, schema)
+	}
+
+	// the rows the migrations inserted are replaced by the ones of the backup
+	if err := repo.DeleteSeeds(ctx, tx); err != nil {
+		return err
+	}
+
+	var filled []string
+	for _, table := range repo.Tables() {
+		count, err := repo.CountRows(ctx, tx, table)
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			filled = append(filled, fmt.Sprintf("%s has %d rows", table, count))
+		}
+	}
+	if len(filled) > 0 {
+		return fmt.Errorf("%w: %s", constants.ErrRestoreNotEmpty, strings.Join(filled, ", "))
+	}
+
+	var current string
+	var rows []archive.Row
+	flush := func() error {
+		if len(rows) == 0 {
+			return nil
+		}
+		if err := repo.RestoreRows(ctx, tx, current, rows); err != nil {
+			return fmt.Errorf("restore %s: %w", current, err)
+		}
+		rows = rows[:0]
+		return nil
+	}
+
+	for {
+		table, row, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		if table != current || len(rows) == restoreBatch {
+			if err := flush(); err != nil {
+				return err
+			}
+			current = table
+		}
+		rows = append(rows, row)
+	}
+
+	return flush()
+}
+
+// create write the file named name through write, replacing it only once
+// written in full
+func create(name string, write func(w io.Writer) error) error {
+	f, err := os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if err := write(f); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), name)
+}
+
+// tablesOf the tables of rows, sorted
+func tablesOf(rows map[string]int) []string {
+	tables := make([]string, 0, len(rows))
+	for table := range rows {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+
+	return tables
+}
+
+// summary write the rows of each table and the checksum of a backup
+func summary(w io.Writer, title string, tables []string, trailer archive.Trailer) error {
+	fmt.Fprintln(w, title)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TABLE\tROWS")
+	for _, table := range tables {
+		fmt.Fprintf(tw, "%s\t%d\n", table, trailer.Rows[table])
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintf(w, "sha256 %s\n", trailer.SHA256)
+	return err
+}
+
+// BackupCommand return instance of backup command object
+func BackupCommand() *cobra.Command {
+	return backupCMD
+}
+
+// RestoreCommand return instance of restore command object
+func RestoreCommand() *cobra.Command {
+	return restoreCMD
+}
@@ -0,0 +1,213 @@
+package backup
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+	"todolist-api/config"
+	"todolist-api/constants"
+	"todolist-api/infra/archive"
+	"todolist-api/infra/db"
+	"todolist-api/infra/db/dbtest"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// memoryRepository a database of the schema schema holding count rows per
+// table, recording the rows restored
+type memoryRepository struct {
+	schema   int64
+	count    map[string]int
+	restored map[string][]archive.Row
+}
+
+func (m *memoryRepository) Tables() []string {
+	return []string{"activities", "todos"}
+}
+
+func (m *memoryRepository) GetSchemaVersion(context.Context, *sqlx.Tx) (int64, error) {
+	return m.schema, nil
+}
+
+func (m *memoryRepository) DumpTable(context.Context, *sqlx.Tx, string, func(row archive.Row) error) error {
+	return nil
+}
+
+func (m *memoryRepository) CountRows(_ context.Context, _ *sqlx.Tx, table string) (int, error) {
+	return m.count[table], nil
+}
+
+func (m *memoryRepository) DeleteSeeds(context.Context, *sqlx.Tx) error {
+	return nil
+}
+
+func (m *memoryRepository) RestoreRows(_ context.Context, _ *sqlx.Tx, table string, rows []archive.Row) error {
+	if m.restored == nil {
+		m.restored = map[string][]archive.Row{}
+	}
+	m.restored[table] = append(m.restored[table], rows...)
+
+	return nil
+}
+
+func (m *memoryRepository) Truncate(context.Context) error {
+	return nil
+}
+
+// archived a backup of schema 20240101, its checksum replaced when set
+func archived(t *testing.T, checksum string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w, err := archive.NewWriter(&buf, archive.Header{Schema: 20240101, CreatedAt: time.Now().UTC()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = w.Write("activities", archive.Row{"id": 1, "title": "Home"})
+	_ = w.Write("todos", archive.Row{"id": 1, "activity_group_id": 1, "title": "Buy milk"})
+	_ = w.Write("todos", archive.Row{"id": 2, "activity_group_id": 1, "title": "Buy eggs"})
+	trailer, err := w.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if checksum == "" {
+		return buf.Bytes()
+	}
+
+	return gzipped(t, bytes.Replace(gunzip(t, buf.Bytes()), []byte(trailer.SHA256), []byte(checksum), 1))
+}
+
+func gunzip(t *testing.T, data []byte) []byte {
+	t.Helper()
+
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return raw
+}
+
+func gzipped(t *testing.T, raw []byte) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write(raw); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestRestore(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   func(t *testing.T) []byte
+		repo   *memoryRepository
+		err    error
+		commit bool
+		// the database refuses the backup before any row is read
+		refused bool
+	}{
+		{
+			name:   "restored",
+			data:   func(t *testing.T) []byte { return archived(t, "") },
+			repo:   &memoryRepository{schema: 20240101},
+			commit: true,
+		},
+		{
+			name: "truncated",
+			data: func(t *testing.T) []byte {
+				// the trailer is missing from the stream
+				raw := gunzip(t, archived(t, ""))
+				return gzipped(t, raw[:bytes.LastIndex(raw[:len(raw)-1], []byte("\n"))+1])
+			},
+			repo: &memoryRepository{schema: 20240101},
+			err:  constants.ErrArchiveTruncated,
+		},
+		{
+			name: "corrupted checksum",
+			data: func(t *testing.T) []byte {
+				return archived(t, "0000000000000000000000000000000000000000000000000000000000000000")
+			},
+			repo: &memoryRepository{schema: 20240101},
+			err:  constants.ErrArchiveCorrupt,
+		},
+		{
+			name:    "other schema",
+			data:    func(t *testing.T) []byte { return archived(t, "") },
+			repo:    &memoryRepository{schema: 20240315},
+			err:     constants.ErrArchiveSchema,
+			refused: true,
+		},
+		{
+			name:    "not empty",
+			data:    func(t *testing.T) []byte { return archived(t, "") },
+			repo:    &memoryRepository{schema: 20240101, count: map[string]int{"todos": 3}},
+			err:     constants.ErrRestoreNotEmpty,
+			refused: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &dbtest.Driver{}
+			conn, err := db.Open(&config.DBConfig{Name: dbtest.Register(d), Host: "test"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+
+			r, err := archive.NewReader(bytes.NewReader(tt.data(t)))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = restoreAll(context.Background(), conn, tt.repo, r)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("error %v, expected %v", err, tt.err)
+			}
+
+			// the rows are written in the transaction, kept only once committed
+			end := "ROLLBACK"
+			if tt.commit {
+				end = "COMMIT"
+			}
+			if queries := d.Queries(); len(queries) != 1 || queries[0] != end {
+				t.Fatalf("transaction ended with %q, expected %s", queries, end)
+			}
+
+			if tt.refused && tt.repo.restored != nil {
+				t.Fatalf("rows %v restored into a database refusing the backup", tt.repo.restored)
+			}
+			if !tt.commit {
+				return
+			}
+			expected := map[string][]archive.Row{
+				"activities": {{"id": json.Number("1"), "title": "Home"}},
+				"todos": {
+					{"id": json.Number("1"), "activity_group_id": json.Number("1"), "title": "Buy milk"},
+					{"id": json.Number("2"), "activity_group_id": json.Number("1"), "title": "Buy eggs"},
+				},
+			}
+			if !reflect.DeepEqual(tt.repo.restored, expected) {
+				t.Fatalf("restored %v, expected %v", tt.repo.restored, expected)
+			}
+		})
+	}
+}
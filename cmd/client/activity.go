@@ -0,0 +1,136 @@
+package client
+
+import (
+	"strconv"
+	"todolist-api/objects/activity"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	activityListCMD = &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List the activity groups",
+		Args:    cobra.NoArgs,
+		RunE:    runActivityList,
+	}
+
+	activityCreateCMD = &cobra.Command{
+		Use:   "create <title>",
+		Short: "Create an activity group",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runActivityCreate,
+	}
+
+	activityRenameCMD = &cobra.Command{
+		Use:   "rename <id> <title>",
+		Short: "Rename an activity group",
+		Args:  cobra.ExactArgs(2),
+		RunE:  runActivityRename,
+	}
+
+	activityDeleteCMD = &cobra.Command{
+		Use:     "delete <id>...",
+		Aliases: []string{"rm"},
+		Short:   "Delete activity groups along with their todos",
+		Args:    cobra.MinimumNArgs(1),
+		RunE:    runActivityDelete,
+	}
+)
+
+func init() {
+	activityListCMD.Flags().String("email", "", "owner of the groups, every group the API key sees by default")
+	activityCreateCMD.Flags().String("email", "", "owner of the group")
+
+	activityCMD.AddCommand(activityListCMD)
+	activityCMD.AddCommand(activityCreateCMD)
+	activityCMD.AddCommand(activityRenameCMD)
+	activityCMD.AddCommand(activityDeleteCMD)
+}
+
+func runActivityList(cmd *cobra.Command, args []string) error {
+	b, output, err := open(cmd)
+	if err != nil {
+		return err
+	}
+	defer b.Close()
+
+	email, _ := cmd.Flags().GetString("email")
+	data, err := b.GetAllActivity(cmd.Context(), activity.FilterActivity{
+		Email: email,
+	})
+	if err != nil {
+		return err
+	}
+
+	return render(cmd.OutOrStdout(), output, data, activityTable(data...))
+}
+
+func runActivityCreate(cmd *cobra.Command, args []string) error {
+	b, output, err := open(cmd)
+	if err != nil {
+		return err
+	}
+	defer b.Close()
+
+	email, _ := cmd.Flags().GetString("email")
+	data, err := b.CreateActivity(cmd.Context(), activity.CreateActivity{
+		Title: args[0],
+		Email: email,
+	})
+	if err != nil {
+		return err
+	}
+
+	return render(cmd.OutOrStdout(), output, data, activityTable(data))
+}
+
+func runActivityRename(cmd *cobra.Command, args []string) error {
+	ids, err := parseIDs(args[:1])
+	if err != nil {
+		return err
+	}
+
+	b, output, err := open(cmd)
+	if err != nil {
+		return err
+	}
+	defer b.Close()
+
+	data, err := b.UpdateActivity(cmd.Context(), ids[0], activity.UpdateActivity{
+		Title: args[1],
+	})
+	if err != nil {
+		return err
+	}
+
+	return render(cmd.OutOrStdout(), output, data, activityTable(data))
+}
+
+func runActivityDelete(cmd *cobra.Command, args []string) error {
+	ids, err := parseIDs(args)
+	if err != nil {
+		return err
+	}
+
+	b, output, err := open(cmd)
+	if err != nil {
+		return err
+	}
+	defer b.Close()
+
+	return remove(cmd, output, ids, func(id int) error {
+		return b.DeleteActivity(cmd.Context(), id)
+	})
+}
+
+// activityTable the rows of the activity groups
+func activityTable(data ...activity.Activity) table {
+	t := table{header: []string{"ID", "TITLE", "EMAIL", "CREATED"}}
+	for _, x := range data {
+		t.rows = append(t.rows, []string{strconv.Itoa(x.ID), x.Title, x.Email, x.CreatedAt})
+	}
+
+	return t
+}
@@ -0,0 +1,25 @@
+package client
+
+import (
+	"context"
+	"todolist-api/objects/activity"
+	"todolist-api/objects/todo"
+)
+
+// backend what the commands act on, a running server over HTTP or the
+// database through the services
+type backend interface {
+	GetAllActivity(ctx context.Context, filter activity.FilterActivity) ([]activity.Activity, error)
+	CreateActivity(ctx context.Context, req activity.CreateActivity) (activity.Activity, error)
+	UpdateActivity(ctx context.Context, id int, req activity.UpdateActivity) (activity.Activity, error)
+	DeleteActivity(ctx context.Context, id int) error
+
+	GetAllTodo(ctx context.Context, filter todo.FilterTodo) ([]todo.Todo, error)
+	GetOneTodo(ctx context.Context, id int) (todo.Todo, error)
+	CreateTodo(ctx context.Context, req todo.CreateTodo) (todo.Todo, error)
+	UpdateTodo(ctx context.Context, id int, req todo.UpdateTodo) (todo.Todo, error)
+	DeleteTodo(ctx context.Context, id int) error
+
+	// Close release what the backend holds
+	Close() error
+}
@@ -0,0 +1,136 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"todolist-api/config"
+	"todolist-api/infra/logger"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	activityCMD = &cobra.Command{
+		Use:   "activity",
+		Short: "Manage the activity groups",
+		Long:  "Manage the activity groups of a running server, or of the database with --direct",
+	}
+
+	todoCMD = &cobra.Command{
+		Use:   "todo",
+		Short: "Manage the todos",
+		Long:  "Manage the todos of a running server, or of the database with --direct",
+	}
+)
+
+func init() {
+	for _, c := range []*cobra.Command{activityCMD, todoCMD} {
+		flags := c.PersistentFlags()
+		flags.String("base-url", "", "address of the server, overrides client.baseURL")
+		flags.String("api-key", "", "API key sent to the server, overrides client.apiKey")
+		flags.StringP("output", "o", OutputTable, "output, table, json or yaml")
+		flags.Bool("direct", false, "act on the database through the services rather than on a server")
+	}
+}
+
+// open the backend the command acts on and the output it asks for
+func open(cmd *cobra.Command) (backend, string, error) {
+	cmd.SilenceUsage = true
+
+	output, _ := cmd.Flags().GetString("output")
+	if !validOutput(output) {
+		return nil, "", fmt.Errorf("output %q is not one of %s, %s or %s", output, OutputTable, OutputJSON, OutputYAML)
+	}
+
+	// flags override the file and the environment
+	config.BindFlag("client.baseURL", cmd.Flags().Lookup("base-url"))
+	config.BindFlag("client.apiKey", cmd.Flags().Lookup("api-key"))
+	cfg, err := config.Load()
+
+	// a server needs only the client settings, the others may be missing
+	if direct, _ := cmd.Flags().GetBool("direct"); !direct {
+		if _, invalid := err.(config.ValidationError); invalid {
+			err = cfg.Client.Validate()
+		}
+		if err != nil {
+			return nil, "", err
+		}
+
+		return newHTTPBackend(cfg.Client), output, nil
+	}
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	if err := logger.Configure(cfg.Log); err != nil {
+		return nil, "", err
+	}
+
+	b, err := newDirectBackend(cfg)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return b, output, nil
+}
+
+// parseIDs read the ids given as arguments
+func parseIDs(args []string) ([]int, error) {
+	ids := make([]int, 0, len(args))
+	for _, arg := range args {
+		id, err := strconv.Atoi(arg)
+		if err != nil || id <= 0 {
+			return nil, fmt.Errorf("%q is not an id", arg)
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// deletion the outcome of a delete command for one id
+type deletion struct {
+	ID      int  `json:"id"`
+	Deleted bool `json:"deleted"`
+}
+
+// remove delete every id with del, stopping at the first failure once the
+// ids deleted so far are written
+func remove(cmd *cobra.Command, output string, ids []int, del func(id int) error) error {
+	done := make([]deletion, 0, len(ids))
+	var failure error
+	for _, id := range ids {
+		if err := del(id); err != nil {
+			failure = fmt.Errorf("delete %d: %w", id, err)
+			break
+		}
+		done = append(done, deletion{ID: id, Deleted: true})
+	}
+
+	t := table{header: []string{"ID", "DELETED"}}
+	for _, x := range done {
+		t.rows = append(t.rows, []string{strconv.Itoa(x.ID), "yes"})
+	}
+	if len(done) > 0 || failure == nil {
+		if err := render(cmd.OutOrStdout(), output, done, t); err != nil {
+			return err
+		}
+	}
+
+	return failure
+}
+
+// errNoChange an edit without any field to change
+var errNoChange = errors.New("nothing to change, give at least one flag")
+
+// ActivityCommand return instance of activity command object
+func ActivityCommand() *cobra.Command {
+	return activityCMD
+}
+
+// TodoCommand return instance of todo command object
+func TodoCommand() *cobra.Command {
+	return todoCMD
+}
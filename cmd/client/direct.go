@@ -0,0 +1,41 @@
+package client
+
+import (
+	"todolist-api/cmd/services/activity"
+	"todolist-api/cmd/services/todo"
+	"todolist-api/config"
+	"todolist-api/infra/context/repository"
+	"todolist-api/infra/context/service"
+	"todolist-api/infra/db"
+	"todolist-api/infra/events"
+)
+
+// directBackend the commands run on the database through the services, as
+// the server would run them, without authentication nor quota
+type directBackend struct {
+	activity.ActivityServiceInterface
+	todo.TodoServiceInterface
+	db *db.DB
+}
+
+func newDirectBackend(cfg config.Config) (*directBackend, error) {
+	conn, err := db.Open(&cfg.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	// the changes still reach the change feed and the webhooks through the
+	// database, the live streams of the servers don't see them
+	broker := events.NewBroker(cfg.Events.BufferSize, events.NewLocalFanOut())
+	serviceCtx := service.NewCtx(repository.NewRepoCtx(conn, broker))
+
+	return &directBackend{
+		ActivityServiceInterface: serviceCtx.ActivityService,
+		TodoServiceInterface:     serviceCtx.TodoService,
+		db:                       conn,
+	}, nil
+}
+
+func (d *directBackend) Close() error {
+	return d.db.Close()
+}
@@ -0,0 +1,183 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"todolist-api/config"
+	"todolist-api/infra/auth"
+	"todolist-api/objects/activity"
+	"todolist-api/objects/todo"
+)
+
+// apiPrefix the version of the API the client speaks
+const apiPrefix = "/v1"
+
+// APIError an error response of the server
+type APIError struct {
+	Status  int
+	Message string
+}
+
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("server answered %d %s", e.Status, http.StatusText(e.Status))
+	}
+
+	return fmt.Sprintf("server answered %d %s: %s", e.Status, http.StatusText(e.Status), e.Message)
+}
+
+// httpBackend the commands sent to a running server
+type httpBackend struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+}
+
+func newHTTPBackend(cfg config.ClientConfig) *httpBackend {
+	return &httpBackend{
+		baseURL: strings.TrimSuffix(cfg.BaseURL, "/") + apiPrefix,
+		apiKey:  cfg.APIKey,
+		client:  &http.Client{Timeout: time.Duration(cfg.Timeout) * time.Second},
+	}
+}
+
+func (h *httpBackend) GetAllActivity(ctx context.Context, filter activity.FilterActivity) ([]activity.Activity, error) {
+	query := url.Values{}
+	if filter.Email != "" {
+		query.Set("email", filter.Email)
+	}
+
+	var data []activity.Activity
+	err := h.do(ctx, http.MethodGet, "/activity-groups", query, nil, &data)
+	return data, err
+}
+
+func (h *httpBackend) CreateActivity(ctx context.Context, req activity.CreateActivity) (activity.Activity, error) {
+	var data activity.Activity
+	err := h.do(ctx, http.MethodPost, "/activity-groups", nil, req, &data)
+	return data, err
+}
+
+func (h *httpBackend) UpdateActivity(ctx context.Context, id int, req activity.UpdateActivity) (activity.Activity, error) {
+	var data activity.Activity
+	err := h.do(ctx, http.MethodPut, fmt.Sprintf("/activity-groups/%d", id), nil, req, &data)
+	return data, err
+}
+
+func (h *httpBackend) DeleteActivity(ctx context.Context, id int) error {
+	return h.do(ctx, http.MethodDelete, fmt.Sprintf("/activity-groups/%d", id), nil, nil, nil)
+}
+
+func (h *httpBackend) GetAllTodo(ctx context.Context, filter todo.FilterTodo) ([]todo.Todo, error) {
+	query := url.Values{}
+	if filter.ActivityGroupID != 0 {
+		query.Set("activity_group_id", strconv.Itoa(filter.ActivityGroupID))
+	}
+	if filter.IsActive != nil {
+		query.Set("is_active", strconv.FormatBool(*filter.IsActive))
+	}
+	if filter.Priority != "" {
+		query.Set("priority", filter.Priority)
+	}
+
+	var data []todo.Todo
+	err := h.do(ctx, http.MethodGet, "/todo-items", query, nil, &data)
+	return data, err
+}
+
+func (h *httpBackend) GetOneTodo(ctx context.Context, id int) (todo.Todo, error) {
+	var data todo.Todo
+	err := h.do(ctx, http.MethodGet, fmt.Sprintf("/todo-items/%d", id), nil, nil, &data)
+	return data, err
+}
+
+func (h *httpBackend) CreateTodo(ctx context.Context, req todo.CreateTodo) (todo.Todo, error) {
+	var data todo.Todo
+	err := h.do(ctx, http.MethodPost, "/todo-items", nil, req, &data)
+	return data, err
+}
+
+func (h *httpBackend) UpdateTodo(ctx context.Context, id int, req todo.UpdateTodo) (todo.Todo, error) {
+	var data todo.Todo
+	err := h.do(ctx, http.MethodPut, fmt.Sprintf("/todo-items/%d", id), nil, req, &data)
+	return data, err
+}
+
+func (h *httpBackend) DeleteTodo(ctx context.Context, id int) error {
+	return h.do(ctx, http.MethodDelete, fmt.Sprintf("/todo-items/%d", id), nil, nil, nil)
+}
+
+func (h *httpBackend) Close() error {
+	h.client.CloseIdleConnections()
+	return nil
+}
+
+// do send a request with body encoded as JSON, decoding the data of the
+// response envelope into out
+func (h *httpBackend) do(ctx context.Context, method, path string, query url.Values, body, out interface{}) error {
+	target := h.baseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if h.apiKey != "" {
+		req.Header.Set(auth.HeaderAPIKey, h.apiKey)
+	}
+
+	res, err := h.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	payload, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode >= http.StatusBadRequest {
+		var failure struct {
+			Message string `json:"message"`
+		}
+		_ = json.Unmarshal(payload, &failure)
+		return &APIError{Status: res.StatusCode, Message: failure.Message}
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	envelope := struct {
+		Data interface{} `json:"data"`
+	}{Data: out}
+	if err := json.Unmarshal(payload, &envelope); err != nil {
+		return fmt.Errorf("decode the response of %s %s: %w", method, path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,82 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// OutputTable aligned columns for a terminal
+	OutputTable = "table"
+	// OutputJSON the objects of the API as JSON
+	OutputJSON = "json"
+	// OutputYAML the objects of the API as YAML, with the keys of the JSON
+	OutputYAML = "yaml"
+)
+
+// table the header and the rows of a table output
+type table struct {
+	header []string
+	rows   [][]string
+}
+
+// render write data in format, the table being the rows of data for the table
+// output
+func render(w io.Writer, format string, data interface{}, t table) error {
+	switch format {
+	case OutputJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(data)
+	case OutputYAML:
+		return printYAML(w, data)
+	default:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, strings.Join(t.header, "\t"))
+		for _, row := range t.rows {
+			fmt.Fprintln(tw, strings.Join(row, "\t"))
+		}
+		return tw.Flush()
+	}
+}
+
+// printYAML write data as YAML going through its JSON, so the keys and their
+// order are the ones of the API
+func printYAML(w io.Writer, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(payload, &doc); err != nil {
+		return err
+	}
+	blockStyle(&doc)
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return err
+	}
+
+	return enc.Close()
+}
+
+// blockStyle drop the flow style the nodes read from JSON have
+func blockStyle(n *yaml.Node) {
+	n.Style = 0
+	for _, child := range n.Content {
+		blockStyle(child)
+	}
+}
+
+// validOutput tell whether format is one of the outputs
+func validOutput(format string) bool {
+	return format == OutputTable || format == OutputJSON || format == OutputYAML
+}
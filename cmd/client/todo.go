@@ -0,0 +1,252 @@
+package client
+
+import (
+	"fmt"
+	"strconv"
+	"todolist-api/objects/todo"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	statusAll    = "all"
+	statusActive = "active"
+	statusDone   = "done"
+)
+
+var (
+	todoAddCMD = &cobra.Command{
+		Use:     "add <title>",
+		Aliases: []string{"create"},
+		Short:   "Add a todo to an activity group",
+		Args:    cobra.ExactArgs(1),
+		RunE:    runTodoAdd,
+	}
+
+	todoListCMD = &cobra.Command{
+		Use:     "ls",
+		Aliases: []string{"list"},
+		Short:   "List the todos",
+		Args:    cobra.NoArgs,
+		RunE:    runTodoList,
+	}
+
+	todoDoneCMD = &cobra.Command{
+		Use:   "done <id>...",
+		Short: "Mark todos as done",
+		Args:  cobra.MinimumNArgs(1),
+		RunE:  runTodoDone,
+	}
+
+	todoEditCMD = &cobra.Command{
+		Use:   "edit <id>",
+		Short: "Change the fields of a todo given as flags",
+		Long:  "Change the fields of a todo given as flags, the others are kept. An empty --due or --rrule removes them.",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runTodoEdit,
+	}
+
+	todoRemoveCMD = &cobra.Command{
+		Use:     "rm <id>...",
+		Aliases: []string{"delete"},
+		Short:   "Delete todos",
+		Args:    cobra.MinimumNArgs(1),
+		RunE:    runTodoRemove,
+	}
+)
+
+func init() {
+	todoAddCMD.Flags().IntP("activity-group", "g", 0, "id of the activity group of the todo")
+	todoAddCMD.Flags().StringP("priority", "p", "", "very-high, high, normal, low or very-low, very-high by default")
+	todoAddCMD.Flags().String("due", "", "due date, RFC 3339 such as 2023-07-01T09:00:00Z")
+	todoAddCMD.Flags().String("rrule", "", "recurrence rule repeating the todo from its due date, such as FREQ=WEEKLY;BYDAY=MO")
+	_ = todoAddCMD.MarkFlagRequired("activity-group")
+
+	todoListCMD.Flags().IntP("activity-group", "g", 0, "id of the activity group, every group by default")
+	todoListCMD.Flags().StringP("priority", "p", "", "only the todos of this priority")
+	todoListCMD.Flags().String("status", statusAll, "all, active or done")
+
+	todoEditCMD.Flags().String("title", "", "title of the todo")
+	todoEditCMD.Flags().StringP("priority", "p", "", "very-high, high, normal, low or very-low")
+	todoEditCMD.Flags().Bool("active", true, "false marks the todo as done, true reopens it")
+	todoEditCMD.Flags().String("due", "", "due date, RFC 3339 such as 2023-07-01T09:00:00Z")
+	todoEditCMD.Flags().String("rrule", "", "recurrence rule repeating the todo from its due date")
+
+	todoCMD.AddCommand(todoAddCMD)
+	todoCMD.AddCommand(todoListCMD)
+	todoCMD.AddCommand(todoDoneCMD)
+	todoCMD.AddCommand(todoEditCMD)
+	todoCMD.AddCommand(todoRemoveCMD)
+}
+
+func runTodoAdd(cmd *cobra.Command, args []string) error {
+	b, output, err := open(cmd)
+	if err != nil {
+		return err
+	}
+	defer b.Close()
+
+	req := todo.CreateTodo{
+		Title:    args[0],
+		IsActive: true,
+	}
+	req.ActivityGroupID, _ = cmd.Flags().GetInt("activity-group")
+	req.Priority, _ = cmd.Flags().GetString("priority")
+	req.DueAt, _ = cmd.Flags().GetString("due")
+	req.RRule, _ = cmd.Flags().GetString("rrule")
+
+	data, err := b.CreateTodo(cmd.Context(), req)
+	if err != nil {
+		return err
+	}
+
+	return render(cmd.OutOrStdout(), output, data, todoTable(data))
+}
+
+func runTodoList(cmd *cobra.Command, args []string) error {
+	var filter todo.FilterTodo
+	filter.ActivityGroupID, _ = cmd.Flags().GetInt("activity-group")
+	filter.Priority, _ = cmd.Flags().GetString("priority")
+
+	status, _ := cmd.Flags().GetString("status")
+	switch status {
+	case statusAll:
+	case statusActive, statusDone:
+		isActive := status == statusActive
+		filter.IsActive = &isActive
+	default:
+		return fmt.Errorf("status %q is not one of %s, %s or %s", status, statusAll, statusActive, statusDone)
+	}
+
+	b, output, err := open(cmd)
+	if err != nil {
+		return err
+	}
+	defer b.Close()
+
+	data, err := b.GetAllTodo(cmd.Context(), filter)
+	if err != nil {
+		return err
+	}
+
+	return render(cmd.OutOrStdout(), output, data, todoTable(data...))
+}
+
+func runTodoDone(cmd *cobra.Command, args []string) error {
+	ids, err := parseIDs(args)
+	if err != nil {
+		return err
+	}
+
+	b, output, err := open(cmd)
+	if err != nil {
+		return err
+	}
+	defer b.Close()
+
+	data := make([]todo.Todo, 0, len(ids))
+	for _, id := range ids {
+		current, err := b.GetOneTodo(cmd.Context(), id)
+		if err != nil {
+			return err
+		}
+
+		updated, err := b.UpdateTodo(cmd.Context(), id, todo.UpdateTodo{
+			Title:    current.Title,
+			IsActive: false,
+			Priority: current.Priority,
+		})
+		if err != nil {
+			return err
+		}
+		data = append(data, updated)
+	}
+
+	return render(cmd.OutOrStdout(), output, data, todoTable(data...))
+}
+
+func runTodoEdit(cmd *cobra.Command, args []string) error {
+	ids, err := parseIDs(args)
+	if err != nil {
+		return err
+	}
+
+	flags := cmd.Flags()
+	if !flags.Changed("title") && !flags.Changed("priority") && !flags.Changed("active") && !flags.Changed("due") && !flags.Changed("rrule") {
+		return errNoChange
+	}
+
+	b, output, err := open(cmd)
+	if err != nil {
+		return err
+	}
+	defer b.Close()
+
+	current, err := b.GetOneTodo(cmd.Context(), ids[0])
+	if err != nil {
+		return err
+	}
+
+	// the update replaces the title, the status and the priority, the flags
+	// missing keep the current ones
+	req := todo.UpdateTodo{
+		Title:    current.Title,
+		IsActive: current.IsActive,
+		Priority: current.Priority,
+	}
+	if flags.Changed("title") {
+		req.Title, _ = flags.GetString("title")
+	}
+	if flags.Changed("priority") {
+		req.Priority, _ = flags.GetString("priority")
+	}
+	if flags.Changed("active") {
+		req.IsActive, _ = flags.GetBool("active")
+	}
+	if flags.Changed("due") {
+		due, _ := flags.GetString("due")
+		req.DueAt = &due
+	}
+	if flags.Changed("rrule") {
+		rrule, _ := flags.GetString("rrule")
+		req.RRule = &rrule
+	}
+
+	data, err := b.UpdateTodo(cmd.Context(), ids[0], req)
+	if err != nil {
+		return err
+	}
+
+	return render(cmd.OutOrStdout(), output, data, todoTable(data))
+}
+
+func runTodoRemove(cmd *cobra.Command, args []string) error {
+	ids, err := parseIDs(args)
+	if err != nil {
+		return err
+	}
+
+	b, output, err := open(cmd)
+	if err != nil {
+		return err
+	}
+	defer b.Close()
+
+	return remove(cmd, output, ids, func(id int) error {
+		return b.DeleteTodo(cmd.Context(), id)
+	})
+}
+
+// todoTable the rows of the todos
+func todoTable(data ...todo.Todo) table {
+	t := table{header: []string{"ID", "GROUP", "TITLE", "PRIORITY", "DONE", "DUE"}}
+	for _, x := range data {
+		done := "no"
+		if !x.IsActive {
+			done = "yes"
+		}
+		t.rows = append(t.rows, []string{strconv.Itoa(x.ID), strconv.Itoa(x.ActivityGroupID), x.Title, x.Priority, done, x.DueAt})
+	}
+
+	return t
+}
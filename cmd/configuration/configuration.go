@@ -0,0 +1,69 @@
+package configuration
+
+import (
+	"fmt"
+	"os"
+	"todolist-api/config"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	configCMD = &cobra.Command{
+		Use:   "config",
+		Short: "Inspect the configuration",
+		Long:  "Inspect the configuration layered from the defaults, the config file, the TODOLIST_* environment variables and the flags",
+	}
+
+	printCMD = &cobra.Command{
+		Use:   "print",
+		Short: "Print the effective configuration, secrets redacted",
+		Long:  "Print the effective configuration as YAML, with the API keys and the database passwords redacted",
+		RunE:  runPrint,
+	}
+
+	validateCMD = &cobra.Command{
+		Use:   "validate",
+		Short: "Validate the configuration",
+		Long:  "Validate the configuration, listing every invalid setting",
+		RunE:  runValidate,
+	}
+)
+
+func init() {
+	configCMD.AddCommand(printCMD)
+	configCMD.AddCommand(validateCMD)
+}
+
+func runPrint(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		// an invalid config is worth printing too
+		if _, invalid := err.(config.ValidationError); !invalid {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "invalid configuration: %v\n", err)
+	}
+
+	if file := config.File(); file != "" {
+		fmt.Fprintf(cmd.OutOrStdout(), "# %s\n", file)
+	}
+
+	return cfg.Redacted().Print(cmd.OutOrStdout())
+}
+
+func runValidate(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+
+	if _, err := config.Load(); err != nil {
+		return err
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), "configuration is valid")
+
+	return nil
+}
+
+// ConfigCommand return instance of config command object
+func ConfigCommand() *cobra.Command {
+	return configCMD
+}
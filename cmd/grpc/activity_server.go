@@ -0,0 +1,72 @@
+package grpc
+
+import (
+	"context"
+	"todolist-api/infra/context/service"
+	"todolist-api/objects/activity"
+	todolistv1 "todolist-api/proto/todolist/v1"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+type activityServer struct {
+	todolistv1.UnimplementedActivityServiceServer
+	*service.Ctx
+}
+
+func (a activityServer) CreateActivity(ctx context.Context, req *todolistv1.CreateActivityRequest) (*todolistv1.Activity, error) {
+	data, err := a.ActivityService.CreateActivity(ctx, activity.CreateActivity{
+		Title: req.GetTitle(),
+		Email: req.GetEmail(),
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toActivity(data), nil
+}
+
+func (a activityServer) GetActivity(ctx context.Context, req *todolistv1.GetActivityRequest) (*todolistv1.Activity, error) {
+	data, err := a.ActivityService.GetOneActivity(ctx, int(req.GetId()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toActivity(data), nil
+}
+
+func (a activityServer) ListActivities(ctx context.Context, req *todolistv1.ListActivitiesRequest) (*todolistv1.ListActivitiesResponse, error) {
+	data, err := a.ActivityService.GetAllActivity(ctx, activity.FilterActivity{
+		Email: req.GetEmail(),
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	res := &todolistv1.ListActivitiesResponse{}
+	for _, x := range data {
+		res.Activities = append(res.Activities, toActivity(x))
+	}
+
+	return res, nil
+}
+
+func (a activityServer) UpdateActivity(ctx context.Context, req *todolistv1.UpdateActivityRequest) (*todolistv1.Activity, error) {
+	data, err := a.ActivityService.UpdateActivity(ctx, int(req.GetId()), activity.UpdateActivity{
+		Title: req.GetTitle(),
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toActivity(data), nil
+}
+
+func (a activityServer) DeleteActivity(ctx context.Context, req *todolistv1.DeleteActivityRequest) (*emptypb.Empty, error) {
+	err := a.ActivityService.DeleteActivity(ctx, int(req.GetId()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
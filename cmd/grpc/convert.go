@@ -0,0 +1,42 @@
+package grpc
+
+import (
+	"time"
+	"todolist-api/constants"
+	"todolist-api/objects/activity"
+	"todolist-api/objects/todo"
+	todolistv1 "todolist-api/proto/todolist/v1"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func toTimestamp(value string) *timestamppb.Timestamp {
+	t, err := time.Parse(constants.DateTimeFormat, value)
+	if err != nil {
+		return nil
+	}
+
+	return timestamppb.New(t)
+}
+
+func toActivity(data activity.Activity) *todolistv1.Activity {
+	return &todolistv1.Activity{
+		Id:        int64(data.ID),
+		Title:     data.Title,
+		Email:     data.Email,
+		CreatedAt: toTimestamp(data.CreatedAt),
+		UpdatedAt: toTimestamp(data.UpdatedAt),
+	}
+}
+
+func toTodo(data todo.Todo) *todolistv1.Todo {
+	return &todolistv1.Todo{
+		Id:              int64(data.ID),
+		Title:           data.Title,
+		ActivityGroupId: int64(data.ActivityGroupID),
+		IsActive:        data.IsActive,
+		Priority:        data.Priority,
+		CreatedAt:       toTimestamp(data.CreatedAt),
+		UpdatedAt:       toTimestamp(data.UpdatedAt),
+	}
+}
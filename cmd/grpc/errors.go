@@ -0,0 +1,63 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"todolist-api/constants"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// invalidArguments the errors about the request rather than the server
+var invalidArguments = []error{
+	constants.ErrTitleCannotBeNull,
+	constants.ErrActivityGroupRequired,
+	constants.ErrDueAtInvalid,
+	constants.ErrRRuleInvalid,
+	constants.ErrRRuleWithoutDueAt,
+	constants.ErrBulkOperationInvalid,
+	constants.ErrBulkModeInvalid,
+	constants.ErrBulkEmpty,
+	constants.ErrBulkTooManyItems,
+	constants.ErrBulkNothingToSet,
+}
+
+// toStatus map a service error to the matching gRPC status
+func toStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	msg := err.Error()
+	switch {
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, msg)
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, msg)
+	case errors.Is(err, constants.ErrNotFound):
+		return status.Error(codes.NotFound, msg)
+	case invalidArgument(err):
+		return status.Error(codes.InvalidArgument, msg)
+	case strings.Contains(msg, "forbidden"):
+		return status.Error(codes.PermissionDenied, msg)
+	case errors.Is(err, constants.ErrQuotaExceeded):
+		return status.Error(codes.ResourceExhausted, msg)
+	case strings.Contains(msg, constants.ErrBeginTransaction.Error()):
+		return status.Error(codes.Unavailable, msg)
+	}
+
+	return status.Error(codes.Internal, msg)
+}
+
+// invalidArgument tell whether err is about the request
+func invalidArgument(err error) bool {
+	for _, target := range invalidArguments {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
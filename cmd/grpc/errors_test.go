@@ -0,0 +1,56 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"todolist-api/constants"
+	infraErrors "todolist-api/infra/errors"
+	"todolist-api/utils"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestToStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		code codes.Code
+	}{
+		{"canceled", fmt.Errorf("get todo: %w", context.Canceled), codes.Canceled},
+		{"deadline", context.DeadlineExceeded, codes.DeadlineExceeded},
+		{"not found", infraErrors.Wrap(utils.ErrDataNotFound(3)), codes.NotFound},
+		{"title", infraErrors.Wrap(constants.ErrTitleCannotBeNull), codes.InvalidArgument},
+		{"activity group", infraErrors.Wrap(constants.ErrActivityGroupRequired), codes.InvalidArgument},
+		{"due at", infraErrors.Wrap(constants.ErrDueAtInvalid), codes.InvalidArgument},
+		{"rrule", infraErrors.Wrap(constants.ErrRRuleInvalid), codes.InvalidArgument},
+		{"rrule without due at", infraErrors.Wrap(constants.ErrRRuleWithoutDueAt), codes.InvalidArgument},
+		{"bulk operation", constants.ErrBulkOperationInvalid, codes.InvalidArgument},
+		{"bulk mode", constants.ErrBulkModeInvalid, codes.InvalidArgument},
+		{"bulk empty", constants.ErrBulkEmpty, codes.InvalidArgument},
+		{"bulk too many items", constants.ErrBulkTooManyItems, codes.InvalidArgument},
+		{"bulk nothing to set", constants.ErrBulkNothingToSet, codes.InvalidArgument},
+		{"forbidden", errors.New("forbidden"), codes.PermissionDenied},
+		{"quota", infraErrors.Wrap(constants.ErrQuotaExceeded), codes.ResourceExhausted},
+		{"begin", infraErrors.Wrap(constants.ErrBeginTransaction), codes.Unavailable},
+		{"other", errors.New("connection reset"), codes.Internal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := toStatus(tt.err)
+			if code := status.Code(err); code != tt.code {
+				t.Fatalf("code %s, expected %s", code, tt.code)
+			}
+			if msg := status.Convert(err).Message(); msg != tt.err.Error() {
+				t.Fatalf("message %q, expected %q", msg, tt.err.Error())
+			}
+		})
+	}
+
+	if err := toStatus(nil); err != nil {
+		t.Fatalf("nil mapped to %v", err)
+	}
+}
@@ -0,0 +1,174 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+	"todolist-api/config"
+	"todolist-api/infra/context/repository"
+	"todolist-api/infra/context/service"
+	"todolist-api/infra/db"
+	"todolist-api/infra/events"
+	"todolist-api/infra/lifecycle"
+	"todolist-api/infra/logger"
+	"todolist-api/infra/metrics"
+	"todolist-api/infra/ratelimit"
+	"todolist-api/infra/tracing"
+	todolistv1 "todolist-api/proto/todolist/v1"
+
+	"github.com/spf13/cobra"
+	gogrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+const defaultAddr = ":9090"
+
+var (
+	grpcCMD = &cobra.Command{
+		Use:   "serve-grpc",
+		Short: "Run grpc server",
+		Long:  "gRPC API Todolist",
+		RunE:  runGRPC,
+	}
+)
+
+func init() {
+	grpcCMD.Flags().String("addr", "", "address of the gRPC API, overrides server.grpcAddr")
+	grpcCMD.Flags().String("log-level", "", "log level, overrides log.level")
+}
+
+// NewServer create a gRPC server exposing the activity and todo services,
+// along with the health and reflection services
+func NewServer(serviceCtx *service.Ctx, broker *events.Broker, authCfg config.AuthConfig) *gogrpc.Server {
+	srv := gogrpc.NewServer(
+		gogrpc.ChainUnaryInterceptor(unaryAuth(authCfg)),
+		gogrpc.ChainStreamInterceptor(streamAuth(authCfg)),
+	)
+
+	todolistv1.RegisterActivityServiceServer(srv, activityServer{Ctx: serviceCtx})
+	todolistv1.RegisterTodoServiceServer(srv, todoServer{Ctx: serviceCtx, broker: broker})
+
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	healthServer.SetServingStatus(todolistv1.ActivityService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthServer.SetServingStatus(todolistv1.TodoService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(srv, healthServer)
+
+	reflection.Register(srv)
+
+	return srv
+}
+
+// Shutdown stop the server gracefully, streams still open once ctx is done are cut
+func Shutdown(ctx context.Context, srv *gogrpc.Server) {
+	stopped := make(chan struct{})
+	go func() {
+		srv.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		srv.Stop()
+	}
+}
+
+// Addr return the configured gRPC address
+func Addr(cfg config.ServerConfig) string {
+	if cfg.GRPCAddr == "" {
+		return defaultAddr
+	}
+
+	return cfg.GRPCAddr
+}
+
+func runGRPC(cmd *cobra.Command, args []string) error {
+	// errors past this point aren't about the usage
+	cmd.SilenceUsage = true
+
+	// flags override the file and the environment
+	config.BindFlag("server.grpcAddr", cmd.Flags().Lookup("addr"))
+	config.BindFlag("log.level", cmd.Flags().Lookup("log-level"))
+	cfg := config.InitConfig()
+	if err := logger.Configure(cfg.Log); err != nil {
+		return err
+	}
+
+	// stops the servers, then closes the database on SIGINT or SIGTERM
+	lc := lifecycle.New(time.Duration(cfg.Server.GraceFulTimeout) * time.Second)
+
+	// prometheus collectors, served on the admin listener when configured
+	m := metrics.New()
+
+	// tracer provider, flushed on shutdown
+	shutdownTracing, err := tracing.Init(context.Background(), cfg.Tracing)
+	if err != nil {
+		return lc.Fail(err)
+	}
+	lc.OnClose("tracing", shutdownTracing)
+
+	db, err := db.Open(&cfg.DB, m.DBHooks())
+	if err != nil {
+		return lc.Fail(err)
+	}
+	lc.OnClose("database", func(context.Context) error {
+		return db.Close()
+	})
+
+	broker := events.NewBroker(cfg.Events.BufferSize, events.NewLocalFanOut())
+	repoCtx := repository.NewRepoCtx(db, broker)
+	todoQuota := ratelimit.NewQuota(ratelimit.NewStore(cfg.RateLimit), "todo", func() int {
+		return cfg.RateLimit.TodoDailyQuota
+	})
+	serviceCtx := service.Trace(service.Instrument(service.Limit(service.NewCtx(repoCtx), todoQuota), m))
+	m.Register(metrics.NewDBStatsCollector(db.Stats))
+
+	// bound before serving anything, so a taken address fails the startup
+	lis, err := net.Listen("tcp", Addr(cfg.Server))
+	if err != nil {
+		return lc.Fail(err)
+	}
+
+	if cfg.Server.AdminAddr != "" {
+		adminLis, err := net.Listen("tcp", cfg.Server.AdminAddr)
+		if err != nil {
+			_ = lis.Close()
+			return lc.Fail(err)
+		}
+
+		adminMux := http.NewServeMux()
+		adminMux.Handle("/metrics", m.Handler())
+		adminSrv := &http.Server{
+			Handler:           adminMux,
+			ReadHeaderTimeout: time.Duration(cfg.Server.ReadHeaderTimeout) * time.Second,
+		}
+		lc.Serve("admin server", func() error {
+			return adminSrv.Serve(adminLis)
+		})
+		lc.OnStop("admin server", adminSrv.Shutdown)
+	}
+
+	srv := NewServer(serviceCtx, broker, cfg.Auth)
+	lc.Serve("grpc server", func() error {
+		return srv.Serve(lis)
+	})
+	lc.OnStop("grpc server", func(ctx context.Context) error {
+		Shutdown(ctx, srv)
+		return nil
+	})
+
+	fmt.Printf("gRPC Listening on %s", lis.Addr())
+
+	// blocks until SIGINT or SIGTERM and the end of the shutdown
+	return lc.Run()
+}
+
+// ServeGRPC return instance of serve gRPC command object
+func ServeGRPC() *cobra.Command {
+	return grpcCMD
+}
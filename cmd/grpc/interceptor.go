@@ -0,0 +1,79 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+	"todolist-api/config"
+	"todolist-api/infra/auth"
+
+	gogrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// authenticated wrap a server stream to expose the authenticated context
+type authenticated struct {
+	gogrpc.ServerStream
+	ctx context.Context
+}
+
+func (a authenticated) Context() context.Context {
+	return a.ctx
+}
+
+// unaryAuth authenticate unary calls with the api-key metadata, health and
+// reflection calls are left open for probes and tooling
+func unaryAuth(cfg config.AuthConfig) gogrpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *gogrpc.UnaryServerInfo, handler gogrpc.UnaryHandler) (interface{}, error) {
+		if public(info.FullMethod) {
+			return handler(ctx, req)
+		}
+
+		ctx, err := authenticate(ctx, cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// streamAuth authenticate streaming calls with the api-key metadata
+func streamAuth(cfg config.AuthConfig) gogrpc.StreamServerInterceptor {
+	return func(srv interface{}, ss gogrpc.ServerStream, info *gogrpc.StreamServerInfo, handler gogrpc.StreamHandler) error {
+		if public(info.FullMethod) {
+			return handler(srv, ss)
+		}
+
+		ctx, err := authenticate(ss.Context(), cfg)
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, authenticated{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func authenticate(ctx context.Context, cfg config.AuthConfig) (context.Context, error) {
+	var key string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(strings.ToLower(auth.HeaderAPIKey)); len(values) > 0 {
+			key = values[0]
+		} else if values := md.Get("authorization"); len(values) > 0 {
+			key = strings.TrimPrefix(values[0], "Bearer ")
+		}
+	}
+
+	user, ok := auth.Authenticate(cfg, key)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "invalid or missing API key")
+	}
+
+	return auth.WithUser(ctx, user), nil
+}
+
+func public(method string) bool {
+	return strings.HasPrefix(method, "/grpc.health.v1.Health/") ||
+		strings.HasPrefix(method, "/grpc.reflection.")
+}
@@ -0,0 +1,196 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"todolist-api/infra/auth"
+	"todolist-api/infra/context/service"
+	"todolist-api/infra/events"
+	"todolist-api/objects/activity"
+	"todolist-api/objects/todo"
+	todolistv1 "todolist-api/proto/todolist/v1"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const eventReset = "reset"
+
+type todoServer struct {
+	todolistv1.UnimplementedTodoServiceServer
+	*service.Ctx
+	broker *events.Broker
+}
+
+func (t todoServer) CreateTodo(ctx context.Context, req *todolistv1.CreateTodoRequest) (*todolistv1.Todo, error) {
+	data, err := t.TodoService.CreateTodo(ctx, todo.CreateTodo{
+		Title:           req.GetTitle(),
+		ActivityGroupID: int(req.GetActivityGroupId()),
+		IsActive:        req.GetIsActive(),
+		Priority:        req.GetPriority(),
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toTodo(data), nil
+}
+
+func (t todoServer) GetTodo(ctx context.Context, req *todolistv1.GetTodoRequest) (*todolistv1.Todo, error) {
+	data, err := t.TodoService.GetOneTodo(ctx, int(req.GetId()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toTodo(data), nil
+}
+
+func (t todoServer) ListTodos(ctx context.Context, req *todolistv1.ListTodosRequest) (*todolistv1.ListTodosResponse, error) {
+	filter := todo.FilterTodo{
+		ActivityGroupID: int(req.GetActivityGroupId()),
+		Priority:        req.GetPriority(),
+	}
+	if req.IsActive != nil {
+		isActive := req.GetIsActive()
+		filter.IsActive = &isActive
+	}
+
+	data, err := t.TodoService.GetAllTodo(ctx, filter)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	res := &todolistv1.ListTodosResponse{}
+	for _, x := range data {
+		res.Todos = append(res.Todos, toTodo(x))
+	}
+
+	return res, nil
+}
+
+func (t todoServer) UpdateTodo(ctx context.Context, req *todolistv1.UpdateTodoRequest) (*todolistv1.Todo, error) {
+	data, err := t.TodoService.UpdateTodo(ctx, int(req.GetId()), todo.UpdateTodo{
+		Title:    req.GetTitle(),
+		IsActive: req.GetIsActive(),
+		Priority: req.GetPriority(),
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toTodo(data), nil
+}
+
+func (t todoServer) MoveTodo(ctx context.Context, req *todolistv1.MoveTodoRequest) (*todolistv1.Todo, error) {
+	data, err := t.TodoService.MoveTodo(ctx, int(req.GetId()), todo.MoveTodo{
+		ActivityGroupID: int(req.GetActivityGroupId()),
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toTodo(data), nil
+}
+
+func (t todoServer) DeleteTodo(ctx context.Context, req *todolistv1.DeleteTodoRequest) (*emptypb.Empty, error) {
+	err := t.TodoService.DeleteTodo(ctx, int(req.GetId()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (t todoServer) WatchTodos(req *todolistv1.WatchTodosRequest, stream todolistv1.TodoService_WatchTodosServer) error {
+	ctx := stream.Context()
+	user, ok := auth.FromContext(ctx)
+	if !ok {
+		return status.Error(codes.Unauthenticated, "invalid or missing API key")
+	}
+
+	groupID := int(req.GetActivityGroupId())
+	if groupID != 0 {
+		group, err := t.ActivityService.GetOneActivity(ctx, groupID)
+		if err != nil {
+			return toStatus(err)
+		}
+
+		if !user.CanAccess(group.Email) {
+			return status.Error(codes.PermissionDenied, fmt.Sprintf("access to activity group %d is forbidden", groupID))
+		}
+	}
+
+	sub, missed, resumed := t.broker.Subscribe(req.GetLastEventId())
+	defer sub.Close()
+
+	if !resumed {
+		if err := stream.Send(&todolistv1.TodoEvent{Type: eventReset, Reset_: true}); err != nil {
+			return err
+		}
+	}
+
+	filter := auth.NewEventFilter(user, func(groupID int) (string, error) {
+		group, err := t.ActivityService.GetOneActivity(ctx, groupID)
+		return group.Email, err
+	})
+	send := func(event events.Event) error {
+		if groupID != 0 && event.ActivityGroupID != groupID {
+			return nil
+		}
+
+		if groupID == 0 && !filter.Visible(event) {
+			return nil
+		}
+
+		msg := toTodoEvent(event)
+		if msg == nil {
+			return nil
+		}
+
+		return stream.Send(msg)
+	}
+
+	for _, event := range missed {
+		if err := send(event); err != nil {
+			return err
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-sub.C:
+			if !ok {
+				return status.Error(codes.Unavailable, "watcher fell behind, resume with last_event_id")
+			}
+
+			if err := send(event); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// toTodoEvent convert a broker event, events without a todo or activity payload are skipped
+func toTodoEvent(event events.Event) *todolistv1.TodoEvent {
+	msg := &todolistv1.TodoEvent{
+		Id:              event.ID,
+		Type:            event.Type,
+		ActivityGroupId: int64(event.ActivityGroupID),
+		CreatedAt:       timestamppb.New(event.CreatedAt),
+	}
+
+	switch data := event.Data.(type) {
+	case todo.Todo:
+		msg.Payload = &todolistv1.TodoEvent_Todo{Todo: toTodo(data)}
+	case activity.Activity:
+		msg.Payload = &todolistv1.TodoEvent_Activity{Activity: toActivity(data)}
+	default:
+		return nil
+	}
+
+	return msg
+}
@@ -7,12 +7,13 @@ import (
 	"strings"
 	"todolist-api/constants"
 	"todolist-api/infra/context/service"
+	"todolist-api/infra/idempotency"
+	"todolist-api/infra/logger"
 	"todolist-api/objects/activity"
 
 	"todolist-api/utils"
 
 	"github.com/gorilla/mux"
-	log "github.com/sirupsen/logrus"
 	"gopkg.in/validator.v2"
 )
 
@@ -24,14 +25,14 @@ func (a activityHandler) CreateActivity(w http.ResponseWriter, r *http.Request)
 	var req activity.CreateActivity
 	err := json.NewDecoder(r.Body).Decode(&req)
 	if err != nil {
-		log.Error(err)
+		logger.FromContext(r.Context()).Error(err)
 		res := utils.SetResponseErrJSON(http.StatusBadRequest, err.Error())
 		res.JSONErrResponse(w)
 		return
 	}
 
 	if err = validator.Validate(req); err != nil {
-		log.Error(err)
+		logger.FromContext(r.Context()).Error(err)
 		res := utils.SetResponseErrJSON(http.StatusBadRequest, err.Error())
 		res.JSONErrResponse(w)
 		return
@@ -40,22 +41,38 @@ func (a activityHandler) CreateActivity(w http.ResponseWriter, r *http.Request)
 	data, err := a.ActivityService.CreateActivity(r.Context(), req)
 	if err != nil {
 		if strings.Contains(err.Error(), constants.ErrTitleCannotBeNull.Error()) {
-			log.Error(err)
+			logger.FromContext(r.Context()).Error(err)
 			res := utils.SetResponseErrJSON(utils.MESSAGE_BAD_REQUEST, err.Error())
 			res.JSONErrResponse(w)
 			return
 		}
+		if strings.Contains(err.Error(), constants.ErrIdempotencyKeyReused.Error()) {
+			res := utils.SetResponseErrJSON(utils.MESSAGE_UNPROCESSABLE, err.Error())
+			res.JSONErrUnprocessableEntity(w)
+			return
+		}
+		if strings.Contains(err.Error(), constants.ErrIdempotencyKeyInUse.Error()) {
+			res := utils.SetResponseErrJSON(utils.MESSAGE_CONFLICT, err.Error())
+			res.JSONErrConflict(w)
+			return
+		}
 		res := utils.SetResponseErrJSON(utils.MESSAGE_INTERNAL_SERVER_ERR, err.Error())
 		res.JSONErrInternalServerResponse(w)
 		return
 	}
 
+	if idempotency.Replayed(r.Context()) {
+		w.Header().Set(idempotency.HeaderReplayed, "true")
+	}
+
 	res := utils.SetResponseJSON(utils.MESSAGE_SUCCESS, "Success", data)
 	res.JSONResponse(w)
 }
 
 func (a activityHandler) GetAllActivity(w http.ResponseWriter, r *http.Request) {
-	data, err := a.ActivityService.GetAllActivity(r.Context())
+	data, err := a.ActivityService.GetAllActivity(r.Context(), activity.FilterActivity{
+		Email: r.URL.Query().Get("email"),
+	})
 	if err != nil {
 		res := utils.SetResponseErrJSON(utils.MESSAGE_INTERNAL_SERVER_ERR, err.Error())
 		res.JSONErrInternalServerResponse(w)
@@ -71,12 +88,12 @@ func (a activityHandler) GetOneActivity(w http.ResponseWriter, r *http.Request)
 	id, err := strconv.Atoi(queryParamID)
 	if err != nil {
 		if strings.Contains(err.Error(), ":id") {
-			log.Error(err)
+			logger.FromContext(r.Context()).Error(err)
 			res := utils.SetResponseErrNotFound(utils.MESSAGE_NOT_FOUND, utils.ErrDataNotFound(":id").Error())
 			res.JSONErrNotFound(w)
 			return
 		}
-		log.Error(err)
+		logger.FromContext(r.Context()).Error(err)
 		res := utils.SetResponseErrJSON(utils.MESSAGE_BAD_REQUEST, err.Error())
 		res.JSONErrResponse(w)
 		return
@@ -85,7 +102,7 @@ func (a activityHandler) GetOneActivity(w http.ResponseWriter, r *http.Request)
 	data, err := a.ActivityService.GetOneActivity(r.Context(), id)
 	if err != nil {
 		if strings.Contains(err.Error(), "Not Found") {
-			log.Error(err)
+			logger.FromContext(r.Context()).Error(err)
 			res := utils.SetResponseErrNotFound(utils.MESSAGE_NOT_FOUND, err.Error())
 			res.JSONErrNotFound(w)
 			return
@@ -104,12 +121,12 @@ func (a activityHandler) UpdateActivity(w http.ResponseWriter, r *http.Request)
 	id, err := strconv.Atoi(queryParamID)
 	if err != nil {
 		if strings.Contains(err.Error(), ":id") {
-			log.Error(err)
+			logger.FromContext(r.Context()).Error(err)
 			res := utils.SetResponseErrNotFound(utils.MESSAGE_NOT_FOUND, utils.ErrDataNotFound(":id").Error())
 			res.JSONErrNotFound(w)
 			return
 		}
-		log.Error(err)
+		logger.FromContext(r.Context()).Error(err)
 		res := utils.SetResponseErrJSON(utils.MESSAGE_BAD_REQUEST, err.Error())
 		res.JSONErrResponse(w)
 		return
@@ -118,14 +135,14 @@ func (a activityHandler) UpdateActivity(w http.ResponseWriter, r *http.Request)
 	var req activity.UpdateActivity
 	err = json.NewDecoder(r.Body).Decode(&req)
 	if err != nil {
-		log.Error(err)
+		logger.FromContext(r.Context()).Error(err)
 		res := utils.SetResponseErrJSON(http.StatusBadRequest, err.Error())
 		res.JSONErrResponse(w)
 		return
 	}
 
 	if err = validator.Validate(req); err != nil {
-		log.Error(err)
+		logger.FromContext(r.Context()).Error(err)
 		res := utils.SetResponseErrJSON(http.StatusBadRequest, err.Error())
 		res.JSONErrResponse(w)
 		return
@@ -134,14 +151,14 @@ func (a activityHandler) UpdateActivity(w http.ResponseWriter, r *http.Request)
 	data, err := a.ActivityService.UpdateActivity(r.Context(), id, req)
 	if err != nil {
 		if strings.Contains(err.Error(), constants.ErrTitleCannotBeNull.Error()) {
-			log.Error(err)
+			logger.FromContext(r.Context()).Error(err)
 			res := utils.SetResponseErrJSON(utils.MESSAGE_BAD_REQUEST, err.Error())
 			res.JSONErrResponse(w)
 			return
 		}
 
 		if strings.Contains(err.Error(), "Not Found") {
-			log.Error(err)
+			logger.FromContext(r.Context()).Error(err)
 			res := utils.SetResponseErrNotFound(utils.MESSAGE_NOT_FOUND, err.Error())
 			res.JSONErrNotFound(w)
 			return
@@ -160,12 +177,12 @@ func (a activityHandler) DeleteActivity(w http.ResponseWriter, r *http.Request)
 	id, err := strconv.Atoi(queryParamID)
 	if err != nil {
 		if strings.Contains(err.Error(), ":id") {
-			log.Error(err)
+			logger.FromContext(r.Context()).Error(err)
 			res := utils.SetResponseErrNotFound(utils.MESSAGE_NOT_FOUND, utils.ErrDataNotFound(":id").Error())
 			res.JSONErrNotFound(w)
 			return
 		}
-		log.Error(err)
+		logger.FromContext(r.Context()).Error(err)
 		res := utils.SetResponseErrJSON(utils.MESSAGE_BAD_REQUEST, err.Error())
 		res.JSONErrResponse(w)
 		return
@@ -174,7 +191,7 @@ func (a activityHandler) DeleteActivity(w http.ResponseWriter, r *http.Request)
 	err = a.ActivityService.DeleteActivity(r.Context(), id)
 	if err != nil {
 		if strings.Contains(err.Error(), "Not Found") {
-			log.Error(err)
+			logger.FromContext(r.Context()).Error(err)
 			res := utils.SetResponseErrNotFound(utils.MESSAGE_NOT_FOUND, err.Error())
 			res.JSONErrNotFound(w)
 			return
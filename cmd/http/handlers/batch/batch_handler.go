@@ -0,0 +1,402 @@
+package batch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"todolist-api/config"
+	"todolist-api/infra/db"
+	"todolist-api/infra/events"
+	"todolist-api/infra/idempotency"
+	"todolist-api/infra/logger"
+	"todolist-api/objects/batch"
+	"todolist-api/utils"
+
+	"github.com/gorilla/mux"
+)
+
+var (
+	// reference the value of an earlier response, such as $1.id
+	reference = regexp.MustCompile(`\$([0-9]+)\.([A-Za-z0-9_]+(?:\.[A-Za-z0-9_]+)*)`)
+	// versionPrefix the version the route templates are mounted under
+	versionPrefix = regexp.MustCompile(`^/v[0-9]+`)
+
+	// errFailedDependency a reference to a request that failed
+	errFailedDependency = errors.New("depends on a request that failed")
+	// errRolledBack a request of an atomic batch failed
+	errRolledBack = errors.New("batch rolled back")
+)
+
+// methods the methods a request of a batch can use
+var methods = map[string]bool{
+	http.MethodGet:    true,
+	http.MethodPost:   true,
+	http.MethodPut:    true,
+	http.MethodPatch:  true,
+	http.MethodDelete: true,
+}
+
+type batchHandler struct {
+	db      *db.DB
+	current func() config.BatchConfig
+	router  *mux.Router
+	paths   map[string]bool
+}
+
+func (b *batchHandler) Mount(router *mux.Router, paths []string) {
+	b.router = router
+	b.paths = make(map[string]bool, len(paths))
+	for _, x := range paths {
+		b.paths[x] = true
+	}
+}
+
+func (b *batchHandler) ServeBatch(w http.ResponseWriter, r *http.Request) {
+	var req batch.Batch
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		logger.FromContext(r.Context()).Error(err)
+		res := utils.SetResponseErrJSON(http.StatusBadRequest, err.Error())
+		res.JSONErrResponse(w)
+		return
+	}
+
+	if err = b.validate(req); err != nil {
+		res := utils.SetResponseErrJSON(utils.MESSAGE_BAD_REQUEST, err.Error())
+		res.JSONErrResponse(w)
+		return
+	}
+
+	result := batch.Result{
+		Atomic:    req.Atomic,
+		Responses: make([]batch.Response, len(req.Requests)),
+	}
+
+	if !req.Atomic {
+		b.run(r, req.Requests, result.Responses, false)
+
+		res := utils.SetResponseJSON(utils.MESSAGE_SUCCESS, "Success", result)
+		res.JSONSuccessResponse(w)
+		return
+	}
+
+	// the live events wait for the commit of the whole batch
+	ctx, held := events.Defer(r.Context())
+	err = b.db.Scope(ctx, func(ctx context.Context) error {
+		if b.run(r.WithContext(ctx), req.Requests, result.Responses, true) {
+			return errRolledBack
+		}
+		return nil
+	})
+	switch {
+	case err == nil:
+		held.Flush(r.Context())
+	case errors.Is(err, errRolledBack):
+		held.Discard()
+		result.RolledBack = true
+	default:
+		held.Discard()
+		logger.FromContext(r.Context()).Error(err)
+		res := utils.SetResponseErrJSON(utils.MESSAGE_INTERNAL_SERVER_ERR, err.Error())
+		res.JSONErrInternalServerResponse(w)
+		return
+	}
+
+	res := utils.SetResponseJSON(utils.MESSAGE_SUCCESS, "Success", result)
+	res.JSONSuccessResponse(w)
+}
+
+func (b *batchHandler) validate(req batch.Batch) error {
+	if len(req.Requests) == 0 {
+		return errors.New("requests cannot be empty")
+	}
+
+	if max := b.current().MaxRequests; len(req.Requests) > max {
+		return fmt.Errorf("a batch has at most %d requests", max)
+	}
+
+	for i, x := range req.Requests {
+		if !methods[strings.ToUpper(x.Method)] {
+			return fmt.Errorf("requests[%d].method: %q is not one of GET, POST, PUT, PATCH or DELETE", i, x.Method)
+		}
+		if !strings.HasPrefix(x.Path, "/") || strings.HasPrefix(x.Path, "//") {
+			return fmt.Errorf("requests[%d].path: must start with /", i)
+		}
+	}
+
+	return nil
+}
+
+// run dispatch the requests in order, recording their responses. With stop,
+// the first failure ends the batch and the remaining requests aren't run. It
+// tells whether a request failed.
+func (b *batchHandler) run(r *http.Request, reqs []batch.Request, res []batch.Response, stop bool) bool {
+	failed := false
+	for i, x := range reqs {
+		sub, err := b.request(r, x, res[:i])
+		switch {
+		case errors.Is(err, errFailedDependency):
+			res[i] = failure(http.StatusFailedDependency, err.Error())
+		case err != nil:
+			res[i] = failure(http.StatusBadRequest, err.Error())
+		default:
+			res[i] = b.dispatch(sub)
+		}
+
+		if res[i].Status < http.StatusBadRequest {
+			continue
+		}
+
+		failed = true
+		if stop {
+			for j := i + 1; j < len(reqs); j++ {
+				res[j] = failure(http.StatusFailedDependency, fmt.Sprintf("not run, request %d failed", i+1))
+			}
+			return true
+		}
+	}
+
+	return failed
+}
+
+// request build the request of x, resolving its references to the responses
+// done so far
+func (b *batchHandler) request(r *http.Request, x batch.Request, done []batch.Response) (*http.Request, error) {
+	path, err := substitute(x.Path, done, func(v interface{}) string {
+		return url.PathEscape(text(v))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := url.Parse(path)
+	if err != nil || u.IsAbs() || u.Host != "" {
+		return nil, fmt.Errorf("%s is not a path of the API", path)
+	}
+	if len(u.Path) > 1 {
+		u.Path = strings.TrimRight(u.Path, "/")
+	}
+
+	var body []byte
+	if len(x.Body) > 0 {
+		body, err = resolveBody(x.Body, done)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sub, err := http.NewRequestWithContext(r.Context(), strings.ToUpper(x.Method), u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	// the credentials of the batch, the key of the batch isn't the one of its requests
+	for k, v := range r.Header {
+		if k == "Content-Length" || k == idempotency.HeaderKey {
+			continue
+		}
+		sub.Header[k] = v
+	}
+	for k, v := range x.Headers {
+		sub.Header.Set(k, v)
+	}
+	if len(body) > 0 {
+		sub.Header.Set("Content-Type", "application/json")
+	}
+	sub.RemoteAddr = r.RemoteAddr
+	sub.Host = r.Host
+
+	var match mux.RouteMatch
+	if b.router.Match(sub, &match) && match.Route != nil {
+		template, _ := match.Route.GetPathTemplate()
+		if !b.paths[versionPrefix.ReplaceAllString(template, "")] {
+			return nil, fmt.Errorf("%s %s can't be part of a batch", sub.Method, u.Path)
+		}
+	}
+
+	return sub, nil
+}
+
+// dispatch serve the request through the router and record the response
+func (b *batchHandler) dispatch(sub *http.Request) batch.Response {
+	rec := &recorder{header: http.Header{}}
+	b.router.ServeHTTP(rec, sub)
+
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+
+	body := bytes.TrimSpace(rec.body.Bytes())
+	if len(body) > 0 && !json.Valid(body) {
+		body, _ = json.Marshal(string(body))
+	}
+
+	return batch.Response{Status: rec.status, Body: body}
+}
+
+// failure the response to a request that couldn't be dispatched
+func failure(status int, message string) batch.Response {
+	body, _ := json.Marshal(utils.SetResponseErrJSON(http.StatusText(status), message))
+
+	return batch.Response{Status: status, Body: body}
+}
+
+// resolveBody replace the references of the body. A string that is only a
+// reference takes the type of the value, such as a number for $1.id.
+func resolveBody(raw json.RawMessage, done []batch.Response) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, fmt.Errorf("body: %w", err)
+	}
+
+	v, err := resolve(v, done)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(v)
+}
+
+func resolve(v interface{}, done []batch.Response) (interface{}, error) {
+	switch x := v.(type) {
+	case map[string]interface{}:
+		for k, item := range x {
+			resolved, err := resolve(item, done)
+			if err != nil {
+				return nil, err
+			}
+			x[k] = resolved
+		}
+	case []interface{}:
+		for i, item := range x {
+			resolved, err := resolve(item, done)
+			if err != nil {
+				return nil, err
+			}
+			x[i] = resolved
+		}
+	case string:
+		if m := reference.FindStringSubmatch(x); m != nil && m[0] == x {
+			return lookup(m, done)
+		}
+		return substitute(x, done, text)
+	}
+
+	return v, nil
+}
+
+// substitute replace the references within s by the text of their values
+func substitute(s string, done []batch.Response, format func(v interface{}) string) (string, error) {
+	var err error
+	res := reference.ReplaceAllStringFunc(s, func(ref string) string {
+		v, e := lookup(reference.FindStringSubmatch(ref), done)
+		if e != nil {
+			if err == nil {
+				err = e
+			}
+			return ref
+		}
+		return format(v)
+	})
+
+	return res, err
+}
+
+// lookup return the value of a reference, m being its submatches: the
+// position of the request from 1, then the path of the field in the data of
+// its response
+func lookup(m []string, done []batch.Response) (interface{}, error) {
+	n, err := strconv.Atoi(m[1])
+	if err != nil || n < 1 || n > len(done) {
+		return nil, fmt.Errorf("%s: request %s isn't run before this one", m[0], m[1])
+	}
+
+	res := done[n-1]
+	if res.Status >= http.StatusBadRequest {
+		return nil, fmt.Errorf("%s: %w, request %d answered %d", m[0], errFailedDependency, n, res.Status)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(res.Body))
+	dec.UseNumber()
+
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, fmt.Errorf("%s: request %d has no JSON response", m[0], n)
+	}
+
+	// the fields are looked up in the data of the envelope
+	if envelope, ok := v.(map[string]interface{}); ok {
+		if data, ok := envelope["data"]; ok {
+			v = data
+		}
+	}
+
+	for _, field := range strings.Split(m[2], ".") {
+		switch x := v.(type) {
+		case map[string]interface{}:
+			item, ok := x[field]
+			if !ok {
+				return nil, fmt.Errorf("%s: the response of request %d has no %s", m[0], n, field)
+			}
+			v = item
+		case []interface{}:
+			idx, err := strconv.Atoi(field)
+			if err != nil || idx < 0 || idx >= len(x) {
+				return nil, fmt.Errorf("%s: the response of request %d has no item %s", m[0], n, field)
+			}
+			v = x[idx]
+		default:
+			return nil, fmt.Errorf("%s: the response of request %d has no %s", m[0], n, field)
+		}
+	}
+
+	return v, nil
+}
+
+// text the value of a reference within a string
+func text(v interface{}) string {
+	switch x := v.(type) {
+	case string:
+		return x
+	case json.Number:
+		return x.String()
+	}
+
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+// recorder hold the response of a request of a batch
+type recorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (r *recorder) Header() http.Header {
+	return r.header
+}
+
+func (r *recorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+}
+
+func (r *recorder) Write(p []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+
+	return r.body.Write(p)
+}
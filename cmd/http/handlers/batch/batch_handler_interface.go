@@ -0,0 +1,23 @@
+package batch
+
+import (
+	"net/http"
+	"todolist-api/config"
+	"todolist-api/infra/db"
+
+	"github.com/gorilla/mux"
+)
+
+type BatchHandlerInterface interface {
+	// Mount dispatch the requests of the batches through router, to the
+	// routes of paths only
+	Mount(router *mux.Router, paths []string)
+	ServeBatch(w http.ResponseWriter, r *http.Request)
+}
+
+func NewBatchHandler(database *db.DB, current func() config.BatchConfig) BatchHandlerInterface {
+	return &batchHandler{
+		db:      database,
+		current: current,
+	}
+}
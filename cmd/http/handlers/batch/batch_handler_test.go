@@ -0,0 +1,287 @@
+package batch_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"todolist-api/cmd/http/handlers/batch"
+	"todolist-api/config"
+	"todolist-api/infra/db"
+	"todolist-api/infra/db/dbtest"
+	"todolist-api/infra/events"
+	objects "todolist-api/objects/batch"
+
+	"github.com/gorilla/mux"
+)
+
+// server the routes a batch dispatches to, writing through the database of
+// the request like the services do
+type server struct {
+	d      *dbtest.Driver
+	db     *db.DB
+	broker *events.Broker
+	sub    *events.Subscription
+	router *mux.Router
+}
+
+func newServer(t *testing.T) *server {
+	t.Helper()
+
+	d := &dbtest.Driver{}
+	conn, err := db.Open(&config.DBConfig{Name: dbtest.Register(d), Host: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+
+	s := &server{d: d, db: conn, broker: events.NewBroker(0, nil), router: mux.NewRouter()}
+	s.sub, _, _ = s.broker.Subscribe(0)
+	t.Cleanup(s.sub.Close)
+
+	v1 := s.router.PathPrefix("/v1").Subrouter()
+	v1.HandleFunc("/items", s.createItem).Methods(http.MethodPost)
+	v1.HandleFunc("/items/{id}", s.getItem).Methods(http.MethodGet)
+	v1.HandleFunc("/fail", s.fail).Methods(http.MethodPost)
+	v1.HandleFunc("/events", s.events).Methods(http.MethodGet)
+
+	h := batch.NewBatchHandler(conn, func() config.BatchConfig { return config.BatchConfig{MaxRequests: 10} })
+	h.Mount(s.router, []string{"/items", "/items/{id}", "/fail", "/events"})
+	s.router.HandleFunc("/batch", h.ServeBatch).Methods(http.MethodPost)
+
+	return s
+}
+
+// createItem insert an item and answer it as item 7 tagged with its title,
+// along with the body it was sent
+func (s *server) createItem(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	var req struct {
+		Title string `json:"title"`
+	}
+	_ = json.Unmarshal(body, &req)
+
+	tx, err := s.db.Begin(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if _, err := tx.ExecContext(r.Context(), "INSERT INTO items (title) VALUES (?)", req.Title); err != nil {
+		_ = tx.Rollback()
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.broker.Publish(r.Context(), events.Event{Type: "item.created", Data: req.Title})
+
+	write(w, http.StatusCreated, map[string]interface{}{
+		"id":   7,
+		"tags": []interface{}{map[string]interface{}{"name": req.Title}},
+		"body": json.RawMessage(body),
+	})
+}
+
+func (s *server) getItem(w http.ResponseWriter, r *http.Request) {
+	write(w, http.StatusOK, map[string]interface{}{"id": mux.Vars(r)["id"]})
+}
+
+// fail write then give up, rolling its write back
+func (s *server) fail(w http.ResponseWriter, r *http.Request) {
+	tx, err := s.db.Begin(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	_, _ = tx.ExecContext(r.Context(), "INSERT INTO failures (id) VALUES (?)", 1)
+	_ = tx.Rollback()
+
+	write(w, http.StatusBadRequest, "invalid")
+}
+
+// events answer the number of events delivered so far
+func (s *server) events(w http.ResponseWriter, _ *http.Request) {
+	write(w, http.StatusOK, len(s.sub.C))
+}
+
+func write(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+}
+
+func (s *server) batch(t *testing.T, req objects.Batch) objects.Result {
+	t.Helper()
+
+	body, _ := json.Marshal(req)
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/batch", bytes.NewReader(body)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", w.Code, w.Body)
+	}
+
+	var res struct {
+		Data objects.Result `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatal(err)
+	}
+
+	return res.Data
+}
+
+// data the data of the response to a request of a batch
+func data(t *testing.T, res objects.Response) interface{} {
+	t.Helper()
+
+	var v struct {
+		Data interface{} `json:"data"`
+	}
+	if err := json.Unmarshal(res.Body, &v); err != nil {
+		t.Fatalf("%s: %v", res.Body, err)
+	}
+
+	return v.Data
+}
+
+func statuses(res objects.Result) []int {
+	codes := make([]int, 0, len(res.Responses))
+	for _, x := range res.Responses {
+		codes = append(codes, x.Status)
+	}
+
+	return codes
+}
+
+func expectQueries(t *testing.T, d *dbtest.Driver, expected ...string) {
+	t.Helper()
+
+	queries := d.Queries()
+	if len(queries) != len(expected) {
+		t.Fatalf("%d statements, expected %d: %q", len(queries), len(expected), queries)
+	}
+	for i, query := range queries {
+		if !strings.HasPrefix(query, expected[i]) {
+			t.Fatalf("statement %d: %q, expected %s", i, query, expected[i])
+		}
+	}
+}
+
+func TestAtomicRollsBackTheSavepointOfTheFailure(t *testing.T) {
+	s := newServer(t)
+
+	res := s.batch(t, objects.Batch{Atomic: true, Requests: []objects.Request{
+		{Method: "POST", Path: "/v1/items", Body: json.RawMessage(`{"title":"milk"}`)},
+		{Method: "POST", Path: "/v1/fail"},
+		{Method: "POST", Path: "/v1/items", Body: json.RawMessage(`{"title":"eggs"}`)},
+	}})
+
+	if !res.RolledBack {
+		t.Fatal("expected the batch to be rolled back")
+	}
+	if codes := statuses(res); codes[0] != 201 || codes[1] != 400 || codes[2] != 424 {
+		t.Fatalf("statuses %v, expected [201 400 424]", codes)
+	}
+
+	// each request runs in its savepoint, the whole scope is rolled back
+	expectQueries(t, s.d,
+		"SAVEPOINT scope_1",
+		"INSERT INTO items",
+		"RELEASE SAVEPOINT scope_1",
+		"SAVEPOINT scope_2",
+		"INSERT INTO failures",
+		"ROLLBACK TO SAVEPOINT scope_2",
+		"ROLLBACK",
+	)
+
+	// the event of the first request is dropped along with its write
+	if n := len(s.sub.C); n != 0 {
+		t.Fatalf("%d events published by a rolled back batch", n)
+	}
+}
+
+func TestAtomicDefersTheEventsToTheCommit(t *testing.T) {
+	s := newServer(t)
+
+	res := s.batch(t, objects.Batch{Atomic: true, Requests: []objects.Request{
+		{Method: "POST", Path: "/v1/items", Body: json.RawMessage(`{"title":"milk"}`)},
+		{Method: "GET", Path: "/v1/events"},
+		{Method: "POST", Path: "/v1/items", Body: json.RawMessage(`{"title":"eggs"}`)},
+	}})
+
+	if res.RolledBack {
+		t.Fatalf("batch rolled back: %v", statuses(res))
+	}
+
+	// nothing was delivered while the batch ran
+	if n := data(t, res.Responses[1]); n != float64(0) {
+		t.Fatalf("%v events delivered before the commit", n)
+	}
+
+	expectQueries(t, s.d,
+		"SAVEPOINT scope_1",
+		"INSERT INTO items",
+		"RELEASE SAVEPOINT scope_1",
+		"SAVEPOINT scope_2",
+		"INSERT INTO items",
+		"RELEASE SAVEPOINT scope_2",
+		"COMMIT",
+	)
+
+	// the held events are published in order once committed
+	for _, title := range []string{"milk", "eggs"} {
+		select {
+		case e := <-s.sub.C:
+			if e.Type != "item.created" || e.Data != title {
+				t.Fatalf("event %+v, expected the creation of %s", e, title)
+			}
+		default:
+			t.Fatalf("no event for %s after the commit", title)
+		}
+	}
+}
+
+func TestReferences(t *testing.T) {
+	s := newServer(t)
+
+	res := s.batch(t, objects.Batch{Requests: []objects.Request{
+		{Method: "POST", Path: "/v1/items", Body: json.RawMessage(`{"title":"milk"}`)},
+		{Method: "GET", Path: "/v1/items/$1.id"},
+		{Method: "POST", Path: "/v1/items", Body: json.RawMessage(`{"parent":"$1.id","label":"tag $1.tags.0.name","ids":["$1.id"]}`)},
+		{Method: "GET", Path: "/v1/items/$1.missing"},
+		{Method: "POST", Path: "/v1/fail"},
+		{Method: "GET", Path: "/v1/items/$5.id"},
+		{Method: "GET", Path: "/v1/items/$9.id"},
+	}})
+
+	expected := []int{201, 200, 201, 400, 400, 424, 400}
+	if codes := statuses(res); len(codes) != len(expected) {
+		t.Fatalf("statuses %v, expected %v", codes, expected)
+	} else {
+		for i := range codes {
+			if codes[i] != expected[i] {
+				t.Fatalf("statuses %v, expected %v", codes, expected)
+			}
+		}
+	}
+
+	// a reference within the path is replaced by the text of the value
+	if item := data(t, res.Responses[1]).(map[string]interface{}); item["id"] != "7" {
+		t.Fatalf("GET /v1/items/$1.id answered %v", item)
+	}
+
+	// a string that is only a reference takes the type of the value
+	body := data(t, res.Responses[2]).(map[string]interface{})["body"]
+	sent, _ := json.Marshal(body)
+	if string(sent) != `{"ids":[7],"label":"tag milk","parent":7}` {
+		t.Fatalf("body sent %s", sent)
+	}
+}
@@ -0,0 +1,170 @@
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"todolist-api/infra/auth"
+	"todolist-api/infra/context/service"
+	"todolist-api/infra/events"
+	"todolist-api/utils"
+
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultHeartbeat = 15 * time.Second
+	retryInterval    = 3000
+
+	// eventReset tell the client its Last-Event-ID can't be resumed and it has to reload
+	eventReset = "reset"
+)
+
+type eventHandler struct {
+	*service.Ctx
+	broker    *events.Broker
+	heartbeat time.Duration
+}
+
+func (e eventHandler) StreamEvents(w http.ResponseWriter, r *http.Request) {
+	user, ok := auth.FromContext(r.Context())
+	if !ok {
+		res := utils.SetResponseErrJSON(utils.MESSAGE_UNAUTHORIZED, "invalid or missing API key")
+		res.JSONErrUnauthorized(w)
+		return
+	}
+
+	filter := auth.NewEventFilter(user, func(groupID int) (string, error) {
+		group, err := e.ActivityService.GetOneActivity(r.Context(), groupID)
+		return group.Email, err
+	})
+	e.stream(w, r, filter.Visible)
+}
+
+func (e eventHandler) StreamActivityEvents(w http.ResponseWriter, r *http.Request) {
+	user, ok := auth.FromContext(r.Context())
+	if !ok {
+		res := utils.SetResponseErrJSON(utils.MESSAGE_UNAUTHORIZED, "invalid or missing API key")
+		res.JSONErrUnauthorized(w)
+		return
+	}
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		log.Error(err)
+		res := utils.SetResponseErrJSON(utils.MESSAGE_BAD_REQUEST, err.Error())
+		res.JSONErrResponse(w)
+		return
+	}
+
+	data, err := e.ActivityService.GetOneActivity(r.Context(), id)
+	if err != nil {
+		if strings.Contains(err.Error(), "Not Found") {
+			log.Error(err)
+			res := utils.SetResponseErrNotFound(utils.MESSAGE_NOT_FOUND, err.Error())
+			res.JSONErrNotFound(w)
+			return
+		}
+		res := utils.SetResponseErrJSON(utils.MESSAGE_INTERNAL_SERVER_ERR, err.Error())
+		res.JSONErrInternalServerResponse(w)
+		return
+	}
+
+	if !user.CanAccess(data.Email) {
+		res := utils.SetResponseErrJSON(utils.MESSAGE_FORBIDDEN, fmt.Sprintf("access to activity group %d is forbidden", id))
+		res.JSONErrForbidden(w)
+		return
+	}
+
+	e.stream(w, r, func(event events.Event) bool {
+		return event.ActivityGroupID == id
+	})
+}
+
+// stream write the events accepted by visible as server-sent events until the client goes away
+func (e eventHandler) stream(w http.ResponseWriter, r *http.Request, visible func(event events.Event) bool) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		res := utils.SetResponseErrJSON(utils.MESSAGE_INTERNAL_SERVER_ERR, "streaming is not supported")
+		res.JSONErrInternalServerResponse(w)
+		return
+	}
+
+	lastID, _ := strconv.ParseUint(r.Header.Get("Last-Event-ID"), 10, 64)
+	sub, missed, resumed := e.broker.Subscribe(lastID)
+	defer sub.Close()
+
+	// the stream outlives the server WriteTimeout
+	if err := utils.DisableWriteDeadline(w); err != nil {
+		log.Warn(err)
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := fmt.Fprintf(w, "retry: %d\n\n", retryInterval); err != nil {
+		return
+	}
+
+	if !resumed {
+		if _, err := fmt.Fprintf(w, "event: %s\ndata: {}\n\n", eventReset); err != nil {
+			return
+		}
+	}
+
+	for _, event := range missed {
+		if visible(event) {
+			if err := writeEvent(w, event); err != nil {
+				return
+			}
+		}
+	}
+	flusher.Flush()
+
+	ticker := time.NewTicker(e.heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-sub.C:
+			if !ok {
+				// dropped by the broker for being too slow, the client resumes with Last-Event-ID
+				return
+			}
+
+			if !visible(event) {
+				continue
+			}
+
+			if err := writeEvent(w, event); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-ticker.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func writeEvent(w http.ResponseWriter, event events.Event) error {
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+
+	return err
+}
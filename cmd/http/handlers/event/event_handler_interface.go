@@ -0,0 +1,25 @@
+package event
+
+import (
+	"net/http"
+	"time"
+	"todolist-api/infra/context/service"
+	"todolist-api/infra/events"
+)
+
+type EventHandlerInterface interface {
+	StreamEvents(w http.ResponseWriter, r *http.Request)
+	StreamActivityEvents(w http.ResponseWriter, r *http.Request)
+}
+
+func NewEventHandler(serviceCtx *service.Ctx, broker *events.Broker, heartbeat time.Duration) EventHandlerInterface {
+	if heartbeat <= 0 {
+		heartbeat = defaultHeartbeat
+	}
+
+	return &eventHandler{
+		Ctx:       serviceCtx,
+		broker:    broker,
+		heartbeat: heartbeat,
+	}
+}
@@ -0,0 +1,65 @@
+package event_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+	"todolist-api/cmd/http/handlers/event"
+	"todolist-api/infra/auth"
+	"todolist-api/infra/context/service"
+	"todolist-api/infra/events"
+)
+
+var eventIDs = regexp.MustCompile(`(?m)^id: (\d+)$`)
+
+func TestStreamResume(t *testing.T) {
+	broker := events.NewBroker(4, events.NewLocalFanOut())
+	for i := 0; i < 6; i++ {
+		broker.Publish(context.Background(), events.Event{Type: "todo.created", ActivityGroupID: 1})
+	}
+	h := event.NewEventHandler(&service.Ctx{}, broker, time.Second)
+
+	tests := []struct {
+		name        string
+		lastEventID string
+		ids         []string
+		reset       bool
+	}{
+		{"replays the events after it", "4", []string{"5", "6"}, false},
+		{"replays past the wrap of the ring", "2", []string{"3", "4", "5", "6"}, false},
+		{"resets when the events are gone", "1", nil, true},
+		{"resets on an unknown ID", "9", nil, true},
+		{"starts live without it", "", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// gone once the replay is written
+			ctx, cancel := context.WithCancel(auth.WithUser(context.Background(), auth.User{Admin: true}))
+			cancel()
+
+			req := httptest.NewRequest(http.MethodGet, "/events", nil).WithContext(ctx)
+			if tt.lastEventID != "" {
+				req.Header.Set("Last-Event-ID", tt.lastEventID)
+			}
+			w := httptest.NewRecorder()
+			h.StreamEvents(w, req)
+
+			body := w.Body.String()
+			var ids []string
+			for _, x := range eventIDs.FindAllStringSubmatch(body, -1) {
+				ids = append(ids, x[1])
+			}
+			if strings.Join(ids, ",") != strings.Join(tt.ids, ",") {
+				t.Fatalf("replayed %v, expected %v", ids, tt.ids)
+			}
+			if reset := strings.Contains(body, "event: reset\n"); reset != tt.reset {
+				t.Fatalf("reset %t, expected %t in %q", reset, tt.reset, body)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package graph
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+	"todolist-api/infra/context/service"
+	"todolist-api/utils"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	log "github.com/sirupsen/logrus"
+)
+
+type graphHandler struct {
+	*service.Ctx
+	schema    graphql.Schema
+	limits    limits
+	heartbeat time.Duration
+}
+
+type graphRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// ServeGraphQL execute queries and mutations, subscriptions are streamed back
+// as server-sent events with one "next" event per result
+func (g graphHandler) ServeGraphQL(w http.ResponseWriter, r *http.Request) {
+	var req graphRequest
+	if r.Method == http.MethodGet {
+		req.Query = r.URL.Query().Get("query")
+		req.OperationName = r.URL.Query().Get("operationName")
+		if v := r.URL.Query().Get("variables"); v != "" {
+			if err := json.Unmarshal([]byte(v), &req.Variables); err != nil {
+				writeResult(w, http.StatusBadRequest, errResult(err))
+				return
+			}
+		}
+	} else if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Error(err)
+		writeResult(w, http.StatusBadRequest, errResult(err))
+		return
+	}
+
+	doc, err := parser.Parse(parser.ParseParams{Source: req.Query})
+	if err != nil {
+		writeResult(w, http.StatusBadRequest, errResult(err))
+		return
+	}
+
+	if err := g.limits.check(doc, req.OperationName); err != nil {
+		writeResult(w, http.StatusBadRequest, errResult(err))
+		return
+	}
+
+	params := graphql.Params{
+		Schema:         g.schema,
+		RequestString:  req.Query,
+		VariableValues: req.Variables,
+		OperationName:  req.OperationName,
+		Context:        withLoaders(r.Context(), g.Ctx),
+	}
+
+	switch operationType(doc, req.OperationName) {
+	case ast.OperationTypeSubscription:
+		g.subscribe(w, r, params)
+		return
+	case ast.OperationTypeMutation:
+		if r.Method == http.MethodGet {
+			writeResult(w, http.StatusMethodNotAllowed, errResult(fmt.Errorf("mutations must be sent with %s", http.MethodPost)))
+			return
+		}
+	}
+
+	writeResult(w, http.StatusOK, graphql.Do(params))
+}
+
+func (g graphHandler) subscribe(w http.ResponseWriter, r *http.Request, params graphql.Params) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		res := utils.SetResponseErrJSON(utils.MESSAGE_INTERNAL_SERVER_ERR, "streaming is not supported")
+		res.JSONErrInternalServerResponse(w)
+		return
+	}
+
+	// the stream outlives the server WriteTimeout
+	if err := utils.DisableWriteDeadline(w); err != nil {
+		log.Warn(err)
+	}
+
+	results := graphql.Subscribe(params)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	ticker := time.NewTicker(g.heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case res, ok := <-results:
+			if !ok {
+				fmt.Fprint(w, "event: complete\ndata: {}\n\n")
+				flusher.Flush()
+				return
+			}
+
+			data, err := json.Marshal(res)
+			if err != nil {
+				log.Error(err)
+				return
+			}
+
+			if _, err := fmt.Fprintf(w, "event: next\ndata: %s\n\n", data); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-ticker.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// operationType return the type of the operation that will be executed
+func operationType(doc *ast.Document, operationName string) string {
+	for _, x := range doc.Definitions {
+		op, ok := x.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		if operationName == "" || (op.Name != nil && op.Name.Value == operationName) {
+			return op.Operation
+		}
+	}
+
+	return ast.OperationTypeQuery
+}
+
+func errResult(err error) *graphql.Result {
+	return &graphql.Result{
+		Errors: []gqlerrors.FormattedError{gqlerrors.FormatError(err)},
+	}
+}
+
+func writeResult(w http.ResponseWriter, status int, res *graphql.Result) {
+	if status == http.StatusMethodNotAllowed {
+		w.Header().Set("Allow", http.MethodPost)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		log.Error(err)
+	}
+}
@@ -0,0 +1,39 @@
+package graph
+
+import (
+	"net/http"
+	"time"
+	"todolist-api/config"
+	"todolist-api/infra/context/service"
+	"todolist-api/infra/events"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const defaultHeartbeat = 15 * time.Second
+
+type GraphHandlerInterface interface {
+	ServeGraphQL(w http.ResponseWriter, r *http.Request)
+}
+
+func NewGraphHandler(serviceCtx *service.Ctx, broker *events.Broker, cfg config.GraphQLConfig, heartbeat time.Duration) GraphHandlerInterface {
+	schema, err := newSchema(serviceCtx, broker)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	if heartbeat <= 0 {
+		heartbeat = defaultHeartbeat
+	}
+
+	return &graphHandler{
+		Ctx:    serviceCtx,
+		schema: schema,
+		limits: limits{
+			schema:        &schema,
+			maxDepth:      cfg.MaxDepth,
+			maxComplexity: cfg.MaxComplexity,
+		},
+		heartbeat: heartbeat,
+	}
+}
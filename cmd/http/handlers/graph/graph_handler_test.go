@@ -0,0 +1,193 @@
+package graph_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+	"todolist-api/cmd/http/handlers/graph"
+	activityService "todolist-api/cmd/services/activity"
+	todoService "todolist-api/cmd/services/todo"
+	"todolist-api/config"
+	"todolist-api/infra/context/service"
+	"todolist-api/infra/events"
+	"todolist-api/objects/activity"
+	"todolist-api/objects/todo"
+)
+
+// activities activity groups held in memory, recording the IDs of each batch
+type activities struct {
+	activityService.ActivityServiceInterface
+
+	data    []activity.Activity
+	batches [][]int
+}
+
+func (s *activities) GetAllActivity(context.Context, activity.FilterActivity) ([]activity.Activity, error) {
+	return s.data, nil
+}
+
+func (s *activities) GetActivityByIDs(_ context.Context, ids []int) (map[int]activity.Activity, error) {
+	s.batches = append(s.batches, sorted(ids))
+
+	res := map[int]activity.Activity{}
+	for _, x := range s.data {
+		res[x.ID] = x
+	}
+
+	return res, nil
+}
+
+// todos todos held in memory, recording the group IDs of each batch
+type todos struct {
+	todoService.TodoServiceInterface
+
+	data    []todo.Todo
+	batches [][]int
+}
+
+func (s *todos) GetAllTodo(context.Context, todo.FilterTodo) ([]todo.Todo, error) {
+	return s.data, nil
+}
+
+func (s *todos) GetTodoByActivityGroupIDs(_ context.Context, ids []int) (map[int][]todo.Todo, error) {
+	s.batches = append(s.batches, sorted(ids))
+
+	res := map[int][]todo.Todo{}
+	for _, x := range s.data {
+		res[x.ActivityGroupID] = append(res[x.ActivityGroupID], x)
+	}
+
+	return res, nil
+}
+
+func sorted(ids []int) []int {
+	res := append([]int(nil), ids...)
+	sort.Ints(res)
+
+	return res
+}
+
+type graphResponse struct {
+	Data   map[string]interface{} `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+func query(t *testing.T, h graph.GraphHandlerInterface, q string) (int, graphResponse) {
+	t.Helper()
+
+	body, _ := json.Marshal(map[string]string{"query": q})
+	req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(string(body)))
+	w := httptest.NewRecorder()
+	h.ServeGraphQL(w, req)
+
+	var res graphResponse
+	if err := json.NewDecoder(w.Body).Decode(&res); err != nil {
+		t.Fatal(err)
+	}
+
+	return w.Code, res
+}
+
+func TestLimits(t *testing.T) {
+	serviceCtx := &service.Ctx{ActivityService: &activities{}, TodoService: &todos{}}
+	h := graph.NewGraphHandler(serviceCtx, events.NewBroker(1, events.NewLocalFanOut()),
+		config.GraphQLConfig{MaxDepth: 3, MaxComplexity: 100}, time.Second)
+
+	tests := []struct {
+		name  string
+		query string
+		err   string
+	}{
+		{
+			name:  "within the limits",
+			query: `{ todos { id activity { title } } }`,
+		},
+		{
+			name:  "too deep",
+			query: `{ activities { todos { activity { id } } } }`,
+			err:   "query depth 4 exceeds the maximum of 3",
+		},
+		{
+			name:  "too deep through a fragment",
+			query: `{ activities { ...groups } } fragment groups on Activity { todos { activity { id } } }`,
+			err:   "query depth 4 exceeds the maximum of 3",
+		},
+		{
+			// 1 + 10 * (1 + 10 * 2), each list multiplying its selection
+			name:  "too complex",
+			query: `{ activities { todos { id title } } }`,
+			err:   "query complexity 211 exceeds the maximum of 100",
+		},
+		{
+			name:  "introspection left out",
+			query: `{ __schema { types { name fields { name type { name } } } } }`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, res := query(t, h, tt.query)
+
+			if tt.err == "" {
+				if status != http.StatusOK || len(res.Errors) > 0 {
+					t.Fatalf("status %d, errors %+v", status, res.Errors)
+				}
+				return
+			}
+
+			if status != http.StatusBadRequest {
+				t.Fatalf("status %d, expected %d", status, http.StatusBadRequest)
+			}
+			if len(res.Errors) != 1 || res.Errors[0].Message != tt.err {
+				t.Fatalf("errors %+v, expected %q", res.Errors, tt.err)
+			}
+		})
+	}
+}
+
+func TestLoaders(t *testing.T) {
+	groups := &activities{data: []activity.Activity{{ID: 1, Title: "home"}, {ID: 2, Title: "work"}, {ID: 3, Title: "shop"}}}
+	items := &todos{}
+	for i := 1; i <= 9; i++ {
+		items.data = append(items.data, todo.Todo{ID: i, Title: "todo", ActivityGroupID: i%3 + 1, IsActive: i%2 == 0})
+	}
+	serviceCtx := &service.Ctx{ActivityService: groups, TodoService: items}
+	h := graph.NewGraphHandler(serviceCtx, events.NewBroker(1, events.NewLocalFanOut()), config.GraphQLConfig{}, time.Second)
+
+	// the group of each of the 9 todos, fetched at once
+	status, res := query(t, h, `{ todos { id activity { title } } }`)
+	if status != http.StatusOK || len(res.Errors) > 0 {
+		t.Fatalf("status %d, errors %+v", status, res.Errors)
+	}
+	if expected := [][]int{{1, 2, 3}}; !reflect.DeepEqual(groups.batches, expected) {
+		t.Fatalf("activity groups loaded in %v, expected %v", groups.batches, expected)
+	}
+	for _, x := range res.Data["todos"].([]interface{}) {
+		if x.(map[string]interface{})["activity"] == nil {
+			t.Fatalf("todo without its group: %v", x)
+		}
+	}
+
+	// the todos of each of the 3 groups, fetched at once and shared by the counts
+	status, res = query(t, h, `{ activities { id todos { id } todoCount activeTodoCount } }`)
+	if status != http.StatusOK || len(res.Errors) > 0 {
+		t.Fatalf("status %d, errors %+v", status, res.Errors)
+	}
+	if expected := [][]int{{1, 2, 3}}; !reflect.DeepEqual(items.batches, expected) {
+		t.Fatalf("todos loaded in %v, expected %v", items.batches, expected)
+	}
+	for _, x := range res.Data["activities"].([]interface{}) {
+		group := x.(map[string]interface{})
+		if len(group["todos"].([]interface{})) != 3 || group["todoCount"] != float64(3) {
+			t.Fatalf("group %v, expected 3 todos", group)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package graph
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// listFactor the number of items a list field is assumed to return when
+// estimating the cost of its selection
+const listFactor = 10
+
+// limits reject queries nesting deeper than maxDepth or whose estimated cost,
+// one point per field with list selections multiplied by listFactor, exceeds
+// maxComplexity. A zero limit is disabled.
+type limits struct {
+	schema        *graphql.Schema
+	maxDepth      int
+	maxComplexity int
+}
+
+func (l limits) check(doc *ast.Document, operationName string) error {
+	var operation *ast.OperationDefinition
+	fragments := map[string]*ast.FragmentDefinition{}
+	for _, x := range doc.Definitions {
+		switch def := x.(type) {
+		case *ast.OperationDefinition:
+			if operationName == "" || (def.Name != nil && def.Name.Value == operationName) {
+				operation = def
+			}
+		case *ast.FragmentDefinition:
+			fragments[def.Name.Value] = def
+		}
+	}
+
+	// unknown operations are reported by the executor
+	if operation == nil {
+		return nil
+	}
+
+	var root *graphql.Object
+	switch operation.Operation {
+	case ast.OperationTypeMutation:
+		root = l.schema.MutationType()
+	case ast.OperationTypeSubscription:
+		root = l.schema.SubscriptionType()
+	default:
+		root = l.schema.QueryType()
+	}
+	if root == nil {
+		return nil
+	}
+
+	m := measure{schema: l.schema, fragments: fragments, spreading: map[string]bool{}}
+	depth, complexity := m.selectionSet(operation.SelectionSet, root, 1)
+
+	if l.maxDepth > 0 && depth > l.maxDepth {
+		return fmt.Errorf("query depth %d exceeds the maximum of %d", depth, l.maxDepth)
+	}
+	if l.maxComplexity > 0 && complexity > l.maxComplexity {
+		return fmt.Errorf("query complexity %d exceeds the maximum of %d", complexity, l.maxComplexity)
+	}
+
+	return nil
+}
+
+type measure struct {
+	schema    *graphql.Schema
+	fragments map[string]*ast.FragmentDefinition
+	spreading map[string]bool
+}
+
+// selectionSet return the depth and cost of the selections made on parent
+func (m measure) selectionSet(set *ast.SelectionSet, parent *graphql.Object, depth int) (int, int) {
+	if set == nil {
+		return depth, 0
+	}
+
+	maxDepth, cost := depth, 0
+	for _, x := range set.Selections {
+		d, c := depth, 0
+
+		switch sel := x.(type) {
+		case *ast.Field:
+			// introspection is left to the tooling
+			if strings.HasPrefix(sel.Name.Value, "__") {
+				continue
+			}
+
+			field, ok := parent.Fields()[sel.Name.Value]
+			if !ok {
+				continue
+			}
+
+			c = 1
+			if child, isList := namedObject(field.Type); child != nil && sel.SelectionSet != nil {
+				childDepth, childCost := m.selectionSet(sel.SelectionSet, child, depth+1)
+				if isList {
+					childCost *= listFactor
+				}
+				d, c = childDepth, c+childCost
+			}
+		case *ast.InlineFragment:
+			d, c = m.selectionSet(sel.SelectionSet, m.condition(sel.TypeCondition, parent), depth)
+		case *ast.FragmentSpread:
+			name := sel.Name.Value
+			fragment, ok := m.fragments[name]
+			if !ok || m.spreading[name] {
+				continue
+			}
+
+			m.spreading[name] = true
+			d, c = m.selectionSet(fragment.SelectionSet, m.condition(fragment.TypeCondition, parent), depth)
+			delete(m.spreading, name)
+		}
+
+		if d > maxDepth {
+			maxDepth = d
+		}
+		cost += c
+	}
+
+	return maxDepth, cost
+}
+
+// condition resolve the type a fragment applies to, defaulting to parent
+func (m measure) condition(named *ast.Named, parent *graphql.Object) *graphql.Object {
+	if named == nil {
+		return parent
+	}
+	if obj, ok := m.schema.Type(named.Name.Value).(*graphql.Object); ok {
+		return obj
+	}
+
+	return parent
+}
+
+// namedObject unwrap the list and non-null modifiers of t
+func namedObject(t graphql.Output) (*graphql.Object, bool) {
+	isList := false
+	for {
+		switch x := t.(type) {
+		case *graphql.List:
+			isList = true
+			t = x.OfType
+		case *graphql.NonNull:
+			t = x.OfType
+		case *graphql.Object:
+			return x, isList
+		default:
+			return nil, isList
+		}
+	}
+}
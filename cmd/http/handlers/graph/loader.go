@@ -0,0 +1,81 @@
+package graph
+
+import (
+	"context"
+	"sync"
+	"todolist-api/infra/context/service"
+	"todolist-api/objects/activity"
+	"todolist-api/objects/todo"
+)
+
+type loadersKey struct{}
+
+// batch collect the keys requested while the executor walks one level of the
+// query, then fetch them all at once the first time a result is needed
+type batch[K comparable, V any] struct {
+	mtx     sync.Mutex
+	fetch   func(keys []K) (map[K]V, error)
+	pending []K
+	queued  map[K]bool
+	cache   map[K]V
+	errs    map[K]error
+}
+
+func newBatch[K comparable, V any](fetch func(keys []K) (map[K]V, error)) *batch[K, V] {
+	return &batch[K, V]{
+		fetch:  fetch,
+		queued: map[K]bool{},
+		cache:  map[K]V{},
+		errs:   map[K]error{},
+	}
+}
+
+// Load queue the key and return a thunk resolving it
+func (b *batch[K, V]) Load(key K) func() (V, error) {
+	b.mtx.Lock()
+	if !b.queued[key] {
+		b.queued[key] = true
+		b.pending = append(b.pending, key)
+	}
+	b.mtx.Unlock()
+
+	return func() (V, error) {
+		b.mtx.Lock()
+		defer b.mtx.Unlock()
+
+		if len(b.pending) > 0 {
+			keys := b.pending
+			b.pending = nil
+
+			results, err := b.fetch(keys)
+			for _, k := range keys {
+				b.cache[k] = results[k]
+				b.errs[k] = err
+			}
+		}
+
+		return b.cache[key], b.errs[key]
+	}
+}
+
+// loaders per request batch loaders, so nested fields don't issue one query per parent
+type loaders struct {
+	todosByGroup *batch[int, []todo.Todo]
+	activities   *batch[int, activity.Activity]
+}
+
+func withLoaders(ctx context.Context, serviceCtx *service.Ctx) context.Context {
+	return context.WithValue(ctx, loadersKey{}, &loaders{
+		todosByGroup: newBatch(func(ids []int) (map[int][]todo.Todo, error) {
+			return serviceCtx.TodoService.GetTodoByActivityGroupIDs(ctx, ids)
+		}),
+		activities: newBatch(func(ids []int) (map[int]activity.Activity, error) {
+			return serviceCtx.ActivityService.GetActivityByIDs(ctx, ids)
+		}),
+	})
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	l, _ := ctx.Value(loadersKey{}).(*loaders)
+	return l
+}
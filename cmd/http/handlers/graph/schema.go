@@ -0,0 +1,445 @@
+package graph
+
+import (
+	"errors"
+	"strconv"
+	"todolist-api/infra/auth"
+	"todolist-api/infra/context/service"
+	"todolist-api/infra/events"
+	"todolist-api/objects/activity"
+	"todolist-api/objects/todo"
+
+	"github.com/graphql-go/graphql"
+)
+
+var errUnauthenticated = errors.New("invalid or missing API key")
+
+// newSchema build the GraphQL schema on top of the activity and todo services
+func newSchema(serviceCtx *service.Ctx, broker *events.Broker) (graphql.Schema, error) {
+	activityType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Activity",
+		Fields: graphql.Fields{
+			"id":        &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"title":     &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"email":     &graphql.Field{Type: graphql.String},
+			"createdAt": &graphql.Field{Type: graphql.String},
+			"updatedAt": &graphql.Field{Type: graphql.String},
+		},
+	})
+
+	todoType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Todo",
+		Fields: graphql.Fields{
+			"id":    &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"title": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"activityGroupId": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Int),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(todo.Todo).ActivityGroupID, nil
+				},
+			},
+			"isActive": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Boolean),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(todo.Todo).IsActive, nil
+				},
+			},
+			"priority": &graphql.Field{Type: graphql.String},
+			"dueAt": &graphql.Field{
+				Type: graphql.String,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if due := p.Source.(todo.Todo).DueAt; due != "" {
+						return due, nil
+					}
+					return nil, nil
+				},
+			},
+			"rrule": &graphql.Field{
+				Type: graphql.String,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if rule := p.Source.(todo.Todo).RRule; rule != "" {
+						return rule, nil
+					}
+					return nil, nil
+				},
+			},
+			"createdAt": &graphql.Field{Type: graphql.String},
+			"updatedAt": &graphql.Field{Type: graphql.String},
+			"activity": &graphql.Field{
+				Type: activityType,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					thunk := loadersFrom(p.Context).activities.Load(p.Source.(todo.Todo).ActivityGroupID)
+					return func() (interface{}, error) {
+						data, err := thunk()
+						if err != nil || data.ID == 0 {
+							return nil, err
+						}
+						return data, nil
+					}, nil
+				},
+			},
+		},
+	})
+
+	todoFilterArgs := graphql.FieldConfigArgument{
+		"isActive": &graphql.ArgumentConfig{Type: graphql.Boolean},
+		"priority": &graphql.ArgumentConfig{Type: graphql.String},
+	}
+
+	activityType.AddFieldConfig("todos", &graphql.Field{
+		Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(todoType))),
+		Args: todoFilterArgs,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			filter := todoFilter(p.Args)
+			thunk := loadersFrom(p.Context).todosByGroup.Load(p.Source.(activity.Activity).ID)
+			return func() (interface{}, error) {
+				data, err := thunk()
+				if err != nil {
+					return nil, err
+				}
+
+				res := []todo.Todo{}
+				for _, x := range data {
+					if matchTodo(x, filter) {
+						res = append(res, x)
+					}
+				}
+				return res, nil
+			}, nil
+		},
+	})
+	activityType.AddFieldConfig("todoCount", &graphql.Field{
+		Type: graphql.NewNonNull(graphql.Int),
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			thunk := loadersFrom(p.Context).todosByGroup.Load(p.Source.(activity.Activity).ID)
+			return func() (interface{}, error) {
+				data, err := thunk()
+				return len(data), err
+			}, nil
+		},
+	})
+	activityType.AddFieldConfig("activeTodoCount", &graphql.Field{
+		Type: graphql.NewNonNull(graphql.Int),
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			thunk := loadersFrom(p.Context).todosByGroup.Load(p.Source.(activity.Activity).ID)
+			return func() (interface{}, error) {
+				data, err := thunk()
+				count := 0
+				for _, x := range data {
+					if x.IsActive {
+						count++
+					}
+				}
+				return count, err
+			}, nil
+		},
+	})
+
+	eventType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "TodoEvent",
+		Fields: graphql.Fields{
+			"id": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.ID),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return strconv.FormatUint(p.Source.(events.Event).ID, 10), nil
+				},
+			},
+			"type": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.String),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(events.Event).Type, nil
+				},
+			},
+			"activityGroupId": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Int),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(events.Event).ActivityGroupID, nil
+				},
+			},
+			"createdAt": &graphql.Field{
+				Type: graphql.String,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(events.Event).CreatedAt, nil
+				},
+			},
+			"todo": &graphql.Field{
+				Type: todoType,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if data, ok := p.Source.(events.Event).Data.(todo.Todo); ok {
+						return data, nil
+					}
+					return nil, nil
+				},
+			},
+			"activity": &graphql.Field{
+				Type: activityType,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if data, ok := p.Source.(events.Event).Data.(activity.Activity); ok {
+						return data, nil
+					}
+					return nil, nil
+				},
+			},
+		},
+	})
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"activities": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(activityType))),
+				Args: graphql.FieldConfigArgument{
+					"email": &graphql.ArgumentConfig{Type: graphql.String},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					email, _ := p.Args["email"].(string)
+					return serviceCtx.ActivityService.GetAllActivity(p.Context, activity.FilterActivity{Email: email})
+				},
+			},
+			"activity": &graphql.Field{
+				Type: activityType,
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return serviceCtx.ActivityService.GetOneActivity(p.Context, p.Args["id"].(int))
+				},
+			},
+			"todos": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(todoType))),
+				Args: graphql.FieldConfigArgument{
+					"activityGroupId": &graphql.ArgumentConfig{Type: graphql.Int},
+					"isActive":        todoFilterArgs["isActive"],
+					"priority":        todoFilterArgs["priority"],
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return serviceCtx.TodoService.GetAllTodo(p.Context, todoFilter(p.Args))
+				},
+			},
+			"todo": &graphql.Field{
+				Type: todoType,
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return serviceCtx.TodoService.GetOneTodo(p.Context, p.Args["id"].(int))
+				},
+			},
+		},
+	})
+
+	mutation := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"createActivity": &graphql.Field{
+				Type: graphql.NewNonNull(activityType),
+				Args: graphql.FieldConfigArgument{
+					"title": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					"email": &graphql.ArgumentConfig{Type: graphql.String},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					email, _ := p.Args["email"].(string)
+					return serviceCtx.ActivityService.CreateActivity(p.Context, activity.CreateActivity{
+						Title: p.Args["title"].(string),
+						Email: email,
+					})
+				},
+			},
+			"updateActivity": &graphql.Field{
+				Type: graphql.NewNonNull(activityType),
+				Args: graphql.FieldConfigArgument{
+					"id":    &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+					"title": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return serviceCtx.ActivityService.UpdateActivity(p.Context, p.Args["id"].(int), activity.UpdateActivity{
+						Title: p.Args["title"].(string),
+					})
+				},
+			},
+			"deleteActivity": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Boolean),
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					err := serviceCtx.ActivityService.DeleteActivity(p.Context, p.Args["id"].(int))
+					return err == nil, err
+				},
+			},
+			"createTodo": &graphql.Field{
+				Type: graphql.NewNonNull(todoType),
+				Args: graphql.FieldConfigArgument{
+					"activityGroupId": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+					"title":           &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					"isActive":        &graphql.ArgumentConfig{Type: graphql.Boolean, DefaultValue: true},
+					"priority":        &graphql.ArgumentConfig{Type: graphql.String},
+					"dueAt":           &graphql.ArgumentConfig{Type: graphql.String},
+					"rrule":           &graphql.ArgumentConfig{Type: graphql.String},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					priority, _ := p.Args["priority"].(string)
+					isActive, _ := p.Args["isActive"].(bool)
+					dueAt, _ := p.Args["dueAt"].(string)
+					rrule, _ := p.Args["rrule"].(string)
+					return serviceCtx.TodoService.CreateTodo(p.Context, todo.CreateTodo{
+						Title:           p.Args["title"].(string),
+						ActivityGroupID: p.Args["activityGroupId"].(int),
+						IsActive:        isActive,
+						Priority:        priority,
+						DueAt:           dueAt,
+						RRule:           rrule,
+					})
+				},
+			},
+			"updateTodo": &graphql.Field{
+				Type: graphql.NewNonNull(todoType),
+				Args: graphql.FieldConfigArgument{
+					"id":       &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+					"title":    &graphql.ArgumentConfig{Type: graphql.String},
+					"isActive": &graphql.ArgumentConfig{Type: graphql.Boolean},
+					"priority": &graphql.ArgumentConfig{Type: graphql.String},
+					"dueAt":    &graphql.ArgumentConfig{Type: graphql.String},
+					"rrule":    &graphql.ArgumentConfig{Type: graphql.String},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					id := p.Args["id"].(int)
+					current, err := serviceCtx.TodoService.GetOneTodo(p.Context, id)
+					if err != nil {
+						return nil, err
+					}
+
+					// omitted arguments keep their current value
+					req := todo.UpdateTodo{
+						Title:    current.Title,
+						IsActive: current.IsActive,
+						Priority: current.Priority,
+					}
+					if v, ok := p.Args["title"].(string); ok {
+						req.Title = v
+					}
+					if v, ok := p.Args["isActive"].(bool); ok {
+						req.IsActive = v
+					}
+					if v, ok := p.Args["priority"].(string); ok {
+						req.Priority = v
+					}
+					if v, ok := p.Args["dueAt"].(string); ok {
+						req.DueAt = &v
+					}
+					if v, ok := p.Args["rrule"].(string); ok {
+						req.RRule = &v
+					}
+
+					return serviceCtx.TodoService.UpdateTodo(p.Context, id, req)
+				},
+			},
+			"moveTodo": &graphql.Field{
+				Type: graphql.NewNonNull(todoType),
+				Args: graphql.FieldConfigArgument{
+					"id":              &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+					"activityGroupId": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return serviceCtx.TodoService.MoveTodo(p.Context, p.Args["id"].(int), todo.MoveTodo{
+						ActivityGroupID: p.Args["activityGroupId"].(int),
+					})
+				},
+			},
+			"deleteTodo": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Boolean),
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					err := serviceCtx.TodoService.DeleteTodo(p.Context, p.Args["id"].(int))
+					return err == nil, err
+				},
+			},
+		},
+	})
+
+	subscription := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Subscription",
+		Fields: graphql.Fields{
+			"todoChanged": &graphql.Field{
+				Type: graphql.NewNonNull(eventType),
+				Args: graphql.FieldConfigArgument{
+					"activityGroupId": &graphql.ArgumentConfig{Type: graphql.Int},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source, nil
+				},
+				Subscribe: func(p graphql.ResolveParams) (interface{}, error) {
+					user, ok := auth.FromContext(p.Context)
+					if !ok {
+						return nil, errUnauthenticated
+					}
+
+					groupID, _ := p.Args["activityGroupId"].(int)
+					filter := auth.NewEventFilter(user, func(groupID int) (string, error) {
+						group, err := serviceCtx.ActivityService.GetOneActivity(p.Context, groupID)
+						return group.Email, err
+					})
+
+					sub, _, _ := broker.Subscribe(0)
+					ch := make(chan interface{})
+					go func() {
+						defer close(ch)
+						defer sub.Close()
+
+						for {
+							select {
+							case <-p.Context.Done():
+								return
+							case event, ok := <-sub.C:
+								if !ok {
+									return
+								}
+								if groupID != 0 && event.ActivityGroupID != groupID {
+									continue
+								}
+								if !filter.Visible(event) {
+									continue
+								}
+
+								select {
+								case ch <- event:
+								case <-p.Context.Done():
+									return
+								}
+							}
+						}
+					}()
+
+					return ch, nil
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{
+		Query:        query,
+		Mutation:     mutation,
+		Subscription: subscription,
+	})
+}
+
+func todoFilter(args map[string]interface{}) todo.FilterTodo {
+	filter := todo.FilterTodo{}
+	filter.ActivityGroupID, _ = args["activityGroupId"].(int)
+	filter.Priority, _ = args["priority"].(string)
+	if v, ok := args["isActive"].(bool); ok {
+		filter.IsActive = &v
+	}
+
+	return filter
+}
+
+func matchTodo(data todo.Todo, filter todo.FilterTodo) bool {
+	if filter.IsActive != nil && data.IsActive != *filter.IsActive {
+		return false
+	}
+
+	return filter.Priority == "" || data.Priority == filter.Priority
+}
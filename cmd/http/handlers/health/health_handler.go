@@ -0,0 +1,43 @@
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+	"todolist-api/infra/health"
+	"todolist-api/infra/logger"
+)
+
+type healthHandler struct {
+	health *health.Health
+}
+
+// ServeHealthz answer as long as the process serves requests
+func (h healthHandler) ServeHealthz(w http.ResponseWriter, r *http.Request) {
+	h.write(w, r, h.health.Live())
+}
+
+// ServeLivez answer as long as the process serves requests, draining
+// included, so the orchestrator doesn't restart it during a shutdown
+func (h healthHandler) ServeLivez(w http.ResponseWriter, r *http.Request) {
+	h.write(w, r, h.health.Live())
+}
+
+// ServeReadyz answer 503 when a dependency is down or the process is draining
+func (h healthHandler) ServeReadyz(w http.ResponseWriter, r *http.Request) {
+	h.write(w, r, h.health.Ready(r.Context()))
+}
+
+func (h healthHandler) write(w http.ResponseWriter, r *http.Request, report health.Report) {
+	status := http.StatusOK
+	if report.Status != health.StatusUp {
+		status = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		logger.FromContext(r.Context()).Error(err)
+	}
+}
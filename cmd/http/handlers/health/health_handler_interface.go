@@ -0,0 +1,18 @@
+package health
+
+import (
+	"net/http"
+	"todolist-api/infra/health"
+)
+
+type HealthHandlerInterface interface {
+	ServeHealthz(w http.ResponseWriter, r *http.Request)
+	ServeLivez(w http.ResponseWriter, r *http.Request)
+	ServeReadyz(w http.ResponseWriter, r *http.Request)
+}
+
+func NewHealthHandler(h *health.Health) HealthHandlerInterface {
+	return &healthHandler{
+		health: h,
+	}
+}
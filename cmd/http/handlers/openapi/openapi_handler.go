@@ -0,0 +1,32 @@
+package openapi
+
+import (
+	_ "embed"
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+)
+
+//go:embed swagger.html
+var swaggerUI []byte
+
+type openAPIHandler struct {
+	spec []byte
+}
+
+func (o openAPIHandler) ServeSpec(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(o.spec); err != nil {
+		log.Error(err)
+	}
+}
+
+func (o openAPIHandler) ServeUI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(swaggerUI); err != nil {
+		log.Error(err)
+	}
+}
@@ -0,0 +1,24 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type OpenAPIHandlerInterface interface {
+	ServeSpec(w http.ResponseWriter, r *http.Request)
+	ServeUI(w http.ResponseWriter, r *http.Request)
+}
+
+func NewOpenAPIHandler(doc *Document) OpenAPIHandlerInterface {
+	spec, err := json.Marshal(doc)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	return &openAPIHandler{
+		spec: spec,
+	}
+}
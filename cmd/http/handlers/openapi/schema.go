@@ -0,0 +1,136 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+	"todolist-api/utils"
+)
+
+const componentsPrefix = "#/components/schemas/"
+
+// rawMessage the type of the fields holding any JSON
+var rawMessage = reflect.TypeOf(json.RawMessage{})
+
+// timeType the type of the fields holding an RFC 3339 time
+var timeType = reflect.TypeOf(time.Time{})
+
+// schemas generate the components of Go types into a shared set. Components
+// generated with snake set use snake_case property names and carry suffix.
+type schemas struct {
+	components map[string]*Schema
+	suffix     string
+	snake      bool
+}
+
+func newSchemas() schemas {
+	return schemas{components: map[string]*Schema{}}
+}
+
+// variant return a generator sharing the components, for another API version
+func (s schemas) variant(suffix string, snake bool) schemas {
+	return schemas{components: s.components, suffix: suffix, snake: snake}
+}
+
+// of return the schema of the value v, named struct types are registered as
+// components and referenced
+func (s schemas) of(v interface{}) *Schema {
+	if v == nil {
+		return &Schema{}
+	}
+
+	return s.typeOf(reflect.TypeOf(v))
+}
+
+// component return the registered component referenced by ref
+func (s schemas) component(ref *Schema) *Schema {
+	return s.components[strings.TrimPrefix(ref.Ref, componentsPrefix)]
+}
+
+func (s schemas) typeOf(t reflect.Type) *Schema {
+	// embedded JSON, any value
+	if t == rawMessage {
+		return &Schema{}
+	}
+
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return s.typeOf(t.Elem())
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: s.typeOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: s.typeOf(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.object(t)
+		}
+
+		name := t.Name() + s.suffix
+		if _, ok := s.components[name]; !ok {
+			// registered before walking the fields so recursive types terminate
+			s.components[name] = &Schema{}
+			*s.components[name] = *s.object(t)
+		}
+
+		return &Schema{Ref: componentsPrefix + name}
+	}
+
+	return &Schema{}
+}
+
+// names return the property names of the fields named names
+func (s schemas) names(names []string) []string {
+	if !s.snake {
+		return names
+	}
+
+	res := make([]string, 0, len(names))
+	for _, name := range names {
+		res = append(res, utils.ToSnakeCase(name))
+	}
+
+	return res
+}
+
+// object describe the exported fields of a struct by their json name
+func (s schemas) object(t reflect.Type) *Schema {
+	res := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		if s.snake {
+			name = utils.ToSnakeCase(name)
+		}
+
+		res.Properties[name] = s.typeOf(f.Type)
+		if !strings.Contains(opts, "omitempty") && f.Type.Kind() != reflect.Pointer {
+			res.Required = append(res.Required, name)
+		}
+	}
+
+	return res
+}
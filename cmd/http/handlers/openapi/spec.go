@@ -0,0 +1,698 @@
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"todolist-api/infra/health"
+	"todolist-api/objects/activity"
+	"todolist-api/objects/batch"
+	"todolist-api/objects/sync"
+	"todolist-api/objects/todo"
+	"todolist-api/objects/transfer"
+	"todolist-api/objects/webhook"
+	"todolist-api/utils"
+)
+
+const (
+	contentJSON        = "application/json"
+	contentEventStream = "text/event-stream"
+	contentHTML        = "text/html"
+	contentCSV         = "text/csv"
+	contentMarkdown    = "text/markdown"
+	contentCalendar    = "text/calendar"
+
+	securityAPIKeyHeader = "apiKeyHeader"
+	securityAPIKeyQuery  = "apiKeyQuery"
+
+	v1 = "/v1"
+	v2 = "/v2"
+)
+
+var pathParam = regexp.MustCompile(`{([^}:]+)(:[^}]+)?}`)
+
+// idempotencyKey the header making a creation safe to retry
+var idempotencyKey = Parameter{
+	Name: "Idempotency-Key", In: "header",
+	Description: "unique key of the request, a retry with the same key and body replays the first response with Idempotent-Replayed: true",
+	Schema:      &Schema{Type: "string", MaxLength: 255},
+}
+
+// idempotencyDescription the behaviour of the creations taking an Idempotency-Key
+const idempotencyDescription = "Safe to retry with an Idempotency-Key: reusing the key with another body answers 422, while the first request is in progress 409."
+
+// endpoint describe one route of the router
+type endpoint struct {
+	Method string
+	Path   string
+	// Versions the prefixes the route is mounted under. Routes of v1 are also
+	// served without prefix, as deprecated aliases.
+	Versions    []string
+	Tag         string
+	Summary     string
+	Description string
+	Query       []Parameter
+	// Body the request payload, Required its mandatory fields
+	Body     interface{}
+	Required []string
+	// Status and Data the success response, wrapped in the utils.Response envelope
+	Status int
+	Data   interface{}
+	// RawBody a request payload that isn't JSON alone, such as a file
+	RawBody *RequestBody
+	// Raw a success response that isn't wrapped, such as a stream, sent
+	// with Status or 200
+	Raw *Response
+	// RawErrors error statuses answered with the Raw body, not ResponseErr
+	RawErrors []int
+	Errors    []int
+	Public    bool
+}
+
+var endpoints = []endpoint{
+	// activity
+	{
+		Method: http.MethodPost, Path: "/activity-groups", Tag: "activity",
+		Versions:    []string{v1, v2},
+		Summary:     "Create an activity group",
+		Description: idempotencyDescription,
+		Query:       []Parameter{idempotencyKey},
+		Body:        activity.CreateActivity{}, Required: []string{"title"},
+		Status: http.StatusCreated, Data: activity.Activity{},
+		Errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusConflict, http.StatusUnprocessableEntity, http.StatusInternalServerError},
+	},
+	{
+		Method: http.MethodGet, Path: "/activity-groups", Tag: "activity",
+		Versions: []string{v1, v2},
+		Summary:  "List activity groups",
+		Query: []Parameter{
+			{Name: "email", In: "query", Description: "only the groups owned by this email", Schema: &Schema{Type: "string", Format: "email"}},
+		},
+		Status: http.StatusOK, Data: []activity.Activity{},
+		Errors: []int{http.StatusUnauthorized, http.StatusInternalServerError},
+	},
+	{
+		Method: http.MethodGet, Path: "/activity-groups/{id}", Tag: "activity",
+		Versions: []string{v1, v2},
+		Summary:  "Get an activity group",
+		Status:   http.StatusOK, Data: activity.Activity{},
+		Errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound, http.StatusInternalServerError},
+	},
+	{
+		Method: http.MethodPut, Path: "/activity-groups/{id}", Tag: "activity",
+		Versions: []string{v1, v2},
+		Summary:  "Rename an activity group",
+		Body:     activity.UpdateActivity{}, Required: []string{"title"},
+		Status: http.StatusOK, Data: activity.Activity{},
+		Errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound, http.StatusInternalServerError},
+	},
+	{
+		Method: http.MethodDelete, Path: "/activity-groups/{id}", Tag: "activity",
+		Versions: []string{v1, v2},
+		Summary:  "Delete an activity group",
+		Status:   http.StatusOK, Data: map[string]interface{}{},
+		Errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound, http.StatusInternalServerError},
+	},
+	{
+		Method: http.MethodGet, Path: "/activity-groups/{id}/events", Tag: "events",
+		Versions:    []string{v1},
+		Summary:     "Stream the changes of an activity group",
+		Description: "Server-sent events named after the change type, such as todo.created, whose data is the changed todo or activity group. Send Last-Event-ID to resume, a reset event means the stream can't be resumed and the client has to reload.",
+		Raw:         streamResponse(),
+		Errors:      []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusInternalServerError},
+	},
+
+	// todo
+	{
+		Method: http.MethodPost, Path: "/todo-items", Tag: "todo",
+		Versions:    []string{v1, v2},
+		Summary:     "Create a todo",
+		Description: idempotencyDescription,
+		Query:       []Parameter{idempotencyKey},
+		Body:        todo.CreateTodo{}, Required: []string{"title", "activity_group_id"},
+		Status: http.StatusCreated, Data: todo.Todo{},
+		Errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusConflict, http.StatusUnprocessableEntity, http.StatusInternalServerError},
+	},
+	{
+		Method: http.MethodGet, Path: "/todo-items", Tag: "todo",
+		Versions: []string{v1, v2},
+		Summary:  "List todos",
+		Query: []Parameter{
+			{Name: "activity_group_id", In: "query", Description: "only the todos of this activity group", Schema: &Schema{Type: "integer"}},
+			{Name: "is_active", In: "query", Description: "only the active or the completed todos", Schema: &Schema{Type: "boolean"}},
+			{Name: "priority", In: "query", Description: "only the todos of this priority", Schema: &Schema{Type: "string"}},
+		},
+		Status: http.StatusOK, Data: []todo.Todo{},
+		Errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusInternalServerError},
+	},
+	{
+		Method: http.MethodPost, Path: "/todo-items/bulk", Tag: "todo",
+		Versions:    []string{v1, v2},
+		Summary:     "Create, update or delete many todos",
+		Description: "operation is create with items, update with ids and set, delete with ids, or complete_group with activity_group_id. The atomic mode, the default, applies every item or none. The best_effort mode applies the valid items and reports the missing todos and groups per item. At most 1000 items.",
+		Body:        todo.BulkTodo{}, Required: []string{"operation"},
+		Status: http.StatusOK, Data: todo.BulkTodoResult{},
+		Errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound, http.StatusInternalServerError},
+	},
+	{
+		Method: http.MethodGet, Path: "/todo-items/{id}", Tag: "todo",
+		Versions: []string{v1, v2},
+		Summary:  "Get a todo",
+		Status:   http.StatusOK, Data: todo.Todo{},
+		Errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound, http.StatusInternalServerError},
+	},
+	{
+		Method: http.MethodPut, Path: "/todo-items/{id}", Tag: "todo",
+		Versions: []string{v1, v2},
+		Summary:  "Update a todo",
+		Body:     todo.UpdateTodo{}, Required: []string{"title"},
+		Status: http.StatusOK, Data: todo.Todo{},
+		Errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound, http.StatusInternalServerError},
+	},
+	{
+		Method: http.MethodDelete, Path: "/todo-items/{id}", Tag: "todo",
+		Versions: []string{v1, v2},
+		Summary:  "Delete a todo",
+		Status:   http.StatusOK, Data: map[string]interface{}{},
+		Errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound, http.StatusInternalServerError},
+	},
+
+	// webhook
+	{
+		Method: http.MethodPost, Path: "/webhooks", Tag: "webhook",
+		Versions:    []string{v1, v2},
+		Summary:     "Subscribe a URL to change events",
+		Description: "Deliveries are signed with HMAC-SHA256 of the timestamp and the body, sent in the X-Todolist-Signature header.",
+		Body:        webhook.CreateWebhook{}, Required: []string{"url", "secret", "event_types"},
+		Status: http.StatusCreated, Data: webhook.Webhook{},
+		Errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusInternalServerError},
+	},
+	{
+		Method: http.MethodGet, Path: "/webhooks", Tag: "webhook",
+		Versions: []string{v1, v2},
+		Summary:  "List webhooks",
+		Status:   http.StatusOK, Data: []webhook.Webhook{},
+		Errors: []int{http.StatusUnauthorized, http.StatusInternalServerError},
+	},
+	{
+		Method: http.MethodGet, Path: "/webhooks/{id}", Tag: "webhook",
+		Versions: []string{v1, v2},
+		Summary:  "Get a webhook",
+		Status:   http.StatusOK, Data: webhook.Webhook{},
+		Errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound, http.StatusInternalServerError},
+	},
+	{
+		Method: http.MethodPut, Path: "/webhooks/{id}", Tag: "webhook",
+		Versions: []string{v1, v2},
+		Summary:  "Update a webhook",
+		Body:     webhook.UpdateWebhook{}, Required: []string{"url", "secret", "event_types"},
+		Status: http.StatusOK, Data: webhook.Webhook{},
+		Errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound, http.StatusInternalServerError},
+	},
+	{
+		Method: http.MethodDelete, Path: "/webhooks/{id}", Tag: "webhook",
+		Versions: []string{v1, v2},
+		Summary:  "Delete a webhook",
+		Status:   http.StatusOK, Data: map[string]interface{}{},
+		Errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound, http.StatusInternalServerError},
+	},
+	{
+		Method: http.MethodGet, Path: "/webhook-deliveries/dead", Tag: "webhook",
+		Versions: []string{v1, v2},
+		Summary:  "List the deliveries that exhausted their attempts",
+		Status:   http.StatusOK, Data: []webhook.Delivery{},
+		Errors: []int{http.StatusUnauthorized, http.StatusInternalServerError},
+	},
+	{
+		Method: http.MethodPost, Path: "/webhook-deliveries/{id}/redeliver", Tag: "webhook",
+		Versions: []string{v1, v2},
+		Summary:  "Queue a dead delivery again",
+		Status:   http.StatusOK, Data: webhook.Delivery{},
+		Errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound, http.StatusConflict, http.StatusInternalServerError},
+	},
+
+	// live changes
+	{
+		Method: http.MethodGet, Path: "/events", Tag: "events",
+		Versions:    []string{v1},
+		Summary:     "Stream the changes of every visible activity group",
+		Description: "Same events as /activity-groups/{id}/events, limited to the groups the API key owns.",
+		Raw:         streamResponse(),
+		Errors:      []int{http.StatusUnauthorized, http.StatusInternalServerError},
+	},
+	{
+		Method: http.MethodGet, Path: "/ws", Tag: "events",
+		Summary:     "Open a collaborative editing websocket",
+		Description: "Upgrades to a websocket speaking JSON commands (subscribe, unsubscribe, create, update, move, ping) and receiving change and presence messages.",
+		Status:      http.StatusSwitchingProtocols,
+		Raw:         &Response{Description: "Switching Protocols"},
+		Errors:      []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden},
+	},
+
+	// batch
+	{
+		Method: http.MethodPost, Path: "/batch", Tag: "batch",
+		Summary:     "Run several API calls in one round trip",
+		Description: "The requests run in order through the routes of the activity groups, todos and webhooks, with the credentials of the batch. A string such as $1.id in the path or the body of a request is replaced by the id of the data of the response to the first request, answering 424 when that request failed. An atomic batch stops at the first failure and keeps none of its changes, which the requests read outside of a transaction don't see until the end of the batch.",
+		Body:        batch.Batch{}, Required: []string{"requests"},
+		Status: http.StatusOK, Data: batch.Result{},
+		Errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusInternalServerError},
+	},
+
+	// sync
+	{
+		Method: http.MethodGet, Path: "/sync", Tag: "sync",
+		Versions:    []string{v1, v2},
+		Summary:     "Pull the changes since the last sync",
+		Description: "Without since, returns every activity group and todo, page after page. With the token of the last pull, returns the groups and todos created or updated since in their current state, and the ones deleted. Pull again with the token returned, at once while has_more is true.",
+		Query: []Parameter{
+			{Name: "since", In: "query", Description: "token returned by the last pull", Schema: &Schema{Type: "string"}},
+			{Name: "limit", In: "query", Description: "most entities returned, up to the configured page size", Schema: &Schema{Type: "integer"}},
+		},
+		Status: http.StatusOK, Data: sync.Changes{},
+		Errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusInternalServerError},
+	},
+	{
+		Method: http.MethodPost, Path: "/sync", Tag: "sync",
+		Versions:    []string{v1, v2},
+		Summary:     "Push the mutations made offline",
+		Description: "The mutations are applied in order in one transaction, each reported applied, conflict or rejected. A field the server changed since the pull of the since token, to another value, is a conflict settled by the configured policy: last_writer_wins keeps the value with the latest timestamp, server_wins the server's. A todo created with a string activity_group_id joins the group created earlier in the push with that client_id. " + idempotencyDescription,
+		Query:       []Parameter{idempotencyKey},
+		Body:        sync.Push{}, Required: []string{"mutations"},
+		Status: http.StatusOK, Data: sync.PushResult{},
+		Errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusConflict, http.StatusUnprocessableEntity, http.StatusInternalServerError},
+	},
+
+	// transfer
+	{
+		Method: http.MethodGet, Path: "/activity-groups/{id}/export", Tag: "transfer",
+		Versions:    []string{v1, v2},
+		Summary:     "Export an activity group and its todos",
+		Description: "Sent as an attachment: the JSON export, a CSV row per todo, or a Markdown heading with a checklist of the todos.",
+		Query:       []Parameter{exportFormat},
+		Raw:         exportResponse(),
+		Errors:      []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusInternalServerError},
+	},
+	{
+		Method: http.MethodGet, Path: "/export", Tag: "transfer",
+		Versions:    []string{v1, v2},
+		Summary:     "Export every activity group of the account",
+		Description: "Same formats as the export of an activity group, with the groups the API key owns.",
+		Query:       []Parameter{exportFormat},
+		Raw:         exportResponse(),
+		Errors:      []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusInternalServerError},
+	},
+	{
+		Method: http.MethodPost, Path: "/import", Tag: "transfer",
+		Versions:    []string{v1, v2},
+		Summary:     "Import activity groups and their todos",
+		Description: "Reads a file in the format of the exports, or the CSV export of a Todoist project (sections as groups) or of a Trello board (lists as groups). Every row is checked before anything is created, then the groups and todos are created in one transaction. An invalid file creates nothing and answers 422 listing its errors. With dry_run=true, nothing is created and the report is answered with 200.",
+		Query: []Parameter{
+			{Name: "format", In: "query", Description: "json, csv, md, todoist, trello or ics, told by the Content-Type when missing", Schema: &Schema{Type: "string", Enum: []interface{}{"json", "csv", "md", "todoist", "trello", "ics"}}},
+			{Name: "dry_run", In: "query", Description: "check the file and report what would be created", Schema: &Schema{Type: "boolean"}},
+		},
+		RawBody: &RequestBody{Required: true, Content: map[string]*MediaType{
+			contentJSON:     {Schema: &Schema{Ref: componentsPrefix + "Export"}},
+			contentCSV:      {Schema: &Schema{Type: "string"}},
+			contentMarkdown: {Schema: &Schema{Type: "string"}},
+			contentCalendar: {Schema: &Schema{Type: "string"}},
+		}},
+		Status: http.StatusCreated, Data: transfer.ImportReport{},
+		Errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusRequestEntityTooLarge, http.StatusUnprocessableEntity, http.StatusInternalServerError},
+	},
+
+	// calendar
+	{
+		Method: http.MethodGet, Path: "/activity-groups/{id}/calendar.ics", Tag: "calendar",
+		Versions:    []string{v1, v2},
+		Summary:     "Calendar feed of an activity group",
+		Description: "An iCalendar file with a VTODO per todo, for the calendar apps to subscribe to. Authenticated by the token of its URL, given by GET /activity-groups/{id}/calendar, rather than by an API key.",
+		Query:       []Parameter{calendarToken},
+		Raw:         calendarResponse(),
+		Errors:      []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound, http.StatusInternalServerError},
+		Public:      true,
+	},
+	{
+		Method: http.MethodGet, Path: "/calendar.ics", Tag: "calendar",
+		Versions:    []string{v1, v2},
+		Summary:     "Calendar feed of every activity group of an account",
+		Description: "Same as the feed of an activity group, with the todos of every group of the email. Authenticated by the token of its URL, given by GET /calendar.",
+		Query: []Parameter{
+			{Name: "email", In: "query", Description: "owner of the groups, every group when missing", Schema: &Schema{Type: "string"}},
+			calendarToken,
+		},
+		Raw:    calendarResponse(),
+		Errors: []int{http.StatusForbidden, http.StatusNotFound, http.StatusInternalServerError},
+		Public: true,
+	},
+	{
+		Method: http.MethodGet, Path: "/activity-groups/{id}/calendar", Tag: "calendar",
+		Versions:    []string{v1, v2},
+		Summary:     "URL of the calendar feed of an activity group",
+		Description: "Answers 404 when no calendar secret is configured.",
+		Status:      http.StatusOK, Data: transfer.CalendarLink{},
+		Errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusInternalServerError},
+	},
+	{
+		Method: http.MethodGet, Path: "/calendar", Tag: "calendar",
+		Versions:    []string{v1, v2},
+		Summary:     "URL of the calendar feed of every activity group of the account",
+		Description: "Answers 404 when no calendar secret is configured.",
+		Status:      http.StatusOK, Data: transfer.CalendarLink{},
+		Errors: []int{http.StatusUnauthorized, http.StatusNotFound},
+	},
+	{
+		Method: http.MethodPost, Path: "/activity-groups/{id}/calendar", Tag: "calendar",
+		Versions:    []string{v1, v2},
+		Summary:     "Import the VTODOs of an iCalendar file into an activity group",
+		Description: "Checked and created as an import: SUMMARY as the title, DUE, PRIORITY, STATUS and RRULE. An invalid file creates nothing and answers 422 listing its errors. With dry_run=true, nothing is created and the report is answered with 200.",
+		Query: []Parameter{
+			{Name: "dry_run", In: "query", Description: "check the file and report what would be created", Schema: &Schema{Type: "boolean"}},
+		},
+		RawBody: &RequestBody{Required: true, Content: map[string]*MediaType{
+			contentCalendar: {Schema: &Schema{Type: "string"}},
+		}},
+		Status: http.StatusCreated, Data: transfer.ImportReport{},
+		Errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusRequestEntityTooLarge, http.StatusUnprocessableEntity, http.StatusInternalServerError},
+	},
+
+	// graphql
+	{
+		Method: http.MethodGet, Path: "/graphql", Tag: "graphql",
+		Summary:     "Run a GraphQL query",
+		Description: "Mutations must be sent with POST. Subscriptions are streamed as server-sent events named next and complete.",
+		Query: []Parameter{
+			{Name: "query", In: "query", Required: true, Schema: &Schema{Type: "string"}},
+			{Name: "operationName", In: "query", Schema: &Schema{Type: "string"}},
+			{Name: "variables", In: "query", Description: "JSON encoded variables", Schema: &Schema{Type: "string"}},
+		},
+		Raw:    graphQLResponse(),
+		Errors: []int{http.StatusUnauthorized},
+	},
+	{
+		Method: http.MethodPost, Path: "/graphql", Tag: "graphql",
+		Summary:     "Run a GraphQL query, mutation or subscription",
+		Description: "Subscriptions are streamed as server-sent events named next and complete.",
+		Body:        GraphQLRequest{}, Required: []string{"query"},
+		Raw:    graphQLResponse(),
+		Errors: []int{http.StatusUnauthorized},
+	},
+
+	// documentation
+	{
+		Method: http.MethodGet, Path: "/openapi.json", Tag: "docs",
+		Summary: "This document",
+		Raw:     &Response{Description: "OpenAPI document", Content: map[string]*MediaType{contentJSON: {}}},
+		Public:  true,
+	},
+	{
+		Method: http.MethodGet, Path: "/docs", Tag: "docs",
+		Summary: "Swagger UI",
+		Raw:     &Response{Description: "Swagger UI page", Content: map[string]*MediaType{contentHTML: {Schema: &Schema{Type: "string"}}}},
+		Public:  true,
+	},
+
+	// probes
+	{
+		Method: http.MethodGet, Path: "/healthz", Tag: "health",
+		Summary: "Tell whether the process is alive",
+		Raw:     healthResponse("Process alive, with its build"),
+		Public:  true,
+	},
+	{
+		Method: http.MethodGet, Path: "/livez", Tag: "health",
+		Summary:     "Liveness probe",
+		Description: "Stays up while the process drains on shutdown.",
+		Raw:         healthResponse("Process alive, with its build"),
+		Public:      true,
+	},
+	{
+		Method: http.MethodGet, Path: "/readyz", Tag: "health",
+		Summary:     "Readiness probe",
+		Description: "Pings the master and each replica and compares the applied migrations with the build. Answers 503 when a dependency is down or the process drains on shutdown.",
+		Raw:         healthResponse("Status, latency and details of each dependency"),
+		RawErrors:   []int{http.StatusServiceUnavailable},
+		Public:      true,
+	},
+}
+
+// GraphQLRequest body of a GraphQL request
+type GraphQLRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+// GraphQLResult body of a GraphQL response
+type GraphQLResult struct {
+	Data   map[string]interface{}   `json:"data,omitempty"`
+	Errors []map[string]interface{} `json:"errors,omitempty"`
+}
+
+func streamResponse() *Response {
+	return &Response{
+		Description: "Stream of server-sent events",
+		Content:     map[string]*MediaType{contentEventStream: {Schema: &Schema{Type: "string"}}},
+	}
+}
+
+// exportFormat the format of an export
+var exportFormat = Parameter{
+	Name: "format", In: "query", Description: "json by default, csv or md",
+	Schema: &Schema{Type: "string", Enum: []interface{}{"json", "csv", "md"}},
+}
+
+func exportResponse() *Response {
+	return &Response{
+		Description: "Export file",
+		Content: map[string]*MediaType{
+			contentJSON:     {Schema: &Schema{Ref: componentsPrefix + "Export"}},
+			contentCSV:      {Schema: &Schema{Type: "string"}},
+			contentMarkdown: {Schema: &Schema{Type: "string"}},
+		},
+	}
+}
+
+// calendarToken the token authenticating a calendar feed
+var calendarToken = Parameter{
+	Name: "token", In: "query", Required: true, Description: "token of the feed",
+	Schema: &Schema{Type: "string"},
+}
+
+func calendarResponse() *Response {
+	return &Response{
+		Description: "iCalendar file",
+		Content:     map[string]*MediaType{contentCalendar: {Schema: &Schema{Type: "string"}}},
+	}
+}
+
+func graphQLResponse() *Response {
+	return &Response{
+		Description: "GraphQL result, or a stream of server-sent events for subscriptions",
+		Content: map[string]*MediaType{
+			contentJSON:        {Schema: &Schema{Ref: componentsPrefix + "GraphQLResult"}},
+			contentEventStream: {Schema: &Schema{Type: "string"}},
+		},
+	}
+}
+
+func healthResponse(description string) *Response {
+	return &Response{
+		Description: description,
+		Content:     map[string]*MediaType{contentJSON: {Schema: &Schema{Ref: componentsPrefix + "Report"}}},
+	}
+}
+
+// NewDocument build the OpenAPI document of every endpoint
+func NewDocument() *Document {
+	s := newSchemas()
+	s.of(GraphQLResult{})
+	s.of(health.Report{})
+	s.of(transfer.Export{})
+
+	// envelopes of utils
+	s.of(utils.Response{})
+	s.of(utils.ResponseErr{})
+	s.of(utils.ResponseErrNotFound{})
+	s.components["Response"].Description = "Envelope of every successful response"
+	s.components["ResponseErr"].Description = "Envelope of every error response"
+	s.components["ResponseErr"].Properties["status"] = &Schema{
+		Type:        []string{"string", "integer"},
+		Description: "Reason phrase or HTTP status code of the error",
+		Examples: []interface{}{
+			utils.MESSAGE_BAD_REQUEST,
+			utils.MESSAGE_UNAUTHORIZED,
+			utils.MESSAGE_FORBIDDEN,
+			utils.MESSAGE_NOT_FOUND,
+			utils.MESSAGE_INTERNAL_SERVER_ERR,
+			http.StatusBadRequest,
+		},
+	}
+	s.components["ResponseErrNotFound"].Description = "Returned when no route matches the URL"
+
+	doc := &Document{
+		OpenAPI: Version,
+		Info: Info{
+			Title:       "Todolist API",
+			Description: "Activity groups, their todos and the change notifications about them.",
+			Version:     APIVersion,
+		},
+		Tags: []Tag{
+			{Name: "activity", Description: "Activity groups"},
+			{Name: "todo", Description: "Todos of the activity groups"},
+			{Name: "webhook", Description: "Webhook subscriptions and deliveries"},
+			{Name: "events", Description: "Live change streams"},
+			{Name: "batch", Description: "Several API calls in one round trip"},
+			{Name: "sync", Description: "Offline sync of the activity groups and todos"},
+			{Name: "transfer", Description: "Export and import of the activity groups and todos as files"},
+			{Name: "calendar", Description: "iCalendar feeds of the todos and import of VTODOs"},
+			{Name: "graphql", Description: "GraphQL endpoint"},
+			{Name: "docs", Description: "API documentation"},
+			{Name: "health", Description: "Health, liveness and readiness probes"},
+		},
+		Paths: map[string]*PathItem{},
+		Components: Components{
+			Schemas: s.components,
+			SecuritySchemes: map[string]*SecurityScheme{
+				securityAPIKeyHeader: {Type: "apiKey", Name: "API-KEY", In: "header"},
+				securityAPIKeyQuery:  {Type: "apiKey", Name: "api_key", In: "query"},
+			},
+		},
+		Security: []map[string][]string{
+			{securityAPIKeyHeader: {}},
+			{securityAPIKeyQuery: {}},
+		},
+	}
+
+	snake := s.variant("V2", true)
+	for _, e := range endpoints {
+		if len(e.Versions) == 0 {
+			doc.add(e, "", s, false)
+			continue
+		}
+
+		for _, v := range e.Versions {
+			if v == v2 {
+				doc.add(e, v, snake, false)
+				continue
+			}
+			doc.add(e, v, s, false)
+		}
+
+		// unversioned alias of v1
+		doc.add(e, "", s, true)
+	}
+
+	return doc
+}
+
+// add document the endpoint mounted under prefix
+func (d *Document) add(e endpoint, prefix string, s schemas, deprecated bool) {
+	path := prefix + e.Path
+	op := &Operation{
+		Tags:        []string{e.Tag},
+		Summary:     e.Summary,
+		Description: e.Description,
+		OperationID: operationID(e.Method, path),
+		Responses:   map[string]*Response{},
+		Deprecated:  deprecated,
+	}
+	if deprecated {
+		op.Description = strings.TrimSpace(op.Description + " Deprecated alias of " + v1 + e.Path + ", see the Deprecation, Sunset and Link response headers.")
+	}
+	if s.snake {
+		op.Description = strings.TrimSpace(op.Description + " The keys of the JSON request and response bodies are snake_case, such as created_at.")
+	}
+
+	for _, m := range pathParam.FindAllStringSubmatch(path, -1) {
+		op.Parameters = append(op.Parameters, Parameter{Name: m[1], In: "path", Required: true, Schema: &Schema{Type: "integer"}})
+	}
+	op.Parameters = append(op.Parameters, e.Query...)
+
+	if e.Body != nil {
+		body := s.of(e.Body)
+		if e.Required != nil {
+			s.component(body).Required = s.names(e.Required)
+		}
+		op.RequestBody = &RequestBody{Required: true, Content: map[string]*MediaType{contentJSON: {Schema: body}}}
+	}
+	if e.RawBody != nil {
+		op.RequestBody = e.RawBody
+	}
+
+	if e.Raw != nil {
+		status := e.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		op.Responses[fmt.Sprint(status)] = e.Raw
+		for _, code := range e.RawErrors {
+			op.Responses[fmt.Sprint(code)] = &Response{Description: http.StatusText(code), Content: e.Raw.Content}
+		}
+	} else {
+		op.Responses[fmt.Sprint(e.Status)] = &Response{
+			Description: http.StatusText(e.Status),
+			Content: map[string]*MediaType{contentJSON: {Schema: &Schema{
+				AllOf: []*Schema{
+					{Ref: componentsPrefix + "Response"},
+					{Properties: map[string]*Schema{"data": s.of(e.Data)}},
+				},
+			}}},
+		}
+	}
+
+	for _, code := range e.Errors {
+		op.Responses[fmt.Sprint(code)] = &Response{
+			Description: http.StatusText(code),
+			Content:     map[string]*MediaType{contentJSON: {Schema: &Schema{Ref: componentsPrefix + "ResponseErr"}}},
+		}
+	}
+
+	if e.Public {
+		op.Security = []map[string][]string{{}}
+	} else {
+		op.Responses[fmt.Sprint(http.StatusTooManyRequests)] = &Response{
+			Description: "Rate limit of the API key, the user or the IP exceeded, see the Retry-After and RateLimit-* headers",
+			Content:     map[string]*MediaType{contentJSON: {Schema: &Schema{Ref: componentsPrefix + "ResponseErr"}}},
+		}
+	}
+
+	path = pathParam.ReplaceAllString(path, "{$1}")
+	if d.Paths[path] == nil {
+		d.Paths[path] = &PathItem{}
+	}
+	(*d.Paths[path])[strings.ToLower(e.Method)] = op
+}
+
+// Bodies the JSON request bodies of the endpoints mounted under version,
+// such as /v2
+func Bodies(version string) []interface{} {
+	res := []interface{}{}
+	for _, e := range endpoints {
+		if e.Body == nil {
+			continue
+		}
+		for _, v := range e.Versions {
+			if v == version {
+				res = append(res, e.Body)
+			}
+		}
+	}
+
+	return res
+}
+
+// operationID derive a stable identifier such as getActivityGroupsById
+func operationID(method, path string) string {
+	id := strings.ToLower(method)
+	for _, part := range strings.FieldsFunc(path, func(r rune) bool { return r == '/' || r == '-' || r == '.' }) {
+		if m := pathParam.FindStringSubmatch(part); m != nil {
+			id += "By" + strings.ToUpper(m[1][:1]) + m[1][1:]
+			continue
+		}
+		id += strings.ToUpper(part[:1]) + part[1:]
+	}
+
+	return id
+}
@@ -0,0 +1,49 @@
+package openapi_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"todolist-api/cmd/http/handlers/openapi"
+	"todolist-api/objects/todo"
+)
+
+func TestDocumentV2Bodies(t *testing.T) {
+	doc := openapi.NewDocument()
+
+	for path, op := range map[string]*openapi.Operation{
+		"/v2/todo-items":      (*doc.Paths["/v2/todo-items"])["post"],
+		"/v2/todo-items/{id}": (*doc.Paths["/v2/todo-items/{id}"])["put"],
+	} {
+		if !strings.Contains(op.Description, "snake_case") {
+			t.Fatalf("%s: description %q doesn't tell the keys are snake_case", path, op.Description)
+		}
+
+		ref := op.RequestBody.Content["application/json"].Schema.Ref
+		if !strings.HasSuffix(ref, "V2") {
+			t.Fatalf("%s: request body %s, expected the v2 schema", path, ref)
+		}
+		schema := doc.Components.Schemas[strings.TrimPrefix(ref, "#/components/schemas/")]
+		for name := range schema.Properties {
+			if strings.ToLower(name) != name {
+				t.Fatalf("%s: property %s isn't snake_case", path, name)
+			}
+		}
+		for _, name := range schema.Required {
+			if _, ok := schema.Properties[name]; !ok {
+				t.Fatalf("%s: required %s isn't a property", path, name)
+			}
+		}
+	}
+
+	// the bodies the v2 routes read in snake_case
+	found := false
+	for _, b := range openapi.Bodies("/v2") {
+		if reflect.TypeOf(b) == reflect.TypeOf(todo.CreateTodo{}) {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatal("the body of POST /v2/todo-items isn't among the v2 bodies")
+	}
+}
@@ -0,0 +1,68 @@
+package openapi
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// Verify compare the routes registered on r with the document, every route
+// has to be documented and every documented operation has to be routed
+func Verify(r *mux.Router, doc *Document) error {
+	routed := map[string]bool{}
+	var missing []string
+
+	err := r.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		// subrouters have no handler of their own
+		if route.GetHandler() == nil {
+			return nil
+		}
+
+		tpl, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+
+		methods, err := route.GetMethods()
+		if err != nil {
+			methods = []string{"*"}
+		}
+
+		path := pathParam.ReplaceAllString(tpl, "{$1}")
+		for _, m := range methods {
+			key := m + " " + path
+			routed[key] = true
+
+			item := doc.Paths[path]
+			if item == nil || (*item)[strings.ToLower(m)] == nil {
+				missing = append(missing, key)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	var stale []string
+	for path, item := range doc.Paths {
+		for m := range *item {
+			if key := strings.ToUpper(m) + " " + path; !routed[key] {
+				stale = append(stale, key)
+			}
+		}
+	}
+
+	if len(missing) == 0 && len(stale) == 0 {
+		return nil
+	}
+
+	sort.Strings(missing)
+	sort.Strings(stale)
+
+	return fmt.Errorf("openapi document out of date, undocumented routes: [%s], unrouted operations: [%s]",
+		strings.Join(missing, ", "), strings.Join(stale, ", "))
+}
@@ -0,0 +1,84 @@
+package openapi_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+	"todolist-api/cmd/http/handlers/activity"
+	"todolist-api/cmd/http/handlers/batch"
+	"todolist-api/cmd/http/handlers/event"
+	"todolist-api/cmd/http/handlers/graph"
+	healthHandler "todolist-api/cmd/http/handlers/health"
+	"todolist-api/cmd/http/handlers/openapi"
+	"todolist-api/cmd/http/handlers/realtime"
+	"todolist-api/cmd/http/handlers/sync"
+	"todolist-api/cmd/http/handlers/todo"
+	"todolist-api/cmd/http/handlers/transfer"
+	"todolist-api/cmd/http/handlers/webhook"
+	"todolist-api/cmd/http/routers"
+	"todolist-api/config"
+	"todolist-api/infra/context/service"
+	"todolist-api/infra/events"
+	"todolist-api/infra/health"
+
+	"github.com/gorilla/mux"
+)
+
+// newRouter the router of the server, its handlers never called
+func newRouter(doc *openapi.Document) *mux.Router {
+	serviceCtx := &service.Ctx{}
+	broker := events.NewBroker(1, events.NewLocalFanOut())
+
+	return routers.InitialRouter(
+		activity.NewActivityHandler(serviceCtx),
+		todo.NewTodoHandler(serviceCtx),
+		webhook.NewWebhookHandler(serviceCtx),
+		event.NewEventHandler(serviceCtx, broker, time.Second),
+		realtime.NewRealtimeHandler(realtime.NewHub(serviceCtx, broker), func() []string { return nil }),
+		graph.NewGraphHandler(serviceCtx, broker, config.GraphQLConfig{}, time.Second),
+		openapi.NewOpenAPIHandler(doc),
+		healthHandler.NewHealthHandler(health.New(health.BuildInfo{}, time.Second)),
+		batch.NewBatchHandler(nil, func() config.BatchConfig { return config.BatchConfig{} }),
+		sync.NewSyncHandler(serviceCtx, func() config.SyncConfig { return config.SyncConfig{} }),
+		transfer.NewTransferHandler(serviceCtx, func() config.ImportConfig {
+			return config.ImportConfig{}
+		}, func() config.CalendarConfig {
+			return config.CalendarConfig{}
+		}),
+		routers.Options{
+			Legacy: &routers.Deprecation{Since: time.Date(2023, time.June, 1, 0, 0, 0, 0, time.UTC), Successor: routers.V1},
+			Bodies: openapi.Bodies(routers.V2),
+		},
+	)
+}
+
+func TestVerify(t *testing.T) {
+	doc := openapi.NewDocument()
+
+	if err := openapi.Verify(newRouter(doc), doc); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVerifyUndocumentedRoute(t *testing.T) {
+	doc := openapi.NewDocument()
+	r := newRouter(doc)
+	r.HandleFunc("/v1/undocumented", func(http.ResponseWriter, *http.Request) {}).Methods(http.MethodGet)
+
+	err := openapi.Verify(r, doc)
+	if err == nil || !strings.Contains(err.Error(), "GET /v1/undocumented") {
+		t.Fatalf("expected GET /v1/undocumented to be reported, got %v", err)
+	}
+}
+
+func TestVerifyUnroutedOperation(t *testing.T) {
+	doc := openapi.NewDocument()
+	r := newRouter(doc)
+	doc.Paths["/v1/unrouted"] = &openapi.PathItem{"delete": &openapi.Operation{}}
+
+	err := openapi.Verify(r, doc)
+	if err == nil || !strings.Contains(err.Error(), "DELETE /v1/unrouted") {
+		t.Fatalf("expected DELETE /v1/unrouted to be reported, got %v", err)
+	}
+}
@@ -0,0 +1,283 @@
+package realtime
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+	"todolist-api/constants"
+	"todolist-api/infra/auth"
+	"todolist-api/objects/realtime"
+	"todolist-api/objects/todo"
+
+	"github.com/gorilla/websocket"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/validator.v2"
+)
+
+const (
+	writeWait      = 10 * time.Second
+	pongWait       = 60 * time.Second
+	pingPeriod     = (pongWait * 9) / 10
+	maxMessageSize = 64 << 10
+	sendBufferSize = 64
+)
+
+// client a single websocket connection
+type client struct {
+	hub  *Hub
+	conn *websocket.Conn
+	user auth.User
+	id   string
+	send chan realtime.Message
+
+	mtx    sync.Mutex
+	groups map[int]bool
+	closed bool
+}
+
+func newClient(hub *Hub, conn *websocket.Conn, user auth.User) *client {
+	return &client{
+		hub:    hub,
+		conn:   conn,
+		user:   user,
+		id:     connectionID(),
+		send:   make(chan realtime.Message, sendBufferSize),
+		groups: make(map[int]bool),
+	}
+}
+
+func (c *client) viewer() realtime.Viewer {
+	return realtime.Viewer{
+		ConnectionID: c.id,
+		Email:        c.user.Email,
+	}
+}
+
+func (c *client) subscribed(groupID int) bool {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	return c.groups[groupID]
+}
+
+func (c *client) subscriptions() []int {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	groups := make([]int, 0, len(c.groups))
+	for groupID := range c.groups {
+		groups = append(groups, groupID)
+	}
+
+	return groups
+}
+
+// enqueue queue a message for the write pump, a client that can't keep up is disconnected
+func (c *client) enqueue(msg realtime.Message) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if c.closed {
+		return
+	}
+
+	select {
+	case c.send <- msg:
+	default:
+		c.closed = true
+		close(c.send)
+	}
+}
+
+func (c *client) close() {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if !c.closed {
+		c.closed = true
+		close(c.send)
+	}
+}
+
+// readPump decode and run the client commands until the connection fails
+func (c *client) readPump(ctx context.Context) {
+	defer func() {
+		c.hub.unregister(ctx, c)
+		c.close()
+		_ = c.conn.Close()
+	}()
+
+	c.conn.SetReadLimit(maxMessageSize)
+	_ = c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		var cmd realtime.Command
+		if err := c.conn.ReadJSON(&cmd); err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+				log.Error(err)
+			}
+			return
+		}
+
+		data, err := c.handle(ctx, cmd)
+		if err != nil {
+			c.enqueue(realtime.Message{Type: constants.MessageError, ID: cmd.ID, Error: err.Error()})
+			continue
+		}
+
+		c.enqueue(realtime.Message{Type: constants.MessageAck, ID: cmd.ID, Data: data})
+	}
+}
+
+// writePump write queued messages and keep the connection alive with pings
+func (c *client) writePump() {
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		ticker.Stop()
+		_ = c.conn.Close()
+	}()
+
+	for {
+		select {
+		case msg, ok := <-c.send:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if !ok {
+				_ = c.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, ""))
+				return
+			}
+
+			if err := c.conn.WriteJSON(msg); err != nil {
+				return
+			}
+		case <-ticker.C:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// handle run a command through the service layer and return the ack payload
+func (c *client) handle(ctx context.Context, cmd realtime.Command) (interface{}, error) {
+	switch cmd.Type {
+	case constants.CommandPing:
+		return constants.MessagePong, nil
+	case constants.CommandSubscribe:
+		if err := c.authorizeGroup(ctx, cmd.ActivityGroupID); err != nil {
+			return nil, err
+		}
+
+		c.mtx.Lock()
+		already := c.groups[cmd.ActivityGroupID]
+		c.groups[cmd.ActivityGroupID] = true
+		c.mtx.Unlock()
+
+		if !already {
+			c.hub.join(ctx, c, cmd.ActivityGroupID)
+		}
+
+		return c.hub.presence(cmd.ActivityGroupID), nil
+	case constants.CommandUnsubscribe:
+		c.mtx.Lock()
+		subscribed := c.groups[cmd.ActivityGroupID]
+		delete(c.groups, cmd.ActivityGroupID)
+		c.mtx.Unlock()
+
+		if subscribed {
+			c.hub.leave(ctx, c, cmd.ActivityGroupID)
+		}
+
+		return nil, nil
+	case constants.CommandCreate:
+		var req todo.CreateTodo
+		if err := decode(cmd.Data, &req); err != nil {
+			return nil, err
+		}
+
+		if err := c.authorizeGroup(ctx, req.ActivityGroupID); err != nil {
+			return nil, err
+		}
+
+		return c.hub.TodoService.CreateTodo(ctx, req)
+	case constants.CommandUpdate:
+		var req todo.UpdateTodo
+		if err := decode(cmd.Data, &req); err != nil {
+			return nil, err
+		}
+
+		if err := c.authorizeTodo(ctx, cmd.TodoID); err != nil {
+			return nil, err
+		}
+
+		return c.hub.TodoService.UpdateTodo(ctx, cmd.TodoID, req)
+	case constants.CommandMove:
+		if err := c.authorizeTodo(ctx, cmd.TodoID); err != nil {
+			return nil, err
+		}
+
+		if err := c.authorizeGroup(ctx, cmd.ActivityGroupID); err != nil {
+			return nil, err
+		}
+
+		return c.hub.TodoService.MoveTodo(ctx, cmd.TodoID, todo.MoveTodo{
+			ActivityGroupID: cmd.ActivityGroupID,
+		})
+	}
+
+	return nil, fmt.Errorf("unknown command %q", cmd.Type)
+}
+
+func (c *client) authorizeGroup(ctx context.Context, groupID int) error {
+	group, err := c.hub.ActivityService.GetOneActivity(ctx, groupID)
+	if err != nil {
+		return err
+	}
+
+	if !c.user.CanAccess(group.Email) {
+		return fmt.Errorf("access to activity group %d is forbidden", groupID)
+	}
+
+	return nil
+}
+
+func (c *client) authorizeTodo(ctx context.Context, todoID int) error {
+	data, err := c.hub.TodoService.GetOneTodo(ctx, todoID)
+	if err != nil {
+		return err
+	}
+
+	if c.user.Admin {
+		return nil
+	}
+
+	return c.authorizeGroup(ctx, data.ActivityGroupID)
+}
+
+func decode(data json.RawMessage, v interface{}) error {
+	if len(data) == 0 {
+		return fmt.Errorf("command data is required")
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return err
+	}
+
+	return validator.Validate(v)
+}
+
+func connectionID() string {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+
+	return hex.EncodeToString(b)
+}
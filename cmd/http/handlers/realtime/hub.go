@@ -0,0 +1,189 @@
+package realtime
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"todolist-api/constants"
+	"todolist-api/infra/context/service"
+	"todolist-api/infra/events"
+	"todolist-api/objects/realtime"
+)
+
+// Hub track the websocket clients of this process and broadcast the broker
+// events to the clients subscribed to the affected activity group. Presence is
+// derived from the presence events going through the broker, so it stays
+// consistent once the broker fan-out spans several instances.
+type Hub struct {
+	*service.Ctx
+	broker *events.Broker
+
+	mtx     sync.RWMutex
+	clients map[*client]struct{}
+	viewers map[int]map[string]realtime.Viewer
+}
+
+// NewHub create a hub fed by broker
+func NewHub(serviceCtx *service.Ctx, broker *events.Broker) *Hub {
+	return &Hub{
+		Ctx:     serviceCtx,
+		broker:  broker,
+		clients: make(map[*client]struct{}),
+		viewers: make(map[int]map[string]realtime.Viewer),
+	}
+}
+
+// Run broadcast broker events until ctx is cancelled, then disconnect every client
+func (h *Hub) Run(ctx context.Context) {
+	var lastID uint64
+
+	for {
+		sub, missed, _ := h.broker.Subscribe(lastID)
+		for _, event := range missed {
+			lastID = event.ID
+			h.dispatch(event)
+		}
+
+		if h.consume(ctx, sub, &lastID) {
+			sub.Close()
+			h.closeAll()
+			return
+		}
+	}
+}
+
+// consume read the subscription until ctx is done (true) or the broker dropped it (false)
+func (h *Hub) consume(ctx context.Context, sub *events.Subscription, lastID *uint64) bool {
+	for {
+		select {
+		case <-ctx.Done():
+			return true
+		case event, ok := <-sub.C:
+			if !ok {
+				return false
+			}
+
+			*lastID = event.ID
+			h.dispatch(event)
+		}
+	}
+}
+
+func (h *Hub) register(c *client) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+
+	h.clients[c] = struct{}{}
+}
+
+// unregister drop the client and announce it left every group it was viewing
+func (h *Hub) unregister(ctx context.Context, c *client) {
+	h.mtx.Lock()
+	_, ok := h.clients[c]
+	delete(h.clients, c)
+	h.mtx.Unlock()
+
+	if !ok {
+		return
+	}
+
+	for _, groupID := range c.subscriptions() {
+		h.leave(ctx, c, groupID)
+	}
+}
+
+func (h *Hub) join(ctx context.Context, c *client, groupID int) {
+	h.broker.Publish(ctx, events.Event{
+		Type:            constants.EventPresenceJoined,
+		ActivityGroupID: groupID,
+		Data:            c.viewer(),
+	})
+}
+
+func (h *Hub) leave(ctx context.Context, c *client, groupID int) {
+	h.broker.Publish(ctx, events.Event{
+		Type:            constants.EventPresenceLeft,
+		ActivityGroupID: groupID,
+		Data:            c.viewer(),
+	})
+}
+
+// presence return the viewers of a group sorted by connection
+func (h *Hub) presence(groupID int) []realtime.Viewer {
+	h.mtx.RLock()
+	defer h.mtx.RUnlock()
+
+	viewers := []realtime.Viewer{}
+	for _, viewer := range h.viewers[groupID] {
+		viewers = append(viewers, viewer)
+	}
+
+	sort.Slice(viewers, func(i, j int) bool {
+		return viewers[i].ConnectionID < viewers[j].ConnectionID
+	})
+
+	return viewers
+}
+
+func (h *Hub) dispatch(event events.Event) {
+	switch event.Type {
+	case constants.EventPresenceJoined, constants.EventPresenceLeft:
+		viewer, ok := event.Data.(realtime.Viewer)
+		if !ok {
+			return
+		}
+
+		h.updatePresence(event.Type, event.ActivityGroupID, viewer)
+		h.broadcast(event.ActivityGroupID, realtime.Message{
+			Type:            constants.MessagePresence,
+			ActivityGroupID: event.ActivityGroupID,
+			Data:            h.presence(event.ActivityGroupID),
+		})
+	default:
+		h.broadcast(event.ActivityGroupID, realtime.Message{
+			Type:            constants.MessageEvent,
+			Event:           event.Type,
+			EventID:         event.ID,
+			ActivityGroupID: event.ActivityGroupID,
+			Data:            event.Data,
+		})
+	}
+}
+
+func (h *Hub) updatePresence(eventType string, groupID int, viewer realtime.Viewer) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+
+	if eventType == constants.EventPresenceLeft {
+		delete(h.viewers[groupID], viewer.ConnectionID)
+		if len(h.viewers[groupID]) == 0 {
+			delete(h.viewers, groupID)
+		}
+		return
+	}
+
+	if h.viewers[groupID] == nil {
+		h.viewers[groupID] = make(map[string]realtime.Viewer)
+	}
+	h.viewers[groupID][viewer.ConnectionID] = viewer
+}
+
+func (h *Hub) broadcast(groupID int, msg realtime.Message) {
+	h.mtx.RLock()
+	defer h.mtx.RUnlock()
+
+	for c := range h.clients {
+		if c.subscribed(groupID) {
+			c.enqueue(msg)
+		}
+	}
+}
+
+func (h *Hub) closeAll() {
+	h.mtx.RLock()
+	defer h.mtx.RUnlock()
+
+	for c := range h.clients {
+		c.close()
+	}
+}
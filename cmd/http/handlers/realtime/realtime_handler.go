@@ -0,0 +1,69 @@
+package realtime
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"todolist-api/infra/auth"
+	"todolist-api/utils"
+
+	"github.com/gorilla/websocket"
+	log "github.com/sirupsen/logrus"
+)
+
+type realtimeHandler struct {
+	hub      *Hub
+	upgrader websocket.Upgrader
+}
+
+func (h realtimeHandler) ServeWS(w http.ResponseWriter, r *http.Request) {
+	user, ok := auth.FromContext(r.Context())
+	if !ok {
+		res := utils.SetResponseErrJSON(utils.MESSAGE_UNAUTHORIZED, "invalid or missing API key")
+		res.JSONErrUnauthorized(w)
+		return
+	}
+
+	// the upgrader writes its own error response
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Error(err)
+		return
+	}
+
+	c := newClient(h.hub, conn, user)
+	h.hub.register(c)
+
+	go c.writePump()
+	// the request context is gone once the handler returns
+	go c.readPump(auth.WithUser(context.Background(), user))
+}
+
+// checkOrigin accept non browser clients, same origin requests and the
+// currently allowed origins
+func checkOrigin(allowedOrigins func() []string) func(r *http.Request) bool {
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			return true
+		}
+
+		u, err := url.Parse(origin)
+		if err != nil {
+			return false
+		}
+
+		if strings.EqualFold(u.Host, r.Host) {
+			return true
+		}
+
+		for _, x := range allowedOrigins() {
+			if x == "*" || strings.EqualFold(x, origin) {
+				return true
+			}
+		}
+
+		return false
+	}
+}
@@ -0,0 +1,22 @@
+package realtime
+
+import (
+	"net/http"
+
+	"github.com/gorilla/websocket"
+)
+
+type RealtimeHandlerInterface interface {
+	ServeWS(w http.ResponseWriter, r *http.Request)
+}
+
+func NewRealtimeHandler(hub *Hub, allowedOrigins func() []string) RealtimeHandlerInterface {
+	return &realtimeHandler{
+		hub: hub,
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  4096,
+			WriteBufferSize: 4096,
+			CheckOrigin:     checkOrigin(allowedOrigins),
+		},
+	}
+}
@@ -0,0 +1,120 @@
+package sync
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"todolist-api/config"
+	"todolist-api/constants"
+	"todolist-api/infra/context/service"
+	"todolist-api/infra/idempotency"
+	"todolist-api/infra/logger"
+	"todolist-api/infra/ratelimit"
+	"todolist-api/objects/sync"
+	"todolist-api/utils"
+)
+
+type syncHandler struct {
+	*service.Ctx
+	current func() config.SyncConfig
+}
+
+// badRequest the errors of a sync request that can't be served as sent
+var badRequest = []error{
+	constants.ErrSyncTokenInvalid,
+	constants.ErrSyncEmpty,
+	constants.ErrSyncTooManyMutations,
+}
+
+func (h syncHandler) Pull(w http.ResponseWriter, r *http.Request) {
+	cfg := h.current()
+	req := sync.Pull{
+		Since: r.URL.Query().Get("since"),
+		Limit: cfg.PageSize,
+	}
+
+	if value := r.URL.Query().Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 {
+			res := utils.SetResponseErrJSON(utils.MESSAGE_BAD_REQUEST, "limit must be a positive integer")
+			res.JSONErrResponse(w)
+			return
+		}
+		if limit < req.Limit {
+			req.Limit = limit
+		}
+	}
+
+	data, err := h.SyncService.Pull(r.Context(), req)
+	if err != nil {
+		h.error(w, r, err)
+		return
+	}
+
+	res := utils.SetResponseJSON(utils.MESSAGE_SUCCESS, "Success", data)
+	res.JSONSuccessResponse(w)
+}
+
+func (h syncHandler) Push(w http.ResponseWriter, r *http.Request) {
+	var req sync.Push
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		logger.FromContext(r.Context()).Error(err)
+		res := utils.SetResponseErrJSON(http.StatusBadRequest, err.Error())
+		res.JSONErrResponse(w)
+		return
+	}
+
+	cfg := h.current()
+	if len(req.Mutations) > cfg.MaxMutations {
+		h.error(w, r, fmt.Errorf("%w, at most %d", constants.ErrSyncTooManyMutations, cfg.MaxMutations))
+		return
+	}
+	req.Policy = cfg.ConflictPolicy
+
+	data, err := h.SyncService.Push(r.Context(), req)
+	if err != nil {
+		h.error(w, r, err)
+		return
+	}
+
+	if idempotency.Replayed(r.Context()) {
+		w.Header().Set(idempotency.HeaderReplayed, "true")
+	}
+
+	res := utils.SetResponseJSON(utils.MESSAGE_SUCCESS, "Success", data)
+	res.JSONSuccessResponse(w)
+}
+
+func (h syncHandler) error(w http.ResponseWriter, r *http.Request, err error) {
+	for _, x := range badRequest {
+		if strings.Contains(err.Error(), x.Error()) {
+			logger.FromContext(r.Context()).Error(err)
+			res := utils.SetResponseErrJSON(utils.MESSAGE_BAD_REQUEST, err.Error())
+			res.JSONErrResponse(w)
+			return
+		}
+	}
+	var quota *ratelimit.QuotaExceeded
+	if errors.As(err, &quota) {
+		res := utils.SetResponseErrJSON(utils.MESSAGE_TOO_MANY_REQUESTS, err.Error())
+		res.JSONErrTooManyRequests(w, time.Until(quota.Reset))
+		return
+	}
+	if strings.Contains(err.Error(), constants.ErrIdempotencyKeyReused.Error()) {
+		res := utils.SetResponseErrJSON(utils.MESSAGE_UNPROCESSABLE, err.Error())
+		res.JSONErrUnprocessableEntity(w)
+		return
+	}
+	if strings.Contains(err.Error(), constants.ErrIdempotencyKeyInUse.Error()) {
+		res := utils.SetResponseErrJSON(utils.MESSAGE_CONFLICT, err.Error())
+		res.JSONErrConflict(w)
+		return
+	}
+	res := utils.SetResponseErrJSON(utils.MESSAGE_INTERNAL_SERVER_ERR, err.Error())
+	res.JSONErrInternalServerResponse(w)
+}
@@ -0,0 +1,20 @@
+package sync
+
+import (
+	"net/http"
+	"todolist-api/config"
+	"todolist-api/infra/context/service"
+)
+
+type SyncHandlerInterface interface {
+	Pull(w http.ResponseWriter, r *http.Request)
+	Push(w http.ResponseWriter, r *http.Request)
+}
+
+// NewSyncHandler serve the offline sync with the settings current returns
+func NewSyncHandler(serviceCtx *service.Ctx, current func() config.SyncConfig) SyncHandlerInterface {
+	return &syncHandler{
+		Ctx:     serviceCtx,
+		current: current,
+	}
+}
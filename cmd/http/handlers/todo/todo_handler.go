@@ -2,16 +2,20 @@ package todo
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 	"todolist-api/constants"
 	"todolist-api/infra/context/service"
+	"todolist-api/infra/idempotency"
+	"todolist-api/infra/logger"
+	"todolist-api/infra/ratelimit"
 	"todolist-api/objects/todo"
 	"todolist-api/utils"
 
 	"github.com/gorilla/mux"
-	log "github.com/sirupsen/logrus"
 	"gopkg.in/validator.v2"
 )
 
@@ -23,14 +27,14 @@ func (t todoHandler) CreateTodo(w http.ResponseWriter, r *http.Request) {
 	var req todo.CreateTodo
 	err := json.NewDecoder(r.Body).Decode(&req)
 	if err != nil {
-		log.Error(err)
+		logger.FromContext(r.Context()).Error(err)
 		res := utils.SetResponseErrJSON(http.StatusBadRequest, err.Error())
 		res.JSONErrResponse(w)
 		return
 	}
 
 	if err = validator.Validate(req); err != nil {
-		log.Error(err)
+		logger.FromContext(r.Context()).Error(err)
 		res := utils.SetResponseErrJSON(http.StatusBadRequest, err.Error())
 		res.JSONErrResponse(w)
 		return
@@ -38,23 +42,51 @@ func (t todoHandler) CreateTodo(w http.ResponseWriter, r *http.Request) {
 
 	data, err := t.TodoService.CreateTodo(r.Context(), req)
 	if err != nil {
-		if strings.Contains(err.Error(), constants.ErrTitleCannotBeNull.Error()) {
-			log.Error(err)
+		if strings.Contains(err.Error(), constants.ErrTitleCannotBeNull.Error()) || invalidSchedule(err) {
+			logger.FromContext(r.Context()).Error(err)
 			res := utils.SetResponseErrJSON(utils.MESSAGE_BAD_REQUEST, err.Error())
 			res.JSONErrResponse(w)
 			return
 		}
+		var quota *ratelimit.QuotaExceeded
+		if errors.As(err, &quota) {
+			res := utils.SetResponseErrJSON(utils.MESSAGE_TOO_MANY_REQUESTS, err.Error())
+			res.JSONErrTooManyRequests(w, time.Until(quota.Reset))
+			return
+		}
+		if strings.Contains(err.Error(), constants.ErrIdempotencyKeyReused.Error()) {
+			res := utils.SetResponseErrJSON(utils.MESSAGE_UNPROCESSABLE, err.Error())
+			res.JSONErrUnprocessableEntity(w)
+			return
+		}
+		if strings.Contains(err.Error(), constants.ErrIdempotencyKeyInUse.Error()) {
+			res := utils.SetResponseErrJSON(utils.MESSAGE_CONFLICT, err.Error())
+			res.JSONErrConflict(w)
+			return
+		}
 		res := utils.SetResponseErrJSON(utils.MESSAGE_INTERNAL_SERVER_ERR, err.Error())
 		res.JSONErrInternalServerResponse(w)
 		return
 	}
 
+	if idempotency.Replayed(r.Context()) {
+		w.Header().Set(idempotency.HeaderReplayed, "true")
+	}
+
 	res := utils.SetResponseJSON(utils.MESSAGE_SUCCESS, "Success", data)
 	res.JSONResponse(w)
 }
 
 func (t todoHandler) GetAllTodo(w http.ResponseWriter, r *http.Request) {
-	data, err := t.TodoService.GetAllTodo(r.Context())
+	filter, err := parseFilter(r)
+	if err != nil {
+		logger.FromContext(r.Context()).Error(err)
+		res := utils.SetResponseErrJSON(utils.MESSAGE_BAD_REQUEST, err.Error())
+		res.JSONErrResponse(w)
+		return
+	}
+
+	data, err := t.TodoService.GetAllTodo(r.Context(), filter)
 	if err != nil {
 		res := utils.SetResponseErrJSON(utils.MESSAGE_INTERNAL_SERVER_ERR, err.Error())
 		res.JSONErrInternalServerResponse(w)
@@ -70,12 +102,12 @@ func (t todoHandler) GetOneTodo(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.Atoi(queryParamID)
 	if err != nil {
 		if strings.Contains(err.Error(), ":id") {
-			log.Error(err)
+			logger.FromContext(r.Context()).Error(err)
 			res := utils.SetResponseErrNotFound(utils.MESSAGE_NOT_FOUND, utils.ErrDataNotFound(":id").Error())
 			res.JSONErrNotFound(w)
 			return
 		}
-		log.Error(err)
+		logger.FromContext(r.Context()).Error(err)
 		res := utils.SetResponseErrJSON(utils.MESSAGE_BAD_REQUEST, err.Error())
 		res.JSONErrResponse(w)
 		return
@@ -84,7 +116,7 @@ func (t todoHandler) GetOneTodo(w http.ResponseWriter, r *http.Request) {
 	data, err := t.TodoService.GetOneTodo(r.Context(), id)
 	if err != nil {
 		if strings.Contains(err.Error(), "Not Found") {
-			log.Error(err)
+			logger.FromContext(r.Context()).Error(err)
 			res := utils.SetResponseErrNotFound(utils.MESSAGE_NOT_FOUND, err.Error())
 			res.JSONErrNotFound(w)
 			return
@@ -103,12 +135,12 @@ func (t todoHandler) UpdateTodo(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.Atoi(queryParamID)
 	if err != nil {
 		if strings.Contains(err.Error(), ":id") {
-			log.Error(err)
+			logger.FromContext(r.Context()).Error(err)
 			res := utils.SetResponseErrNotFound(utils.MESSAGE_NOT_FOUND, utils.ErrDataNotFound(":id").Error())
 			res.JSONErrNotFound(w)
 			return
 		}
-		log.Error(err)
+		logger.FromContext(r.Context()).Error(err)
 		res := utils.SetResponseErrJSON(utils.MESSAGE_BAD_REQUEST, err.Error())
 		res.JSONErrResponse(w)
 		return
@@ -117,14 +149,14 @@ func (t todoHandler) UpdateTodo(w http.ResponseWriter, r *http.Request) {
 	var req todo.UpdateTodo
 	err = json.NewDecoder(r.Body).Decode(&req)
 	if err != nil {
-		log.Error(err)
+		logger.FromContext(r.Context()).Error(err)
 		res := utils.SetResponseErrJSON(http.StatusBadRequest, err.Error())
 		res.JSONErrResponse(w)
 		return
 	}
 
 	if err = validator.Validate(req); err != nil {
-		log.Error(err)
+		logger.FromContext(r.Context()).Error(err)
 		res := utils.SetResponseErrJSON(http.StatusBadRequest, err.Error())
 		res.JSONErrResponse(w)
 		return
@@ -132,15 +164,15 @@ func (t todoHandler) UpdateTodo(w http.ResponseWriter, r *http.Request) {
 
 	data, err := t.TodoService.UpdateTodo(r.Context(), id, req)
 	if err != nil {
-		if strings.Contains(err.Error(), constants.ErrTitleCannotBeNull.Error()) {
-			log.Error(err)
+		if strings.Contains(err.Error(), constants.ErrTitleCannotBeNull.Error()) || invalidSchedule(err) {
+			logger.FromContext(r.Context()).Error(err)
 			res := utils.SetResponseErrJSON(utils.MESSAGE_BAD_REQUEST, err.Error())
 			res.JSONErrResponse(w)
 			return
 		}
 
 		if strings.Contains(err.Error(), "Not Found") {
-			log.Error(err)
+			logger.FromContext(r.Context()).Error(err)
 			res := utils.SetResponseErrNotFound(utils.MESSAGE_NOT_FOUND, err.Error())
 			res.JSONErrNotFound(w)
 			return
@@ -159,12 +191,12 @@ func (t todoHandler) DeleteTodo(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.Atoi(queryParamID)
 	if err != nil {
 		if strings.Contains(err.Error(), ":id") {
-			log.Error(err)
+			logger.FromContext(r.Context()).Error(err)
 			res := utils.SetResponseErrNotFound(utils.MESSAGE_NOT_FOUND, utils.ErrDataNotFound(":id").Error())
 			res.JSONErrNotFound(w)
 			return
 		}
-		log.Error(err)
+		logger.FromContext(r.Context()).Error(err)
 		res := utils.SetResponseErrJSON(utils.MESSAGE_BAD_REQUEST, err.Error())
 		res.JSONErrResponse(w)
 		return
@@ -173,7 +205,7 @@ func (t todoHandler) DeleteTodo(w http.ResponseWriter, r *http.Request) {
 	err = t.TodoService.DeleteTodo(r.Context(), id)
 	if err != nil {
 		if strings.Contains(err.Error(), "Not Found") {
-			log.Error(err)
+			logger.FromContext(r.Context()).Error(err)
 			res := utils.SetResponseErrNotFound(utils.MESSAGE_NOT_FOUND, err.Error())
 			res.JSONErrNotFound(w)
 			return
@@ -188,3 +220,92 @@ func (t todoHandler) DeleteTodo(w http.ResponseWriter, r *http.Request) {
 	res := utils.SetResponseJSON(utils.MESSAGE_SUCCESS, "Success", data)
 	res.JSONSuccessResponse(w)
 }
+
+// parseFilter read the activity_group_id, is_active and priority query parameters
+func parseFilter(r *http.Request) (todo.FilterTodo, error) {
+	var filter todo.FilterTodo
+	query := r.URL.Query()
+
+	if v := query.Get("activity_group_id"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			return filter, err
+		}
+		filter.ActivityGroupID = id
+	}
+
+	if v := query.Get("is_active"); v != "" {
+		isActive, err := strconv.ParseBool(v)
+		if err != nil {
+			return filter, err
+		}
+		filter.IsActive = &isActive
+	}
+
+	filter.Priority = query.Get("priority")
+
+	return filter, nil
+}
+
+// bulkBadRequest the errors of a bulk request that can't be applied as sent
+var bulkBadRequest = []error{
+	constants.ErrTitleCannotBeNull,
+	constants.ErrBulkOperationInvalid,
+	constants.ErrBulkModeInvalid,
+	constants.ErrBulkEmpty,
+	constants.ErrBulkTooManyItems,
+	constants.ErrBulkNothingToSet,
+	constants.ErrActivityGroupRequired,
+	constants.ErrDueAtInvalid,
+	constants.ErrRRuleInvalid,
+	constants.ErrRRuleWithoutDueAt,
+}
+
+// invalidSchedule tell whether err is about the due date or the recurrence
+// rule of the todo
+func invalidSchedule(err error) bool {
+	return strings.Contains(err.Error(), constants.ErrDueAtInvalid.Error()) ||
+		strings.Contains(err.Error(), constants.ErrRRuleInvalid.Error()) ||
+		strings.Contains(err.Error(), constants.ErrRRuleWithoutDueAt.Error())
+}
+
+func (t todoHandler) BulkTodo(w http.ResponseWriter, r *http.Request) {
+	var req todo.BulkTodo
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		logger.FromContext(r.Context()).Error(err)
+		res := utils.SetResponseErrJSON(http.StatusBadRequest, err.Error())
+		res.JSONErrResponse(w)
+		return
+	}
+
+	data, err := t.TodoService.BulkTodo(r.Context(), req)
+	if err != nil {
+		for _, x := range bulkBadRequest {
+			if strings.Contains(err.Error(), x.Error()) {
+				logger.FromContext(r.Context()).Error(err)
+				res := utils.SetResponseErrJSON(utils.MESSAGE_BAD_REQUEST, err.Error())
+				res.JSONErrResponse(w)
+				return
+			}
+		}
+		if strings.Contains(err.Error(), "Not Found") {
+			logger.FromContext(r.Context()).Error(err)
+			res := utils.SetResponseErrNotFound(utils.MESSAGE_NOT_FOUND, err.Error())
+			res.JSONErrNotFound(w)
+			return
+		}
+		var quota *ratelimit.QuotaExceeded
+		if errors.As(err, &quota) {
+			res := utils.SetResponseErrJSON(utils.MESSAGE_TOO_MANY_REQUESTS, err.Error())
+			res.JSONErrTooManyRequests(w, time.Until(quota.Reset))
+			return
+		}
+		res := utils.SetResponseErrJSON(utils.MESSAGE_INTERNAL_SERVER_ERR, err.Error())
+		res.JSONErrInternalServerResponse(w)
+		return
+	}
+
+	res := utils.SetResponseJSON(utils.MESSAGE_SUCCESS, "Success", data)
+	res.JSONSuccessResponse(w)
+}
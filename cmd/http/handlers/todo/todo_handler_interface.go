@@ -11,6 +11,7 @@ type TodoHandlerInterface interface {
 	GetOneTodo(w http.ResponseWriter, r *http.Request)
 	UpdateTodo(w http.ResponseWriter, r *http.Request)
 	DeleteTodo(w http.ResponseWriter, r *http.Request)
+	BulkTodo(w http.ResponseWriter, r *http.Request)
 }
 
 func NewTodoHandler(serviceCtx *service.Ctx) TodoHandlerInterface {
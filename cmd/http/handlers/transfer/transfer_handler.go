@@ -0,0 +1,226 @@
+package transfer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"todolist-api/config"
+	"todolist-api/constants"
+	"todolist-api/infra/auth"
+	"todolist-api/infra/codec"
+	"todolist-api/infra/context/service"
+	"todolist-api/infra/logger"
+	"todolist-api/infra/ratelimit"
+	"todolist-api/objects/transfer"
+	"todolist-api/utils"
+
+	"github.com/gorilla/mux"
+)
+
+type transferHandler struct {
+	*service.Ctx
+	current  func() config.ImportConfig
+	calendar func() config.CalendarConfig
+}
+
+// formats the format of an import by the media type of its body
+var formats = map[string]string{
+	"application/json": constants.FormatJSON,
+	"text/csv":         constants.FormatCSV,
+	"text/markdown":    constants.FormatMarkdown,
+	"text/calendar":    constants.FormatICS,
+}
+
+func (h transferHandler) ExportActivity(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		logger.FromContext(r.Context()).Error(err)
+		res := utils.SetResponseErrJSON(utils.MESSAGE_BAD_REQUEST, err.Error())
+		res.JSONErrResponse(w)
+		return
+	}
+
+	format, ok := exportFormat(w, r)
+	if !ok {
+		return
+	}
+
+	data, err := h.TransferService.ExportActivity(r.Context(), id)
+	if err != nil {
+		if strings.Contains(err.Error(), "Not Found") {
+			logger.FromContext(r.Context()).Error(err)
+			res := utils.SetResponseErrNotFound(utils.MESSAGE_NOT_FOUND, err.Error())
+			res.JSONErrNotFound(w)
+			return
+		}
+		res := utils.SetResponseErrJSON(utils.MESSAGE_INTERNAL_SERVER_ERR, err.Error())
+		res.JSONErrInternalServerResponse(w)
+		return
+	}
+
+	user, _ := auth.FromContext(r.Context())
+	if !user.CanAccess(data.ActivityGroups[0].Email) {
+		res := utils.SetResponseErrJSON(utils.MESSAGE_FORBIDDEN, fmt.Sprintf("access to activity group %d is forbidden", id))
+		res.JSONErrForbidden(w)
+		return
+	}
+
+	write(w, r, format, fmt.Sprintf("activity-group-%d", id), data)
+}
+
+// ExportAll export the activity groups of the caller, every group for an
+// administrator
+func (h transferHandler) ExportAll(w http.ResponseWriter, r *http.Request) {
+	format, ok := exportFormat(w, r)
+	if !ok {
+		return
+	}
+
+	data, err := h.TransferService.ExportAll(r.Context(), owner(r))
+	if err != nil {
+		res := utils.SetResponseErrJSON(utils.MESSAGE_INTERNAL_SERVER_ERR, err.Error())
+		res.JSONErrInternalServerResponse(w)
+		return
+	}
+
+	write(w, r, format, "todolist-export", data)
+}
+
+// Import create the activity groups and the todos of the file in the body,
+// in the format of the format query parameter or else of the Content-Type.
+// Nothing is created when the file has an error, the report listing them on
+// a dry run.
+func (h transferHandler) Import(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		format = formats[mediaType]
+	}
+
+	dryRun := false
+	if value := r.URL.Query().Get("dry_run"); value != "" {
+		var err error
+		dryRun, err = strconv.ParseBool(value)
+		if err != nil {
+			res := utils.SetResponseErrJSON(utils.MESSAGE_BAD_REQUEST, "dry_run must be true or false")
+			res.JSONErrResponse(w)
+			return
+		}
+	}
+
+	h.importFile(w, r, transfer.Import{
+		Format: format,
+		Email:  owner(r),
+		DryRun: dryRun,
+	})
+}
+
+// importFile read the file of the body in the format of req and import it,
+// answering with the report
+func (h transferHandler) importFile(w http.ResponseWriter, r *http.Request, req transfer.Import) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, h.current().MaxSize))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			res := utils.SetResponseErrJSON(utils.MESSAGE_TOO_LARGE, fmt.Sprintf("%s, at most %d bytes", constants.ErrImportTooLarge, tooLarge.Limit))
+			res.JSONErrRequestEntityTooLarge(w)
+			return
+		}
+		logger.FromContext(r.Context()).Error(err)
+		res := utils.SetResponseErrJSON(utils.MESSAGE_BAD_REQUEST, err.Error())
+		res.JSONErrResponse(w)
+		return
+	}
+
+	req.Groups, req.Errors, err = codec.Decode(req.Format, body)
+	if err != nil {
+		logger.FromContext(r.Context()).Error(err)
+		res := utils.SetResponseErrJSON(utils.MESSAGE_BAD_REQUEST, err.Error())
+		res.JSONErrResponse(w)
+		return
+	}
+
+	data, err := h.TransferService.Import(r.Context(), req)
+	if err != nil {
+		var quota *ratelimit.QuotaExceeded
+		if errors.As(err, &quota) {
+			res := utils.SetResponseErrJSON(utils.MESSAGE_TOO_MANY_REQUESTS, err.Error())
+			res.JSONErrTooManyRequests(w, time.Until(quota.Reset))
+			return
+		}
+		res := utils.SetResponseErrJSON(utils.MESSAGE_INTERNAL_SERVER_ERR, err.Error())
+		res.JSONErrInternalServerResponse(w)
+		return
+	}
+
+	if data.DryRun {
+		res := utils.SetResponseJSON(utils.MESSAGE_SUCCESS, "Success", data)
+		res.JSONSuccessResponse(w)
+		return
+	}
+
+	if !data.Valid {
+		messages := make([]string, 0, len(data.Errors))
+		for _, x := range data.Errors {
+			messages = append(messages, x.Location+": "+x.Message)
+		}
+		res := utils.SetResponseErrJSON(utils.MESSAGE_UNPROCESSABLE, fmt.Sprintf("%s: %s", constants.ErrImportInvalid, strings.Join(messages, "; ")))
+		res.JSONErrUnprocessableEntity(w)
+		return
+	}
+
+	res := utils.SetResponseJSON(utils.MESSAGE_SUCCESS, "Success", data)
+	res.JSONResponse(w)
+}
+
+// owner the email owning the groups the caller exports or imports, none for
+// an administrator who sees every group and keeps the owners of a file
+func owner(r *http.Request) string {
+	user, _ := auth.FromContext(r.Context())
+	if user.Admin {
+		return ""
+	}
+
+	return user.Email
+}
+
+// exportFormat read the format of an export, json by default
+func exportFormat(w http.ResponseWriter, r *http.Request) (string, bool) {
+	format := r.URL.Query().Get("format")
+	switch format {
+	case "":
+		return constants.FormatJSON, true
+	case constants.FormatJSON, constants.FormatCSV, constants.FormatMarkdown:
+		return format, true
+	default:
+		res := utils.SetResponseErrJSON(utils.MESSAGE_BAD_REQUEST, constants.ErrExportFormatInvalid.Error())
+		res.JSONErrResponse(w)
+		return "", false
+	}
+}
+
+// write send the export as a file named name, encoded before anything is
+// written so a failure still gets an error response
+func write(w http.ResponseWriter, r *http.Request, format, name string, data transfer.Export) {
+	var buf bytes.Buffer
+	if err := codec.Encode(&buf, format, data); err != nil {
+		logger.FromContext(r.Context()).Error(err)
+		res := utils.SetResponseErrJSON(utils.MESSAGE_INTERNAL_SERVER_ERR, err.Error())
+		res.JSONErrInternalServerResponse(w)
+		return
+	}
+
+	w.Header().Set("Content-Type", codec.ContentType(format))
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, name, format))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		logger.FromContext(r.Context()).Error(err)
+	}
+}
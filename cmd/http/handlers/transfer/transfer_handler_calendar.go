@@ -0,0 +1,258 @@
+package transfer
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"todolist-api/constants"
+	"todolist-api/infra/auth"
+	"todolist-api/infra/codec"
+	"todolist-api/infra/logger"
+	"todolist-api/objects/transfer"
+	"todolist-api/utils"
+
+	"github.com/gorilla/mux"
+)
+
+// CalendarActivity serve the feed of an activity group to the calendar apps,
+// authenticated by the token of its URL
+func (h transferHandler) CalendarActivity(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		logger.FromContext(r.Context()).Error(err)
+		res := utils.SetResponseErrJSON(utils.MESSAGE_BAD_REQUEST, err.Error())
+		res.JSONErrResponse(w)
+		return
+	}
+
+	if !h.checkToken(w, r, activitySubject(id)) {
+		return
+	}
+
+	h.feed(w, r, id, "", fmt.Sprintf("activity-group-%d", id))
+}
+
+// CalendarAll serve the feed of every activity group of the account of the
+// email query parameter, authenticated by the token of its URL
+func (h transferHandler) CalendarAll(w http.ResponseWriter, r *http.Request) {
+	email := r.URL.Query().Get("email")
+	if !h.checkToken(w, r, accountSubject(email)) {
+		return
+	}
+
+	h.feed(w, r, 0, email, "todolist")
+}
+
+// CalendarActivityLink give the URL of the feed of an activity group
+func (h transferHandler) CalendarActivityLink(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		logger.FromContext(r.Context()).Error(err)
+		res := utils.SetResponseErrJSON(utils.MESSAGE_BAD_REQUEST, err.Error())
+		res.JSONErrResponse(w)
+		return
+	}
+
+	secret, ok := h.secret(w)
+	if !ok {
+		return
+	}
+
+	if !h.canAccess(w, r, id) {
+		return
+	}
+
+	token := calendarToken(secret, activitySubject(id))
+	res := utils.SetResponseJSON(utils.MESSAGE_SUCCESS, "Success", feedLink(r, url.Values{"token": {token}}, token))
+	res.JSONSuccessResponse(w)
+}
+
+// CalendarLink give the URL of the feed of every activity group of the
+// caller, every group for an administrator
+func (h transferHandler) CalendarLink(w http.ResponseWriter, r *http.Request) {
+	secret, ok := h.secret(w)
+	if !ok {
+		return
+	}
+
+	email := owner(r)
+	token := calendarToken(secret, accountSubject(email))
+	query := url.Values{"token": {token}}
+	if email != "" {
+		query.Set("email", email)
+	}
+
+	res := utils.SetResponseJSON(utils.MESSAGE_SUCCESS, "Success", feedLink(r, query, token))
+	res.JSONSuccessResponse(w)
+}
+
+// ImportCalendar add the VTODOs of the iCalendar file in the body to an
+// activity group, as an import does
+func (h transferHandler) ImportCalendar(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		logger.FromContext(r.Context()).Error(err)
+		res := utils.SetResponseErrJSON(utils.MESSAGE_BAD_REQUEST, err.Error())
+		res.JSONErrResponse(w)
+		return
+	}
+
+	dryRun := false
+	if value := r.URL.Query().Get("dry_run"); value != "" {
+		dryRun, err = strconv.ParseBool(value)
+		if err != nil {
+			res := utils.SetResponseErrJSON(utils.MESSAGE_BAD_REQUEST, "dry_run must be true or false")
+			res.JSONErrResponse(w)
+			return
+		}
+	}
+
+	if !h.canAccess(w, r, id) {
+		return
+	}
+
+	h.importFile(w, r, transfer.Import{
+		Format:          constants.FormatICS,
+		ActivityGroupID: id,
+		DryRun:          dryRun,
+	})
+}
+
+// feed send the calendar of the activity group id, or of every group of
+// email when id is zero, as an iCalendar file named name
+func (h transferHandler) feed(w http.ResponseWriter, r *http.Request, id int, email, name string) {
+	data, err := h.TransferService.Calendar(r.Context(), id, email)
+	if err != nil {
+		if strings.Contains(err.Error(), "Not Found") {
+			logger.FromContext(r.Context()).Error(err)
+			res := utils.SetResponseErrNotFound(utils.MESSAGE_NOT_FOUND, err.Error())
+			res.JSONErrNotFound(w)
+			return
+		}
+		res := utils.SetResponseErrJSON(utils.MESSAGE_INTERNAL_SERVER_ERR, err.Error())
+		res.JSONErrInternalServerResponse(w)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := codec.EncodeCalendar(&buf, data); err != nil {
+		logger.FromContext(r.Context()).Error(err)
+		res := utils.SetResponseErrJSON(utils.MESSAGE_INTERNAL_SERVER_ERR, err.Error())
+		res.JSONErrInternalServerResponse(w)
+		return
+	}
+
+	w.Header().Set("Content-Type", codec.ContentType(constants.FormatICS))
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`inline; filename="%s.%s"`, name, constants.FormatICS))
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		logger.FromContext(r.Context()).Error(err)
+	}
+}
+
+// secret the secret signing the tokens of the feeds, answering not found
+// when the feeds are off
+func (h transferHandler) secret(w http.ResponseWriter) (string, bool) {
+	secret := h.calendar().Secret
+	if secret == "" {
+		res := utils.SetResponseErrNotFound(utils.MESSAGE_NOT_FOUND, constants.ErrCalendarDisabled.Error())
+		res.JSONErrNotFound(w)
+		return "", false
+	}
+
+	return secret, true
+}
+
+// checkToken tell whether the token query parameter is the one of subject,
+// answering forbidden when it is not
+func (h transferHandler) checkToken(w http.ResponseWriter, r *http.Request, subject string) bool {
+	secret, ok := h.secret(w)
+	if !ok {
+		return false
+	}
+
+	token := r.URL.Query().Get("token")
+	if !hmac.Equal([]byte(token), []byte(calendarToken(secret, subject))) {
+		res := utils.SetResponseErrJSON(utils.MESSAGE_FORBIDDEN, constants.ErrCalendarTokenInvalid.Error())
+		res.JSONErrForbidden(w)
+		return false
+	}
+
+	return true
+}
+
+// canAccess tell whether the caller owns the activity group id, answering
+// not found or forbidden when it does not
+func (h transferHandler) canAccess(w http.ResponseWriter, r *http.Request, id int) bool {
+	data, err := h.ActivityService.GetOneActivity(r.Context(), id)
+	if err != nil {
+		if strings.Contains(err.Error(), "Not Found") {
+			logger.FromContext(r.Context()).Error(err)
+			res := utils.SetResponseErrNotFound(utils.MESSAGE_NOT_FOUND, err.Error())
+			res.JSONErrNotFound(w)
+			return false
+		}
+		res := utils.SetResponseErrJSON(utils.MESSAGE_INTERNAL_SERVER_ERR, err.Error())
+		res.JSONErrInternalServerResponse(w)
+		return false
+	}
+
+	user, _ := auth.FromContext(r.Context())
+	if !user.CanAccess(data.Email) {
+		res := utils.SetResponseErrJSON(utils.MESSAGE_FORBIDDEN, fmt.Sprintf("access to activity group %d is forbidden", id))
+		res.JSONErrForbidden(w)
+		return false
+	}
+
+	return true
+}
+
+// calendarToken the token of the feed of subject, signed with the secret so
+// it needs no storage and changes along with the secret
+func calendarToken(secret, subject string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(subject))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// activitySubject what the token of the feed of an activity group signs
+func activitySubject(id int) string {
+	return fmt.Sprintf("activity-group:%d", id)
+}
+
+// accountSubject what the token of the feed of an account signs, every group
+// for an empty email
+func accountSubject(email string) string {
+	return "account:" + strings.ToLower(email)
+}
+
+// feedLink the URL of the feed of the link requested, its path ending in .ics
+func feedLink(r *http.Request, query url.Values, token string) transfer.CalendarLink {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto != "" {
+		scheme = proto
+	}
+
+	link := url.URL{
+		Scheme:   scheme,
+		Host:     r.Host,
+		Path:     r.URL.Path + ".ics",
+		RawQuery: query.Encode(),
+	}
+
+	return transfer.CalendarLink{
+		URL:   link.String(),
+		Token: token,
+	}
+}
@@ -0,0 +1,28 @@
+package transfer
+
+import (
+	"net/http"
+	"todolist-api/config"
+	"todolist-api/infra/context/service"
+)
+
+type TransferHandlerInterface interface {
+	ExportActivity(w http.ResponseWriter, r *http.Request)
+	ExportAll(w http.ResponseWriter, r *http.Request)
+	Import(w http.ResponseWriter, r *http.Request)
+	CalendarActivity(w http.ResponseWriter, r *http.Request)
+	CalendarAll(w http.ResponseWriter, r *http.Request)
+	CalendarActivityLink(w http.ResponseWriter, r *http.Request)
+	CalendarLink(w http.ResponseWriter, r *http.Request)
+	ImportCalendar(w http.ResponseWriter, r *http.Request)
+}
+
+// NewTransferHandler serve the exports, the imports and the calendar feeds
+// with the settings current and calendar return
+func NewTransferHandler(serviceCtx *service.Ctx, current func() config.ImportConfig, calendar func() config.CalendarConfig) TransferHandlerInterface {
+	return &transferHandler{
+		Ctx:      serviceCtx,
+		current:  current,
+		calendar: calendar,
+	}
+}
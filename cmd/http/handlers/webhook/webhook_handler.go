@@ -0,0 +1,229 @@
+package webhook
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"todolist-api/constants"
+	"todolist-api/infra/context/service"
+	"todolist-api/infra/errors"
+	"todolist-api/infra/logger"
+	"todolist-api/objects/webhook"
+	"todolist-api/utils"
+
+	"github.com/gorilla/mux"
+	"gopkg.in/validator.v2"
+)
+
+type webhookHandler struct {
+	*service.Ctx
+}
+
+func (h webhookHandler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	var req webhook.CreateWebhook
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		logger.FromContext(r.Context()).Error(err)
+		res := utils.SetResponseErrJSON(http.StatusBadRequest, err.Error())
+		res.JSONErrResponse(w)
+		return
+	}
+
+	if err = validator.Validate(req); err != nil {
+		logger.FromContext(r.Context()).Error(err)
+		res := utils.SetResponseErrJSON(http.StatusBadRequest, err.Error())
+		res.JSONErrResponse(w)
+		return
+	}
+
+	data, err := h.WebhookService.CreateWebhook(r.Context(), req)
+	if err != nil {
+		if isValidationErr(err) {
+			logger.FromContext(r.Context()).Error(err)
+			res := utils.SetResponseErrJSON(utils.MESSAGE_BAD_REQUEST, err.Error())
+			res.JSONErrResponse(w)
+			return
+		}
+		res := utils.SetResponseErrJSON(utils.MESSAGE_INTERNAL_SERVER_ERR, err.Error())
+		res.JSONErrInternalServerResponse(w)
+		return
+	}
+
+	res := utils.SetResponseJSON(utils.MESSAGE_SUCCESS, "Success", data)
+	res.JSONResponse(w)
+}
+
+func (h webhookHandler) GetAllWebhook(w http.ResponseWriter, r *http.Request) {
+	data, err := h.WebhookService.GetAllWebhook(r.Context())
+	if err != nil {
+		res := utils.SetResponseErrJSON(utils.MESSAGE_INTERNAL_SERVER_ERR, err.Error())
+		res.JSONErrInternalServerResponse(w)
+		return
+	}
+
+	res := utils.SetResponseJSON(utils.MESSAGE_SUCCESS, "Success", data)
+	res.JSONSuccessResponse(w)
+}
+
+func (h webhookHandler) GetOneWebhook(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	data, err := h.WebhookService.GetOneWebhook(r.Context(), id)
+	if err != nil {
+		if strings.Contains(err.Error(), "Not Found") {
+			logger.FromContext(r.Context()).Error(err)
+			res := utils.SetResponseErrNotFound(utils.MESSAGE_NOT_FOUND, err.Error())
+			res.JSONErrNotFound(w)
+			return
+		}
+		res := utils.SetResponseErrJSON(utils.MESSAGE_INTERNAL_SERVER_ERR, err.Error())
+		res.JSONErrInternalServerResponse(w)
+		return
+	}
+
+	res := utils.SetResponseJSON(utils.MESSAGE_SUCCESS, "Success", data)
+	res.JSONSuccessResponse(w)
+}
+
+func (h webhookHandler) UpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	var req webhook.UpdateWebhook
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		logger.FromContext(r.Context()).Error(err)
+		res := utils.SetResponseErrJSON(http.StatusBadRequest, err.Error())
+		res.JSONErrResponse(w)
+		return
+	}
+
+	if err = validator.Validate(req); err != nil {
+		logger.FromContext(r.Context()).Error(err)
+		res := utils.SetResponseErrJSON(http.StatusBadRequest, err.Error())
+		res.JSONErrResponse(w)
+		return
+	}
+
+	data, err := h.WebhookService.UpdateWebhook(r.Context(), id, req)
+	if err != nil {
+		if isValidationErr(err) {
+			logger.FromContext(r.Context()).Error(err)
+			res := utils.SetResponseErrJSON(utils.MESSAGE_BAD_REQUEST, err.Error())
+			res.JSONErrResponse(w)
+			return
+		}
+
+		if strings.Contains(err.Error(), "Not Found") {
+			logger.FromContext(r.Context()).Error(err)
+			res := utils.SetResponseErrNotFound(utils.MESSAGE_NOT_FOUND, err.Error())
+			res.JSONErrNotFound(w)
+			return
+		}
+		res := utils.SetResponseErrJSON(utils.MESSAGE_INTERNAL_SERVER_ERR, err.Error())
+		res.JSONErrInternalServerResponse(w)
+		return
+	}
+
+	res := utils.SetResponseJSON(utils.MESSAGE_SUCCESS, "Success", data)
+	res.JSONSuccessResponse(w)
+}
+
+func (h webhookHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.WebhookService.DeleteWebhook(r.Context(), id)
+	if err != nil {
+		if strings.Contains(err.Error(), "Not Found") {
+			logger.FromContext(r.Context()).Error(err)
+			res := utils.SetResponseErrNotFound(utils.MESSAGE_NOT_FOUND, err.Error())
+			res.JSONErrNotFound(w)
+			return
+		}
+		res := utils.SetResponseErrJSON(utils.MESSAGE_INTERNAL_SERVER_ERR, err.Error())
+		res.JSONErrInternalServerResponse(w)
+		return
+	}
+
+	data := make(map[string]interface{})
+
+	res := utils.SetResponseJSON(utils.MESSAGE_SUCCESS, "Success", data)
+	res.JSONSuccessResponse(w)
+}
+
+func (h webhookHandler) GetDeadDelivery(w http.ResponseWriter, r *http.Request) {
+	data, err := h.WebhookService.GetDeadDelivery(r.Context())
+	if err != nil {
+		res := utils.SetResponseErrJSON(utils.MESSAGE_INTERNAL_SERVER_ERR, err.Error())
+		res.JSONErrInternalServerResponse(w)
+		return
+	}
+
+	res := utils.SetResponseJSON(utils.MESSAGE_SUCCESS, "Success", data)
+	res.JSONSuccessResponse(w)
+}
+
+func (h webhookHandler) RedeliverDelivery(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	data, err := h.WebhookService.RedeliverDelivery(r.Context(), id)
+	if err != nil {
+		if strings.Contains(err.Error(), "Not Found") {
+			logger.FromContext(r.Context()).Error(err)
+			res := utils.SetResponseErrNotFound(utils.MESSAGE_NOT_FOUND, err.Error())
+			res.JSONErrNotFound(w)
+			return
+		}
+		if errors.Is(err, constants.ErrDeliveryNotDead) {
+			res := utils.SetResponseErrJSON(utils.MESSAGE_CONFLICT, err.Error())
+			res.JSONErrConflict(w)
+			return
+		}
+		res := utils.SetResponseErrJSON(utils.MESSAGE_INTERNAL_SERVER_ERR, err.Error())
+		res.JSONErrInternalServerResponse(w)
+		return
+	}
+
+	res := utils.SetResponseJSON(utils.MESSAGE_SUCCESS, "Success", data)
+	res.JSONSuccessResponse(w)
+}
+
+// pathID parse the {id} path variable, writing a bad request response when it is not a number
+func pathID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		logger.FromContext(r.Context()).Error(err)
+		res := utils.SetResponseErrJSON(utils.MESSAGE_BAD_REQUEST, err.Error())
+		res.JSONErrResponse(w)
+		return 0, false
+	}
+
+	return id, true
+}
+
+func isValidationErr(err error) bool {
+	for _, x := range []error{
+		constants.ErrURLCannotBeNull,
+		constants.ErrURLInvalid,
+		constants.ErrSecretCannotBeNull,
+		constants.ErrEventTypesCannotBeNull,
+	} {
+		if strings.Contains(err.Error(), x.Error()) {
+			return true
+		}
+	}
+
+	return strings.Contains(err.Error(), "is not supported")
+}
@@ -0,0 +1,22 @@
+package webhook
+
+import (
+	"net/http"
+	"todolist-api/infra/context/service"
+)
+
+type WebhookHandlerInterface interface {
+	CreateWebhook(w http.ResponseWriter, r *http.Request)
+	GetAllWebhook(w http.ResponseWriter, r *http.Request)
+	GetOneWebhook(w http.ResponseWriter, r *http.Request)
+	UpdateWebhook(w http.ResponseWriter, r *http.Request)
+	DeleteWebhook(w http.ResponseWriter, r *http.Request)
+	GetDeadDelivery(w http.ResponseWriter, r *http.Request)
+	RedeliverDelivery(w http.ResponseWriter, r *http.Request)
+}
+
+func NewWebhookHandler(serviceCtx *service.Ctx) WebhookHandlerInterface {
+	return &webhookHandler{
+		serviceCtx,
+	}
+}
@@ -0,0 +1,126 @@
+package webhook_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+	"todolist-api/cmd/http/handlers/webhook"
+	"todolist-api/config"
+	"todolist-api/constants"
+	"todolist-api/data/models"
+	"todolist-api/data/repositories/webhook/webhooktest"
+	"todolist-api/infra/context/repository"
+	"todolist-api/infra/context/service"
+	"todolist-api/infra/db"
+	"todolist-api/infra/db/dbtest"
+	dispatcher "todolist-api/infra/webhook"
+
+	"github.com/gorilla/mux"
+)
+
+func TestRedeliverDelivery(t *testing.T) {
+	var received int32
+	rcv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&received, 1)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer rcv.Close()
+
+	conn, err := db.Open(&config.DBConfig{Name: dbtest.Register(&dbtest.Driver{}), Host: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	repo := webhooktest.New()
+	repoCtx := &repository.RepoCtx{DB: conn, WebhookRepository: repo}
+	ctx := context.Background()
+
+	hook, _ := repo.CreateWebhook(ctx, nil, models.Webhook{
+		URL:        rcv.URL,
+		Secret:     "s3cret",
+		EventTypes: constants.EventTodoCreated,
+		IsActive:   true,
+	})
+	dead, _ := repo.CreateDelivery(ctx, nil, models.WebhookDelivery{
+		WebhookID:      hook.WebhookID,
+		EventID:        1,
+		EventType:      constants.EventTodoCreated,
+		Payload:        "{}",
+		Status:         constants.DeliveryStatusDead,
+		Attempts:       8,
+		NextAttemptAt:  time.Now().Add(-time.Hour),
+		LastStatusCode: http.StatusBadGateway,
+		LastError:      "receiver responded with status 502",
+	})
+
+	r := mux.NewRouter()
+	r.HandleFunc("/v1/webhook-deliveries/{id}/redeliver", webhook.NewWebhookHandler(service.NewCtx(repoCtx)).RedeliverDelivery).Methods(http.MethodPost)
+	redeliver := func(id string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/webhook-deliveries/"+id+"/redeliver", nil))
+		return w
+	}
+
+	w := redeliver(strconv.Itoa(dead.DeliveryID))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", w.Code, w.Body.String())
+	}
+	var res struct {
+		Data struct {
+			ID        int    `json:"id"`
+			Status    string `json:"status"`
+			Attempts  int    `json:"attempts"`
+			LastError string `json:"last_error"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatal(err)
+	}
+	// a fresh retry cycle, the previous error kept for reference
+	if res.Data.ID != dead.DeliveryID || res.Data.Status != constants.DeliveryStatusPending || res.Data.Attempts != 0 || res.Data.LastError != dead.LastError {
+		t.Fatalf("unexpected delivery %s", w.Body.String())
+	}
+
+	// due at once, the dispatcher sends it on its next tick
+	if err := dispatcher.NewDispatcher(repoCtx, config.WebhookConfig{}).Tick(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&received); n != 1 {
+		t.Fatalf("%d requests, expected 1", n)
+	}
+	if sent := repo.Delivery(dead.DeliveryID); sent.Status != constants.DeliveryStatusDelivered || sent.Attempts != 1 {
+		t.Fatalf("unexpected delivery %+v", sent)
+	}
+
+	// only a dead delivery is sent again, the others would be sent twice
+	pending, _ := repo.CreateDelivery(ctx, nil, models.WebhookDelivery{
+		WebhookID:     hook.WebhookID,
+		EventID:       2,
+		EventType:     constants.EventTodoCreated,
+		Payload:       "{}",
+		Status:        constants.DeliveryStatusPending,
+		Attempts:      3,
+		NextAttemptAt: time.Now().Add(time.Hour),
+	})
+	for _, x := range []models.WebhookDelivery{repo.Delivery(dead.DeliveryID), pending} {
+		if w := redeliver(strconv.Itoa(x.DeliveryID)); w.Code != http.StatusConflict {
+			t.Fatalf("%s delivery: status %d, expected 409", x.Status, w.Code)
+		}
+		if kept := repo.Delivery(x.DeliveryID); kept.Status != x.Status || kept.Attempts != x.Attempts {
+			t.Fatalf("%s delivery changed to %+v", x.Status, kept)
+		}
+	}
+
+	if w := redeliver("999"); w.Code != http.StatusNotFound {
+		t.Fatalf("unknown delivery: status %d", w.Code)
+	}
+	if w := redeliver("abc"); w.Code != http.StatusBadRequest {
+		t.Fatalf("invalid id: status %d", w.Code)
+	}
+}
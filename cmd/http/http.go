@@ -2,33 +2,52 @@ package http
 
 import (
 	"context"
-	"flag"
 	"fmt"
-	"log"
+	"net"
 	"net/http"
-	"os"
-	"os/signal"
 	"time"
+	grpcServer "todolist-api/cmd/grpc"
 	"todolist-api/cmd/http/handlers/activity"
+	"todolist-api/cmd/http/handlers/batch"
+	"todolist-api/cmd/http/handlers/event"
+	"todolist-api/cmd/http/handlers/graph"
+	healthHandler "todolist-api/cmd/http/handlers/health"
+	"todolist-api/cmd/http/handlers/openapi"
+	"todolist-api/cmd/http/handlers/realtime"
+	"todolist-api/cmd/http/handlers/sync"
 	"todolist-api/cmd/http/handlers/todo"
+	"todolist-api/cmd/http/handlers/transfer"
+	"todolist-api/cmd/http/handlers/webhook"
+	"todolist-api/cmd/http/middleware"
 	"todolist-api/cmd/http/routers"
 	"todolist-api/config"
-	activityRepository "todolist-api/data/repositories/activity"
-	todoRepository "todolist-api/data/repositories/todo"
+	"todolist-api/infra/auth"
 	"todolist-api/infra/db"
+	"todolist-api/infra/events"
+	"todolist-api/infra/health"
+	"todolist-api/infra/idempotency"
+	"todolist-api/infra/lifecycle"
+	"todolist-api/infra/logger"
+	"todolist-api/infra/metrics"
+	"todolist-api/infra/ratelimit"
+	"todolist-api/infra/tracing"
+	webhookDispatcher "todolist-api/infra/webhook"
+	"todolist-api/migrations"
 
 	"todolist-api/infra/context/repository"
 	"todolist-api/infra/context/service"
 
-	activityService "todolist-api/cmd/services/activity"
-	todoService "todolist-api/cmd/services/todo"
-
-	"github.com/rs/cors"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
 
 var (
+	// build of the binary, reported by the probes
+	buildInfo health.BuildInfo
+
+	// how long the shutdown waits for requests and workers, server.gracefulTimeout when unset
+	gracefulTimeout time.Duration
+
 	routerCMD = &cobra.Command{
 		Use:   "serve-http",
 		Short: "Run http server",
@@ -37,112 +56,306 @@ var (
 	}
 )
 
-// initRepoCtx for context repository
-func initRepoCtx(db *db.DB) *repository.RepoCtx {
-	activityRepository := activityRepository.NewActivityRepository(db)
-	todoRepository := todoRepository.NewTodoRepository(db)
-
-	return &repository.RepoCtx{
-		DB:                 db,
-		ActivityRepository: activityRepository,
-		TodoRepository:     todoRepository,
-	}
-}
-
-// initServiceCtx for contextService
-func initServiceCtx(ctx *repository.RepoCtx) *service.Ctx {
-	activityService := activityService.NewActivityService(ctx)
-	todoService := todoService.NewTodoService(ctx)
-
-	return &service.Ctx{
-		ActivityService: activityService,
-		TodoService:     todoService,
-	}
+func init() {
+	routerCMD.Flags().DurationVar(&gracefulTimeout, "graceful-timeout", 0, "the duration for which the server gracefully wait for existing connections and workers to finish - e.g. 15s or 1m")
+	routerCMD.Flags().String("addr", "", "address of the API, overrides server.addr")
+	routerCMD.Flags().String("log-level", "", "log level, overrides log.level")
 }
 
 func runHTTP(cmd *cobra.Command, args []string) error {
+	// errors past this point aren't about the usage
+	cmd.SilenceUsage = true
+
 	// initial config
 	ctx := context.Background()
+	// flags override the file and the environment
+	config.BindFlag("server.addr", cmd.Flags().Lookup("addr"))
+	config.BindFlag("log.level", cmd.Flags().Lookup("log-level"))
 	cfg := config.InitConfig()
+	if err := logger.Configure(cfg.Log); err != nil {
+		return err
+	}
+
+	if !cmd.Flags().Changed("graceful-timeout") {
+		gracefulTimeout = time.Second * time.Duration(cfg.Server.GraceFulTimeout)
+	}
+
+	// stops the servers, then the workers, then closes the database on SIGINT or SIGTERM
+	lc := lifecycle.New(gracefulTimeout)
+
+	// prometheus collectors, served on the admin listener when configured
+	m := metrics.New()
+
+	// tracer provider, flushed on shutdown
+	shutdownTracing, err := tracing.Init(ctx, cfg.Tracing)
+	if err != nil {
+		return lc.Fail(err)
+	}
+	lc.OnClose("tracing", shutdownTracing)
 
 	// this Pings the database trying to connect, panics on error
 	// use sqlx.Open() for sql.Open() semantics
-	db, err := db.Open(&cfg.DB)
+	db, err := db.Open(&cfg.DB, m.DBHooks())
 	if err != nil {
-		log.Fatalln(err)
+		return lc.Fail(err)
 	}
+	lc.OnClose("database", func(context.Context) error {
+		return db.Close()
+	})
 
-	var wait time.Duration
-	flag.DurationVar(&wait, "graceful-timeout", time.Second*time.Duration(cfg.Server.GraceFulTimeout), "the duration for which the server gracefully wait for existing connections to finish - e.g. 15s or 1m")
-	flag.Parse()
+	// live change broker, fed by the services after each commit
+	broker := events.NewBroker(cfg.Events.BufferSize, events.NewLocalFanOut())
 
 	// init repo ctx
-	repoCtx := initRepoCtx(db)
+	repoCtx := repository.NewRepoCtx(db, broker)
+
+	// live settings, reloaded on change of the config file or on SIGHUP
+	corsHandler := middleware.NewCORS(cfg.CORS)
+	store := config.NewStore(cfg)
+	store.OnChange(func(old, cfg config.Config) {
+		if err := logger.Configure(cfg.Log); err != nil {
+			logrus.Error(err)
+		}
+		corsHandler.Update(cfg.CORS)
+		db.SetMaxOpenConns(cfg.DB.MaxOpenConn)
+		db.SetMaxIdleConns(cfg.DB.MaxIdleConn)
+		db.SetConnMaxLifetime(time.Duration(cfg.DB.ConnMaxLifetime) * time.Second)
+		db.SetConnMaxIdleTime(time.Duration(cfg.DB.ConnMaxIdleTime) * time.Second)
+	})
+
+	// rate limit buckets and quota counters, shared through redis when configured
+	limitStore := ratelimit.NewStore(cfg.RateLimit)
+	todoQuota := ratelimit.NewQuota(limitStore, "todo", func() int {
+		return store.Get().RateLimit.TodoDailyQuota
+	})
 
 	// init service ctx
-	serviceCtx := initServiceCtx(repoCtx)
+	serviceCtx := service.Trace(service.Instrument(service.Limit(service.NewCtx(repoCtx), todoQuota), m))
+
+	m.Register(
+		metrics.NewDBStatsCollector(db.Stats),
+		metrics.NewTodoCollector(func(ctx context.Context) (map[string]int, error) {
+			counts, err := repoCtx.TodoRepository.CountTodoByState(ctx)
+			if err != nil {
+				return nil, err
+			}
+
+			res := map[string]int{"active": 0, "done": 0}
+			for _, x := range counts {
+				if x.IsActive {
+					res["active"] += x.Count
+				} else {
+					res["done"] += x.Count
+				}
+			}
+
+			return res, nil
+		}),
+	)
+
+	// probes, /readyz fails as soon as the shutdown starts
+	probes := health.New(buildInfo, time.Duration(cfg.Health.Timeout)*time.Second)
+	probes.Register(
+		health.Database(db),
+		health.Migrations(db, migrations.FS),
+	)
+
+	// websocket hub, lives as long as the server
+	hub := realtime.NewHub(serviceCtx, broker)
 
 	// init handler
 	activityHandler := activity.NewActivityHandler(serviceCtx)
 	todoHandler := todo.NewTodoHandler(serviceCtx)
+	webhookHandler := webhook.NewWebhookHandler(serviceCtx)
+	eventHandler := event.NewEventHandler(serviceCtx, broker, time.Duration(cfg.Events.Heartbeat)*time.Second)
+	realtimeHandler := realtime.NewRealtimeHandler(hub, func() []string {
+		return store.Get().CORS.AllowedOrigins
+	})
+	graphHandler := graph.NewGraphHandler(serviceCtx, broker, cfg.GraphQL, time.Duration(cfg.Events.Heartbeat)*time.Second)
+	apiDoc := openapi.NewDocument()
+	openapiHandler := openapi.NewOpenAPIHandler(apiDoc)
+	probeHandler := healthHandler.NewHealthHandler(probes)
+	batchHandler := batch.NewBatchHandler(db, func() config.BatchConfig {
+		return store.Get().Batch
+	})
+	syncHandler := sync.NewSyncHandler(serviceCtx, func() config.SyncConfig {
+		return store.Get().Sync
+	})
+	transferHandler := transfer.NewTransferHandler(serviceCtx, func() config.ImportConfig {
+		return store.Get().Import
+	}, func() config.CalendarConfig {
+		return store.Get().Calendar
+	})
+
+	// unversioned aliases of /v1, announced as deprecated
+	since, sunset, err := cfg.Legacy.Dates()
+	if err != nil {
+		return lc.Fail(err)
+	}
 
 	// initial router
 	r := routers.InitialRouter(
 		activityHandler,
 		todoHandler,
+		webhookHandler,
+		eventHandler,
+		realtimeHandler,
+		graphHandler,
+		openapiHandler,
+		probeHandler,
+		batchHandler,
+		syncHandler,
+		transferHandler,
+		routers.Options{
+			Legacy: &routers.Deprecation{Since: since, Sunset: sunset, Successor: routers.V1},
+			Bodies: openapi.Bodies(routers.V2),
+		},
+	)
+	r.Use(
+		middleware.RouteTemplate,
+		middleware.Features(func() config.FeaturesConfig {
+			return store.Get().Features
+		}),
+		auth.Middleware(cfg.Auth, append(append([]string{}, routers.PublicPaths...), routers.FeedPaths...)...),
+		middleware.RateLimit(ratelimit.NewLimiter(limitStore), func() config.RateLimitConfig {
+			return store.Get().RateLimit
+		}, routers.PublicPaths...),
+		middleware.Idempotency(func() config.IdempotencyConfig {
+			return store.Get().Idempotency
+		}),
 	)
 
-	corsHandler := cors.New(cors.Options{
-		AllowedHeaders: []string{"Origin", "Authorization", "Content-Type", "Access-Control-Allow-Origin", "API-KEY"},
-		AllowedMethods: []string{"HEAD", "PUT", "PATCH", "GET", "POST", "DELETE", "OPTIONS"},
-		AllowedOrigins: []string{
-			"http://localhost:3030",
-		},
-		OptionsPassthrough: false,
-		AllowCredentials:   true,
-	})
+	// refuse to start with routes missing from the API document
+	if err := openapi.Verify(r, apiDoc); err != nil {
+		return lc.Fail(err)
+	}
+
+	// listeners bound before any worker starts, so a taken address fails the startup
+	lis, err := net.Listen("tcp", cfg.Server.Addr)
+	if err != nil {
+		return lc.Fail(err)
+	}
+	listeners := []net.Listener{lis}
+	// closes the listeners not served yet when the startup fails
+	fail := func(err error) error {
+		for _, l := range listeners {
+			_ = l.Close()
+		}
+		return lc.Fail(err)
+	}
+
+	var adminLis net.Listener
+	if cfg.Server.AdminAddr != "" {
+		adminLis, err = net.Listen("tcp", cfg.Server.AdminAddr)
+		if err != nil {
+			return fail(err)
+		}
+		listeners = append(listeners, adminLis)
+	}
+
+	// optional gRPC listener
+	var grpcLis net.Listener
+	if cfg.Server.GRPCAddr != "" {
+		grpcLis, err = net.Listen("tcp", cfg.Server.GRPCAddr)
+		if err != nil {
+			return fail(err)
+		}
+	}
+
+	lc.Go("config watcher", store.Watch)
+	lc.Go("websocket hub", hub.Run)
+
+	// webhook delivery worker, stopped on shutdown
+	lc.Go("webhook dispatcher", webhookDispatcher.NewDispatcher(repoCtx, cfg.Webhook).Run)
+
+	// deletes the Idempotency-Key past their TTL
+	lc.Go("idempotency cleaner", idempotency.NewCleaner(repoCtx.IdempotencyRepository, time.Duration(cfg.Idempotency.CleanupInterval)*time.Second).Run)
+
+	// request ID, tracing, access log, metrics and panic recovery around every request
+	var handler http.Handler = middleware.Chain(
+		corsHandler.Handler(routers.TrimTrailingSlash(r)),
+		middleware.RequestID,
+		middleware.Tracing,
+		middleware.AccessLog(middleware.NewAccessLogger()),
+		middleware.Metrics(m),
+		middleware.Recover,
+	)
+
+	// metrics are served next to the API to the admins only unless an admin
+	// listener is configured
+	var adminSrv *http.Server
+	if adminLis != nil {
+		adminMux := http.NewServeMux()
+		adminMux.Handle("/metrics", m.Handler())
+		adminSrv = &http.Server{
+			Handler:           adminMux,
+			WriteTimeout:      time.Duration(cfg.Server.WriteTimeout) * time.Second,
+			ReadTimeout:       time.Duration(cfg.Server.ReadTimeout) * time.Second,
+			ReadHeaderTimeout: time.Duration(cfg.Server.ReadHeaderTimeout) * time.Second,
+			IdleTimeout:       time.Duration(cfg.Server.IdleTimeout) * time.Second,
+		}
+
+		lc.Serve("admin server", func() error {
+			return adminSrv.Serve(adminLis)
+		})
+		lc.OnStop("admin server", adminSrv.Shutdown)
+	} else {
+		mainMux := http.NewServeMux()
+		mainMux.Handle("/metrics", auth.Admin(cfg.Auth)(m.Handler()))
+		mainMux.Handle("/", handler)
+		handler = mainMux
+	}
+
+	// request contexts are cancelled on shutdown so event streams end
+	baseCtx, cancelBase := context.WithCancel(ctx)
+	defer cancelBase()
 
 	// server conf
 	srv := &http.Server{
-		Handler: corsHandler.Handler(r),
-		Addr:    cfg.Server.Addr,
+		Handler: handler,
 		// Good practice: enforce timeouts for servers you create!
-		WriteTimeout: time.Duration(cfg.Server.WriteTimeout) * time.Second,
-		ReadTimeout:  time.Duration(cfg.Server.ReadTimeout) * time.Second,
+		WriteTimeout:      time.Duration(cfg.Server.WriteTimeout) * time.Second,
+		ReadTimeout:       time.Duration(cfg.Server.ReadTimeout) * time.Second,
+		ReadHeaderTimeout: time.Duration(cfg.Server.ReadHeaderTimeout) * time.Second,
+		IdleTimeout:       time.Duration(cfg.Server.IdleTimeout) * time.Second,
+		BaseContext: func(net.Listener) context.Context {
+			return baseCtx
+		},
 	}
+	srv.RegisterOnShutdown(cancelBase)
 
-	fmt.Printf("API Listening on %s", cfg.Server.Addr)
-	// Run our server in a goroutine so that it doesn't block.
-	go func() {
-		if err := srv.ListenAndServe(); err != nil {
-			log.Println(err)
-		}
-	}()
-
-	c := make(chan os.Signal, 1)
-	// We'll accept graceful shutdowns when quit via SIGINT (Ctrl+C)
-	// SIGKILL, SIGQUIT or SIGTERM (Ctrl+/) will not be caught.
-	signal.Notify(c, os.Interrupt)
-
-	// Block until we receive our signal.
-	<-c
-
-	// Create a deadline to wait for.
-	ctx, cancel := context.WithTimeout(context.Background(), wait)
-	defer cancel()
-	// Doesn't block if no connections, but will otherwise wait
-	// until the timeout deadline.
-	err = srv.Shutdown(ctx)
-	if err != nil {
-		logrus.Error(err)
+	// gRPC server sharing the services and the change broker
+	if grpcLis != nil {
+		grpcSrv := grpcServer.NewServer(serviceCtx, broker, cfg.Auth)
+		lc.Serve("grpc server", func() error {
+			return grpcSrv.Serve(grpcLis)
+		})
+		lc.OnStop("grpc server", func(ctx context.Context) error {
+			grpcServer.Shutdown(ctx, grpcSrv)
+			return nil
+		})
 	}
-	// Optionally, you could run srv.Shutdown in a goroutine and block on
-	// <-ctx.Done() if your application should wait for other services
-	// to finalize based on context cancellation.
-	log.Println("shutting down")
-	os.Exit(0)
 
-	return nil
+	fmt.Printf("API Listening on %s", lis.Addr())
+	lc.Serve("http server", func() error {
+		return srv.Serve(lis)
+	})
+	// stops accepting connections, then waits for the requests in flight
+	lc.OnStop("http server", srv.Shutdown)
+
+	// let the load balancer see /readyz fail before refusing connections
+	lc.OnDrain(func() {
+		probes.Drain()
+		time.Sleep(time.Duration(cfg.Health.DrainDelay) * time.Second)
+	})
+
+	// blocks until SIGINT or SIGTERM and the end of the shutdown
+	return lc.Run()
+}
+
+// SetBuildInfo set the build reported by the probes
+func SetBuildInfo(info health.BuildInfo) {
+	buildInfo = info
 }
 
 // ServeHTTP return instance of serve HTTP command object
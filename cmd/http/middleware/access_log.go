@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"time"
+	"todolist-api/infra/logger"
+
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type routeKey struct{}
+
+// route the path template of the route serving a request, filled in by
+// RouteTemplate once the router matched
+type route struct {
+	template string
+}
+
+// AccessLog store a log entry tagged with the request ID, and the trace ID
+// when the request is traced, in the request context and write one access log
+// line per request to access
+func AccessLog(access *log.Logger) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			requestID := GetRequestID(r.Context())
+
+			fields := log.Fields{"request_id": requestID}
+			if sc := trace.SpanContextFromContext(r.Context()); sc.IsValid() {
+				fields["trace_id"] = sc.TraceID().String()
+			}
+
+			r, matched := withRoute(r)
+			r = r.WithContext(logger.WithEntry(r.Context(), log.WithFields(fields)))
+
+			rw := wrap(w)
+			next.ServeHTTP(rw, r)
+
+			path := matched.template
+			if path == "" {
+				path = r.URL.Path
+			}
+
+			access.WithFields(fields).WithFields(log.Fields{
+				"method":     r.Method,
+				"path":       path,
+				"status":     rw.Status(),
+				"latency_ms": float64(time.Since(start).Microseconds()) / 1000,
+				"bytes":      rw.bytes,
+			}).Info("request")
+		})
+	}
+}
+
+// RouteTemplate record the path template of the matched route, it has to be
+// installed on the router with Use since only the router knows the route
+func RouteTemplate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if matched, ok := r.Context().Value(routeKey{}).(*route); ok {
+			if current := mux.CurrentRoute(r); current != nil {
+				matched.template, _ = current.GetPathTemplate()
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// withRoute return the route of the request, adding it to the request context
+// on the first call
+func withRoute(r *http.Request) (*http.Request, *route) {
+	if matched, ok := r.Context().Value(routeKey{}).(*route); ok {
+		return r, matched
+	}
+
+	matched := &route{}
+	return r.WithContext(context.WithValue(r.Context(), routeKey{}, matched)), matched
+}
+
+// GetRoute return the path template of the route serving the request ctx
+// belongs to, empty when no route matched
+func GetRoute(ctx context.Context) string {
+	if matched, ok := ctx.Value(routeKey{}).(*route); ok {
+		return matched.template
+	}
+
+	return ""
+}
+
+// NewAccessLogger create the logger of the access log, one JSON object per line
+func NewAccessLogger() *log.Logger {
+	access := log.New()
+	access.SetFormatter(&log.JSONFormatter{})
+
+	return access
+}
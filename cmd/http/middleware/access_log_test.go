@@ -0,0 +1,78 @@
+package middleware_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"todolist-api/cmd/http/middleware"
+	"todolist-api/infra/logger"
+
+	"github.com/gorilla/mux"
+)
+
+func TestAccessLog(t *testing.T) {
+	var out bytes.Buffer
+	access := middleware.NewAccessLogger()
+	access.SetOutput(&out)
+
+	var entryID interface{}
+	r := mux.NewRouter()
+	r.Use(middleware.RouteTemplate)
+	r.HandleFunc("/v1/todo-items/{id}", func(w http.ResponseWriter, r *http.Request) {
+		// the handlers log with the request ID
+		entryID = logger.FromContext(r.Context()).Data["request_id"]
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":7}`))
+	}).Methods(http.MethodPut)
+
+	h := middleware.Chain(r, middleware.RequestID, middleware.AccessLog(access))
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		logged string
+		status float64
+		bytes  float64
+	}{
+		{"route template", http.MethodPut, "/v1/todo-items/7", "/v1/todo-items/{id}", http.StatusCreated, 8},
+		{"no route", http.MethodGet, "/v1/nowhere", "/v1/nowhere", http.StatusNotFound, 19},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out.Reset()
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set(middleware.HeaderRequestID, "req-"+tt.method)
+			h.ServeHTTP(httptest.NewRecorder(), req)
+
+			var line map[string]interface{}
+			if err := json.Unmarshal(out.Bytes(), &line); err != nil {
+				t.Fatalf("access log %q: %v", out.String(), err)
+			}
+
+			expected := map[string]interface{}{
+				"request_id": "req-" + tt.method,
+				"method":     tt.method,
+				"path":       tt.logged,
+				"status":     tt.status,
+				"bytes":      tt.bytes,
+				"msg":        "request",
+			}
+			for k, v := range expected {
+				if line[k] != v {
+					t.Fatalf("%s %v, expected %v in %v", k, line[k], v, line)
+				}
+			}
+			if _, ok := line["latency_ms"].(float64); !ok {
+				t.Fatalf("no latency in %v", line)
+			}
+		})
+	}
+
+	if entryID != "req-PUT" {
+		t.Fatalf("handler entry tagged with %v", entryID)
+	}
+}
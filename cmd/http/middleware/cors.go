@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"net/http"
+	"sync/atomic"
+	"todolist-api/config"
+	"todolist-api/infra/idempotency"
+
+	"github.com/rs/cors"
+)
+
+// CORS answer the preflight requests and set the CORS headers for the allowed
+// origins, which can be replaced while serving
+type CORS struct {
+	cors atomic.Pointer[cors.Cors]
+}
+
+// NewCORS return the CORS handling of cfg
+func NewCORS(cfg config.CORSConfig) *CORS {
+	c := &CORS{}
+	c.Update(cfg)
+
+	return c
+}
+
+// Update replace the allowed origins, the next requests see the change
+func (c *CORS) Update(cfg config.CORSConfig) {
+	c.cors.Store(cors.New(cors.Options{
+		AllowedHeaders:     []string{"Origin", "Authorization", "Content-Type", "Access-Control-Allow-Origin", "API-KEY", "Last-Event-ID", "X-Request-ID", "traceparent", "tracestate", idempotency.HeaderKey},
+		AllowedMethods:     []string{"HEAD", "PUT", "PATCH", "GET", "POST", "DELETE", "OPTIONS"},
+		ExposedHeaders:     []string{HeaderRequestID, HeaderRateLimitLimit, HeaderRateLimitRemaining, HeaderRateLimitReset, "Retry-After", idempotency.HeaderReplayed},
+		AllowedOrigins:     cfg.AllowedOrigins,
+		OptionsPassthrough: false,
+		AllowCredentials:   cfg.AllowCredentials,
+		MaxAge:             cfg.MaxAge,
+	}))
+}
+
+// Handler wrap next with the current CORS handling
+func (c *CORS) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.cors.Load().ServeHTTP(w, r, next.ServeHTTP)
+	})
+}
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"net/http"
+	"todolist-api/config"
+	"todolist-api/utils"
+
+	"github.com/gorilla/mux"
+)
+
+// features the routes of the endpoints that can be switched off
+var features = map[string]func(config.FeaturesConfig) bool{
+	"/graphql": func(f config.FeaturesConfig) bool { return f.GraphQL },
+	"/ws":      func(f config.FeaturesConfig) bool { return f.Websocket },
+}
+
+// Features answer 404 on the routes of the features switched off in the
+// current config. It has to be used on the router.
+func Features(current func() config.FeaturesConfig) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if route := mux.CurrentRoute(r); route != nil {
+				template, _ := route.GetPathTemplate()
+				if enabled, ok := features[template]; ok && !enabled(current()) {
+					utils.SetResponseErrURLNotFound(utils.MESSAGE_NOT_FOUND).JSONErrURLNotFound(w)
+					return
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
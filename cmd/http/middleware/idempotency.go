@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+	"todolist-api/config"
+	"todolist-api/infra/auth"
+	"todolist-api/infra/idempotency"
+	"todolist-api/utils"
+
+	"github.com/gorilla/mux"
+)
+
+// maxIdempotencyKey the longest key accepted, the size of the column
+const maxIdempotencyKey = 255
+
+// idempotentRoutes the routes honouring the Idempotency-Key header, by method
+// and template without the version prefix
+var idempotentRoutes = map[string]bool{
+	"POST /todo-items":      true,
+	"POST /activity-groups": true,
+	"POST /sync":            true,
+}
+
+// Idempotency attach the Idempotency-Key of the request to its context on the
+// routes creating a resource, for the services to store the response with
+// the creation and replay it on a retry. The key is scoped to the caller and
+// the route, the fingerprint covers the method, the route and the body. It
+// has to be used on the router, after the authentication.
+func Idempotency(current func() config.IdempotencyConfig) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(idempotency.HeaderKey)
+			route := mux.CurrentRoute(r)
+			if key == "" || route == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			template, _ := route.GetPathTemplate()
+			name := r.Method + " " + versionPrefix.ReplaceAllString(template, "")
+			if !idempotentRoutes[name] {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if len(key) > maxIdempotencyKey {
+				utils.SetResponseErrJSON(utils.MESSAGE_BAD_REQUEST, "Idempotency-Key must not be longer than 255 characters").JSONErrResponse(w)
+				return
+			}
+
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				utils.SetResponseErrJSON(utils.MESSAGE_BAD_REQUEST, err.Error()).JSONErrResponse(w)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			// the same JSON with another layout is the same request
+			compact := &bytes.Buffer{}
+			if err := json.Compact(compact, body); err == nil {
+				body = compact.Bytes()
+			}
+
+			ctx := idempotency.WithRequest(r.Context(), &idempotency.Request{
+				Scope:       name + " " + caller(r),
+				Key:         key,
+				Fingerprint: idempotency.Fingerprint([]byte(name), body),
+				TTL:         time.Duration(current().TTL) * time.Second,
+			})
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// caller identify the owner of the keys, the user or else the API key, every
+// caller sharing the keys with the authentication disabled
+func caller(r *http.Request) string {
+	u, ok := auth.FromContext(r.Context())
+	switch {
+	case !ok:
+		return "anonymous"
+	case u.Email != "":
+		return "user:" + strings.ToLower(u.Email)
+	case u.APIKey != "":
+		sum := sha256.Sum256([]byte(u.APIKey))
+		return "key:" + hex.EncodeToString(sum[:8])
+	default:
+		return "anonymous"
+	}
+}
@@ -0,0 +1,169 @@
+package middleware_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"todolist-api/cmd/http/middleware"
+	"todolist-api/config"
+	"todolist-api/constants"
+	"todolist-api/data/repositories/idempotency/idempotencytest"
+	"todolist-api/infra/idempotency"
+
+	"github.com/gorilla/mux"
+)
+
+// idempotent a router creating todos the way the services do, replaying the
+// response of a key and storing it along with the creation. With holdNext
+// set, the next request blocks on hold between the two.
+type idempotent struct {
+	router   *mux.Router
+	created  int32
+	holdNext int32
+	hold     chan struct{}
+	waiting  chan struct{}
+}
+
+func newIdempotent() *idempotent {
+	s := &idempotent{
+		router:  mux.NewRouter(),
+		hold:    make(chan struct{}),
+		waiting: make(chan struct{}, 1),
+	}
+	repo := idempotencytest.New()
+
+	s.router.Use(middleware.Idempotency(func() config.IdempotencyConfig {
+		return config.IdempotencyConfig{TTL: 3600}
+	}))
+	s.router.HandleFunc("/v1/todo-items", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Title string `json:"title"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+
+		var res struct {
+			ID    int32  `json:"id"`
+			Title string `json:"title"`
+		}
+		ok, err := idempotency.Replay(r.Context(), repo, nil, &res)
+		if err == nil && !ok {
+			if atomic.CompareAndSwapInt32(&s.holdNext, 1, 0) {
+				s.waiting <- struct{}{}
+				<-s.hold
+			}
+			res.ID = atomic.AddInt32(&s.created, 1)
+			res.Title = req.Title
+			err = idempotency.Save(r.Context(), repo, nil, res)
+		}
+
+		switch {
+		case errors.Is(err, constants.ErrIdempotencyKeyReused):
+			w.WriteHeader(http.StatusUnprocessableEntity)
+		case errors.Is(err, constants.ErrIdempotencyKeyInUse):
+			w.WriteHeader(http.StatusConflict)
+		case err != nil:
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			if idempotency.Replayed(r.Context()) {
+				w.Header().Set(idempotency.HeaderReplayed, "true")
+			}
+			w.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(w).Encode(res)
+		}
+	}).Methods(http.MethodPost)
+
+	return s
+}
+
+func (s *idempotent) post(path, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	if key != "" {
+		req.Header.Set(idempotency.HeaderKey, key)
+	}
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, req)
+
+	return w
+}
+
+func TestIdempotencyReplay(t *testing.T) {
+	s := newIdempotent()
+
+	first := s.post("/v1/todo-items", "k1", `{"title":"milk"}`)
+	if first.Code != http.StatusCreated || first.Header().Get(idempotency.HeaderReplayed) != "" {
+		t.Fatalf("first request: %d %v", first.Code, first.Header())
+	}
+
+	// the same JSON laid out differently is the same request
+	retry := s.post("/v1/todo-items", "k1", "{\n  \"title\": \"milk\"\n}")
+	if retry.Code != http.StatusCreated || retry.Header().Get(idempotency.HeaderReplayed) != "true" {
+		t.Fatalf("retry: %d %v", retry.Code, retry.Header())
+	}
+	if retry.Body.String() != first.Body.String() {
+		t.Fatalf("retry answered %s, first request %s", retry.Body, first.Body)
+	}
+	if s.created != 1 {
+		t.Fatalf("%d todos created for one key", s.created)
+	}
+
+	// without key every request creates
+	s.post("/v1/todo-items", "", `{"title":"milk"}`)
+	s.post("/v1/todo-items", "", `{"title":"milk"}`)
+	if s.created != 3 {
+		t.Fatalf("%d todos created, expected 3", s.created)
+	}
+}
+
+func TestIdempotencyReusedKey(t *testing.T) {
+	s := newIdempotent()
+
+	s.post("/v1/todo-items", "k1", `{"title":"milk"}`)
+	w := s.post("/v1/todo-items", "k1", `{"title":"eggs"}`)
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("status %d, expected %d", w.Code, http.StatusUnprocessableEntity)
+	}
+	if s.created != 1 {
+		t.Fatalf("%d todos created for one key", s.created)
+	}
+}
+
+func TestIdempotencyKeyInUse(t *testing.T) {
+	s := newIdempotent()
+
+	// the first request is held after finding no key
+	s.holdNext = 1
+	var wg sync.WaitGroup
+	var held *httptest.ResponseRecorder
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		held = s.post("/v1/todo-items", "k1", `{"title":"milk"}`)
+	}()
+	<-s.waiting
+
+	// a retry meanwhile runs and stores the key first
+	if w := s.post("/v1/todo-items", "k1", `{"title":"milk"}`); w.Code != http.StatusCreated {
+		t.Fatalf("retry: status %d", w.Code)
+	}
+
+	// the first request then finds the key taken
+	close(s.hold)
+	wg.Wait()
+	if held.Code != http.StatusConflict {
+		t.Fatalf("status %d, expected %d", held.Code, http.StatusConflict)
+	}
+}
+
+func TestIdempotencyKeyTooLong(t *testing.T) {
+	s := newIdempotent()
+
+	w := s.post("/v1/todo-items", strings.Repeat("k", 256), `{"title":"milk"}`)
+	if w.Code != http.StatusBadRequest || s.created != 0 {
+		t.Fatalf("status %d and %d todos created", w.Code, s.created)
+	}
+}
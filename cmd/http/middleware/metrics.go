@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+	"todolist-api/infra/metrics"
+)
+
+// unmatchedRoute label of the requests no route matched, so arbitrary paths
+// don't become label values
+const unmatchedRoute = "unmatched"
+
+var knownMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodPost:    true,
+	http.MethodPut:     true,
+	http.MethodPatch:   true,
+	http.MethodDelete:  true,
+	http.MethodOptions: true,
+}
+
+// Metrics record the rate, errors and duration of the requests per route
+// template. The router has to record the template with RouteTemplate.
+func Metrics(m *metrics.Metrics) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			done := m.InFlight()
+			defer done()
+
+			start := time.Now()
+			r, matched := withRoute(r)
+
+			rw := wrap(w)
+			next.ServeHTTP(rw, r)
+
+			path := matched.template
+			if path == "" {
+				path = unmatchedRoute
+			}
+
+			method := r.Method
+			if !knownMethods[method] {
+				method = "OTHER"
+			}
+
+			m.ObserveRequest(method, path, rw.Status(), time.Since(start))
+		})
+	}
+}
@@ -0,0 +1,15 @@
+package middleware
+
+import "net/http"
+
+// Middleware wrap a handler
+type Middleware func(http.Handler) http.Handler
+
+// Chain wrap h with the middlewares, the first one being the outermost
+func Chain(h http.Handler, middlewares ...Middleware) http.Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		h = middlewares[i](h)
+	}
+
+	return h
+}
@@ -0,0 +1,127 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"todolist-api/config"
+	"todolist-api/infra/auth"
+	"todolist-api/infra/logger"
+	"todolist-api/infra/ratelimit"
+	"todolist-api/utils"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	// HeaderRateLimitLimit tokens of the bucket when full
+	HeaderRateLimitLimit = "RateLimit-Limit"
+	// HeaderRateLimitRemaining tokens left in the bucket
+	HeaderRateLimitRemaining = "RateLimit-Remaining"
+	// HeaderRateLimitReset seconds until the bucket is full again
+	HeaderRateLimitReset = "RateLimit-Reset"
+)
+
+// versionPrefix the version the route templates are mounted under
+var versionPrefix = regexp.MustCompile(`^/v[0-9]+`)
+
+// RateLimit take a token from the buckets of the API key, the user and the IP
+// of the caller, or from the buckets of the route when it has its own limits,
+// and answer 429 once one is empty. It has to be used on the router, after
+// the authentication. Requests to the public paths aren't limited.
+func RateLimit(limiter *ratelimit.Limiter, current func() config.RateLimitConfig, public ...string) mux.MiddlewareFunc {
+	skip := map[string]bool{}
+	for _, x := range public {
+		skip[x] = true
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			cfg := current()
+			if !cfg.Enabled || skip[r.URL.Path] {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			res, ok, err := limiter.Allow(r.Context(), buckets(r, cfg))
+			if err != nil {
+				// fail open, the store is down
+				logger.FromContext(r.Context()).Error(err)
+				next.ServeHTTP(w, r)
+				return
+			}
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set(HeaderRateLimitLimit, strconv.Itoa(res.Limit))
+			w.Header().Set(HeaderRateLimitRemaining, strconv.Itoa(res.Remaining))
+			w.Header().Set(HeaderRateLimitReset, strconv.Itoa(int(math.Ceil(res.Reset.Seconds()))))
+
+			if !res.Allowed {
+				utils.SetResponseErrJSON(utils.MESSAGE_TOO_MANY_REQUESTS, "rate limit exceeded").JSONErrTooManyRequests(w, res.RetryAfter)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// buckets return the buckets of the caller, the ones of the route when the
+// route has its own limits
+func buckets(r *http.Request, cfg config.RateLimitConfig) []ratelimit.Bucket {
+	apiKey, user, ip := ratelimit.Limit(cfg.APIKey), ratelimit.Limit(cfg.User), ratelimit.Limit(cfg.IP)
+	scope := ""
+
+	if route := mux.CurrentRoute(r); route != nil {
+		template, _ := route.GetPathTemplate()
+		template = versionPrefix.ReplaceAllString(template, "")
+		for _, x := range cfg.Routes {
+			if strings.EqualFold(x.Method, r.Method) && x.Path == template {
+				limit := ratelimit.Limit{Rate: x.Rate, Burst: x.Burst}
+				apiKey, user, ip = limit, limit, limit
+				scope = r.Method + " " + template + ":"
+				break
+			}
+		}
+	}
+
+	var res []ratelimit.Bucket
+	if u, ok := auth.FromContext(r.Context()); ok {
+		if u.APIKey != "" {
+			// the key itself stays out of the store
+			sum := sha256.Sum256([]byte(u.APIKey))
+			res = append(res, ratelimit.Bucket{Key: scope + "key:" + hex.EncodeToString(sum[:8]), Limit: apiKey})
+		}
+		if u.Email != "" {
+			res = append(res, ratelimit.Bucket{Key: scope + "user:" + strings.ToLower(u.Email), Limit: user})
+		}
+	}
+
+	return append(res, ratelimit.Bucket{Key: scope + "ip:" + clientIP(r, cfg.TrustForwardedFor), Limit: ip})
+}
+
+// clientIP return the IP of the caller, the first of X-Forwarded-For when
+// the proxy in front is trusted
+func clientIP(r *http.Request, trustForwardedFor bool) string {
+	if trustForwardedFor {
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			first, _, _ := strings.Cut(forwarded, ",")
+			return strings.TrimSpace(first)
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
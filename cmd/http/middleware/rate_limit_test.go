@@ -0,0 +1,140 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"todolist-api/cmd/http/middleware"
+	"todolist-api/config"
+	"todolist-api/infra/auth"
+	"todolist-api/infra/ratelimit"
+
+	"github.com/gorilla/mux"
+)
+
+// rateLimited a router limited by cfg, the caller being user when not empty
+func rateLimited(cfg config.RateLimitConfig, user *auth.User) http.Handler {
+	r := mux.NewRouter()
+	if user != nil {
+		r.Use(func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				next.ServeHTTP(w, req.WithContext(auth.WithUser(req.Context(), *user)))
+			})
+		})
+	}
+	r.Use(middleware.RateLimit(ratelimit.NewLimiter(ratelimit.NewMemoryStore()), func() config.RateLimitConfig {
+		return cfg
+	}, "/healthz"))
+
+	ok := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}
+	r.HandleFunc("/v1/todo-items", ok).Methods(http.MethodGet, http.MethodPost)
+	r.HandleFunc("/healthz", ok).Methods(http.MethodGet)
+
+	return r
+}
+
+func call(h http.Handler, method, path, ip string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	req.RemoteAddr = ip + ":4242"
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	return w
+}
+
+func TestRateLimitHeaders(t *testing.T) {
+	// a token every 1000 seconds, so none comes back during the test
+	h := rateLimited(config.RateLimitConfig{
+		Enabled: true,
+		IP:      config.LimitConfig{Rate: 0.001, Burst: 2},
+	}, nil)
+
+	w := call(h, http.MethodGet, "/v1/todo-items", "10.0.0.1")
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("status %d", w.Code)
+	}
+	expected := map[string]string{
+		"RateLimit-Limit":     "2",
+		"RateLimit-Remaining": "1",
+		"RateLimit-Reset":     "1000",
+	}
+	for header, value := range expected {
+		if got := w.Header().Get(header); got != value {
+			t.Fatalf("%s %q, expected %q", header, got, value)
+		}
+	}
+
+	if w := call(h, http.MethodGet, "/v1/todo-items", "10.0.0.1"); w.Code != http.StatusNoContent || w.Header().Get("RateLimit-Remaining") != "0" {
+		t.Fatalf("second call: %d, remaining %s", w.Code, w.Header().Get("RateLimit-Remaining"))
+	}
+
+	w = call(h, http.MethodGet, "/v1/todo-items", "10.0.0.1")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("status %d, expected 429", w.Code)
+	}
+	if got := w.Header().Get("Retry-After"); got != "1000" {
+		t.Fatalf("Retry-After %q, expected 1000", got)
+	}
+	if got := w.Header().Get("RateLimit-Remaining"); got != "0" {
+		t.Fatalf("RateLimit-Remaining %q, expected 0", got)
+	}
+
+	// another IP has its own bucket, the public paths aren't limited
+	if w := call(h, http.MethodGet, "/v1/todo-items", "10.0.0.2"); w.Code != http.StatusNoContent {
+		t.Fatalf("another IP: status %d", w.Code)
+	}
+	if w := call(h, http.MethodGet, "/healthz", "10.0.0.1"); w.Code != http.StatusNoContent || w.Header().Get("RateLimit-Limit") != "" {
+		t.Fatalf("public path: status %d, limit %q", w.Code, w.Header().Get("RateLimit-Limit"))
+	}
+}
+
+func TestRateLimitDisabled(t *testing.T) {
+	h := rateLimited(config.RateLimitConfig{
+		IP: config.LimitConfig{Rate: 0.001, Burst: 1},
+	}, nil)
+
+	for i := 0; i < 3; i++ {
+		if w := call(h, http.MethodGet, "/v1/todo-items", "10.0.0.1"); w.Code != http.StatusNoContent || w.Header().Get("RateLimit-Limit") != "" {
+			t.Fatalf("call %d: status %d", i, w.Code)
+		}
+	}
+}
+
+func TestRateLimitUser(t *testing.T) {
+	// the user is out of tokens whatever the IP
+	h := rateLimited(config.RateLimitConfig{
+		Enabled: true,
+		User:    config.LimitConfig{Rate: 0.001, Burst: 1},
+		IP:      config.LimitConfig{Rate: 0.001, Burst: 10},
+	}, &auth.User{Email: "alice@example.com"})
+
+	if w := call(h, http.MethodGet, "/v1/todo-items", "10.0.0.1"); w.Code != http.StatusNoContent || w.Header().Get("RateLimit-Limit") != "1" {
+		t.Fatalf("status %d, limit %q", w.Code, w.Header().Get("RateLimit-Limit"))
+	}
+	if w := call(h, http.MethodGet, "/v1/todo-items", "10.0.0.2"); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("status %d, expected 429", w.Code)
+	}
+}
+
+func TestRateLimitRoute(t *testing.T) {
+	// the route has its own bucket, the version prefix left out of its path
+	h := rateLimited(config.RateLimitConfig{
+		Enabled: true,
+		IP:      config.LimitConfig{Rate: 0.001, Burst: 10},
+		Routes: []config.RouteLimitConfig{
+			{Method: "post", Path: "/todo-items", Rate: 0.001, Burst: 1},
+		},
+	}, nil)
+
+	if w := call(h, http.MethodPost, "/v1/todo-items", "10.0.0.1"); w.Code != http.StatusNoContent || w.Header().Get("RateLimit-Limit") != "1" {
+		t.Fatalf("status %d, limit %q", w.Code, w.Header().Get("RateLimit-Limit"))
+	}
+	if w := call(h, http.MethodPost, "/v1/todo-items", "10.0.0.1"); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("status %d, expected 429", w.Code)
+	}
+	if w := call(h, http.MethodGet, "/v1/todo-items", "10.0.0.1"); w.Code != http.StatusNoContent || w.Header().Get("RateLimit-Remaining") != "9" {
+		t.Fatalf("GET: status %d, remaining %q", w.Code, w.Header().Get("RateLimit-Remaining"))
+	}
+}
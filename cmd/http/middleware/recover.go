@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"todolist-api/infra/logger"
+	"todolist-api/utils"
+)
+
+// bodyHeaders the headers a handler sets for the body it meant to send, wrong
+// for the error answered in its place
+var bodyHeaders = []string{"Content-Length", "Content-Encoding", "Content-Disposition", "Content-Range", "ETag", "Last-Modified"}
+
+// Recover turn a panic of the handler into a 500 response instead of a reset
+// connection, and log it with its stack
+func Recover(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rw := wrap(w)
+
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+			// the handler asked to abort the response on purpose
+			if rec == http.ErrAbortHandler {
+				panic(rec)
+			}
+
+			logger.FromContext(r.Context()).
+				WithField("stack", string(debug.Stack())).
+				Error(fmt.Sprintf("panic: %v", rec))
+
+			// too late to answer once the headers are out
+			if rw.status != 0 {
+				return
+			}
+
+			for _, h := range bodyHeaders {
+				rw.Header().Del(h)
+			}
+			res := utils.SetResponseErrJSON(utils.MESSAGE_INTERNAL_SERVER_ERR, "internal server error")
+			res.JSONErrInternalServerResponse(rw)
+		}()
+
+		next.ServeHTTP(rw, r)
+	})
+}
@@ -0,0 +1,124 @@
+package middleware_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"todolist-api/cmd/http/middleware"
+)
+
+// headerWrites a recorder counting the status lines written
+type headerWrites struct {
+	*httptest.ResponseRecorder
+	count int
+}
+
+func (w *headerWrites) WriteHeader(status int) {
+	w.count++
+	w.ResponseRecorder.WriteHeader(status)
+}
+
+func (w *headerWrites) Write(p []byte) (int, error) {
+	if w.count == 0 {
+		w.count++
+	}
+
+	return w.ResponseRecorder.Write(p)
+}
+
+func (w *headerWrites) Flush() {
+	if w.count == 0 {
+		w.count++
+	}
+	w.ResponseRecorder.Flush()
+}
+
+func TestRecover(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		status  int
+		json    bool
+	}{
+		{
+			name: "panic before the response",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				// the headers of the export it meant to send
+				w.Header().Set("Content-Disposition", `attachment; filename="export.csv"`)
+				w.Header().Set("Content-Length", "1024")
+				panic("boom")
+			},
+			status: http.StatusInternalServerError,
+			json:   true,
+		},
+		{
+			name: "panic after the headers",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				_, _ = w.Write([]byte("partial"))
+				panic("boom")
+			},
+			status: http.StatusOK,
+		},
+		{
+			name: "panic after a flush",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.(http.Flusher).Flush()
+				panic("boom")
+			},
+			status: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &headerWrites{ResponseRecorder: httptest.NewRecorder()}
+			h := middleware.Chain(tt.handler, middleware.RequestID, middleware.Recover)
+			h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/export", nil))
+
+			if w.Code != tt.status {
+				t.Fatalf("status %d, expected %d", w.Code, tt.status)
+			}
+			if w.count != 1 {
+				t.Fatalf("status written %d times", w.count)
+			}
+			if w.Header().Get(middleware.HeaderRequestID) == "" {
+				t.Fatal("request ID dropped")
+			}
+			if !tt.json {
+				return
+			}
+
+			var res struct {
+				Message string `json:"message"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil || res.Message == "" {
+				t.Fatalf("body %q: %v", w.Body, err)
+			}
+			if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+				t.Fatalf("content type %q", ct)
+			}
+			for _, h := range []string{"Content-Disposition", "Content-Length"} {
+				if v := w.Header().Get(h); v != "" {
+					t.Fatalf("%s: %s kept on the error", h, v)
+				}
+			}
+		})
+	}
+}
+
+func TestRecoverAbort(t *testing.T) {
+	h := middleware.Recover(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+
+	defer func() {
+		if rec := recover(); rec != http.ErrAbortHandler {
+			t.Fatalf("recovered %v, expected %v", rec, http.ErrAbortHandler)
+		}
+	}()
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	t.Fatal("abort swallowed")
+}
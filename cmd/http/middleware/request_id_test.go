@@ -0,0 +1,66 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"todolist-api/cmd/http/middleware"
+)
+
+var generatedID = regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+func TestRequestID(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		kept     bool
+		generate bool
+	}{
+		{"propagated", "req-42", true, false},
+		{"generated when missing", "", false, true},
+		{"replaced when too long", strings.Repeat("a", 129), false, true},
+		{"replaced when not printable", "req 42", false, true},
+		{"replaced on a control character", "req\x0142", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var seen string
+			h := middleware.RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				seen = middleware.GetRequestID(r.Context())
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set(middleware.HeaderRequestID, tt.header)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+
+			echoed := w.Header().Get(middleware.HeaderRequestID)
+			if echoed != seen {
+				t.Fatalf("echoed %q, the handler saw %q", echoed, seen)
+			}
+			if tt.kept && echoed != tt.header {
+				t.Fatalf("request ID %q, expected %q", echoed, tt.header)
+			}
+			if tt.generate && !generatedID.MatchString(echoed) {
+				t.Fatalf("generated request ID %q", echoed)
+			}
+		})
+	}
+
+	// every request gets its own ID
+	h := middleware.RequestID(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	ids := map[string]bool{}
+	for i := 0; i < 10; i++ {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		ids[w.Header().Get(middleware.HeaderRequestID)] = true
+	}
+	if len(ids) != 10 {
+		t.Fatalf("%d IDs for 10 requests", len(ids))
+	}
+}
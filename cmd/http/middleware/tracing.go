@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"net/http"
+	"todolist-api/infra/tracing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/semconv/v1.17.0/httpconv"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracing open the server span of the request, continuing the trace of the
+// W3C traceparent header of the caller. The span is named after the route
+// template once the router recorded it with RouteTemplate.
+func Tracing(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := tracing.Tracer().Start(ctx, "HTTP "+r.Method,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(httpconv.ServerRequest("", r)...),
+		)
+		defer span.End()
+
+		r, matched := withRoute(r.WithContext(ctx))
+
+		rw := wrap(w)
+		next.ServeHTTP(rw, r)
+
+		if matched.template != "" {
+			span.SetName(r.Method + " " + matched.template)
+			span.SetAttributes(semconv.HTTPRoute(matched.template))
+		}
+		span.SetAttributes(semconv.HTTPStatusCode(rw.Status()))
+		span.SetStatus(httpconv.ServerStatus(rw.Status()))
+	})
+}
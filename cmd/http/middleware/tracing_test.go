@@ -0,0 +1,132 @@
+package middleware_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"todolist-api/cmd/http/handlers/activity"
+	"todolist-api/cmd/http/middleware"
+	"todolist-api/config"
+	"todolist-api/infra/context/repository"
+	"todolist-api/infra/context/service"
+	"todolist-api/infra/db"
+	"todolist-api/infra/db/dbtest"
+	"todolist-api/infra/tracing"
+
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+// spanNamed the span named name, failing the test when missing
+func spanNamed(t *testing.T, spans []sdktrace.ReadOnlySpan, name string) sdktrace.ReadOnlySpan {
+	t.Helper()
+
+	names := make([]string, 0, len(spans))
+	for _, span := range spans {
+		if span.Name() == name {
+			return span
+		}
+		names = append(names, span.Name())
+	}
+	t.Fatalf("no span %s in %v", name, names)
+
+	return nil
+}
+
+// attr the value of the attribute key of span
+func attr(span sdktrace.ReadOnlySpan, key attribute.Key) attribute.Value {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+
+	return attribute.Value{}
+}
+
+func TestTracingSpanTree(t *testing.T) {
+	// installs the W3C propagator, the exporter is replaced by the recorder
+	if _, err := tracing.Init(context.Background(), config.TracingConfig{Exporter: tracing.ExporterNone}); err != nil {
+		t.Fatal(err)
+	}
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+	})
+
+	now := time.Date(2023, time.July, 1, 9, 0, 0, 0, time.UTC)
+	conn, err := db.Open(&config.DBConfig{
+		Name: dbtest.Register(&dbtest.Driver{
+			Columns: []string{"id", "title", "email", "updated_at", "created_at"},
+			Rows: [][]driver.Value{
+				{int64(1), "Home chores", "alice@example.com", now, now},
+				{int64(2), "Team backlog", "bruno@example.com", now, now},
+			},
+		}),
+		Host: "test",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	serviceCtx := service.Trace(service.NewCtx(repository.NewRepoCtx(conn, nil)))
+	r := mux.NewRouter()
+	r.Use(middleware.RouteTemplate)
+	r.HandleFunc("/v1/activity-groups", activity.NewActivityHandler(serviceCtx).GetAllActivity).Methods(http.MethodGet)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/activity-groups", nil)
+	req.Header.Set("traceparent", traceparent)
+	w := httptest.NewRecorder()
+	middleware.Tracing(r).ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", w.Code, w.Body.String())
+	}
+
+	spans := recorder.Ended()
+	server := spanNamed(t, spans, "GET /v1/activity-groups")
+	svc := spanNamed(t, spans, "ActivityService.GetAllActivity")
+	query := spanNamed(t, spans, "db.activity.GetAllActivity")
+
+	// the server span continues the trace of the caller
+	if server.SpanContext().TraceID().String() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Fatalf("trace %s, expected the one of traceparent", server.SpanContext().TraceID())
+	}
+	if server.Parent().SpanID().String() != "00f067aa0ba902b7" || !server.Parent().IsRemote() {
+		t.Fatalf("server parent %s, expected the remote span of traceparent", server.Parent().SpanID())
+	}
+	if server.SpanKind() != trace.SpanKindServer {
+		t.Fatalf("server span of kind %s", server.SpanKind())
+	}
+	if v := attr(server, "http.route"); v.AsString() != "/v1/activity-groups" {
+		t.Fatalf("http.route %q", v.AsString())
+	}
+
+	if svc.Parent().SpanID() != server.SpanContext().SpanID() {
+		t.Fatal("the service span is not a child of the server span")
+	}
+	if query.Parent().SpanID() != svc.SpanContext().SpanID() {
+		t.Fatal("the query span is not a child of the service span")
+	}
+	if query.SpanContext().TraceID() != server.SpanContext().TraceID() {
+		t.Fatal("the query span is of another trace")
+	}
+
+	if v := attr(query, "db.statement.name"); v.AsString() != "activity.GetAllActivity" {
+		t.Fatalf("db.statement.name %q", v.AsString())
+	}
+	if v := attr(query, "db.rows_returned"); v.AsInt64() != 2 {
+		t.Fatalf("db.rows_returned %d", v.AsInt64())
+	}
+}
@@ -0,0 +1,131 @@
+package routers
+
+import (
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"todolist-api/utils"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	// HEAD for the headers a GET would return, answered by the GET route
+	HEAD = "HEAD"
+	// OPT for the methods allowed on a path, answered from the route table
+	OPT = "OPTIONS"
+)
+
+// notFound answer the paths no route matches
+func notFound(w http.ResponseWriter, r *http.Request) {
+	res := utils.SetResponseErrURLNotFound(r.URL.Path + " not found")
+	res.JSONErrURLNotFound(w)
+}
+
+// methodNotAllowed answer the paths routed for other methods. HEAD is served
+// by the GET route, OPTIONS lists the allowed methods, anything else is a 405.
+// The GET routes of streaming, the templates without version prefix, answer
+// no HEAD since it would open the stream.
+func methodNotAllowed(router *mux.Router, streaming []string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		allow := allowedMethods(router, r.URL.Path, streaming)
+
+		switch {
+		case r.Method == HEAD && contains(allow, HEAD):
+			// the server discards the body of HEAD responses
+			get := r.Clone(r.Context())
+			get.Method = GET
+			router.ServeHTTP(w, get)
+			return
+		case r.Method == OPT:
+			w.Header().Set("Allow", strings.Join(allow, ", "))
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		res := utils.SetResponseErrJSON(utils.MESSAGE_METHOD_NOT_ALLOWED, r.Method+" is not allowed on "+r.URL.Path)
+		res.JSONErrMethodNotAllowed(w, allow)
+	}
+}
+
+// allowedMethods list the methods routed for path, along with the implicit
+// OPTIONS and the HEAD of a GET route other than streaming
+func allowedMethods(router *mux.Router, path string, streaming []string) []string {
+	set := map[string]bool{OPT: true}
+	head := false
+
+	_ = router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		expr, err := route.GetPathRegexp()
+		if err != nil {
+			return nil
+		}
+		if ok, _ := regexp.MatchString(expr, path); !ok {
+			return nil
+		}
+
+		methods, err := route.GetMethods()
+		if err != nil {
+			return nil
+		}
+		for _, m := range methods {
+			set[m] = true
+		}
+
+		if contains(methods, GET) {
+			tpl, _ := route.GetPathTemplate()
+			head = head || !contains(streaming, unversioned(tpl))
+		}
+
+		return nil
+	})
+
+	if head {
+		set[HEAD] = true
+	}
+
+	allow := make([]string, 0, len(set))
+	for m := range set {
+		allow = append(allow, m)
+	}
+	sort.Strings(allow)
+
+	return allow
+}
+
+// unversioned the template of a route without its version prefix
+func unversioned(tpl string) string {
+	for _, prefix := range []string{V1, V2} {
+		if strings.HasPrefix(tpl, prefix+"/") {
+			return strings.TrimPrefix(tpl, prefix)
+		}
+	}
+
+	return tpl
+}
+
+func contains(list []string, x string) bool {
+	for _, v := range list {
+		if v == x {
+			return true
+		}
+	}
+
+	return false
+}
+
+// TrimTrailingSlash route /todo-items/ like /todo-items. It wraps the router
+// because mux middlewares only run once a route matched.
+func TrimTrailingSlash(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.URL.Path) > 1 && strings.HasSuffix(r.URL.Path, "/") {
+			r.URL.Path = strings.TrimRight(r.URL.Path, "/")
+			if r.URL.Path == "" {
+				r.URL.Path = "/"
+			}
+			r.URL.RawPath = strings.TrimRight(r.URL.RawPath, "/")
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -3,8 +3,16 @@ package routers
 import (
 	"net/http"
 	"todolist-api/cmd/http/handlers/activity"
+	"todolist-api/cmd/http/handlers/batch"
+	"todolist-api/cmd/http/handlers/event"
+	"todolist-api/cmd/http/handlers/graph"
+	"todolist-api/cmd/http/handlers/health"
+	"todolist-api/cmd/http/handlers/openapi"
+	"todolist-api/cmd/http/handlers/realtime"
+	"todolist-api/cmd/http/handlers/sync"
 	"todolist-api/cmd/http/handlers/todo"
-	"todolist-api/utils"
+	"todolist-api/cmd/http/handlers/transfer"
+	"todolist-api/cmd/http/handlers/webhook"
 
 	"github.com/gorilla/mux"
 )
@@ -20,41 +28,150 @@ const (
 	DEL = "DELETE"
 )
 
+const (
+	// V1 prefix of the routes returning the original payloads
+	V1 = "/v1"
+	// V2 prefix of the routes reading and returning snake_case payloads
+	V2 = "/v2"
+)
+
+// Options the settings of the router built by the caller. Legacy announces
+// the retirement of the unversioned aliases of /v1, Bodies are the request
+// bodies read in snake_case under /v2.
+type Options struct {
+	Legacy *Deprecation
+	Bodies []interface{}
+}
+
+// PublicPaths paths served without authentication
+var PublicPaths = []string{
+	"/openapi.json",
+	"/docs",
+	"/healthz",
+	"/livez",
+	"/readyz",
+}
+
+// FeedPaths route templates authenticated by the token of their URL rather
+// than by an API key, for the calendar apps
+var FeedPaths = []string{
+	"/activity-groups/{id}/calendar.ics",
+	"/calendar.ics",
+}
+
 // InitialRouter for object routers
 func InitialRouter(
 	activityHandler activity.ActivityHandlerInterface,
 	todoHandler todo.TodoHandlerInterface,
+	webhookHandler webhook.WebhookHandlerInterface,
+	eventHandler event.EventHandlerInterface,
+	realtimeHandler realtime.RealtimeHandlerInterface,
+	graphHandler graph.GraphHandlerInterface,
+	openapiHandler openapi.OpenAPIHandlerInterface,
+	healthHandler health.HealthHandlerInterface,
+	batchHandler batch.BatchHandlerInterface,
+	syncHandler sync.SyncHandlerInterface,
+	transferHandler transfer.TransferHandlerInterface,
+	opts Options,
 ) *mux.Router {
 	r := mux.NewRouter()
 
-	r.NotFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if r.URL.Path != "/activity-groups" {
-			res := utils.SetResponseErrURLNotFound(r.URL.Path + " not found")
-			res.JSONErrURLNotFound(w)
-			return
-		}
-	})
-	r.NotFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if r.URL.Path != "/todo-items" {
-			res := utils.SetResponseErrURLNotFound(r.URL.Path + " not found")
-			res.JSONErrURLNotFound(w)
-			return
-		}
-	})
-
-	// activity
-	r.HandleFunc("/activity-groups", activityHandler.CreateActivity).Methods(POS)
-	r.HandleFunc("/activity-groups", activityHandler.GetAllActivity).Methods(GET)
-	r.HandleFunc("/activity-groups/{id}", activityHandler.GetOneActivity).Methods(GET)
-	r.HandleFunc("/activity-groups/{id}", activityHandler.UpdateActivity).Methods(PUT)
-	r.HandleFunc("/activity-groups/{id}", activityHandler.DeleteActivity).Methods(DEL)
-
-	// todo
-	r.HandleFunc("/todo-items", todoHandler.CreateTodo).Methods(POS)
-	r.HandleFunc("/todo-items", todoHandler.GetAllTodo).Methods(GET)
-	r.HandleFunc("/todo-items/{id}", todoHandler.GetOneTodo).Methods(GET)
-	r.HandleFunc("/todo-items/{id}", todoHandler.UpdateTodo).Methods(PUT)
-	r.HandleFunc("/todo-items/{id}", todoHandler.DeleteTodo).Methods(DEL)
+	r.NotFoundHandler = http.HandlerFunc(notFound)
+
+	resources := []route{
+		// activity
+		{POS, "/activity-groups", activityHandler.CreateActivity},
+		{GET, "/activity-groups", activityHandler.GetAllActivity},
+		{GET, "/activity-groups/{id}", activityHandler.GetOneActivity},
+		{PUT, "/activity-groups/{id}", activityHandler.UpdateActivity},
+		{DEL, "/activity-groups/{id}", activityHandler.DeleteActivity},
+
+		// todo
+		{POS, "/todo-items", todoHandler.CreateTodo},
+		{POS, "/todo-items/bulk", todoHandler.BulkTodo},
+		{GET, "/todo-items", todoHandler.GetAllTodo},
+		{GET, "/todo-items/{id}", todoHandler.GetOneTodo},
+		{PUT, "/todo-items/{id}", todoHandler.UpdateTodo},
+		{DEL, "/todo-items/{id}", todoHandler.DeleteTodo},
+
+		// webhook
+		{POS, "/webhooks", webhookHandler.CreateWebhook},
+		{GET, "/webhooks", webhookHandler.GetAllWebhook},
+		{GET, "/webhooks/{id}", webhookHandler.GetOneWebhook},
+		{PUT, "/webhooks/{id}", webhookHandler.UpdateWebhook},
+		{DEL, "/webhooks/{id}", webhookHandler.DeleteWebhook},
+		{GET, "/webhook-deliveries/dead", webhookHandler.GetDeadDelivery},
+		{POS, "/webhook-deliveries/{id}/redeliver", webhookHandler.RedeliverDelivery},
+
+		// sync
+		{GET, "/sync", syncHandler.Pull},
+		{POS, "/sync", syncHandler.Push},
+	}
+
+	// live changes, their payloads are the v1 objects
+	streams := []route{
+		{GET, "/activity-groups/{id}/events", eventHandler.StreamActivityEvents},
+		{GET, "/events", eventHandler.StreamEvents},
+	}
+
+	// a HEAD on the GET routes opening a stream would open it
+	streaming := []string{"/ws", "/graphql"}
+	for _, x := range streams {
+		streaming = append(streaming, x.path)
+	}
+	r.MethodNotAllowedHandler = methodNotAllowed(r, streaming)
+
+	// files, exported in the format asked and left out of the batches
+	files := []route{
+		{GET, "/activity-groups/{id}/export", transferHandler.ExportActivity},
+		{GET, "/export", transferHandler.ExportAll},
+		{POS, "/import", transferHandler.Import},
+		{GET, "/activity-groups/{id}/calendar.ics", transferHandler.CalendarActivity},
+		{GET, "/calendar.ics", transferHandler.CalendarAll},
+		{GET, "/activity-groups/{id}/calendar", transferHandler.CalendarActivityLink},
+		{GET, "/calendar", transferHandler.CalendarLink},
+		{POS, "/activity-groups/{id}/calendar", transferHandler.ImportCalendar},
+	}
+
+	// v1, the original payloads
+	v1 := r.PathPrefix(V1).Subrouter()
+	register(v1, resources, nil)
+	register(v1, files, nil)
+	register(v1, streams, nil)
+
+	// v2, snake_case payloads
+	v2 := r.PathPrefix(V2).Subrouter()
+	v2.Use(SnakeCase(opts.Bodies...))
+	register(v2, resources, nil)
+	register(v2, files, nil)
+
+	// unversioned aliases of v1, kept for the clients in the field
+	register(r, resources, opts.Legacy)
+	register(r, files, opts.Legacy)
+	register(r, streams, opts.Legacy)
+
+	// websocket
+	r.HandleFunc("/ws", realtimeHandler.ServeWS).Methods(GET)
+
+	// batch, its requests go through the resource routes of r
+	paths := make([]string, 0, len(resources))
+	for _, x := range resources {
+		paths = append(paths, x.path)
+	}
+	batchHandler.Mount(r, paths)
+	r.HandleFunc("/batch", batchHandler.ServeBatch).Methods(POS)
+
+	// graphql
+	r.HandleFunc("/graphql", graphHandler.ServeGraphQL).Methods(GET, POS)
+
+	// documentation
+	r.HandleFunc("/openapi.json", openapiHandler.ServeSpec).Methods(GET)
+	r.HandleFunc("/docs", openapiHandler.ServeUI).Methods(GET)
+
+	// probes
+	r.HandleFunc("/healthz", healthHandler.ServeHealthz).Methods(GET)
+	r.HandleFunc("/livez", healthHandler.ServeLivez).Methods(GET)
+	r.HandleFunc("/readyz", healthHandler.ServeReadyz).Methods(GET)
 
 	return r
 }
@@ -0,0 +1,384 @@
+package routers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+	"todolist-api/cmd/http/handlers/activity"
+	"todolist-api/cmd/http/handlers/batch"
+	"todolist-api/cmd/http/handlers/event"
+	"todolist-api/cmd/http/handlers/graph"
+	healthHandler "todolist-api/cmd/http/handlers/health"
+	"todolist-api/cmd/http/handlers/openapi"
+	"todolist-api/cmd/http/handlers/realtime"
+	"todolist-api/cmd/http/handlers/sync"
+	"todolist-api/cmd/http/handlers/todo"
+	"todolist-api/cmd/http/handlers/transfer"
+	"todolist-api/cmd/http/handlers/webhook"
+	"todolist-api/config"
+	"todolist-api/infra/context/service"
+	"todolist-api/infra/events"
+	"todolist-api/infra/health"
+
+	"github.com/gorilla/mux"
+)
+
+// endpoint a route of the table and a path it matches
+type endpoint struct {
+	method   string
+	template string
+	path     string
+}
+
+var (
+	// resourceEndpoints served under /v1, /v2 and unversioned
+	resourceEndpoints = []endpoint{
+		{POS, "/activity-groups", "/activity-groups"},
+		{GET, "/activity-groups", "/activity-groups"},
+		{GET, "/activity-groups/{id}", "/activity-groups/1"},
+		{PUT, "/activity-groups/{id}", "/activity-groups/1"},
+		{DEL, "/activity-groups/{id}", "/activity-groups/1"},
+		{POS, "/todo-items", "/todo-items"},
+		{POS, "/todo-items/bulk", "/todo-items/bulk"},
+		{GET, "/todo-items", "/todo-items"},
+		{GET, "/todo-items/{id}", "/todo-items/1"},
+		{PUT, "/todo-items/{id}", "/todo-items/1"},
+		{DEL, "/todo-items/{id}", "/todo-items/1"},
+		{POS, "/webhooks", "/webhooks"},
+		{GET, "/webhooks", "/webhooks"},
+		{GET, "/webhooks/{id}", "/webhooks/1"},
+		{PUT, "/webhooks/{id}", "/webhooks/1"},
+		{DEL, "/webhooks/{id}", "/webhooks/1"},
+		{GET, "/webhook-deliveries/dead", "/webhook-deliveries/dead"},
+		{POS, "/webhook-deliveries/{id}/redeliver", "/webhook-deliveries/1/redeliver"},
+		{GET, "/sync", "/sync"},
+		{POS, "/sync", "/sync"},
+		{GET, "/activity-groups/{id}/export", "/activity-groups/1/export"},
+		{GET, "/export", "/export"},
+		{POS, "/import", "/import"},
+		{GET, "/activity-groups/{id}/calendar.ics", "/activity-groups/1/calendar.ics"},
+		{GET, "/calendar.ics", "/calendar.ics"},
+		{GET, "/activity-groups/{id}/calendar", "/activity-groups/1/calendar"},
+		{GET, "/calendar", "/calendar"},
+		{POS, "/activity-groups/{id}/calendar", "/activity-groups/1/calendar"},
+	}
+
+	// streamEndpoints served under /v1 and unversioned
+	streamEndpoints = []endpoint{
+		{GET, "/activity-groups/{id}/events", "/activity-groups/1/events"},
+		{GET, "/events", "/events"},
+	}
+
+	// rootEndpoints served unversioned only
+	rootEndpoints = []endpoint{
+		{GET, "/ws", "/ws"},
+		{POS, "/batch", "/batch"},
+		{GET, "/graphql", "/graphql"},
+		{POS, "/graphql", "/graphql"},
+		{GET, "/openapi.json", "/openapi.json"},
+		{GET, "/docs", "/docs"},
+		{GET, "/healthz", "/healthz"},
+		{GET, "/livez", "/livez"},
+		{GET, "/readyz", "/readyz"},
+	}
+)
+
+// newRouter the router of the server, with handlers answering without a
+// database only where the tests call them
+func newRouter() *mux.Router {
+	serviceCtx := &service.Ctx{}
+	broker := events.NewBroker(1, events.NewLocalFanOut())
+
+	return InitialRouter(
+		activity.NewActivityHandler(serviceCtx),
+		todo.NewTodoHandler(serviceCtx),
+		webhook.NewWebhookHandler(serviceCtx),
+		event.NewEventHandler(serviceCtx, broker, time.Second),
+		realtime.NewRealtimeHandler(realtime.NewHub(serviceCtx, broker), func() []string { return nil }),
+		graph.NewGraphHandler(serviceCtx, broker, config.GraphQLConfig{}, time.Second),
+		openapi.NewOpenAPIHandler(openapi.NewDocument()),
+		healthHandler.NewHealthHandler(health.New(health.BuildInfo{}, time.Second)),
+		batch.NewBatchHandler(nil, func() config.BatchConfig { return config.BatchConfig{} }),
+		sync.NewSyncHandler(serviceCtx, func() config.SyncConfig { return config.SyncConfig{} }),
+		transfer.NewTransferHandler(serviceCtx, func() config.ImportConfig {
+			return config.ImportConfig{}
+		}, func() config.CalendarConfig {
+			return config.CalendarConfig{}
+		}),
+		Options{
+			Legacy: &Deprecation{Since: time.Date(2023, time.June, 1, 0, 0, 0, 0, time.UTC), Successor: V1},
+			Bodies: openapi.Bodies(V2),
+		},
+	)
+}
+
+// routeTable every endpoint with its prefix
+func routeTable() []endpoint {
+	var table []endpoint
+	add := func(prefix string, endpoints []endpoint) {
+		for _, e := range endpoints {
+			table = append(table, endpoint{e.method, prefix + e.template, prefix + e.path})
+		}
+	}
+
+	for _, prefix := range []string{V1, V2, ""} {
+		add(prefix, resourceEndpoints)
+	}
+	for _, prefix := range []string{V1, ""} {
+		add(prefix, streamEndpoints)
+	}
+	add("", rootEndpoints)
+
+	return table
+}
+
+// templateParam a variable of a route template
+var templateParam = regexp.MustCompile(`\{[^}]+\}`)
+
+// streamed tell whether the GET of e opens a stream, answering no HEAD
+func streamed(e endpoint) bool {
+	tpl := strings.TrimPrefix(e.template, V1)
+	if tpl == "/ws" || tpl == "/graphql" {
+		return true
+	}
+	for _, x := range streamEndpoints {
+		if x.template == tpl {
+			return true
+		}
+	}
+
+	return false
+}
+
+// allowOf the Allow header of path according to the route table, every
+// template matching it counting, such as /todo-items/{id} for /todo-items/bulk
+func allowOf(table []endpoint, path string) string {
+	set := map[string]bool{OPT: true}
+	for _, e := range table {
+		parts := templateParam.Split(e.template, -1)
+		for i := range parts {
+			parts[i] = regexp.QuoteMeta(parts[i])
+		}
+		if regexp.MustCompile("^" + strings.Join(parts, "[^/]+") + "$").MatchString(path) {
+			set[e.method] = true
+			if e.method == GET && !streamed(e) {
+				set[HEAD] = true
+			}
+		}
+	}
+
+	allow := make([]string, 0, len(set))
+	for m := range set {
+		allow = append(allow, m)
+	}
+	sort.Strings(allow)
+
+	return strings.Join(allow, ", ")
+}
+
+func TestRouteTable(t *testing.T) {
+	r := newRouter()
+
+	for _, e := range routeTable() {
+		t.Run(e.method+" "+e.path, func(t *testing.T) {
+			var match mux.RouteMatch
+			if !r.Match(httptest.NewRequest(e.method, e.path, nil), &match) || match.MatchErr != nil {
+				t.Fatalf("no route, %v", match.MatchErr)
+			}
+
+			tpl, err := match.Route.GetPathTemplate()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tpl != e.template {
+				t.Fatalf("routed to %s, expected %s", tpl, e.template)
+			}
+
+			methods, _ := match.Route.GetMethods()
+			if len(methods) == 0 || !contains(methods, e.method) {
+				t.Fatalf("route of %v, expected %s", methods, e.method)
+			}
+		})
+	}
+}
+
+func TestRouteTableMethodNotAllowed(t *testing.T) {
+	r := newRouter()
+	table := routeTable()
+
+	seen := map[string]bool{}
+	for _, e := range table {
+		if seen[e.path] {
+			continue
+		}
+		seen[e.path] = true
+
+		t.Run(e.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodPatch, e.path, nil))
+
+			if w.Code != http.StatusMethodNotAllowed {
+				t.Fatalf("status %d, expected 405", w.Code)
+			}
+			if allow := w.Header().Get("Allow"); allow != allowOf(table, e.path) {
+				t.Fatalf("Allow %q, expected %q", allow, allowOf(table, e.path))
+			}
+			assertJSONError(t, w, http.StatusMethodNotAllowed)
+		})
+	}
+}
+
+func TestNotFound(t *testing.T) {
+	r := newRouter()
+
+	for _, path := range []string{"/nope", "/v1/nope", "/v2/events", "/v1/todo-items/1/nope", "/v3/todo-items"} {
+		t.Run(path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(GET, path, nil))
+
+			if w.Code != http.StatusNotFound {
+				t.Fatalf("status %d, expected 404", w.Code)
+			}
+			assertJSONError(t, w, http.StatusNotFound)
+		})
+	}
+}
+
+func TestOptions(t *testing.T) {
+	r := newRouter()
+
+	tests := []struct {
+		path  string
+		allow string
+	}{
+		{"/v1/todo-items", "GET, HEAD, OPTIONS, POST"},
+		{"/v2/todo-items/1", "DELETE, GET, HEAD, OPTIONS, PUT"},
+		{"/activity-groups/1/calendar", "GET, HEAD, OPTIONS, POST"},
+		// the routes of /todo-items/{id} match it too
+		{"/todo-items/bulk", "DELETE, GET, HEAD, OPTIONS, POST, PUT"},
+		// a HEAD would open the stream of a subscription
+		{"/graphql", "GET, OPTIONS, POST"},
+		{"/v1/events", "GET, OPTIONS"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(OPT, tt.path, nil))
+
+			if w.Code != http.StatusNoContent {
+				t.Fatalf("status %d, expected 204", w.Code)
+			}
+			if allow := w.Header().Get("Allow"); allow != tt.allow {
+				t.Fatalf("Allow %q, expected %q", allow, tt.allow)
+			}
+		})
+	}
+}
+
+func TestHeadServedByGet(t *testing.T) {
+	r := newRouter()
+
+	for _, path := range []string{"/openapi.json", "/livez", "/docs"} {
+		t.Run(path, func(t *testing.T) {
+			get := httptest.NewRecorder()
+			r.ServeHTTP(get, httptest.NewRequest(GET, path, nil))
+
+			head := httptest.NewRecorder()
+			r.ServeHTTP(head, httptest.NewRequest(HEAD, path, nil))
+
+			if head.Code != get.Code || head.Code != http.StatusOK {
+				t.Fatalf("HEAD status %d, GET status %d", head.Code, get.Code)
+			}
+			if head.Header().Get("Content-Type") != get.Header().Get("Content-Type") {
+				t.Fatalf("HEAD Content-Type %q, GET %q", head.Header().Get("Content-Type"), get.Header().Get("Content-Type"))
+			}
+		})
+	}
+
+	// no GET to answer it
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(HEAD, "/batch", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("HEAD /batch status %d, expected 405", w.Code)
+	}
+}
+
+func TestHeadOpensNoStream(t *testing.T) {
+	r := newRouter()
+
+	for _, path := range []string{"/events", "/v1/events", "/activity-groups/1/events", "/v1/activity-groups/1/events", "/ws", "/graphql"} {
+		t.Run(path, func(t *testing.T) {
+			// the stream would block the request until the test times out
+			done := make(chan *httptest.ResponseRecorder, 1)
+			go func() {
+				w := httptest.NewRecorder()
+				r.ServeHTTP(w, httptest.NewRequest(HEAD, path+"?query=subscription{todoChanged{id}}", nil))
+				done <- w
+			}()
+
+			select {
+			case w := <-done:
+				if w.Code != http.StatusMethodNotAllowed {
+					t.Fatalf("status %d, expected 405", w.Code)
+				}
+				if allow := w.Header().Get("Allow"); strings.Contains(allow, HEAD) {
+					t.Fatalf("Allow %q lists HEAD", allow)
+				}
+				assertJSONError(t, w, http.StatusMethodNotAllowed)
+			case <-time.After(2 * time.Second):
+				t.Fatal("HEAD opened the stream")
+			}
+		})
+	}
+}
+
+func TestTrimTrailingSlash(t *testing.T) {
+	h := TrimTrailingSlash(newRouter())
+
+	tests := []struct {
+		method string
+		path   string
+		status int
+	}{
+		{GET, "/openapi.json/", http.StatusOK},
+		{GET, "/livez//", http.StatusOK},
+		{OPT, "/v1/todo-items/", http.StatusNoContent},
+		{http.MethodPatch, "/v2/activity-groups/1/", http.StatusMethodNotAllowed},
+		{GET, "/nope/", http.StatusNotFound},
+		{GET, "/", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+
+			if w.Code != tt.status {
+				t.Fatalf("status %d, expected %d", w.Code, tt.status)
+			}
+		})
+	}
+}
+
+// assertJSONError check w holds a JSON error of status
+func assertJSONError(t *testing.T, w *httptest.ResponseRecorder, status int) {
+	t.Helper()
+
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+		t.Fatalf("Content-Type %q, expected JSON", ct)
+	}
+
+	var body map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("body %q isn't JSON: %v", w.Body.String(), err)
+	}
+	if len(body) == 0 {
+		t.Fatalf("empty error body for %d", status)
+	}
+}
@@ -0,0 +1,215 @@
+package routers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"todolist-api/utils"
+
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+)
+
+// route one entry of a route table
+type route struct {
+	method  string
+	path    string
+	handler http.HandlerFunc
+}
+
+// register add the routes to r, announcing their deprecation when d is set
+func register(r *mux.Router, routes []route, d *Deprecation) {
+	for _, x := range routes {
+		handler := x.handler
+		if d != nil {
+			handler = d.wrap(handler)
+		}
+		r.HandleFunc(x.path, handler).Methods(x.method)
+	}
+}
+
+// Deprecation announce the retirement of a route with the Deprecation (RFC 9745)
+// and Sunset (RFC 8594) response headers
+type Deprecation struct {
+	// Since when the route is deprecated
+	Since time.Time
+	// Sunset when the route stops answering, left out while unknown
+	Sunset time.Time
+	// Successor the prefix of the route replacing it, linked as successor-version
+	Successor string
+}
+
+func (d Deprecation) wrap(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Deprecation", "@"+strconv.FormatInt(d.Since.Unix(), 10))
+		if !d.Sunset.IsZero() {
+			w.Header().Set("Sunset", d.Sunset.UTC().Format(http.TimeFormat))
+		}
+		if d.Successor != "" {
+			w.Header().Add("Link", "<"+d.Successor+r.URL.Path+`>; rel="successor-version"`)
+		}
+
+		next(w, r)
+	}
+}
+
+// SnakeCase rewrite the keys of JSON responses to snake_case, such as
+// createdAt to created_at, and the snake_case keys of JSON requests back to
+// the names of the fields of bodies
+func SnakeCase(bodies ...interface{}) mux.MiddlewareFunc {
+	names := map[string]string{}
+	for _, b := range bodies {
+		fieldNames(reflect.TypeOf(b), names, map[reflect.Type]bool{})
+	}
+
+	return func(next http.Handler) http.Handler {
+		return snakeCase(next, names)
+	}
+}
+
+func snakeCase(next http.Handler, names map[string]string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(names) > 0 && r.Body != nil && strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				utils.SetResponseErrJSON(http.StatusBadRequest, err.Error()).JSONErrResponse(w)
+				return
+			}
+
+			// left to the handler to reject when it isn't JSON
+			if rewritten, err := renameJSON(body, names); err == nil {
+				body = rewritten
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			r.ContentLength = int64(len(body))
+		}
+
+		buf := &bufferedWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(buf, r)
+
+		body := buf.body.Bytes()
+		if strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") {
+			if rewritten, err := snakeCaseJSON(body); err == nil {
+				body = rewritten
+			} else {
+				log.Error(err)
+			}
+		}
+
+		w.WriteHeader(buf.status)
+		if _, err := w.Write(body); err != nil {
+			log.Error(err)
+		}
+	})
+}
+
+// bufferedWriter hold the response until the handler returns
+type bufferedWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *bufferedWriter) WriteHeader(status int) {
+	w.status = status
+}
+
+func (w *bufferedWriter) Write(p []byte) (int, error) {
+	return w.body.Write(p)
+}
+
+func (w *bufferedWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func snakeCaseJSON(data []byte) ([]byte, error) {
+	return rewriteJSON(data, utils.ToSnakeCase)
+}
+
+// renameJSON rename the keys of data found in names
+func renameJSON(data []byte, names map[string]string) ([]byte, error) {
+	return rewriteJSON(data, func(k string) string {
+		if name, ok := names[k]; ok {
+			return name
+		}
+		return k
+	})
+}
+
+func rewriteJSON(data []byte, rename func(string) string) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	if err := json.NewEncoder(&out).Encode(rewriteKeys(v, rename)); err != nil {
+		return nil, err
+	}
+
+	return out.Bytes(), nil
+}
+
+func rewriteKeys(v interface{}, rename func(string) string) interface{} {
+	switch x := v.(type) {
+	case map[string]interface{}:
+		res := make(map[string]interface{}, len(x))
+		for k, item := range x {
+			res[rename(k)] = rewriteKeys(item, rename)
+		}
+		return res
+	case []interface{}:
+		for i := range x {
+			x[i] = rewriteKeys(x[i], rename)
+		}
+		return x
+	}
+
+	return v
+}
+
+// fieldNames add to names the json names of the fields of t, nested ones
+// included, by their snake_case name when it differs
+func fieldNames(t reflect.Type, names map[string]string, seen map[reflect.Type]bool) {
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map:
+		fieldNames(t.Elem(), names, seen)
+		return
+	case reflect.Struct:
+	default:
+		return
+	}
+
+	if seen[t] {
+		return
+	}
+	seen[t] = true
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		if snake := utils.ToSnakeCase(name); snake != name {
+			names[snake] = name
+		}
+
+		fieldNames(f.Type, names, seen)
+	}
+}
@@ -0,0 +1,83 @@
+package routers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// camelBody a request body of v1 with camelCase names
+type camelBody struct {
+	DueDate string `json:"dueDate"`
+	Items   []struct {
+		StartsAt string `json:"startsAt"`
+	} `json:"items"`
+	Title string `json:"title"`
+}
+
+func TestSnakeCase(t *testing.T) {
+	var received map[string]interface{}
+	handler := SnakeCase(camelBody{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Error(err)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"data":{"activityGroupId":1,"createdAt":"2024-01-01","items":[{"startsAt":"2024-01-02"}]}}`))
+	}))
+
+	// a key unknown to the body, such as one set by the client, is kept
+	req := httptest.NewRequest(http.MethodPost, "/v2/todo-items", bytes.NewBufferString(`{"due_date":"2024-01-03","items":[{"starts_at":"2024-01-04"}],"title":"Buy milk","other_key":true}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	expected := map[string]interface{}{
+		"dueDate":   "2024-01-03",
+		"items":     []interface{}{map[string]interface{}{"startsAt": "2024-01-04"}},
+		"title":     "Buy milk",
+		"other_key": true,
+	}
+	if !reflect.DeepEqual(received, expected) {
+		t.Fatalf("handler read %v, expected %v", received, expected)
+	}
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status %d", w.Code)
+	}
+	var res map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatal(err)
+	}
+	expected = map[string]interface{}{"data": map[string]interface{}{
+		"activity_group_id": float64(1),
+		"created_at":        "2024-01-01",
+		"items":             []interface{}{map[string]interface{}{"starts_at": "2024-01-02"}},
+	}}
+	if !reflect.DeepEqual(res, expected) {
+		t.Fatalf("response %s", w.Body.String())
+	}
+}
+
+func TestSnakeCaseInvalidRequest(t *testing.T) {
+	handler := SnakeCase(camelBody{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var v interface{}
+		if err := json.NewDecoder(r.Body).Decode(&v); err == nil {
+			t.Error("expected the handler to read the invalid body as is")
+		}
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/v2/todo-items", bytes.NewBufferString(`{"due_date":`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status %d", w.Code)
+	}
+}
@@ -4,7 +4,14 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"todolist-api/cmd/backup"
+	"todolist-api/cmd/client"
+	"todolist-api/cmd/configuration"
+	"todolist-api/cmd/grpc"
 	"todolist-api/cmd/http"
+	"todolist-api/cmd/seed"
+	"todolist-api/config"
+	"todolist-api/infra/health"
 
 	"github.com/spf13/cobra"
 )
@@ -50,13 +57,35 @@ var (
 )
 
 func init() {
+	config.AddFlags(rootCmd.PersistentFlags())
+
 	rootCmd.AddCommand(versionCmd)
+	rootCmd.AddCommand(configuration.ConfigCommand())
 	rootCmd.AddCommand(http.ServeHTTP())
+	rootCmd.AddCommand(grpc.ServeGRPC())
+	rootCmd.AddCommand(client.ActivityCommand())
+	rootCmd.AddCommand(client.TodoCommand())
+	rootCmd.AddCommand(backup.BackupCommand())
+	rootCmd.AddCommand(backup.RestoreCommand())
+	rootCmd.AddCommand(seed.SeedCommand())
+}
+
+// BuildInfo return the build information reported by the health endpoints
+func (a *AppInfo) BuildInfo() health.BuildInfo {
+	return health.BuildInfo{
+		Name:      a.AppName,
+		Version:   a.AppVersion,
+		Commit:    a.AppCommit,
+		GoVersion: a.BuildGoVersion,
+		Arch:      a.BuildArch,
+		Date:      strings.Replace(a.BuildDate, "_", " ", -1),
+	}
 }
 
 // Execute run root command
 func Execute(appInfo *AppInfo) {
 	app = appInfo
+	http.SetBuildInfo(app.BuildInfo())
 	if err := rootCmd.Execute(); err != nil {
 		os.Exit(1)
 	}
@@ -0,0 +1,151 @@
+package seed
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+	"todolist-api/constants"
+	"todolist-api/data/models"
+)
+
+const (
+	// history how far back the activity groups are created
+	history = 90 * 24 * time.Hour
+	// dueWithin the days after its creation a todo is due at most
+	dueWithin = 30
+	// epoch the default end of the history, fixed so a seed always draws
+	// the same data
+	epoch = "2024-01-01T00:00:00Z"
+)
+
+var (
+	firstNames = []string{
+		"alice", "bruno", "chloe", "dimas", "elena", "farah", "gilang", "hana",
+		"ivan", "julia", "kevin", "laras", "maya", "nadia", "omar", "putri",
+	}
+	lastNames = []string{
+		"anderson", "baker", "costa", "dewi", "evans", "fischer", "gunawan", "hartono",
+		"ito", "jensen", "kusuma", "lopez", "martin", "nguyen", "okafor", "pratama",
+	}
+	domains = []string{"example.com", "example.org", "example.net", "mail.example.com"}
+
+	groupSubjects = []string{
+		"Home", "Garden", "Office", "Team", "Family", "Side project", "Travel",
+		"Fitness", "Reading", "Finance", "Wedding", "Product launch",
+	}
+	groupKinds = []string{"chores", "planning", "errands", "backlog", "goals", "checklist", "ideas", "prep"}
+
+	verbs = []string{
+		"Call", "Email", "Buy", "Fix", "Review", "Book", "Plan", "Clean",
+		"Update", "Prepare", "Renew", "Schedule", "Pay", "Draft", "Return",
+	}
+	objects = []string{
+		"the plumber", "groceries", "the quarterly report", "flight tickets", "the car insurance",
+		"the garage", "the budget spreadsheet", "a dentist appointment", "the library books",
+		"the slides for Monday", "the electricity bill", "the team retrospective", "the passport",
+		"a birthday present", "the bike", "the release notes",
+	}
+
+	// priorityWeights how often each of constants.Priorities is drawn
+	priorityWeights = []int{1, 2, 4, 2, 1}
+
+	rrules = []string{"FREQ=DAILY", "FREQ=WEEKLY;BYDAY=MO", "FREQ=WEEKLY;BYDAY=FR", "FREQ=MONTHLY"}
+)
+
+// generator draw the activity groups and the todos from rng, their
+// timestamps spread over the history before now
+type generator struct {
+	rng *rand.Rand
+	now time.Time
+}
+
+// newGenerator the generator of seed, the timestamps being before now
+func newGenerator(seed int64, now time.Time) *generator {
+	return &generator{
+		rng: rand.New(rand.NewSource(seed)),
+		now: now.UTC(),
+	}
+}
+
+// activity draw an activity group and its todos, ActivityGroupID being left
+// to set once the group is inserted
+func (g *generator) activity(todos int) (models.Activity, []models.Todo) {
+	first := pick(g.rng, firstNames)
+	last := pick(g.rng, lastNames)
+
+	data := models.Activity{
+		Title:     fmt.Sprintf("%s %s", pick(g.rng, groupSubjects), pick(g.rng, groupKinds)),
+		Email:     fmt.Sprintf("%s.%s@%s", first, last, pick(g.rng, domains)),
+		CreatedAt: g.between(g.now.Add(-history), g.now),
+	}
+	data.UpdatedAt = g.between(data.CreatedAt, g.now)
+
+	items := make([]models.Todo, 0, todos)
+	for i := 0; i < todos; i++ {
+		items = append(items, g.todo(data.CreatedAt))
+	}
+
+	return data, items
+}
+
+// todo draw a todo created after since, done about a third of the time, due
+// more often than not and then sometimes repeating
+func (g *generator) todo(since time.Time) models.Todo {
+	data := models.Todo{
+		Title:     fmt.Sprintf("%s %s", pick(g.rng, verbs), pick(g.rng, objects)),
+		IsActive:  g.rng.Intn(3) != 0,
+		Priority:  g.priority(),
+		CreatedAt: g.between(since, g.now),
+	}
+	data.UpdatedAt = data.CreatedAt
+	if !data.IsActive {
+		data.UpdatedAt = g.between(data.CreatedAt, g.now)
+	}
+
+	if g.rng.Intn(5) < 3 {
+		// during the working hours of a day after the creation
+		day := data.CreatedAt.Truncate(24*time.Hour).AddDate(0, 0, 1+g.rng.Intn(dueWithin))
+		due := day.Add(time.Duration(9+g.rng.Intn(9)) * time.Hour)
+		data.DueAt = &due
+
+		if data.IsActive && g.rng.Intn(6) == 0 {
+			rrule := pick(g.rng, rrules)
+			data.RRule = &rrule
+		}
+	}
+
+	return data
+}
+
+// priority draw a priority, normal being the most frequent
+func (g *generator) priority() string {
+	total := 0
+	for _, w := range priorityWeights {
+		total += w
+	}
+
+	n := g.rng.Intn(total)
+	for i, w := range priorityWeights {
+		if n < w {
+			return constants.Priorities[i]
+		}
+		n -= w
+	}
+
+	return constants.Priority
+}
+
+// between draw a time from start to end, to the second
+func (g *generator) between(start, end time.Time) time.Time {
+	span := int64(end.Sub(start) / time.Second)
+	if span <= 0 {
+		return start
+	}
+
+	return start.Add(time.Duration(g.rng.Int63n(span)) * time.Second)
+}
+
+// pick draw one of values
+func pick(rng *rand.Rand, values []string) string {
+	return values[rng.Intn(len(values))]
+}
@@ -0,0 +1,39 @@
+package seed
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGeneratorDeterministic(t *testing.T) {
+	now, err := time.Parse(time.RFC3339, epoch)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a, b := newGenerator(42, now), newGenerator(42, now)
+	for i := 0; i < 50; i++ {
+		groupA, todosA := a.activity(20)
+		groupB, todosB := b.activity(20)
+		if !reflect.DeepEqual(groupA, groupB) || !reflect.DeepEqual(todosA, todosB) {
+			t.Fatalf("group %d differs with the same seed:\n%+v\n%+v", i, groupA, groupB)
+		}
+
+		if groupA.CreatedAt.After(now) || groupA.CreatedAt.Before(now.Add(-history)) {
+			t.Fatalf("group %d created at %s, out of the history", i, groupA.CreatedAt)
+		}
+		for _, x := range todosA {
+			if x.CreatedAt.Before(groupA.CreatedAt) || x.UpdatedAt.After(now) {
+				t.Fatalf("group %d: todo created at %s, updated at %s", i, x.CreatedAt, x.UpdatedAt)
+			}
+		}
+	}
+
+	// another seed draws other data
+	groupA, _ := newGenerator(42, now).activity(20)
+	groupC, _ := newGenerator(43, now).activity(20)
+	if reflect.DeepEqual(groupA, groupC) {
+		t.Fatal("seeds 42 and 43 drew the same group")
+	}
+}
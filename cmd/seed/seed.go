@@ -0,0 +1,184 @@
+package seed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+	"todolist-api/config"
+	"todolist-api/constants"
+	"todolist-api/data/models"
+	"todolist-api/infra/context/repository"
+	"todolist-api/infra/db"
+	"todolist-api/infra/logger"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	// batchGroups the activity groups inserted along with their todos by a
+	// transaction
+	batchGroups = 100
+	// batchTodos the todos inserted by a statement
+	batchTodos = 500
+)
+
+var seedCMD = &cobra.Command{
+	Use:   "seed",
+	Short: "Fill the database with demo data",
+	Long: "Insert fake activity groups and todos of every priority, some done, some due or repeating, " +
+		"created over the 90 days before --now. The same --seed and --now draw the same data, " +
+		"and the ids start over with --reset, which empties the database first and is refused in production.",
+	Args: cobra.NoArgs,
+	RunE: runSeed,
+}
+
+func init() {
+	seedCMD.Flags().Int("groups", 10, "number of activity groups")
+	seedCMD.Flags().Int("todos-per-group", 20, "number of todos of each activity group")
+	seedCMD.Flags().Int64("seed", 42, "seed of the random data")
+	seedCMD.Flags().String("now", epoch, "end of the history of the data, in RFC 3339")
+	seedCMD.Flags().Bool("reset", false, "empty every table before seeding")
+}
+
+// tally the todos seeded by priority, active and done
+type tally map[string][2]int
+
+func runSeed(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	ctx := cmd.Context()
+	groups, _ := cmd.Flags().GetInt("groups")
+	todos, _ := cmd.Flags().GetInt("todos-per-group")
+	seed, _ := cmd.Flags().GetInt64("seed")
+	reset, _ := cmd.Flags().GetBool("reset")
+	flagNow, _ := cmd.Flags().GetString("now")
+
+	if groups < 0 || todos < 0 {
+		return errors.New("--groups and --todos-per-group can't be negative")
+	}
+
+	now, err := time.Parse(time.RFC3339, flagNow)
+	if err != nil {
+		return fmt.Errorf("--now: %w", err)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if reset && cfg.IsProduction() {
+		return constants.ErrResetProduction
+	}
+	if err := logger.Configure(cfg.Log); err != nil {
+		return err
+	}
+
+	conn, err := db.Open(&cfg.DB)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	repoCtx := repository.NewRepoCtx(conn, nil)
+	if reset {
+		if err := repoCtx.BackupRepository.Truncate(ctx); err != nil {
+			return fmt.Errorf("reset: %w", err)
+		}
+	}
+
+	gen := newGenerator(seed, now)
+	counts := tally{}
+	for start := 0; start < groups; start += batchGroups {
+		end := start + batchGroups
+		if end > groups {
+			end = groups
+		}
+
+		if err := insert(ctx, conn, repoCtx, gen, end-start, todos, counts); err != nil {
+			return fmt.Errorf("seed activity groups %d to %d: %w", start+1, end, err)
+		}
+	}
+
+	title := fmt.Sprintf("seeded %d activity groups and %d todos with seed %d", groups, groups*todos, seed)
+	return summary(cmd.OutOrStdout(), title, counts)
+}
+
+// insert n activity groups of todos each drawn from gen in one transaction
+func insert(ctx context.Context, conn *db.DB, repoCtx *repository.RepoCtx, gen *generator, n, todos int, counts tally) error {
+	groups := make([]models.Activity, 0, n)
+	items := make([][]models.Todo, 0, n)
+	for i := 0; i < n; i++ {
+		group, children := gen.activity(todos)
+		groups = append(groups, group)
+		items = append(items, children)
+	}
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	ids, err := repoCtx.ActivityRepository.SeedActivities(ctx, tx, groups)
+	if err != nil {
+		return err
+	}
+
+	var batch []models.Todo
+	for i, children := range items {
+		for _, x := range children {
+			x.ActivityGroupID = ids[i]
+			batch = append(batch, x)
+
+			if len(batch) == batchTodos {
+				if err := repoCtx.TodoRepository.SeedTodos(ctx, tx, batch); err != nil {
+					return err
+				}
+				batch = batch[:0]
+			}
+		}
+	}
+	if err := repoCtx.TodoRepository.SeedTodos(ctx, tx, batch); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	for _, children := range items {
+		for _, x := range children {
+			c := counts[x.Priority]
+			if x.IsActive {
+				c[0]++
+			} else {
+				c[1]++
+			}
+			counts[x.Priority] = c
+		}
+	}
+
+	return nil
+}
+
+// summary write the todos seeded of each priority
+func summary(w io.Writer, title string, counts tally) error {
+	fmt.Fprintln(w, title)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "PRIORITY\tACTIVE\tDONE")
+	for _, priority := range constants.Priorities {
+		fmt.Fprintf(tw, "%s\t%d\t%d\n", priority, counts[priority][0], counts[priority][1])
+	}
+
+	return tw.Flush()
+}
+
+// SeedCommand return instance of seed command object
+func SeedCommand() *cobra.Command {
+	return seedCMD
+}
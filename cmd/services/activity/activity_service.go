@@ -4,8 +4,11 @@ import (
 	"context"
 	"todolist-api/constants"
 	"todolist-api/data/models"
+	"todolist-api/data/repositories/change"
 	"todolist-api/infra/context/repository"
 	"todolist-api/infra/errors"
+	"todolist-api/infra/events"
+	"todolist-api/infra/idempotency"
 	"todolist-api/objects/activity"
 )
 
@@ -24,6 +27,14 @@ func (a activityService) CreateActivity(ctx context.Context, req activity.Create
 		return activity.Activity{}, errors.Wrap(constants.ErrTitleCannotBeNull)
 	}
 
+	// a retry gets the activity created by the first request
+	var replayed activity.Activity
+	ok, err := idempotency.Replay(ctx, a.IdempotencyRepository, tx, &replayed)
+	if err != nil || ok {
+		_ = tx.Rollback()
+		return replayed, err
+	}
+
 	activityID, err := a.ActivityRepository.CreateActivity(ctx, tx, models.Activity{
 		Title: req.Title,
 		Email: req.Email,
@@ -39,37 +50,68 @@ func (a activityService) CreateActivity(ctx context.Context, req activity.Create
 		return activity.Activity{}, err
 	}
 
-	err = tx.Commit()
+	result := NewActivity(data)
+
+	err = a.WebhookRepository.EnqueueEvent(ctx, tx, constants.EventActivityCreated, result)
 	if err != nil {
 		_ = tx.Rollback()
 		return activity.Activity{}, err
 	}
 
-	return activity.Activity{
-		ID:        data.ActivityID,
-		Title:     data.Title,
-		Email:     data.Email,
-		CreatedAt: data.CreatedAt.UTC().Format(constants.DateTimeFormat),
-		UpdatedAt: data.UpdatedAt.UTC().Format(constants.DateTimeFormat),
-	}, nil
+	err = idempotency.Save(ctx, a.IdempotencyRepository, tx, result)
+	if err != nil {
+		_ = tx.Rollback()
+		return activity.Activity{}, err
+	}
+
+	err = change.Commit(ctx, a.ChangeRepository, tx, models.Change{
+		Entity:    constants.SyncActivity,
+		EntityID:  result.ID,
+		Operation: constants.ChangeUpsert,
+		Fields:    []string{constants.FieldTitle, constants.FieldEmail},
+	})
+	if err != nil {
+		_ = tx.Rollback()
+		return activity.Activity{}, err
+	}
+
+	a.Publisher.Publish(ctx, events.Event{
+		Type:            constants.EventActivityCreated,
+		ActivityGroupID: result.ID,
+		Data:            result,
+	})
+
+	return result, nil
 }
 
-func (a activityService) GetAllActivity(ctx context.Context) ([]activity.Activity, error) {
+func (a activityService) GetAllActivity(ctx context.Context, filter activity.FilterActivity) ([]activity.Activity, error) {
 	tmpActivityData := []activity.Activity{}
 
-	data, err := a.ActivityRepository.GetAllActivity(ctx)
+	data, err := a.ActivityRepository.GetAllActivity(ctx, models.ActivityFilter{
+		Email: filter.Email,
+	})
 	if err != nil {
 		return tmpActivityData, err
 	}
 
 	for _, x := range data {
-		tmpActivityData = append(tmpActivityData, activity.Activity{
-			ID:        x.ActivityID,
-			Title:     x.Title,
-			Email:     x.Email,
-			CreatedAt: x.CreatedAt.UTC().Format(constants.DateTimeFormat),
-			UpdatedAt: x.UpdatedAt.UTC().Format(constants.DateTimeFormat),
-		})
+		tmpActivityData = append(tmpActivityData, NewActivity(x))
+	}
+
+	return tmpActivityData, nil
+}
+
+// GetActivityByIDs load several activity groups in one query, keyed by ID
+func (a activityService) GetActivityByIDs(ctx context.Context, ids []int) (map[int]activity.Activity, error) {
+	tmpActivityData := make(map[int]activity.Activity, len(ids))
+
+	data, err := a.ActivityRepository.GetActivityByIDs(ctx, ids)
+	if err != nil {
+		return tmpActivityData, err
+	}
+
+	for _, x := range data {
+		tmpActivityData[x.ActivityID] = NewActivity(x)
 	}
 
 	return tmpActivityData, nil
@@ -93,13 +135,7 @@ func (a activityService) GetOneActivity(ctx context.Context, id int) (activity.A
 		return activity.Activity{}, err
 	}
 
-	return activity.Activity{
-		ID:        data.ActivityID,
-		Title:     data.Title,
-		Email:     data.Email,
-		CreatedAt: data.CreatedAt.UTC().Format(constants.DateTimeFormat),
-		UpdatedAt: data.UpdatedAt.UTC().Format(constants.DateTimeFormat),
-	}, nil
+	return NewActivity(data), nil
 }
 
 func (a activityService) UpdateActivity(ctx context.Context, id int, req activity.UpdateActivity) (activity.Activity, error) {
@@ -127,19 +163,32 @@ func (a activityService) UpdateActivity(ctx context.Context, id int, req activit
 		return activity.Activity{}, err
 	}
 
-	err = tx.Commit()
+	result := NewActivity(data)
+
+	err = a.WebhookRepository.EnqueueEvent(ctx, tx, constants.EventActivityUpdated, result)
 	if err != nil {
 		_ = tx.Rollback()
 		return activity.Activity{}, err
 	}
 
-	return activity.Activity{
-		ID:        data.ActivityID,
-		Title:     data.Title,
-		Email:     data.Email,
-		CreatedAt: data.CreatedAt.UTC().Format(constants.DateTimeFormat),
-		UpdatedAt: data.UpdatedAt.UTC().Format(constants.DateTimeFormat),
-	}, nil
+	err = change.Commit(ctx, a.ChangeRepository, tx, models.Change{
+		Entity:    constants.SyncActivity,
+		EntityID:  result.ID,
+		Operation: constants.ChangeUpsert,
+		Fields:    []string{constants.FieldTitle},
+	})
+	if err != nil {
+		_ = tx.Rollback()
+		return activity.Activity{}, err
+	}
+
+	a.Publisher.Publish(ctx, events.Event{
+		Type:            constants.EventActivityUpdated,
+		ActivityGroupID: result.ID,
+		Data:            result,
+	})
+
+	return result, nil
 }
 
 func (a activityService) DeleteActivity(ctx context.Context, id int) error {
@@ -160,11 +209,40 @@ func (a activityService) DeleteActivity(ctx context.Context, id int) error {
 		return err
 	}
 
-	err = tx.Commit()
+	deleted := NewActivity(data)
+
+	err = a.WebhookRepository.EnqueueEvent(ctx, tx, constants.EventActivityDeleted, deleted)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	err = change.Commit(ctx, a.ChangeRepository, tx, models.Change{
+		Entity:    constants.SyncActivity,
+		EntityID:  deleted.ID,
+		Operation: constants.ChangeDelete,
+	})
 	if err != nil {
 		_ = tx.Rollback()
 		return err
 	}
 
+	a.Publisher.Publish(ctx, events.Event{
+		Type:            constants.EventActivityDeleted,
+		ActivityGroupID: deleted.ID,
+		Data:            deleted,
+	})
+
 	return nil
 }
+
+// NewActivity the activity of the API stored as data
+func NewActivity(data models.Activity) activity.Activity {
+	return activity.Activity{
+		ID:        data.ActivityID,
+		Title:     data.Title,
+		Email:     data.Email,
+		CreatedAt: data.CreatedAt.UTC().Format(constants.DateTimeFormat),
+		UpdatedAt: data.UpdatedAt.UTC().Format(constants.DateTimeFormat),
+	}
+}
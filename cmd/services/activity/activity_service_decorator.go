@@ -0,0 +1,58 @@
+package activity
+
+import (
+	"context"
+	"todolist-api/infra/decorator"
+	"todolist-api/objects/activity"
+)
+
+// activityServiceDecorator observe every call of the wrapped service
+type activityServiceDecorator struct {
+	next ActivityServiceInterface
+	d    *decorator.Decorator
+}
+
+// NewActivityServiceDecorator decorate the service with d, such as its timings or
+// its spans
+func NewActivityServiceDecorator(next ActivityServiceInterface, d *decorator.Decorator) ActivityServiceInterface {
+	return &activityServiceDecorator{
+		next: next,
+		d:    d,
+	}
+}
+
+func (s activityServiceDecorator) CreateActivity(ctx context.Context, req activity.CreateActivity) (activity.Activity, error) {
+	return decorator.Call(ctx, s.d, "CreateActivity", func(ctx context.Context) (activity.Activity, error) {
+		return s.next.CreateActivity(ctx, req)
+	})
+}
+
+func (s activityServiceDecorator) GetAllActivity(ctx context.Context, filter activity.FilterActivity) ([]activity.Activity, error) {
+	return decorator.Call(ctx, s.d, "GetAllActivity", func(ctx context.Context) ([]activity.Activity, error) {
+		return s.next.GetAllActivity(ctx, filter)
+	})
+}
+
+func (s activityServiceDecorator) GetActivityByIDs(ctx context.Context, ids []int) (map[int]activity.Activity, error) {
+	return decorator.Call(ctx, s.d, "GetActivityByIDs", func(ctx context.Context) (map[int]activity.Activity, error) {
+		return s.next.GetActivityByIDs(ctx, ids)
+	})
+}
+
+func (s activityServiceDecorator) GetOneActivity(ctx context.Context, id int) (activity.Activity, error) {
+	return decorator.Call(ctx, s.d, "GetOneActivity", func(ctx context.Context) (activity.Activity, error) {
+		return s.next.GetOneActivity(ctx, id)
+	})
+}
+
+func (s activityServiceDecorator) UpdateActivity(ctx context.Context, id int, req activity.UpdateActivity) (activity.Activity, error) {
+	return decorator.Call(ctx, s.d, "UpdateActivity", func(ctx context.Context) (activity.Activity, error) {
+		return s.next.UpdateActivity(ctx, id, req)
+	})
+}
+
+func (s activityServiceDecorator) DeleteActivity(ctx context.Context, id int) error {
+	return decorator.Exec(ctx, s.d, "DeleteActivity", func(ctx context.Context) error {
+		return s.next.DeleteActivity(ctx, id)
+	})
+}
@@ -8,7 +8,8 @@ import (
 
 type ActivityServiceInterface interface {
 	CreateActivity(ctx context.Context, req activity.CreateActivity) (activity.Activity, error)
-	GetAllActivity(ctx context.Context) ([]activity.Activity, error)
+	GetAllActivity(ctx context.Context, filter activity.FilterActivity) ([]activity.Activity, error)
+	GetActivityByIDs(ctx context.Context, ids []int) (map[int]activity.Activity, error)
 	GetOneActivity(ctx context.Context, id int) (activity.Activity, error)
 	UpdateActivity(ctx context.Context, id int, req activity.UpdateActivity) (activity.Activity, error)
 	DeleteActivity(ctx context.Context, id int) error
@@ -0,0 +1,247 @@
+package sync
+
+import (
+	"context"
+	"encoding/base64"
+	"strconv"
+	"strings"
+	activityservice "todolist-api/cmd/services/activity"
+	todoservice "todolist-api/cmd/services/todo"
+	"todolist-api/constants"
+	"todolist-api/data/models"
+	"todolist-api/infra/context/repository"
+	"todolist-api/infra/errors"
+	"todolist-api/objects/activity"
+	"todolist-api/objects/sync"
+	"todolist-api/objects/todo"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type syncService struct {
+	*repository.RepoCtx
+}
+
+// token the position of a client in the change feed, Seq being the last
+// change it got. A client without token first loads every entity, the
+// snapshot at Seq, Entity and After telling where the last page stopped.
+type token struct {
+	Seq    int64
+	Entity string
+	After  int
+}
+
+func (t token) String() string {
+	raw := strconv.FormatInt(t.Seq, 10)
+	if t.Entity != "" {
+		raw += ":" + t.Entity + ":" + strconv.Itoa(t.After)
+	}
+
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// parseToken read the token of a client, last being the sequence of the
+// server
+func parseToken(s string, last int64) (token, error) {
+	invalid := errors.Wrap(constants.ErrSyncTokenInvalid)
+
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return token{}, invalid
+	}
+
+	parts := strings.Split(string(raw), ":")
+	if len(parts) != 1 && len(parts) != 3 {
+		return token{}, invalid
+	}
+
+	var t token
+	t.Seq, err = strconv.ParseInt(parts[0], 10, 64)
+	if err != nil || t.Seq < 0 || t.Seq > last {
+		return token{}, invalid
+	}
+
+	if len(parts) == 3 {
+		t.Entity = parts[1]
+		t.After, err = strconv.Atoi(parts[2])
+		if err != nil || t.After < 0 || (t.Entity != constants.SyncActivity && t.Entity != constants.SyncTodo) {
+			return token{}, invalid
+		}
+	}
+
+	return t, nil
+}
+
+// Pull read the feed in one transaction, the sequence first so the changes
+// and the entities come from the snapshot it was read in. The sequence is
+// only committed in order, a change past the token is never committed
+// after a pull returned it.
+func (s syncService) Pull(ctx context.Context, req sync.Pull) (sync.Changes, error) {
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return sync.Changes{}, errors.Wrap(constants.ErrBeginTransaction)
+	}
+
+	last, err := s.ChangeRepository.GetLastSeq(ctx, tx)
+	if err != nil {
+		_ = tx.Rollback()
+		return sync.Changes{}, err
+	}
+
+	from := token{Seq: last, Entity: constants.SyncActivity}
+	if req.Since != "" {
+		from, err = parseToken(req.Since, last)
+		if err != nil {
+			_ = tx.Rollback()
+			return sync.Changes{}, err
+		}
+	}
+
+	result := sync.Changes{
+		Activities: []activity.Activity{},
+		Todos:      []todo.Todo{},
+		Deleted:    []sync.Tombstone{},
+	}
+
+	if from.Entity != "" {
+		err = s.snapshot(ctx, tx, &result, from, last, req.Limit)
+	} else {
+		err = s.feed(ctx, tx, &result, from, last, req.Limit)
+	}
+	if err != nil {
+		_ = tx.Rollback()
+		return sync.Changes{}, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		_ = tx.Rollback()
+		return sync.Changes{}, err
+	}
+
+	return result, nil
+}
+
+// snapshot page through the activity groups then the todos. The entities
+// changed while the client pages come again from the feed, past the sequence
+// of the snapshot.
+func (s syncService) snapshot(ctx context.Context, tx *sqlx.Tx, result *sync.Changes, from token, last int64, limit int) error {
+	if from.Entity == constants.SyncActivity {
+		data, err := s.ChangeRepository.GetActivityPage(ctx, tx, from.After, limit+1)
+		if err != nil {
+			return err
+		}
+
+		if len(data) > limit {
+			data = data[:limit]
+			for _, x := range data {
+				result.Activities = append(result.Activities, activityservice.NewActivity(x))
+			}
+			result.Token = token{Seq: from.Seq, Entity: constants.SyncActivity, After: data[len(data)-1].ActivityID}.String()
+			result.HasMore = true
+			return nil
+		}
+
+		for _, x := range data {
+			result.Activities = append(result.Activities, activityservice.NewActivity(x))
+		}
+		limit -= len(data)
+		from = token{Seq: from.Seq, Entity: constants.SyncTodo}
+	}
+
+	data, err := s.ChangeRepository.GetTodoPage(ctx, tx, from.After, limit+1)
+	if err != nil {
+		return err
+	}
+
+	if len(data) > limit {
+		data = data[:limit]
+		after := from.After
+		if len(data) > 0 {
+			after = data[len(data)-1].TodoID
+		}
+		for _, x := range data {
+			result.Todos = append(result.Todos, todoservice.NewTodo(x))
+		}
+		result.Token = token{Seq: from.Seq, Entity: constants.SyncTodo, After: after}.String()
+		result.HasMore = true
+		return nil
+	}
+
+	for _, x := range data {
+		result.Todos = append(result.Todos, todoservice.NewTodo(x))
+	}
+	result.Token = token{Seq: from.Seq}.String()
+	result.HasMore = from.Seq < last
+
+	return nil
+}
+
+// feed return the entities changed past the token, once each in the state of
+// their last change
+func (s syncService) feed(ctx context.Context, tx *sqlx.Tx, result *sync.Changes, from token, last int64, limit int) error {
+	changes, err := s.ChangeRepository.GetChangeSince(ctx, tx, from.Seq, limit+1)
+	if err != nil {
+		return err
+	}
+
+	next := last
+	if len(changes) > limit {
+		changes = changes[:limit]
+		next = changes[len(changes)-1].Seq
+		result.HasMore = true
+	}
+	result.Token = token{Seq: next}.String()
+
+	activityIDs := []int{}
+	todoIDs := []int{}
+	for _, x := range changes {
+		if x.Operation == constants.ChangeDelete {
+			result.Deleted = append(result.Deleted, sync.Tombstone{
+				Entity:    x.Entity,
+				ID:        x.EntityID,
+				DeletedAt: x.CreatedAt.UTC().Format(constants.DateTimeFormat),
+			})
+			continue
+		}
+
+		if x.Entity == constants.SyncActivity {
+			activityIDs = append(activityIDs, x.EntityID)
+		} else {
+			todoIDs = append(todoIDs, x.EntityID)
+		}
+	}
+
+	activities, err := s.ChangeRepository.GetActivityByIDs(ctx, tx, activityIDs)
+	if err != nil {
+		return err
+	}
+
+	todos, err := s.ChangeRepository.GetTodoByIDs(ctx, tx, todoIDs)
+	if err != nil {
+		return err
+	}
+
+	// in the order of the feed
+	byActivityID := make(map[int]models.Activity, len(activities))
+	for _, x := range activities {
+		byActivityID[x.ActivityID] = x
+	}
+	for _, id := range activityIDs {
+		if x, ok := byActivityID[id]; ok {
+			result.Activities = append(result.Activities, activityservice.NewActivity(x))
+		}
+	}
+
+	byTodoID := make(map[int]models.Todo, len(todos))
+	for _, x := range todos {
+		byTodoID[x.TodoID] = x
+	}
+	for _, id := range todoIDs {
+		if x, ok := byTodoID[id]; ok {
+			result.Todos = append(result.Todos, todoservice.NewTodo(x))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,34 @@
+package sync
+
+import (
+	"context"
+	"todolist-api/infra/decorator"
+	"todolist-api/objects/sync"
+)
+
+// syncServiceDecorator observe every call of the wrapped service
+type syncServiceDecorator struct {
+	next SyncServiceInterface
+	d    *decorator.Decorator
+}
+
+// NewSyncServiceDecorator decorate the service with d, such as its timings or
+// its spans
+func NewSyncServiceDecorator(next SyncServiceInterface, d *decorator.Decorator) SyncServiceInterface {
+	return &syncServiceDecorator{
+		next: next,
+		d:    d,
+	}
+}
+
+func (s syncServiceDecorator) Pull(ctx context.Context, req sync.Pull) (sync.Changes, error) {
+	return decorator.Call(ctx, s.d, "Pull", func(ctx context.Context) (sync.Changes, error) {
+		return s.next.Pull(ctx, req)
+	})
+}
+
+func (s syncServiceDecorator) Push(ctx context.Context, req sync.Push) (sync.PushResult, error) {
+	return decorator.Call(ctx, s.d, "Push", func(ctx context.Context) (sync.PushResult, error) {
+		return s.next.Push(ctx, req)
+	})
+}
@@ -0,0 +1,18 @@
+package sync
+
+import (
+	"context"
+	"todolist-api/infra/context/repository"
+	"todolist-api/objects/sync"
+)
+
+type SyncServiceInterface interface {
+	Pull(ctx context.Context, req sync.Pull) (sync.Changes, error)
+	Push(ctx context.Context, req sync.Push) (sync.PushResult, error)
+}
+
+func NewSyncService(ctx *repository.RepoCtx) SyncServiceInterface {
+	return &syncService{
+		ctx,
+	}
+}
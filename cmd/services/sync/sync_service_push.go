@@ -0,0 +1,657 @@
+package sync
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+	activityservice "todolist-api/cmd/services/activity"
+	todoservice "todolist-api/cmd/services/todo"
+	"todolist-api/constants"
+	"todolist-api/data/models"
+	"todolist-api/infra/errors"
+	"todolist-api/infra/idempotency"
+	"todolist-api/objects/sync"
+	"todolist-api/utils"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// push the state of a push, its writes, the IDs of the groups it created by
+// client ID and the fields it wrote
+type push struct {
+	*todoservice.Writes
+	policy  string
+	base    int64
+	now     time.Time
+	groups  map[string]int
+	written map[string]bool
+}
+
+// rejected report a mutation the client has to fix, nothing of it written
+type rejected struct {
+	err error
+}
+
+func (r rejected) Error() string {
+	return r.err.Error()
+}
+
+func reject(err error) error {
+	return rejected{err: err}
+}
+
+// Push apply the mutations in order in one transaction. The entities are
+// locked before their field clocks are read, so a write of the API can't
+// slip between the check of a conflict and the write of the mutation. A
+// field is in conflict when the server changed it past the token of the
+// client, and set to another value than the client's.
+func (s syncService) Push(ctx context.Context, req sync.Push) (sync.PushResult, error) {
+	if len(req.Mutations) == 0 {
+		return sync.PushResult{}, errors.Wrap(constants.ErrSyncEmpty)
+	}
+
+	if req.Policy == "" {
+		req.Policy = constants.SyncLastWriterWins
+	}
+
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return sync.PushResult{}, errors.Wrap(constants.ErrBeginTransaction)
+	}
+
+	p := &push{
+		Writes:  todoservice.NewWrites(s.RepoCtx, tx),
+		policy:  req.Policy,
+		now:     time.Now(),
+		groups:  map[string]int{},
+		written: map[string]bool{},
+	}
+
+	if req.Since != "" {
+		last, err := s.ChangeRepository.GetLastSeq(ctx, tx)
+		if err != nil {
+			_ = tx.Rollback()
+			return sync.PushResult{}, err
+		}
+
+		from, err := parseToken(req.Since, last)
+		if err != nil {
+			_ = tx.Rollback()
+			return sync.PushResult{}, err
+		}
+		p.base = from.Seq
+	}
+
+	// a retry gets the outcome of the first push
+	var replayed sync.PushResult
+	ok, err := idempotency.Replay(ctx, s.IdempotencyRepository, tx, &replayed)
+	if err != nil || ok {
+		_ = tx.Rollback()
+		return replayed, err
+	}
+
+	result := sync.PushResult{
+		Results: make([]sync.MutationResult, len(req.Mutations)),
+	}
+	for i, m := range req.Mutations {
+		res := &result.Results[i]
+		res.Index = i
+		res.ClientID = m.ClientID
+		res.Entity = m.Entity
+		res.ID = m.ID
+
+		err = s.apply(ctx, tx, p, res, m)
+		if r, ok := err.(rejected); ok {
+			res.Status = constants.SyncRejected
+			res.Error = r.Error()
+			continue
+		}
+		if err != nil {
+			_ = tx.Rollback()
+			return sync.PushResult{}, errors.Wrap(fmt.Errorf("mutations[%d]: %w", i, err))
+		}
+	}
+
+	err = idempotency.Save(ctx, s.IdempotencyRepository, tx, result)
+	if err != nil {
+		_ = tx.Rollback()
+		return sync.PushResult{}, err
+	}
+
+	err = p.Commit(ctx)
+	if err != nil {
+		_ = tx.Rollback()
+		return sync.PushResult{}, err
+	}
+
+	p.Publish(ctx)
+
+	return result, nil
+}
+
+// apply one mutation, reporting its outcome in res
+func (s syncService) apply(ctx context.Context, tx *sqlx.Tx, p *push, res *sync.MutationResult, m sync.Mutation) error {
+	if m.Entity != constants.SyncActivity && m.Entity != constants.SyncTodo {
+		return reject(constants.ErrSyncEntityInvalid)
+	}
+
+	if m.Timestamp.IsZero() {
+		return reject(constants.ErrSyncTimestampRequired)
+	}
+
+	// a clock ahead of the server doesn't win every conflict to come
+	if m.Timestamp.After(p.now) {
+		m.Timestamp = p.now
+	}
+
+	switch m.Operation {
+	case constants.SyncCreate:
+		if m.Entity == constants.SyncActivity {
+			return s.createActivity(ctx, tx, p, res, m)
+		}
+		return s.createTodo(ctx, tx, p, res, m)
+	case constants.SyncUpdate:
+		if m.Entity == constants.SyncActivity {
+			return s.updateActivity(ctx, tx, p, res, m)
+		}
+		return s.updateTodo(ctx, tx, p, res, m)
+	case constants.SyncDelete:
+		return s.delete(ctx, tx, p, res, m)
+	default:
+		return reject(constants.ErrSyncOperationInvalid)
+	}
+}
+
+func (s syncService) createActivity(ctx context.Context, tx *sqlx.Tx, p *push, res *sync.MutationResult, m sync.Mutation) error {
+	var data models.Activity
+	err := decodeFields(m.Fields, map[string]interface{}{
+		constants.FieldTitle: &data.Title,
+		constants.FieldEmail: &data.Email,
+	})
+	if err != nil {
+		return err
+	}
+
+	if data.Title == "" {
+		return reject(constants.ErrTitleCannotBeNull)
+	}
+
+	created, err := s.ActivityRepository.CreateActivity(ctx, tx, data)
+	if err != nil {
+		return err
+	}
+
+	data, err = s.ActivityRepository.GetOneActivity(ctx, tx, created.ActivityID)
+	if err != nil {
+		return err
+	}
+
+	if m.ClientID != "" {
+		p.groups[m.ClientID] = data.ActivityID
+	}
+
+	result := activityservice.NewActivity(data)
+	res.ID = result.ID
+	res.Status = constants.SyncApplied
+	res.Activity = &result
+
+	p.Change(constants.SyncActivity, result.ID, constants.ChangeUpsert, m.Timestamp, constants.FieldTitle, constants.FieldEmail)
+
+	return p.Enqueue(ctx, constants.EventActivityCreated, result.ID, result)
+}
+
+func (s syncService) createTodo(ctx context.Context, tx *sqlx.Tx, p *push, res *sync.MutationResult, m sync.Mutation) error {
+	var data models.Todo
+	var group json.RawMessage
+	var due, rule *string
+	err := decodeFields(m.Fields, map[string]interface{}{
+		constants.FieldTitle:           &data.Title,
+		constants.FieldIsActive:        &data.IsActive,
+		constants.FieldPriority:        &data.Priority,
+		constants.FieldActivityGroupID: &group,
+		constants.FieldDueAt:           &due,
+		constants.FieldRRule:           &rule,
+	})
+	if err != nil {
+		return err
+	}
+
+	if data.Title == "" {
+		return reject(constants.ErrTitleCannotBeNull)
+	}
+
+	if group == nil {
+		return reject(constants.ErrActivityGroupRequired)
+	}
+
+	data.DueAt, data.RRule, err = todoservice.Schedule(utils.NullString(due), utils.NullString(rule))
+	if err != nil {
+		return reject(err)
+	}
+
+	data.ActivityGroupID, err = s.activityGroup(ctx, tx, p, group)
+	if err != nil {
+		return err
+	}
+
+	if data.Priority == "" {
+		data.Priority = constants.Priority
+	}
+
+	result, err := p.Create(ctx, data, m.Timestamp)
+	if err != nil {
+		return err
+	}
+
+	res.ID = result.ID
+	res.Status = constants.SyncApplied
+	res.Todo = &result
+
+	return nil
+}
+
+func (s syncService) updateActivity(ctx context.Context, tx *sqlx.Tx, p *push, res *sync.MutationResult, m sync.Mutation) error {
+	before, err := s.ActivityRepository.GetOneActivityForUpdate(ctx, tx, m.ID)
+	if err != nil {
+		return notFound(err)
+	}
+
+	data := before
+	err = decodeFields(m.Fields, map[string]interface{}{
+		constants.FieldTitle: &data.Title,
+	})
+	if err != nil {
+		return err
+	}
+
+	if data.Title == "" {
+		return reject(constants.ErrTitleCannotBeNull)
+	}
+
+	fields, err := s.resolve(ctx, tx, p, res, m, map[string][2]interface{}{
+		constants.FieldTitle: {data.Title, before.Title},
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(fields) > 0 {
+		err = s.ActivityRepository.UpdateActivity(ctx, tx, m.ID, models.Activity{
+			Title: data.Title,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	data, err = s.ActivityRepository.GetOneActivity(ctx, tx, m.ID)
+	if err != nil {
+		return err
+	}
+
+	result := activityservice.NewActivity(data)
+	res.Activity = &result
+
+	if len(fields) == 0 {
+		return nil
+	}
+
+	p.Change(constants.SyncActivity, result.ID, constants.ChangeUpsert, m.Timestamp, fields...)
+
+	return p.Enqueue(ctx, constants.EventActivityUpdated, result.ID, result)
+}
+
+func (s syncService) updateTodo(ctx context.Context, tx *sqlx.Tx, p *push, res *sync.MutationResult, m sync.Mutation) error {
+	locked, err := s.TodoRepository.GetTodoByIDs(ctx, tx, []int{m.ID})
+	if err != nil {
+		return err
+	}
+
+	if len(locked) == 0 {
+		return reject(utils.ErrDataNotFound(m.ID))
+	}
+
+	before := locked[0]
+	data := before
+	var group json.RawMessage
+	// the due date and the recurrence are kept unless given, null clearing them
+	due, rule := utils.FormatNullTime(before.DueAt, time.RFC3339), utils.NullString(before.RRule)
+	dueAt, rrule := &due, &rule
+	err = decodeFields(m.Fields, map[string]interface{}{
+		constants.FieldTitle:           &data.Title,
+		constants.FieldIsActive:        &data.IsActive,
+		constants.FieldPriority:        &data.Priority,
+		constants.FieldActivityGroupID: &group,
+		constants.FieldDueAt:           &dueAt,
+		constants.FieldRRule:           &rrule,
+	})
+	if err != nil {
+		return err
+	}
+
+	data.DueAt, data.RRule, err = todoservice.Schedule(utils.NullString(dueAt), utils.NullString(rrule))
+	if err != nil {
+		return reject(err)
+	}
+
+	if data.Title == "" {
+		return reject(constants.ErrTitleCannotBeNull)
+	}
+
+	if data.Priority == "" {
+		data.Priority = constants.Priority
+	}
+
+	if group != nil {
+		data.ActivityGroupID, err = s.activityGroup(ctx, tx, p, group)
+		if err != nil {
+			return err
+		}
+	}
+
+	candidates := map[string][2]interface{}{}
+	if _, ok := m.Fields[constants.FieldTitle]; ok {
+		candidates[constants.FieldTitle] = [2]interface{}{data.Title, before.Title}
+	}
+	if _, ok := m.Fields[constants.FieldIsActive]; ok {
+		candidates[constants.FieldIsActive] = [2]interface{}{data.IsActive, before.IsActive}
+	}
+	if _, ok := m.Fields[constants.FieldPriority]; ok {
+		candidates[constants.FieldPriority] = [2]interface{}{data.Priority, before.Priority}
+	}
+	if group != nil {
+		candidates[constants.FieldActivityGroupID] = [2]interface{}{data.ActivityGroupID, before.ActivityGroupID}
+	}
+	if _, ok := m.Fields[constants.FieldDueAt]; ok {
+		candidates[constants.FieldDueAt] = [2]interface{}{dueAtValue(data.DueAt), dueAtValue(before.DueAt)}
+	}
+	if _, ok := m.Fields[constants.FieldRRule]; ok {
+		candidates[constants.FieldRRule] = [2]interface{}{rruleValue(data.RRule), rruleValue(before.RRule)}
+	}
+
+	fields, err := s.resolve(ctx, tx, p, res, m, candidates)
+	if err != nil {
+		return err
+	}
+
+	// the server keeps its value for the fields the client lost
+	patch := before
+	for _, f := range fields {
+		switch f {
+		case constants.FieldTitle:
+			patch.Title = data.Title
+		case constants.FieldIsActive:
+			patch.IsActive = data.IsActive
+		case constants.FieldPriority:
+			patch.Priority = data.Priority
+		case constants.FieldActivityGroupID:
+			patch.ActivityGroupID = data.ActivityGroupID
+		case constants.FieldDueAt:
+			patch.DueAt = data.DueAt
+		case constants.FieldRRule:
+			patch.RRule = data.RRule
+		}
+	}
+
+	// the recurrence won without the due date the server cleared
+	if patch.RRule != nil && patch.DueAt == nil {
+		return reject(errors.Wrap(constants.ErrRRuleWithoutDueAt))
+	}
+
+	result, err := p.Update(ctx, before, patch, fields, m.Timestamp)
+	if err != nil {
+		return err
+	}
+	res.Todo = &result
+
+	return nil
+}
+
+// delete the entity unless the server wins a field it changed since the
+// last pull of the client. An entity already gone is deleted.
+func (s syncService) delete(ctx context.Context, tx *sqlx.Tx, p *push, res *sync.MutationResult, m sync.Mutation) error {
+	var values map[string]interface{}
+	var activityData models.Activity
+	var todoData models.Todo
+
+	if m.Entity == constants.SyncActivity {
+		data, err := s.ActivityRepository.GetOneActivityForUpdate(ctx, tx, m.ID)
+		if errors.Is(err, constants.ErrNotFound) {
+			res.Status = constants.SyncApplied
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		activityData = data
+		values = map[string]interface{}{
+			constants.FieldTitle: data.Title,
+			constants.FieldEmail: data.Email,
+		}
+	} else {
+		locked, err := s.TodoRepository.GetTodoByIDs(ctx, tx, []int{m.ID})
+		if err != nil {
+			return err
+		}
+		if len(locked) == 0 {
+			res.Status = constants.SyncApplied
+			return nil
+		}
+		todoData = locked[0]
+		values = map[string]interface{}{
+			constants.FieldTitle:           todoData.Title,
+			constants.FieldIsActive:        todoData.IsActive,
+			constants.FieldPriority:        todoData.Priority,
+			constants.FieldActivityGroupID: todoData.ActivityGroupID,
+			constants.FieldDueAt:           dueAtValue(todoData.DueAt),
+			constants.FieldRRule:           rruleValue(todoData.RRule),
+		}
+	}
+
+	clocks, err := s.clocks(ctx, tx, p, m)
+	if err != nil {
+		return err
+	}
+
+	// the entity is kept as a whole, a field won by the server keeps them all
+	resolution := constants.SyncResolutionClient
+	for _, c := range clocks {
+		if !p.wins(m.Timestamp, c) {
+			resolution = constants.SyncResolutionServer
+		}
+	}
+	for _, c := range clocks {
+		res.Conflicts = append(res.Conflicts, sync.Conflict{
+			Field:           c.Field,
+			ServerValue:     values[c.Field],
+			ServerChangedAt: c.ChangedAt.UTC().Format(constants.DateTimeFormat),
+			Resolution:      resolution,
+		})
+	}
+
+	if resolution == constants.SyncResolutionServer {
+		res.Status = constants.SyncConflict
+		if m.Entity == constants.SyncActivity {
+			result := activityservice.NewActivity(activityData)
+			res.Activity = &result
+		} else {
+			result := todoservice.NewTodo(todoData)
+			res.Todo = &result
+		}
+		return nil
+	}
+
+	res.Status = constants.SyncApplied
+
+	if m.Entity == constants.SyncActivity {
+		err = s.ActivityRepository.DeleteActivity(ctx, tx, m.ID)
+		if err != nil {
+			return err
+		}
+		p.Change(constants.SyncActivity, m.ID, constants.ChangeDelete, m.Timestamp)
+		return p.Enqueue(ctx, constants.EventActivityDeleted, m.ID, activityservice.NewActivity(activityData))
+	}
+
+	_, err = p.Delete(ctx, todoData, m.Timestamp)
+
+	return err
+}
+
+// resolve settle the fields the client sets, by name its value and the one
+// of the server, and return the fields to write. The unchanged fields are
+// left alone, the conflicts are reported in res.
+func (s syncService) resolve(ctx context.Context, tx *sqlx.Tx, p *push, res *sync.MutationResult, m sync.Mutation, candidates map[string][2]interface{}) ([]string, error) {
+	clocks, err := s.clocks(ctx, tx, p, m)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(candidates))
+	for f := range candidates {
+		names = append(names, f)
+	}
+	sort.Strings(names)
+
+	res.Status = constants.SyncApplied
+	fields := []string{}
+	for _, f := range names {
+		client, server := candidates[f][0], candidates[f][1]
+		if client == server {
+			continue
+		}
+
+		c, ok := clocks[f]
+		if !ok {
+			fields = append(fields, f)
+			continue
+		}
+
+		resolution := constants.SyncResolutionServer
+		if p.wins(m.Timestamp, c) {
+			resolution = constants.SyncResolutionClient
+			fields = append(fields, f)
+		} else {
+			res.Status = constants.SyncConflict
+		}
+
+		res.Conflicts = append(res.Conflicts, sync.Conflict{
+			Field:           f,
+			ClientValue:     client,
+			ServerValue:     server,
+			ServerChangedAt: c.ChangedAt.UTC().Format(constants.DateTimeFormat),
+			Resolution:      resolution,
+		})
+	}
+
+	for _, f := range fields {
+		p.written[written(m.Entity, m.ID, f)] = true
+	}
+
+	return fields, nil
+}
+
+// clocks load the clocks of the fields of the entity the server changed past
+// the token of the client, by field. The fields written earlier by the same
+// push are no conflict.
+func (s syncService) clocks(ctx context.Context, tx *sqlx.Tx, p *push, m sync.Mutation) (map[string]models.ChangeField, error) {
+	data, err := s.ChangeRepository.GetChangeField(ctx, tx, m.Entity, m.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := map[string]models.ChangeField{}
+	for _, x := range data {
+		if x.Seq > p.base && !p.written[written(m.Entity, m.ID, x.Field)] {
+			res[x.Field] = x
+		}
+	}
+
+	return res, nil
+}
+
+// wins tell whether the client wins the conflict on a field, the server
+// winning a tie
+func (p *push) wins(at time.Time, c models.ChangeField) bool {
+	return p.policy == constants.SyncLastWriterWins && at.After(c.ChangedAt)
+}
+
+// activityGroup resolve the activity_group_id of a todo, the ID of a group or
+// the client ID of a group created earlier by the push, and check the group
+func (s syncService) activityGroup(ctx context.Context, tx *sqlx.Tx, p *push, raw json.RawMessage) (int, error) {
+	var clientID string
+	if json.Unmarshal(raw, &clientID) == nil {
+		id, ok := p.groups[clientID]
+		if !ok {
+			return 0, reject(fmt.Errorf("%w: %q", constants.ErrSyncClientIDUnknown, clientID))
+		}
+		return id, nil
+	}
+
+	var id int
+	if err := json.Unmarshal(raw, &id); err != nil {
+		return 0, reject(fmt.Errorf("%w: %s", constants.ErrSyncFieldInvalid, constants.FieldActivityGroupID))
+	}
+
+	_, err := s.ActivityRepository.GetOneActivity(ctx, tx, id)
+	if err != nil {
+		return 0, notFound(err)
+	}
+
+	return id, nil
+}
+
+// decodeFields decode each field into its destination, rejecting the fields
+// unknown or of the wrong type
+func decodeFields(fields map[string]json.RawMessage, dst map[string]interface{}) error {
+	for name, raw := range fields {
+		v, ok := dst[name]
+		if !ok {
+			return reject(fmt.Errorf("%w: %s", constants.ErrSyncFieldInvalid, name))
+		}
+
+		if r, ok := v.(*json.RawMessage); ok {
+			*r = raw
+			continue
+		}
+
+		if err := json.Unmarshal(raw, v); err != nil {
+			return reject(fmt.Errorf("%w: %s", constants.ErrSyncFieldInvalid, name))
+		}
+	}
+
+	return nil
+}
+
+// notFound reject the mutation of a missing entity
+func notFound(err error) error {
+	if errors.Is(err, constants.ErrNotFound) {
+		return reject(err)
+	}
+
+	return err
+}
+
+// dueAtValue the due date of a todo as synced, in RFC 3339 or null
+func dueAtValue(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+
+	return t.UTC().Format(time.RFC3339)
+}
+
+// rruleValue the recurrence of a todo as synced, null for none
+func rruleValue(s *string) interface{} {
+	if s == nil {
+		return nil
+	}
+
+	return *s
+}
+
+// written the key of a field of an entity written by the push
+func written(entity string, id int, field string) string {
+	return fmt.Sprintf("%s/%d/%s", entity, id, field)
+}
@@ -0,0 +1,59 @@
+package sync
+
+import (
+	"context"
+	"todolist-api/constants"
+	"todolist-api/infra/idempotency"
+	"todolist-api/infra/ratelimit"
+	"todolist-api/objects/sync"
+)
+
+// syncServiceQuota count the todos a push creates against the daily quota of
+// the user, like the creations of the todo service
+type syncServiceQuota struct {
+	SyncServiceInterface
+	quota *ratelimit.Quota
+}
+
+// NewSyncServiceQuota decorate the service with the daily creation quota
+func NewSyncServiceQuota(next SyncServiceInterface, quota *ratelimit.Quota) SyncServiceInterface {
+	return &syncServiceQuota{
+		SyncServiceInterface: next,
+		quota:                quota,
+	}
+}
+
+// Push count the todo creations of the push, giving back the ones not
+// applied
+func (s syncServiceQuota) Push(ctx context.Context, req sync.Push) (sync.PushResult, error) {
+	n := 0
+	for _, m := range req.Mutations {
+		if m.Entity == constants.SyncTodo && m.Operation == constants.SyncCreate {
+			n++
+		}
+	}
+
+	release, err := s.quota.Take(ctx, n)
+	if err != nil {
+		return sync.PushResult{}, err
+	}
+
+	data, err := s.SyncServiceInterface.Push(ctx, req)
+	if err != nil || idempotency.Replayed(ctx) {
+		release(n)
+		return data, err
+	}
+
+	failed := 0
+	for i, x := range data.Results {
+		m := req.Mutations[i]
+		if m.Entity == constants.SyncTodo && m.Operation == constants.SyncCreate && x.Status != constants.SyncApplied {
+			failed++
+		}
+	}
+	if failed > 0 {
+		release(failed)
+	}
+
+	return data, err
+}
@@ -0,0 +1,296 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+	"todolist-api/config"
+	"todolist-api/constants"
+	"todolist-api/data/models"
+	"todolist-api/data/repositories/change"
+	"todolist-api/infra/context/repository"
+	"todolist-api/infra/db"
+	"todolist-api/infra/db/dbtest"
+	"todolist-api/objects/sync"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// changeRepository a feed of changes and entities held in memory
+type changeRepository struct {
+	change.ChangeRepositoryInterface
+
+	last       int64
+	changes    []models.Change
+	fields     []models.ChangeField
+	activities []models.Activity
+	todos      []models.Todo
+}
+
+func (r *changeRepository) GetLastSeq(context.Context, *sqlx.Tx) (int64, error) {
+	return r.last, nil
+}
+
+func (r *changeRepository) GetChangeSince(_ context.Context, _ *sqlx.Tx, seq int64, limit int) ([]models.Change, error) {
+	res := []models.Change{}
+	for _, x := range r.changes {
+		if x.Seq > seq && len(res) < limit {
+			res = append(res, x)
+		}
+	}
+
+	return res, nil
+}
+
+func (r *changeRepository) GetChangeField(_ context.Context, _ *sqlx.Tx, entity string, id int) ([]models.ChangeField, error) {
+	res := []models.ChangeField{}
+	for _, x := range r.fields {
+		if x.Entity == entity && x.EntityID == id {
+			res = append(res, x)
+		}
+	}
+
+	return res, nil
+}
+
+func (r *changeRepository) GetActivityPage(_ context.Context, _ *sqlx.Tx, afterID int, limit int) ([]models.Activity, error) {
+	res := []models.Activity{}
+	for _, x := range r.activities {
+		if x.ActivityID > afterID && len(res) < limit {
+			res = append(res, x)
+		}
+	}
+
+	return res, nil
+}
+
+func (r *changeRepository) GetActivityByIDs(_ context.Context, _ *sqlx.Tx, ids []int) ([]models.Activity, error) {
+	res := []models.Activity{}
+	for _, x := range r.activities {
+		for _, id := range ids {
+			if x.ActivityID == id {
+				res = append(res, x)
+			}
+		}
+	}
+
+	return res, nil
+}
+
+func (r *changeRepository) GetTodoPage(_ context.Context, _ *sqlx.Tx, afterID int, limit int) ([]models.Todo, error) {
+	res := []models.Todo{}
+	for _, x := range r.todos {
+		if x.TodoID > afterID && len(res) < limit {
+			res = append(res, x)
+		}
+	}
+
+	return res, nil
+}
+
+func (r *changeRepository) GetTodoByIDs(_ context.Context, _ *sqlx.Tx, ids []int) ([]models.Todo, error) {
+	res := []models.Todo{}
+	for _, x := range r.todos {
+		for _, id := range ids {
+			if x.TodoID == id {
+				res = append(res, x)
+			}
+		}
+	}
+
+	return res, nil
+}
+
+func TestResolve(t *testing.T) {
+	changedAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	repo := &changeRepository{fields: []models.ChangeField{
+		// changed by the server past the token of the client
+		{Entity: constants.SyncTodo, EntityID: 1, Field: constants.FieldTitle, Seq: 7, ChangedAt: changedAt},
+		// changed before the token, the client saw it
+		{Entity: constants.SyncTodo, EntityID: 1, Field: constants.FieldIsActive, Seq: 3, ChangedAt: changedAt},
+	}}
+	s := syncService{RepoCtx: &repository.RepoCtx{ChangeRepository: repo}}
+
+	tests := []struct {
+		name       string
+		policy     string
+		at         time.Time
+		fields     []string
+		status     string
+		resolution string
+	}{
+		{
+			name:       "last writer wins, client later",
+			policy:     constants.SyncLastWriterWins,
+			at:         changedAt.Add(time.Minute),
+			fields:     []string{constants.FieldIsActive, constants.FieldTitle},
+			status:     constants.SyncApplied,
+			resolution: constants.SyncResolutionClient,
+		},
+		{
+			name:       "last writer wins, server later",
+			policy:     constants.SyncLastWriterWins,
+			at:         changedAt.Add(-time.Minute),
+			fields:     []string{constants.FieldIsActive},
+			status:     constants.SyncConflict,
+			resolution: constants.SyncResolutionServer,
+		},
+		{
+			name:       "last writer wins, tie",
+			policy:     constants.SyncLastWriterWins,
+			at:         changedAt,
+			fields:     []string{constants.FieldIsActive},
+			status:     constants.SyncConflict,
+			resolution: constants.SyncResolutionServer,
+		},
+		{
+			name:       "server wins, client later",
+			policy:     constants.SyncServerWins,
+			at:         changedAt.Add(time.Minute),
+			fields:     []string{constants.FieldIsActive},
+			status:     constants.SyncConflict,
+			resolution: constants.SyncResolutionServer,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &push{policy: tt.policy, base: 5, written: map[string]bool{}}
+			m := sync.Mutation{Entity: constants.SyncTodo, Operation: constants.SyncUpdate, ID: 1, Timestamp: tt.at}
+			res := &sync.MutationResult{}
+
+			fields, err := s.resolve(context.Background(), nil, p, res, m, map[string][2]interface{}{
+				constants.FieldTitle:    {"Buy oat milk", "Buy milk"},
+				constants.FieldIsActive: {false, true},
+				// the same value is no change, conflicting or not
+				constants.FieldPriority: {"high", "high"},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(fields, tt.fields) {
+				t.Fatalf("fields %v, expected %v", fields, tt.fields)
+			}
+			if res.Status != tt.status {
+				t.Fatalf("status %s, expected %s", res.Status, tt.status)
+			}
+
+			// only the title changed on both sides
+			expected := []sync.Conflict{{
+				Field:           constants.FieldTitle,
+				ClientValue:     "Buy oat milk",
+				ServerValue:     "Buy milk",
+				ServerChangedAt: changedAt.Format(constants.DateTimeFormat),
+				Resolution:      tt.resolution,
+			}}
+			if !reflect.DeepEqual(res.Conflicts, expected) {
+				t.Fatalf("conflicts %+v, expected %+v", res.Conflicts, expected)
+			}
+
+			// a field written is no conflict for the rest of the push
+			for _, f := range tt.fields {
+				if !p.written[written(constants.SyncTodo, 1, f)] {
+					t.Fatalf("%s not recorded as written", f)
+				}
+			}
+		})
+	}
+}
+
+func TestPullPages(t *testing.T) {
+	conn, err := db.Open(&config.DBConfig{Name: dbtest.Register(&dbtest.Driver{}), Host: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	repo := &changeRepository{
+		last:       9,
+		activities: []models.Activity{{ActivityID: 1}, {ActivityID: 2}, {ActivityID: 3}},
+		todos:      []models.Todo{{TodoID: 10}, {TodoID: 11}},
+	}
+	s := NewSyncService(&repository.RepoCtx{DB: conn, ChangeRepository: repo})
+	ctx := context.Background()
+
+	// ids the activity groups, the todos and the tombstones of a page
+	ids := func(res sync.Changes) [3][]int {
+		var x [3][]int
+		for _, a := range res.Activities {
+			x[0] = append(x[0], a.ID)
+		}
+		for _, a := range res.Todos {
+			x[1] = append(x[1], a.ID)
+		}
+		for _, a := range res.Deleted {
+			x[2] = append(x[2], a.ID)
+		}
+		return x
+	}
+
+	pages := []struct {
+		token   token
+		ids     [3][]int
+		hasMore bool
+	}{
+		// the snapshot at sequence 9, the groups then the todos
+		{token{Seq: 9, Entity: constants.SyncActivity, After: 2}, [3][]int{{1, 2}, nil, nil}, true},
+		{token{Seq: 9, Entity: constants.SyncTodo, After: 10}, [3][]int{{3}, {10}, nil}, true},
+		// the changes committed while paging come next from the feed
+		{token{Seq: 9}, [3][]int{nil, {11}, nil}, true},
+		{token{Seq: 11}, [3][]int{nil, {11}, {3}}, false},
+	}
+
+	since := ""
+	for i, page := range pages {
+		if i == 2 {
+			repo.last = 11
+			repo.changes = []models.Change{
+				{Seq: 10, Entity: constants.SyncTodo, EntityID: 11, Operation: constants.ChangeUpsert},
+				{Seq: 11, Entity: constants.SyncActivity, EntityID: 3, Operation: constants.ChangeDelete},
+			}
+		}
+
+		res, err := s.Pull(ctx, sync.Pull{Since: since, Limit: 2})
+		if err != nil {
+			t.Fatalf("page %d: %v", i, err)
+		}
+		if res.Token != page.token.String() || res.HasMore != page.hasMore {
+			t.Fatalf("page %d: token %s and has more %t, expected %+v and %t", i, res.Token, res.HasMore, page.token, page.hasMore)
+		}
+		if got := ids(res); !reflect.DeepEqual(got, page.ids) {
+			t.Fatalf("page %d: %v, expected %v", i, got, page.ids)
+		}
+
+		since = res.Token
+	}
+
+	// a token past the sequence of the server wasn't given by it
+	_, err = s.Pull(ctx, sync.Pull{Since: token{Seq: 12}.String(), Limit: 2})
+	if !errors.Is(err, constants.ErrSyncTokenInvalid) {
+		t.Fatalf("error %v, expected %v", err, constants.ErrSyncTokenInvalid)
+	}
+}
+
+func TestParseToken(t *testing.T) {
+	tests := []struct {
+		raw   string
+		token token
+		err   bool
+	}{
+		{token{Seq: 4}.String(), token{Seq: 4}, false},
+		{token{Seq: 4, Entity: constants.SyncTodo, After: 7}.String(), token{Seq: 4, Entity: constants.SyncTodo, After: 7}, false},
+		{token{Seq: 5}.String(), token{}, true},
+		{token{Seq: 4, Entity: "webhook", After: 7}.String(), token{}, true},
+		{"not base64!", token{}, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseToken(tt.raw, 4)
+		if (err != nil) != tt.err || got != tt.token {
+			t.Errorf("parseToken(%q) = %+v, %v", tt.raw, got, err)
+		}
+	}
+}
@@ -2,11 +2,16 @@ package todo
 
 import (
 	"context"
+	"fmt"
+	"time"
 	"todolist-api/constants"
 	"todolist-api/data/models"
 	"todolist-api/infra/context/repository"
 	"todolist-api/infra/errors"
+	"todolist-api/infra/ical"
+	"todolist-api/infra/idempotency"
 	"todolist-api/objects/todo"
+	"todolist-api/utils"
 )
 
 type todoService struct {
@@ -28,58 +33,81 @@ func (t todoService) CreateTodo(ctx context.Context, req todo.CreateTodo) (todo.
 		req.Priority = constants.Priority
 	}
 
-	todoID, err := t.TodoRepository.CreateTodo(ctx, tx, models.Todo{
+	dueAt, rrule, err := Schedule(req.DueAt, req.RRule)
+	if err != nil {
+		_ = tx.Rollback()
+		return todo.Todo{}, err
+	}
+
+	// a retry gets the todo created by the first request
+	var replayed todo.Todo
+	ok, err := idempotency.Replay(ctx, t.IdempotencyRepository, tx, &replayed)
+	if err != nil || ok {
+		_ = tx.Rollback()
+		return replayed, err
+	}
+
+	w := NewWrites(t.RepoCtx, tx)
+	result, err := w.Create(ctx, models.Todo{
 		Title:           req.Title,
 		ActivityGroupID: req.ActivityGroupID,
 		IsActive:        req.IsActive,
 		Priority:        req.Priority,
-	})
+		DueAt:           dueAt,
+		RRule:           rrule,
+	}, time.Time{})
 	if err != nil {
 		_ = tx.Rollback()
 		return todo.Todo{}, err
 	}
 
-	data, err := t.TodoRepository.GetOneTodo(ctx, tx, todoID.TodoID)
+	err = idempotency.Save(ctx, t.IdempotencyRepository, tx, result)
 	if err != nil {
 		_ = tx.Rollback()
 		return todo.Todo{}, err
 	}
 
-	err = tx.Commit()
+	err = w.Commit(ctx)
 	if err != nil {
 		_ = tx.Rollback()
 		return todo.Todo{}, err
 	}
 
-	return todo.Todo{
-		ID:              data.TodoID,
-		Title:           data.Title,
-		ActivityGroupID: data.ActivityGroupID,
-		IsActive:        data.IsActive,
-		Priority:        data.Priority,
-		CreatedAt:       data.CreatedAt.UTC().Format(constants.DateTimeFormat),
-		UpdatedAt:       data.UpdatedAt.UTC().Format(constants.DateTimeFormat),
-	}, nil
+	w.Publish(ctx)
+
+	return result, nil
 }
 
-func (t todoService) GetAllTodo(ctx context.Context) ([]todo.Todo, error) {
+func (t todoService) GetAllTodo(ctx context.Context, filter todo.FilterTodo) ([]todo.Todo, error) {
 	tmpTodoData := []todo.Todo{}
 
-	data, err := t.TodoRepository.GetAllTodo(ctx)
+	data, err := t.TodoRepository.GetAllTodo(ctx, models.TodoFilter{
+		ActivityGroupID: filter.ActivityGroupID,
+		IsActive:        filter.IsActive,
+		Priority:        filter.Priority,
+	})
 	if err != nil {
 		return tmpTodoData, err
 	}
 
 	for _, x := range data {
-		tmpTodoData = append(tmpTodoData, todo.Todo{
-			ID:              x.TodoID,
-			Title:           x.Title,
-			ActivityGroupID: x.ActivityGroupID,
-			IsActive:        x.IsActive,
-			Priority:        x.Priority,
-			UpdatedAt:       x.UpdatedAt.UTC().Format(constants.DateTimeFormat),
-			CreatedAt:       x.CreatedAt.UTC().Format(constants.DateTimeFormat),
-		})
+		tmpTodoData = append(tmpTodoData, NewTodo(x))
+	}
+
+	return tmpTodoData, nil
+}
+
+// GetTodoByActivityGroupIDs load the todos of several activity groups in one query, keyed by group
+func (t todoService) GetTodoByActivityGroupIDs(ctx context.Context, ids []int) (map[int][]todo.Todo, error) {
+	tmpTodoData := make(map[int][]todo.Todo, len(ids))
+
+	data, err := t.TodoRepository.GetTodoByActivityGroupIDs(ctx, ids)
+	if err != nil {
+		return tmpTodoData, err
+	}
+
+	for _, x := range data {
+		tmpTodoData[x.ActivityGroupID] = append(tmpTodoData[x.ActivityGroupID], NewTodo(x))
 	}
 
 	return tmpTodoData, nil
@@ -103,15 +131,7 @@ func (t todoService) GetOneTodo(ctx context.Context, id int) (todo.Todo, error)
 		return todo.Todo{}, err
 	}
 
-	return todo.Todo{
-		ID:              data.TodoID,
-		Title:           data.Title,
-		ActivityGroupID: data.ActivityGroupID,
-		IsActive:        data.IsActive,
-		Priority:        data.Priority,
-		UpdatedAt:       data.UpdatedAt.UTC().Format(constants.DateTimeFormat),
-		CreatedAt:       data.CreatedAt.UTC().Format(constants.DateTimeFormat),
-	}, nil
+	return NewTodo(data), nil
 }
 
 func (t todoService) UpdateTodo(ctx context.Context, id int, req todo.UpdateTodo) (todo.Todo, error) {
@@ -129,37 +149,97 @@ func (t todoService) UpdateTodo(ctx context.Context, id int, req todo.UpdateTodo
 		req.Priority = constants.Priority
 	}
 
-	err = t.TodoRepository.UpdateTodo(ctx, tx, id, models.Todo{
-		Title:    req.Title,
-		IsActive: req.IsActive,
-		Priority: req.Priority,
-	})
+	before, err := t.TodoRepository.GetOneTodo(ctx, tx, id)
 	if err != nil {
 		_ = tx.Rollback()
 		return todo.Todo{}, err
 	}
 
-	data, err := t.TodoRepository.GetOneTodo(ctx, tx, id)
+	// the due date and the recurrence are kept unless given
+	due, rule := utils.FormatNullTime(before.DueAt, time.RFC3339), utils.NullString(before.RRule)
+	if req.DueAt != nil {
+		due = *req.DueAt
+	}
+	if req.RRule != nil {
+		rule = *req.RRule
+	}
+
+	dueAt, rrule, err := Schedule(due, rule)
 	if err != nil {
 		_ = tx.Rollback()
 		return todo.Todo{}, err
 	}
 
-	err = tx.Commit()
+	data := before
+	data.Title = req.Title
+	data.IsActive = req.IsActive
+	data.Priority = req.Priority
+	data.DueAt = dueAt
+	data.RRule = rrule
+
+	fields := []string{constants.FieldTitle, constants.FieldIsActive, constants.FieldPriority}
+	if req.DueAt != nil {
+		fields = append(fields, constants.FieldDueAt)
+	}
+	if req.RRule != nil {
+		fields = append(fields, constants.FieldRRule)
+	}
+
+	w := NewWrites(t.RepoCtx, tx)
+	result, err := w.Update(ctx, before, data, fields, time.Time{})
 	if err != nil {
 		_ = tx.Rollback()
 		return todo.Todo{}, err
 	}
 
-	return todo.Todo{
-		ID:              data.TodoID,
-		Title:           data.Title,
-		ActivityGroupID: data.ActivityGroupID,
-		IsActive:        data.IsActive,
-		Priority:        data.Priority,
-		UpdatedAt:       data.UpdatedAt.UTC().Format(constants.DateTimeFormat),
-		CreatedAt:       data.CreatedAt.UTC().Format(constants.DateTimeFormat),
-	}, nil
+	err = w.Commit(ctx)
+	if err != nil {
+		_ = tx.Rollback()
+		return todo.Todo{}, err
+	}
+
+	w.Publish(ctx)
+
+	return result, nil
+}
+
+func (t todoService) MoveTodo(ctx context.Context, id int, req todo.MoveTodo) (todo.Todo, error) {
+	tx, err := t.DB.Begin(ctx)
+	if err != nil {
+		return todo.Todo{}, errors.Wrap(constants.ErrBeginTransaction)
+	}
+
+	before, err := t.TodoRepository.GetOneTodo(ctx, tx, id)
+	if err != nil {
+		_ = tx.Rollback()
+		return todo.Todo{}, err
+	}
+
+	_, err = t.ActivityRepository.GetOneActivity(ctx, tx, req.ActivityGroupID)
+	if err != nil {
+		_ = tx.Rollback()
+		return todo.Todo{}, err
+	}
+
+	data := before
+	data.ActivityGroupID = req.ActivityGroupID
+
+	w := NewWrites(t.RepoCtx, tx)
+	result, err := w.Update(ctx, before, data, []string{constants.FieldActivityGroupID}, time.Time{})
+	if err != nil {
+		_ = tx.Rollback()
+		return todo.Todo{}, err
+	}
+
+	err = w.Commit(ctx)
+	if err != nil {
+		_ = tx.Rollback()
+		return todo.Todo{}, err
+	}
+
+	w.Publish(ctx)
+
+	return result, nil
 }
 
 func (t todoService) DeleteTodo(ctx context.Context, id int) error {
@@ -174,17 +254,67 @@ func (t todoService) DeleteTodo(ctx context.Context, id int) error {
 		return err
 	}
 
-	err = t.TodoRepository.DeleteTodo(ctx, tx, data.TodoID)
+	w := NewWrites(t.RepoCtx, tx)
+	_, err = w.Delete(ctx, data, time.Time{})
 	if err != nil {
 		_ = tx.Rollback()
 		return err
 	}
 
-	err = tx.Commit()
+	err = w.Commit(ctx)
 	if err != nil {
 		_ = tx.Rollback()
 		return err
 	}
 
+	w.Publish(ctx)
+
 	return nil
 }
+
+// Schedule parse the due date and the recurrence rule of a todo, empty being
+// none. A recurrence repeats the todo from its due date.
+func Schedule(dueAt, rrule string) (*time.Time, *string, error) {
+	var due *time.Time
+	if dueAt != "" {
+		t, err := time.Parse(time.RFC3339, dueAt)
+		if err != nil {
+			return nil, nil, errors.Wrap(constants.ErrDueAtInvalid)
+		}
+		t = t.UTC()
+		due = &t
+	}
+
+	var rule *string
+	if rrule != "" {
+		r, err := ical.ParseRRule(rrule)
+		if err != nil {
+			return nil, nil, errors.Wrap(fmt.Errorf("%w: %v", constants.ErrRRuleInvalid, err))
+		}
+		if len(r) > constants.MaxRRuleLength {
+			return nil, nil, errors.Wrap(fmt.Errorf("%w: longer than %d characters", constants.ErrRRuleInvalid, constants.MaxRRuleLength))
+		}
+		rule = &r
+	}
+
+	if rule != nil && due == nil {
+		return nil, nil, errors.Wrap(constants.ErrRRuleWithoutDueAt)
+	}
+
+	return due, rule, nil
+}
+
+// NewTodo the todo of the API stored as data
+func NewTodo(data models.Todo) todo.Todo {
+	return todo.Todo{
+		ID:              data.TodoID,
+		Title:           data.Title,
+		ActivityGroupID: data.ActivityGroupID,
+		IsActive:        data.IsActive,
+		Priority:        data.Priority,
+		DueAt:           utils.FormatNullTime(data.DueAt, constants.DateTimeFormat),
+		RRule:           utils.NullString(data.RRule),
+		UpdatedAt:       data.UpdatedAt.UTC().Format(constants.DateTimeFormat),
+		CreatedAt:       data.CreatedAt.UTC().Format(constants.DateTimeFormat),
+	}
+}
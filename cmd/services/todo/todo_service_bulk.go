@@ -0,0 +1,416 @@
+package todo
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"todolist-api/constants"
+	"todolist-api/data/models"
+	"todolist-api/infra/errors"
+	"todolist-api/objects/todo"
+	"todolist-api/utils"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// bulk the state of a bulk operation, its items and its writes
+type bulk struct {
+	*Writes
+	mode  string
+	items []todo.BulkTodoItem
+}
+
+// fail report the failure of item i, or fail the whole operation in atomic
+// mode. Only the errors about the item itself, such as a missing todo, are
+// reported in best effort mode.
+func (b *bulk) fail(i int, err error) error {
+	if b.mode == constants.BulkModeAtomic || !itemError(err) {
+		return errors.Wrap(fmt.Errorf("items[%d]: %w", i, err))
+	}
+
+	b.items[i].Status = constants.BulkItemError
+	b.items[i].Error = err.Error()
+
+	return nil
+}
+
+// ok report the success of item i
+func (b *bulk) ok(i int, data todo.Todo) {
+	b.items[i].ID = data.ID
+	b.items[i].Status = constants.BulkItemOK
+	b.items[i].Todo = &data
+}
+
+// itemErrors the errors about an item rather than the database
+var itemErrors = []error{
+	constants.ErrNotFound,
+	constants.ErrTitleCannotBeNull,
+	constants.ErrDueAtInvalid,
+	constants.ErrRRuleInvalid,
+	constants.ErrRRuleWithoutDueAt,
+}
+
+// itemError tell whether err is about the item rather than the database
+func itemError(err error) bool {
+	for _, target := range itemErrors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (t todoService) BulkTodo(ctx context.Context, req todo.BulkTodo) (todo.BulkTodoResult, error) {
+	if req.Mode == "" {
+		req.Mode = constants.BulkModeAtomic
+	}
+
+	if req.Mode != constants.BulkModeAtomic && req.Mode != constants.BulkModeBestEffort {
+		return todo.BulkTodoResult{}, errors.Wrap(constants.ErrBulkModeInvalid)
+	}
+
+	var n int
+	switch req.Operation {
+	case constants.BulkCreate:
+		n = len(req.Items)
+	case constants.BulkUpdate, constants.BulkDelete:
+		n = len(req.IDs)
+	case constants.BulkCompleteGroup:
+		if req.ActivityGroupID == 0 {
+			return todo.BulkTodoResult{}, errors.Wrap(constants.ErrActivityGroupRequired)
+		}
+		n = -1
+	default:
+		return todo.BulkTodoResult{}, errors.Wrap(constants.ErrBulkOperationInvalid)
+	}
+
+	if n == 0 {
+		return todo.BulkTodoResult{}, errors.Wrap(constants.ErrBulkEmpty)
+	}
+
+	if n > constants.BulkMaxItems {
+		return todo.BulkTodoResult{}, errors.Wrap(fmt.Errorf("%w, at most %d", constants.ErrBulkTooManyItems, constants.BulkMaxItems))
+	}
+
+	if req.Operation == constants.BulkUpdate && (req.Set == nil || (req.Set.IsActive == nil && req.Set.Priority == nil && req.Set.ActivityGroupID == nil)) {
+		return todo.BulkTodoResult{}, errors.Wrap(constants.ErrBulkNothingToSet)
+	}
+
+	tx, err := t.DB.Begin(ctx)
+	if err != nil {
+		return todo.BulkTodoResult{}, errors.Wrap(constants.ErrBeginTransaction)
+	}
+
+	b := &bulk{Writes: NewWrites(t.RepoCtx, tx), mode: req.Mode}
+	if n > 0 {
+		b.items = make([]todo.BulkTodoItem, n)
+		for i := range b.items {
+			b.items[i].Index = i
+		}
+	}
+
+	switch req.Operation {
+	case constants.BulkCreate:
+		err = t.bulkCreate(ctx, tx, b, req.Items)
+	case constants.BulkUpdate:
+		err = t.bulkUpdate(ctx, tx, b, req.IDs, *req.Set)
+	case constants.BulkDelete:
+		err = t.bulkDelete(ctx, tx, b, req.IDs)
+	case constants.BulkCompleteGroup:
+		err = t.bulkCompleteGroup(ctx, tx, b, req.ActivityGroupID)
+	}
+	if err != nil {
+		_ = tx.Rollback()
+		return todo.BulkTodoResult{}, err
+	}
+
+	err = b.Commit(ctx)
+	if err != nil {
+		_ = tx.Rollback()
+		return todo.BulkTodoResult{}, err
+	}
+
+	b.Publish(ctx)
+
+	result := todo.BulkTodoResult{
+		Operation: req.Operation,
+		Mode:      req.Mode,
+		Items:     b.items,
+	}
+	if result.Items == nil {
+		result.Items = []todo.BulkTodoItem{}
+	}
+	for _, x := range result.Items {
+		if x.Status == constants.BulkItemOK {
+			result.Succeeded++
+		} else {
+			result.Failed++
+		}
+	}
+
+	return result, nil
+}
+
+// bulkCreate insert the valid todos
+func (t todoService) bulkCreate(ctx context.Context, tx *sqlx.Tx, b *bulk, items []todo.CreateTodo) error {
+	groups := map[int]error{}
+	data := []models.Todo{}
+	index := []int{}
+
+	for i, x := range items {
+		err := t.checkActivityGroup(ctx, tx, groups, x.ActivityGroupID)
+		if err == nil && x.Title == "" {
+			err = constants.ErrTitleCannotBeNull
+		}
+		var dueAt *time.Time
+		var rrule *string
+		if err == nil {
+			dueAt, rrule, err = Schedule(x.DueAt, x.RRule)
+		}
+		if err != nil {
+			if err = b.fail(i, err); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if x.Priority == "" {
+			x.Priority = constants.Priority
+		}
+
+		data = append(data, models.Todo{
+			Title:           x.Title,
+			ActivityGroupID: x.ActivityGroupID,
+			IsActive:        x.IsActive,
+			Priority:        x.Priority,
+			DueAt:           dueAt,
+			RRule:           rrule,
+		})
+		index = append(index, i)
+	}
+
+	ids, err := t.TodoRepository.CreateTodos(ctx, tx, data)
+	if err != nil {
+		return err
+	}
+
+	created, err := t.getTodoByIDs(ctx, tx, ids)
+	if err != nil {
+		return err
+	}
+
+	for j, id := range ids {
+		result, err := b.Created(ctx, created[id], time.Time{})
+		if err != nil {
+			return err
+		}
+		b.ok(index[j], result)
+	}
+
+	return nil
+}
+
+// bulkUpdate set the same fields on the existing todos with a single statement
+func (t todoService) bulkUpdate(ctx context.Context, tx *sqlx.Tx, b *bulk, ids []int, set todo.BulkTodoSet) error {
+	patch := models.TodoPatch{
+		IsActive:        set.IsActive,
+		Priority:        set.Priority,
+		ActivityGroupID: set.ActivityGroupID,
+	}
+
+	if patch.Priority != nil && *patch.Priority == "" {
+		priority := constants.Priority
+		patch.Priority = &priority
+	}
+
+	// the destination applies to every item, a missing one fails them all
+	if patch.ActivityGroupID != nil {
+		_, err := t.ActivityRepository.GetOneActivity(ctx, tx, *patch.ActivityGroupID)
+		if err != nil {
+			return err
+		}
+	}
+
+	before, found, err := t.existing(ctx, tx, b, ids)
+	if err != nil {
+		return err
+	}
+
+	fields := []string{}
+	if patch.IsActive != nil {
+		fields = append(fields, constants.FieldIsActive)
+	}
+	if patch.Priority != nil {
+		fields = append(fields, constants.FieldPriority)
+	}
+	if patch.ActivityGroupID != nil {
+		fields = append(fields, constants.FieldActivityGroupID)
+	}
+
+	_, err = t.TodoRepository.UpdateTodos(ctx, tx, found, patch)
+	if err != nil {
+		return err
+	}
+
+	after, err := t.getTodoByIDs(ctx, tx, found)
+	if err != nil {
+		return err
+	}
+
+	done := map[int]bool{}
+	for i, id := range ids {
+		data, ok := after[id]
+		if !ok {
+			continue
+		}
+
+		// a todo listed twice changes once
+		if done[id] {
+			b.ok(i, NewTodo(data))
+			continue
+		}
+		done[id] = true
+
+		result, err := b.Updated(ctx, before[id], data, fields, time.Time{})
+		if err != nil {
+			return err
+		}
+		b.ok(i, result)
+	}
+
+	return nil
+}
+
+// bulkDelete delete the existing todos with a single statement
+func (t todoService) bulkDelete(ctx context.Context, tx *sqlx.Tx, b *bulk, ids []int) error {
+	before, found, err := t.existing(ctx, tx, b, ids)
+	if err != nil {
+		return err
+	}
+
+	_, err = t.TodoRepository.DeleteTodos(ctx, tx, found)
+	if err != nil {
+		return err
+	}
+
+	done := map[int]bool{}
+	for i, id := range ids {
+		data, ok := before[id]
+		if !ok {
+			continue
+		}
+
+		if done[id] {
+			b.ok(i, NewTodo(data))
+			continue
+		}
+		done[id] = true
+
+		deleted, err := b.Deleted(ctx, data, time.Time{})
+		if err != nil {
+			return err
+		}
+		b.ok(i, deleted)
+	}
+
+	return nil
+}
+
+// bulkCompleteGroup deactivate the active todos of the group, an item each
+func (t todoService) bulkCompleteGroup(ctx context.Context, tx *sqlx.Tx, b *bulk, activityGroupID int) error {
+	_, err := t.ActivityRepository.GetOneActivity(ctx, tx, activityGroupID)
+	if err != nil {
+		return err
+	}
+
+	active, err := t.TodoRepository.GetActiveTodoByActivityGroupID(ctx, tx, activityGroupID)
+	if err != nil {
+		return err
+	}
+
+	ids := make([]int, 0, len(active))
+	before := make(map[int]models.Todo, len(active))
+	for _, x := range active {
+		ids = append(ids, x.TodoID)
+		before[x.TodoID] = x
+	}
+
+	inactive := false
+	_, err = t.TodoRepository.UpdateTodos(ctx, tx, ids, models.TodoPatch{IsActive: &inactive})
+	if err != nil {
+		return err
+	}
+
+	after, err := t.getTodoByIDs(ctx, tx, ids)
+	if err != nil {
+		return err
+	}
+
+	b.items = make([]todo.BulkTodoItem, len(ids))
+	for i, id := range ids {
+		result, err := b.Updated(ctx, before[id], after[id], []string{constants.FieldIsActive}, time.Time{})
+		if err != nil {
+			return err
+		}
+		b.items[i].Index = i
+		b.ok(i, result)
+	}
+
+	return nil
+}
+
+// existing load the todos of ids, reporting the missing ones, and return
+// them by ID along with the IDs found
+func (t todoService) existing(ctx context.Context, tx *sqlx.Tx, b *bulk, ids []int) (map[int]models.Todo, []int, error) {
+	data, err := t.getTodoByIDs(ctx, tx, ids)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// found in the order of ids, so the statements on them are the same for
+	// the same request
+	found := make([]int, 0, len(data))
+	seen := make(map[int]bool, len(data))
+	for i, id := range ids {
+		if _, ok := data[id]; !ok {
+			if err = b.fail(i, errors.Wrap(utils.ErrDataNotFound(id))); err != nil {
+				return nil, nil, err
+			}
+			continue
+		}
+		if !seen[id] {
+			seen[id] = true
+			found = append(found, id)
+		}
+	}
+
+	return data, found, nil
+}
+
+// getTodoByIDs load the todos of ids, locked until the end of tx, by ID
+func (t todoService) getTodoByIDs(ctx context.Context, tx *sqlx.Tx, ids []int) (map[int]models.Todo, error) {
+	data, err := t.TodoRepository.GetTodoByIDs(ctx, tx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(map[int]models.Todo, len(data))
+	for _, x := range data {
+		res[x.TodoID] = x
+	}
+
+	return res, nil
+}
+
+// checkActivityGroup tell whether the group exists, remembering the answer
+func (t todoService) checkActivityGroup(ctx context.Context, tx *sqlx.Tx, groups map[int]error, id int) error {
+	if err, ok := groups[id]; ok {
+		return err
+	}
+
+	_, err := t.ActivityRepository.GetOneActivity(ctx, tx, id)
+	groups[id] = err
+
+	return err
+}
@@ -0,0 +1,33 @@
+package todo
+
+import (
+	"fmt"
+	"testing"
+	"todolist-api/constants"
+	"todolist-api/infra/errors"
+	"todolist-api/utils"
+)
+
+func TestItemError(t *testing.T) {
+	tests := []struct {
+		err  error
+		item bool
+	}{
+		{errors.Wrap(utils.ErrDataNotFound(7)), true},
+		{errors.Wrap(constants.ErrTitleCannotBeNull), true},
+		{constants.ErrTitleCannotBeNull, true},
+		{errors.Wrap(constants.ErrDueAtInvalid), true},
+		{errors.Wrap(fmt.Errorf("%w: unknown FREQ", constants.ErrRRuleInvalid)), true},
+		{errors.Wrap(constants.ErrRRuleWithoutDueAt), true},
+		{errors.Wrap(constants.ErrBeginTransaction), false},
+		// a database error quoting a message of the API isn't about the item
+		{fmt.Errorf("Error 1062: Duplicate entry 'Not Found' for key 'title'"), false},
+		{fmt.Errorf("Error 1406: Data too long, title cannot be null"), false},
+	}
+
+	for _, tt := range tests {
+		if got := itemError(tt.err); got != tt.item {
+			t.Errorf("itemError(%q) = %t, expected %t", tt.err, got, tt.item)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package todo
+
+import (
+	"context"
+	"todolist-api/infra/decorator"
+	"todolist-api/objects/todo"
+)
+
+// todoServiceDecorator observe every call of the wrapped service
+type todoServiceDecorator struct {
+	next TodoServiceInterface
+	d    *decorator.Decorator
+}
+
+// NewTodoServiceDecorator decorate the service with d, such as its timings or
+// its spans
+func NewTodoServiceDecorator(next TodoServiceInterface, d *decorator.Decorator) TodoServiceInterface {
+	return &todoServiceDecorator{
+		next: next,
+		d:    d,
+	}
+}
+
+func (s todoServiceDecorator) CreateTodo(ctx context.Context, req todo.CreateTodo) (todo.Todo, error) {
+	return decorator.Call(ctx, s.d, "CreateTodo", func(ctx context.Context) (todo.Todo, error) {
+		return s.next.CreateTodo(ctx, req)
+	})
+}
+
+func (s todoServiceDecorator) GetAllTodo(ctx context.Context, filter todo.FilterTodo) ([]todo.Todo, error) {
+	return decorator.Call(ctx, s.d, "GetAllTodo", func(ctx context.Context) ([]todo.Todo, error) {
+		return s.next.GetAllTodo(ctx, filter)
+	})
+}
+
+func (s todoServiceDecorator) GetTodoByActivityGroupIDs(ctx context.Context, ids []int) (map[int][]todo.Todo, error) {
+	return decorator.Call(ctx, s.d, "GetTodoByActivityGroupIDs", func(ctx context.Context) (map[int][]todo.Todo, error) {
+		return s.next.GetTodoByActivityGroupIDs(ctx, ids)
+	})
+}
+
+func (s todoServiceDecorator) GetOneTodo(ctx context.Context, id int) (todo.Todo, error) {
+	return decorator.Call(ctx, s.d, "GetOneTodo", func(ctx context.Context) (todo.Todo, error) {
+		return s.next.GetOneTodo(ctx, id)
+	})
+}
+
+func (s todoServiceDecorator) UpdateTodo(ctx context.Context, id int, req todo.UpdateTodo) (todo.Todo, error) {
+	return decorator.Call(ctx, s.d, "UpdateTodo", func(ctx context.Context) (todo.Todo, error) {
+		return s.next.UpdateTodo(ctx, id, req)
+	})
+}
+
+func (s todoServiceDecorator) MoveTodo(ctx context.Context, id int, req todo.MoveTodo) (todo.Todo, error) {
+	return decorator.Call(ctx, s.d, "MoveTodo", func(ctx context.Context) (todo.Todo, error) {
+		return s.next.MoveTodo(ctx, id, req)
+	})
+}
+
+func (s todoServiceDecorator) DeleteTodo(ctx context.Context, id int) error {
+	return decorator.Exec(ctx, s.d, "DeleteTodo", func(ctx context.Context) error {
+		return s.next.DeleteTodo(ctx, id)
+	})
+}
+
+func (s todoServiceDecorator) BulkTodo(ctx context.Context, req todo.BulkTodo) (todo.BulkTodoResult, error) {
+	return decorator.Call(ctx, s.d, "BulkTodo", func(ctx context.Context) (todo.BulkTodoResult, error) {
+		return s.next.BulkTodo(ctx, req)
+	})
+}
@@ -8,10 +8,13 @@ import (
 
 type TodoServiceInterface interface {
 	CreateTodo(ctx context.Context, req todo.CreateTodo) (todo.Todo, error)
-	GetAllTodo(ctx context.Context) ([]todo.Todo, error)
+	GetAllTodo(ctx context.Context, filter todo.FilterTodo) ([]todo.Todo, error)
+	GetTodoByActivityGroupIDs(ctx context.Context, ids []int) (map[int][]todo.Todo, error)
 	GetOneTodo(ctx context.Context, id int) (todo.Todo, error)
 	UpdateTodo(ctx context.Context, id int, req todo.UpdateTodo) (todo.Todo, error)
+	MoveTodo(ctx context.Context, id int, req todo.MoveTodo) (todo.Todo, error)
 	DeleteTodo(ctx context.Context, id int) error
+	BulkTodo(ctx context.Context, req todo.BulkTodo) (todo.BulkTodoResult, error)
 }
 
 func NewTodoService(ctx *repository.RepoCtx) TodoServiceInterface {
@@ -0,0 +1,61 @@
+package todo
+
+import (
+	"context"
+	"todolist-api/constants"
+	"todolist-api/infra/idempotency"
+	"todolist-api/infra/ratelimit"
+	"todolist-api/objects/todo"
+)
+
+// todoServiceQuota count the todos each user creates against the daily
+// quota, the other methods go straight to the wrapped service
+type todoServiceQuota struct {
+	TodoServiceInterface
+	quota *ratelimit.Quota
+}
+
+// NewTodoServiceQuota decorate the service with the daily creation quota
+func NewTodoServiceQuota(next TodoServiceInterface, quota *ratelimit.Quota) TodoServiceInterface {
+	return &todoServiceQuota{
+		TodoServiceInterface: next,
+		quota:                quota,
+	}
+}
+
+func (s todoServiceQuota) CreateTodo(ctx context.Context, req todo.CreateTodo) (todo.Todo, error) {
+	release, err := s.quota.Take(ctx, 1)
+	if err != nil {
+		return todo.Todo{}, err
+	}
+
+	// a replayed retry didn't create another todo
+	data, err := s.TodoServiceInterface.CreateTodo(ctx, req)
+	if err != nil || idempotency.Replayed(ctx) {
+		release(1)
+	}
+
+	return data, err
+}
+
+// BulkTodo count the todos of a bulk creation, giving back the ones that
+// failed
+func (s todoServiceQuota) BulkTodo(ctx context.Context, req todo.BulkTodo) (todo.BulkTodoResult, error) {
+	if req.Operation != constants.BulkCreate {
+		return s.TodoServiceInterface.BulkTodo(ctx, req)
+	}
+
+	release, err := s.quota.Take(ctx, len(req.Items))
+	if err != nil {
+		return todo.BulkTodoResult{}, err
+	}
+
+	data, err := s.TodoServiceInterface.BulkTodo(ctx, req)
+	if err != nil {
+		release(len(req.Items))
+	} else if data.Failed > 0 {
+		release(data.Failed)
+	}
+
+	return data, err
+}
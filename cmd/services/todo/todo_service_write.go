@@ -0,0 +1,208 @@
+package todo
+
+import (
+	"context"
+	"time"
+	"todolist-api/constants"
+	"todolist-api/data/models"
+	"todolist-api/data/repositories/change"
+	"todolist-api/infra/context/repository"
+	"todolist-api/infra/events"
+	"todolist-api/objects/todo"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Writes the writes of one transaction, shared by the todo, bulk and sync
+// services. Each write of a todo goes along with its change to the feed and
+// its webhook events, the changes being recorded and the live events
+// published around the commit.
+type Writes struct {
+	*repository.RepoCtx
+	tx      *sqlx.Tx
+	changes []models.Change
+	pending []events.Event
+}
+
+// NewWrites start the writes of tx
+func NewWrites(ctx *repository.RepoCtx, tx *sqlx.Tx) *Writes {
+	return &Writes{
+		RepoCtx: ctx,
+		tx:      tx,
+	}
+}
+
+// Create insert the todo, changed at at, and return it as stored
+func (w *Writes) Create(ctx context.Context, data models.Todo, at time.Time) (todo.Todo, error) {
+	created, err := w.TodoRepository.CreateTodo(ctx, w.tx, data)
+	if err != nil {
+		return todo.Todo{}, err
+	}
+
+	data, err = w.TodoRepository.GetOneTodo(ctx, w.tx, created.TodoID)
+	if err != nil {
+		return todo.Todo{}, err
+	}
+
+	return w.Created(ctx, data, at)
+}
+
+// Created report the todo inserted by the caller
+func (w *Writes) Created(ctx context.Context, data models.Todo, at time.Time) (todo.Todo, error) {
+	result := NewTodo(data)
+	w.Change(constants.SyncTodo, result.ID, constants.ChangeUpsert, at, constants.FieldTitle, constants.FieldActivityGroupID, constants.FieldIsActive, constants.FieldPriority, constants.FieldDueAt, constants.FieldRRule)
+
+	return result, w.Enqueue(ctx, constants.EventTodoCreated, result.ActivityGroupID, result)
+}
+
+// Update write the fields of data onto the todo before, the activity group
+// moving the todo, and return it as stored. Nothing is written without
+// fields.
+func (w *Writes) Update(ctx context.Context, before, data models.Todo, fields []string, at time.Time) (todo.Todo, error) {
+	updated, moved := split(fields)
+
+	if updated {
+		err := w.TodoRepository.UpdateTodo(ctx, w.tx, before.TodoID, models.Todo{
+			Title:    data.Title,
+			IsActive: data.IsActive,
+			Priority: data.Priority,
+			DueAt:    data.DueAt,
+			RRule:    data.RRule,
+		})
+		if err != nil {
+			return todo.Todo{}, err
+		}
+	}
+
+	if moved {
+		err := w.TodoRepository.MoveTodo(ctx, w.tx, before.TodoID, data.ActivityGroupID)
+		if err != nil {
+			return todo.Todo{}, err
+		}
+	}
+
+	after, err := w.TodoRepository.GetOneTodo(ctx, w.tx, before.TodoID)
+	if err != nil {
+		return todo.Todo{}, err
+	}
+
+	if len(fields) == 0 {
+		return NewTodo(after), nil
+	}
+
+	return w.Updated(ctx, before, after, fields, at)
+}
+
+// Updated report the fields of the todo changed by the caller from before to
+// after. Setting other fields than the activity group is an update, a
+// deactivation a completion and setting the group a move, told to both the
+// source and the destination group.
+func (w *Writes) Updated(ctx context.Context, before, after models.Todo, fields []string, at time.Time) (todo.Todo, error) {
+	result := NewTodo(after)
+	w.Change(constants.SyncTodo, result.ID, constants.ChangeUpsert, at, fields...)
+
+	updated, moved := split(fields)
+
+	if updated {
+		err := w.Enqueue(ctx, constants.EventTodoUpdated, result.ActivityGroupID, result)
+		if err != nil {
+			return todo.Todo{}, err
+		}
+	}
+
+	// an active todo that gets deactivated is considered completed
+	if before.IsActive && !after.IsActive {
+		err := w.Enqueue(ctx, constants.EventTodoCompleted, result.ActivityGroupID, result)
+		if err != nil {
+			return todo.Todo{}, err
+		}
+	}
+
+	if moved {
+		err := w.Enqueue(ctx, constants.EventTodoMoved, result.ActivityGroupID, result)
+		if err != nil {
+			return todo.Todo{}, err
+		}
+
+		if before.ActivityGroupID != after.ActivityGroupID {
+			w.pending = append(w.pending, events.Event{
+				Type:            constants.EventTodoMoved,
+				ActivityGroupID: before.ActivityGroupID,
+				Data:            result,
+			})
+		}
+	}
+
+	return result, nil
+}
+
+// Delete delete the todo, returning it as it was
+func (w *Writes) Delete(ctx context.Context, data models.Todo, at time.Time) (todo.Todo, error) {
+	err := w.TodoRepository.DeleteTodo(ctx, w.tx, data.TodoID)
+	if err != nil {
+		return todo.Todo{}, err
+	}
+
+	return w.Deleted(ctx, data, at)
+}
+
+// Deleted report the todo deleted by the caller
+func (w *Writes) Deleted(ctx context.Context, data models.Todo, at time.Time) (todo.Todo, error) {
+	deleted := NewTodo(data)
+	w.Change(constants.SyncTodo, deleted.ID, constants.ChangeDelete, at)
+
+	return deleted, w.Enqueue(ctx, constants.EventTodoDeleted, deleted.ActivityGroupID, deleted)
+}
+
+// Change add a change to the feed, changed at at or at the commit when zero
+func (w *Writes) Change(entity string, id int, operation string, at time.Time, fields ...string) {
+	w.changes = append(w.changes, models.Change{
+		Entity:    entity,
+		EntityID:  id,
+		Operation: operation,
+		Fields:    fields,
+		ChangedAt: at,
+	})
+}
+
+// Enqueue the webhook event in the transaction and keep the live event for
+// after the commit
+func (w *Writes) Enqueue(ctx context.Context, eventType string, activityGroupID int, data interface{}) error {
+	err := w.WebhookRepository.EnqueueEvent(ctx, w.tx, eventType, data)
+	if err != nil {
+		return err
+	}
+
+	w.pending = append(w.pending, events.Event{
+		Type:            eventType,
+		ActivityGroupID: activityGroupID,
+		Data:            data,
+	})
+
+	return nil
+}
+
+// Commit record the changes to the feed and commit the transaction
+func (w *Writes) Commit(ctx context.Context) error {
+	return change.Commit(ctx, w.ChangeRepository, w.tx, w.changes...)
+}
+
+// Publish the live events, once committed
+func (w *Writes) Publish(ctx context.Context) {
+	for _, e := range w.pending {
+		w.Publisher.Publish(ctx, e)
+	}
+}
+
+// split tell whether fields update the todo and whether they move it
+func split(fields []string) (updated, moved bool) {
+	for _, f := range fields {
+		if f == constants.FieldActivityGroupID {
+			moved = true
+		} else {
+			updated = true
+		}
+	}
+
+	return updated, moved
+}
@@ -0,0 +1,386 @@
+package transfer
+
+import (
+	"context"
+	"fmt"
+	"time"
+	activityservice "todolist-api/cmd/services/activity"
+	todoservice "todolist-api/cmd/services/todo"
+	"todolist-api/constants"
+	"todolist-api/data/models"
+	"todolist-api/data/repositories/change"
+	"todolist-api/infra/context/repository"
+	"todolist-api/infra/errors"
+	"todolist-api/infra/events"
+	"todolist-api/infra/ical"
+	"todolist-api/objects/activity"
+	"todolist-api/objects/transfer"
+	"todolist-api/utils"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type transferService struct {
+	*repository.RepoCtx
+}
+
+func (t transferService) ExportActivity(ctx context.Context, id int) (transfer.Export, error) {
+	tx, err := t.DB.Begin(ctx)
+	if err != nil {
+		return transfer.Export{}, errors.Wrap(constants.ErrBeginTransaction)
+	}
+
+	data, err := t.ActivityRepository.GetOneActivity(ctx, tx, id)
+	if err != nil {
+		_ = tx.Rollback()
+		return transfer.Export{}, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		_ = tx.Rollback()
+		return transfer.Export{}, err
+	}
+
+	todos, err := t.TodoRepository.GetAllTodo(ctx, models.TodoFilter{
+		ActivityGroupID: id,
+	})
+	if err != nil {
+		return transfer.Export{}, err
+	}
+
+	return newExport([]models.Activity{data}, todos), nil
+}
+
+func (t transferService) ExportAll(ctx context.Context, email string) (transfer.Export, error) {
+	data, err := t.ActivityRepository.GetAllActivity(ctx, models.ActivityFilter{
+		Email: email,
+	})
+	if err != nil {
+		return transfer.Export{}, err
+	}
+
+	if len(data) == 0 {
+		return newExport(data, nil), nil
+	}
+
+	ids := make([]int, 0, len(data))
+	for _, x := range data {
+		ids = append(ids, x.ActivityID)
+	}
+
+	todos, err := t.TodoRepository.GetTodoByActivityGroupIDs(ctx, ids)
+	if err != nil {
+		return transfer.Export{}, err
+	}
+
+	return newExport(data, todos), nil
+}
+
+// newExport group the todos under their activity group, in the order of the
+// groups
+func newExport(groups []models.Activity, todos []models.Todo) transfer.Export {
+	result := transfer.Export{
+		Version:        constants.ExportVersion,
+		ExportedAt:     time.Now().UTC().Format(constants.DateTimeFormat),
+		ActivityGroups: make([]transfer.Group, 0, len(groups)),
+	}
+
+	index := make(map[int]int, len(groups))
+	for i, x := range groups {
+		index[x.ActivityID] = i
+		result.ActivityGroups = append(result.ActivityGroups, transfer.Group{
+			Title: x.Title,
+			Email: x.Email,
+			Todos: []transfer.Todo{},
+		})
+	}
+
+	for _, x := range todos {
+		i, ok := index[x.ActivityGroupID]
+		if !ok {
+			continue
+		}
+
+		result.ActivityGroups[i].Todos = append(result.ActivityGroups[i].Todos, transfer.Todo{
+			Title:    x.Title,
+			Priority: x.Priority,
+			IsActive: x.IsActive,
+			DueAt:    utils.FormatNullTime(x.DueAt, time.RFC3339),
+			RRule:    utils.NullString(x.RRule),
+		})
+	}
+
+	return result
+}
+
+// Import check every group and todo of the file, then create them all in a
+// single transaction. Nothing is created when an error is found or on a dry
+// run, the report telling what would be.
+func (t transferService) Import(ctx context.Context, req transfer.Import) (transfer.ImportReport, error) {
+	report := transfer.ImportReport{
+		Format: req.Format,
+		DryRun: req.DryRun,
+		Groups: len(req.Groups),
+		Errors: append([]transfer.ImportError{}, req.Errors...),
+	}
+
+	for i := range req.Groups {
+		g := &req.Groups[i]
+		report.Todos += len(g.Todos)
+
+		if g.Email == "" || req.Email != "" {
+			g.Email = req.Email
+		}
+		report.Errors = append(report.Errors, validate(g, req.ActivityGroupID == 0)...)
+	}
+
+	// the todos join an existing group, no group is created
+	if req.ActivityGroupID != 0 {
+		report.Groups = 0
+	}
+
+	switch {
+	case len(report.Errors) > 0:
+	case req.ActivityGroupID == 0 && len(req.Groups) == 0:
+		report.Errors = append(report.Errors, transfer.ImportError{
+			Location: "file",
+			Message:  "no activity group found",
+		})
+	case req.ActivityGroupID != 0 && report.Todos == 0:
+		report.Errors = append(report.Errors, transfer.ImportError{
+			Location: "file",
+			Message:  "no todo found",
+		})
+	}
+
+	report.Valid = len(report.Errors) == 0
+	if !report.Valid || req.DryRun {
+		return report, nil
+	}
+
+	tx, err := t.DB.Begin(ctx)
+	if err != nil {
+		return transfer.ImportReport{}, errors.Wrap(constants.ErrBeginTransaction)
+	}
+
+	b := &batch{}
+	if req.ActivityGroupID != 0 {
+		err = t.insertInto(ctx, tx, b, req.ActivityGroupID, req.Groups)
+	} else {
+		report.ActivityGroups, err = t.insert(ctx, tx, b, req.Groups)
+	}
+	if err != nil {
+		_ = tx.Rollback()
+		return transfer.ImportReport{}, err
+	}
+
+	err = change.Commit(ctx, t.ChangeRepository, tx, b.changes...)
+	if err != nil {
+		_ = tx.Rollback()
+		return transfer.ImportReport{}, err
+	}
+
+	for _, e := range b.pending {
+		t.Publisher.Publish(ctx, e)
+	}
+
+	return report, nil
+}
+
+// validate check the group, unless its todos join an existing one, and its
+// todos like their creation would, setting the default priority
+func validate(g *transfer.Group, group bool) []transfer.ImportError {
+	errs := []transfer.ImportError{}
+	check := func(location, field, value string, required bool) {
+		switch {
+		case required && value == "":
+			errs = append(errs, transfer.ImportError{Location: location, Message: field + " is required"})
+		case len(value) > constants.MaxTitleLength:
+			errs = append(errs, transfer.ImportError{
+				Location: location,
+				Message:  fmt.Sprintf("%s is longer than %d characters", field, constants.MaxTitleLength),
+			})
+		}
+	}
+
+	if group {
+		check(g.Location, "title", g.Title, true)
+		check(g.Location, "email", g.Email, false)
+	}
+
+	for i := range g.Todos {
+		x := &g.Todos[i]
+		if x.Priority == "" {
+			x.Priority = constants.Priority
+		}
+
+		check(x.Location, "title", x.Title, true)
+		check(x.Location, "priority", x.Priority, false)
+
+		if x.DueAt != "" {
+			if _, err := time.Parse(time.RFC3339, x.DueAt); err != nil {
+				errs = append(errs, transfer.ImportError{Location: x.Location, Message: constants.ErrDueAtInvalid.Error()})
+			}
+		}
+
+		if x.RRule != "" {
+			rule, err := ical.ParseRRule(x.RRule)
+			switch {
+			case err != nil:
+				errs = append(errs, transfer.ImportError{Location: x.Location, Message: fmt.Sprintf("%s: %v", constants.ErrRRuleInvalid, err)})
+			case len(rule) > constants.MaxRRuleLength:
+				errs = append(errs, transfer.ImportError{
+					Location: x.Location,
+					Message:  fmt.Sprintf("rrule is longer than %d characters", constants.MaxRRuleLength),
+				})
+			case x.DueAt == "":
+				errs = append(errs, transfer.ImportError{Location: x.Location, Message: constants.ErrRRuleWithoutDueAt.Error()})
+			}
+			x.RRule = rule
+		}
+	}
+
+	return errs
+}
+
+// batch the changes to the feed of an import and the events published once
+// it commits
+type batch struct {
+	changes []models.Change
+	pending []events.Event
+}
+
+// insert create the groups and their todos in b, and return the groups
+func (t transferService) insert(ctx context.Context, tx *sqlx.Tx, b *batch, groups []transfer.Group) ([]activity.Activity, error) {
+	result := make([]activity.Activity, 0, len(groups))
+
+	for _, g := range groups {
+		activityID, err := t.ActivityRepository.CreateActivity(ctx, tx, models.Activity{
+			Title: g.Title,
+			Email: g.Email,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := t.ActivityRepository.GetOneActivity(ctx, tx, activityID.ActivityID)
+		if err != nil {
+			return nil, err
+		}
+
+		group := activityservice.NewActivity(data)
+		result = append(result, group)
+		b.changes = append(b.changes, models.Change{
+			Entity:    constants.SyncActivity,
+			EntityID:  group.ID,
+			Operation: constants.ChangeUpsert,
+			Fields:    []string{constants.FieldTitle, constants.FieldEmail},
+		})
+
+		err = t.WebhookRepository.EnqueueEvent(ctx, tx, constants.EventActivityCreated, group)
+		if err != nil {
+			return nil, err
+		}
+		b.pending = append(b.pending, events.Event{
+			Type:            constants.EventActivityCreated,
+			ActivityGroupID: group.ID,
+			Data:            group,
+		})
+
+		err = t.insertTodos(ctx, tx, b, group.ID, g.Todos)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// insertInto add the todos of the groups to the existing activity group in b
+func (t transferService) insertInto(ctx context.Context, tx *sqlx.Tx, b *batch, activityGroupID int, groups []transfer.Group) error {
+	_, err := t.ActivityRepository.GetOneActivity(ctx, tx, activityGroupID)
+	if err != nil {
+		return err
+	}
+
+	for _, g := range groups {
+		err = t.insertTodos(ctx, tx, b, activityGroupID, g.Todos)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// insertTodos create the todos of the group, with a statement per
+// BulkMaxItems todos
+func (t transferService) insertTodos(ctx context.Context, tx *sqlx.Tx, b *batch, activityGroupID int, todos []transfer.Todo) error {
+	for start := 0; start < len(todos); start += constants.BulkMaxItems {
+		end := start + constants.BulkMaxItems
+		if end > len(todos) {
+			end = len(todos)
+		}
+
+		data := make([]models.Todo, 0, end-start)
+		for _, x := range todos[start:end] {
+			row := models.Todo{
+				Title:           x.Title,
+				ActivityGroupID: activityGroupID,
+				IsActive:        x.IsActive,
+				Priority:        x.Priority,
+			}
+			// both were checked by validate
+			if x.DueAt != "" {
+				due, _ := time.Parse(time.RFC3339, x.DueAt)
+				due = due.UTC()
+				row.DueAt = &due
+			}
+			if x.RRule != "" {
+				rule := x.RRule
+				row.RRule = &rule
+			}
+			data = append(data, row)
+		}
+
+		ids, err := t.TodoRepository.CreateTodos(ctx, tx, data)
+		if err != nil {
+			return err
+		}
+
+		rows, err := t.TodoRepository.GetTodoByIDs(ctx, tx, ids)
+		if err != nil {
+			return err
+		}
+
+		byID := make(map[int]models.Todo, len(rows))
+		for _, x := range rows {
+			byID[x.TodoID] = x
+		}
+
+		for _, id := range ids {
+			x := byID[id]
+			result := todoservice.NewTodo(x)
+
+			b.changes = append(b.changes, models.Change{
+				Entity:    constants.SyncTodo,
+				EntityID:  result.ID,
+				Operation: constants.ChangeUpsert,
+				Fields:    []string{constants.FieldTitle, constants.FieldActivityGroupID, constants.FieldIsActive, constants.FieldPriority, constants.FieldDueAt, constants.FieldRRule},
+			})
+
+			err = t.WebhookRepository.EnqueueEvent(ctx, tx, constants.EventTodoCreated, result)
+			if err != nil {
+				return err
+			}
+			b.pending = append(b.pending, events.Event{
+				Type:            constants.EventTodoCreated,
+				ActivityGroupID: result.ActivityGroupID,
+				Data:            result,
+			})
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,94 @@
+package transfer
+
+import (
+	"context"
+	"todolist-api/constants"
+	"todolist-api/data/models"
+	"todolist-api/infra/errors"
+	"todolist-api/objects/transfer"
+	"todolist-api/utils"
+)
+
+// calendarName the name of the calendar of every group of an account
+const calendarName = "Todolist"
+
+func (t transferService) Calendar(ctx context.Context, id int, email string) (transfer.Calendar, error) {
+	var groups []models.Activity
+	var todos []models.Todo
+
+	if id != 0 {
+		tx, err := t.DB.Begin(ctx)
+		if err != nil {
+			return transfer.Calendar{}, errors.Wrap(constants.ErrBeginTransaction)
+		}
+
+		data, err := t.ActivityRepository.GetOneActivity(ctx, tx, id)
+		if err != nil {
+			_ = tx.Rollback()
+			return transfer.Calendar{}, err
+		}
+
+		err = tx.Commit()
+		if err != nil {
+			_ = tx.Rollback()
+			return transfer.Calendar{}, err
+		}
+
+		groups = []models.Activity{data}
+		todos, err = t.TodoRepository.GetAllTodo(ctx, models.TodoFilter{
+			ActivityGroupID: id,
+		})
+		if err != nil {
+			return transfer.Calendar{}, err
+		}
+	} else {
+		var err error
+		groups, err = t.ActivityRepository.GetAllActivity(ctx, models.ActivityFilter{
+			Email: email,
+		})
+		if err != nil {
+			return transfer.Calendar{}, err
+		}
+
+		if len(groups) > 0 {
+			ids := make([]int, 0, len(groups))
+			for _, x := range groups {
+				ids = append(ids, x.ActivityID)
+			}
+
+			todos, err = t.TodoRepository.GetTodoByActivityGroupIDs(ctx, ids)
+			if err != nil {
+				return transfer.Calendar{}, err
+			}
+		}
+	}
+
+	result := transfer.Calendar{
+		Name:  calendarName,
+		Todos: make([]transfer.CalendarTodo, 0, len(todos)),
+	}
+	if id != 0 {
+		result.Name = groups[0].Title
+	}
+
+	titles := make(map[int]string, len(groups))
+	for _, x := range groups {
+		titles[x.ActivityID] = x.Title
+	}
+
+	for _, x := range todos {
+		result.Todos = append(result.Todos, transfer.CalendarTodo{
+			ID:        x.TodoID,
+			Group:     titles[x.ActivityGroupID],
+			Title:     x.Title,
+			Priority:  x.Priority,
+			IsActive:  x.IsActive,
+			DueAt:     x.DueAt,
+			RRule:     utils.NullString(x.RRule),
+			CreatedAt: x.CreatedAt,
+			UpdatedAt: x.UpdatedAt,
+		})
+	}
+
+	return result, nil
+}
@@ -0,0 +1,46 @@
+package transfer
+
+import (
+	"context"
+	"todolist-api/infra/decorator"
+	"todolist-api/objects/transfer"
+)
+
+// transferServiceDecorator observe every call of the wrapped service
+type transferServiceDecorator struct {
+	next TransferServiceInterface
+	d    *decorator.Decorator
+}
+
+// NewTransferServiceDecorator decorate the service with d, such as its timings or
+// its spans
+func NewTransferServiceDecorator(next TransferServiceInterface, d *decorator.Decorator) TransferServiceInterface {
+	return &transferServiceDecorator{
+		next: next,
+		d:    d,
+	}
+}
+
+func (s transferServiceDecorator) ExportActivity(ctx context.Context, id int) (transfer.Export, error) {
+	return decorator.Call(ctx, s.d, "ExportActivity", func(ctx context.Context) (transfer.Export, error) {
+		return s.next.ExportActivity(ctx, id)
+	})
+}
+
+func (s transferServiceDecorator) ExportAll(ctx context.Context, email string) (transfer.Export, error) {
+	return decorator.Call(ctx, s.d, "ExportAll", func(ctx context.Context) (transfer.Export, error) {
+		return s.next.ExportAll(ctx, email)
+	})
+}
+
+func (s transferServiceDecorator) Calendar(ctx context.Context, id int, email string) (transfer.Calendar, error) {
+	return decorator.Call(ctx, s.d, "Calendar", func(ctx context.Context) (transfer.Calendar, error) {
+		return s.next.Calendar(ctx, id, email)
+	})
+}
+
+func (s transferServiceDecorator) Import(ctx context.Context, req transfer.Import) (transfer.ImportReport, error) {
+	return decorator.Call(ctx, s.d, "Import", func(ctx context.Context) (transfer.ImportReport, error) {
+		return s.next.Import(ctx, req)
+	})
+}
@@ -0,0 +1,22 @@
+package transfer
+
+import (
+	"context"
+	"todolist-api/infra/context/repository"
+	"todolist-api/objects/transfer"
+)
+
+type TransferServiceInterface interface {
+	ExportActivity(ctx context.Context, id int) (transfer.Export, error)
+	ExportAll(ctx context.Context, email string) (transfer.Export, error)
+	Import(ctx context.Context, req transfer.Import) (transfer.ImportReport, error)
+	// Calendar the todos of the activity group id, or of every group owned by
+	// email when id is 0, every group when email is empty too
+	Calendar(ctx context.Context, id int, email string) (transfer.Calendar, error)
+}
+
+func NewTransferService(ctx *repository.RepoCtx) TransferServiceInterface {
+	return &transferService{
+		ctx,
+	}
+}
@@ -0,0 +1,47 @@
+package transfer
+
+import (
+	"context"
+	"todolist-api/infra/ratelimit"
+	"todolist-api/objects/transfer"
+)
+
+// transferServiceQuota count the todos an import creates against the daily
+// quota of the user, like the creations of the todo service
+type transferServiceQuota struct {
+	TransferServiceInterface
+	quota *ratelimit.Quota
+}
+
+// NewTransferServiceQuota decorate the service with the daily creation quota
+func NewTransferServiceQuota(next TransferServiceInterface, quota *ratelimit.Quota) TransferServiceInterface {
+	return &transferServiceQuota{
+		TransferServiceInterface: next,
+		quota:                    quota,
+	}
+}
+
+// Import count the todos of the file, giving them back when nothing was
+// created. A dry run creates nothing and isn't counted.
+func (s transferServiceQuota) Import(ctx context.Context, req transfer.Import) (transfer.ImportReport, error) {
+	n := 0
+	for _, g := range req.Groups {
+		n += len(g.Todos)
+	}
+
+	if req.DryRun {
+		return s.TransferServiceInterface.Import(ctx, req)
+	}
+
+	release, err := s.quota.Take(ctx, n)
+	if err != nil {
+		return transfer.ImportReport{}, err
+	}
+
+	data, err := s.TransferServiceInterface.Import(ctx, req)
+	if err != nil || !data.Valid {
+		release(n)
+	}
+
+	return data, err
+}
@@ -0,0 +1,91 @@
+package transfer_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"todolist-api/cmd/services/transfer"
+	"todolist-api/config"
+	"todolist-api/constants"
+	"todolist-api/infra/codec"
+	"todolist-api/infra/context/repository"
+	"todolist-api/infra/db"
+	"todolist-api/infra/db/dbtest"
+	objects "todolist-api/objects/transfer"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files of testdata")
+
+func TestImportDryRun(t *testing.T) {
+	for _, name := range []string{"import.json", "invalid.json"} {
+		t.Run(name, func(t *testing.T) {
+			d := &dbtest.Driver{}
+			conn, err := db.Open(&config.DBConfig{Name: dbtest.Register(d), Host: "test"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+
+			data, err := os.ReadFile(filepath.Join("testdata", name))
+			if err != nil {
+				t.Fatal(err)
+			}
+			groups, errs, err := codec.Decode(constants.FormatJSON, data)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			s := transfer.NewTransferService(repository.NewRepoCtx(conn, nil))
+			report, err := s.Import(context.Background(), objects.Import{
+				Format: constants.FormatJSON,
+				Groups: groups,
+				Errors: errs,
+				Email:  "me@example.com",
+				DryRun: true,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// a dry run leaves the database alone
+			if queries := d.Queries(); len(queries) > 0 {
+				t.Fatalf("statements run by a dry run: %q", queries)
+			}
+			if !report.DryRun || report.ActivityGroups != nil {
+				t.Fatalf("unexpected report %+v", report)
+			}
+
+			got, err := json.MarshalIndent(report, "", "  ")
+			if err != nil {
+				t.Fatal(err)
+			}
+			golden(t, name+".golden", append(got, '\n'))
+		})
+	}
+}
+
+// golden compare got with the golden file name of testdata, rewriting it
+// with -update
+func golden(t *testing.T, name string, got []byte) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	expected, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, expected) {
+		t.Fatalf("%s differs, run go test -update to accept:\n%s", path, got)
+	}
+}
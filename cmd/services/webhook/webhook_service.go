@@ -0,0 +1,282 @@
+package webhook
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"time"
+	"todolist-api/constants"
+	"todolist-api/data/models"
+	"todolist-api/infra/context/repository"
+	"todolist-api/infra/errors"
+	"todolist-api/objects/webhook"
+	"todolist-api/utils"
+)
+
+type webhookService struct {
+	*repository.RepoCtx
+}
+
+func (w webhookService) CreateWebhook(ctx context.Context, req webhook.CreateWebhook) (webhook.Webhook, error) {
+	if err := validateWebhook(req.URL, req.Secret, req.EventTypes); err != nil {
+		return webhook.Webhook{}, err
+	}
+
+	tx, err := w.DB.Begin(ctx)
+	if err != nil {
+		return webhook.Webhook{}, errors.Wrap(constants.ErrBeginTransaction)
+	}
+
+	webhookID, err := w.WebhookRepository.CreateWebhook(ctx, tx, models.Webhook{
+		URL:        req.URL,
+		Secret:     req.Secret,
+		EventTypes: strings.Join(req.EventTypes, ","),
+		IsActive:   true,
+	})
+	if err != nil {
+		_ = tx.Rollback()
+		return webhook.Webhook{}, err
+	}
+
+	data, err := w.WebhookRepository.GetOneWebhook(ctx, tx, webhookID.WebhookID)
+	if err != nil {
+		_ = tx.Rollback()
+		return webhook.Webhook{}, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		_ = tx.Rollback()
+		return webhook.Webhook{}, err
+	}
+
+	return toWebhook(data), nil
+}
+
+func (w webhookService) GetAllWebhook(ctx context.Context) ([]webhook.Webhook, error) {
+	tmpWebhookData := []webhook.Webhook{}
+
+	data, err := w.WebhookRepository.GetAllWebhook(ctx)
+	if err != nil {
+		return tmpWebhookData, err
+	}
+
+	for _, x := range data {
+		tmpWebhookData = append(tmpWebhookData, toWebhook(x))
+	}
+
+	return tmpWebhookData, nil
+}
+
+func (w webhookService) GetOneWebhook(ctx context.Context, id int) (webhook.Webhook, error) {
+	tx, err := w.DB.Begin(ctx)
+	if err != nil {
+		return webhook.Webhook{}, errors.Wrap(constants.ErrBeginTransaction)
+	}
+
+	data, err := w.WebhookRepository.GetOneWebhook(ctx, tx, id)
+	if err != nil {
+		_ = tx.Rollback()
+		return webhook.Webhook{}, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		_ = tx.Rollback()
+		return webhook.Webhook{}, err
+	}
+
+	return toWebhook(data), nil
+}
+
+func (w webhookService) UpdateWebhook(ctx context.Context, id int, req webhook.UpdateWebhook) (webhook.Webhook, error) {
+	if err := validateWebhook(req.URL, req.Secret, req.EventTypes); err != nil {
+		return webhook.Webhook{}, err
+	}
+
+	tx, err := w.DB.Begin(ctx)
+	if err != nil {
+		return webhook.Webhook{}, errors.Wrap(constants.ErrBeginTransaction)
+	}
+
+	_, err = w.WebhookRepository.GetOneWebhook(ctx, tx, id)
+	if err != nil {
+		_ = tx.Rollback()
+		return webhook.Webhook{}, err
+	}
+
+	err = w.WebhookRepository.UpdateWebhook(ctx, tx, id, models.Webhook{
+		URL:        req.URL,
+		Secret:     req.Secret,
+		EventTypes: strings.Join(req.EventTypes, ","),
+		IsActive:   req.IsActive,
+	})
+	if err != nil {
+		_ = tx.Rollback()
+		return webhook.Webhook{}, err
+	}
+
+	data, err := w.WebhookRepository.GetOneWebhook(ctx, tx, id)
+	if err != nil {
+		_ = tx.Rollback()
+		return webhook.Webhook{}, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		_ = tx.Rollback()
+		return webhook.Webhook{}, err
+	}
+
+	return toWebhook(data), nil
+}
+
+func (w webhookService) DeleteWebhook(ctx context.Context, id int) error {
+	tx, err := w.DB.Begin(ctx)
+	if err != nil {
+		return errors.Wrap(constants.ErrBeginTransaction)
+	}
+
+	data, err := w.WebhookRepository.GetOneWebhook(ctx, tx, id)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	err = w.WebhookRepository.DeleteWebhook(ctx, tx, data.WebhookID)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+func (w webhookService) GetDeadDelivery(ctx context.Context) ([]webhook.Delivery, error) {
+	tmpDeliveryData := []webhook.Delivery{}
+
+	data, err := w.WebhookRepository.GetDeliveryByStatus(ctx, constants.DeliveryStatusDead)
+	if err != nil {
+		return tmpDeliveryData, err
+	}
+
+	for _, x := range data {
+		tmpDeliveryData = append(tmpDeliveryData, toDelivery(x))
+	}
+
+	return tmpDeliveryData, nil
+}
+
+func (w webhookService) RedeliverDelivery(ctx context.Context, id int) (webhook.Delivery, error) {
+	tx, err := w.DB.Begin(ctx)
+	if err != nil {
+		return webhook.Delivery{}, errors.Wrap(constants.ErrBeginTransaction)
+	}
+
+	data, err := w.WebhookRepository.GetOneDelivery(ctx, tx, id)
+	if err != nil {
+		_ = tx.Rollback()
+		return webhook.Delivery{}, err
+	}
+
+	// a delivery still retried or delivered would be sent twice
+	if data.Status != constants.DeliveryStatusDead {
+		_ = tx.Rollback()
+		return webhook.Delivery{}, errors.Wrap(constants.ErrDeliveryNotDead)
+	}
+
+	// a redelivery starts a fresh retry cycle, the previous error is kept for reference
+	data.Status = constants.DeliveryStatusPending
+	data.Attempts = 0
+	data.NextAttemptAt = time.Now()
+
+	err = w.WebhookRepository.UpdateDelivery(ctx, tx, data)
+	if err != nil {
+		_ = tx.Rollback()
+		return webhook.Delivery{}, err
+	}
+
+	data, err = w.WebhookRepository.GetOneDelivery(ctx, tx, id)
+	if err != nil {
+		_ = tx.Rollback()
+		return webhook.Delivery{}, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		_ = tx.Rollback()
+		return webhook.Delivery{}, err
+	}
+
+	return toDelivery(data), nil
+}
+
+func validateWebhook(rawURL, secret string, eventTypes []string) error {
+	if rawURL == "" {
+		return errors.Wrap(constants.ErrURLCannotBeNull)
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.Wrap(constants.ErrURLInvalid)
+	}
+
+	if secret == "" {
+		return errors.Wrap(constants.ErrSecretCannotBeNull)
+	}
+
+	if len(eventTypes) == 0 {
+		return errors.Wrap(constants.ErrEventTypesCannotBeNull)
+	}
+
+	for _, eventType := range eventTypes {
+		if !isSupportedEventType(eventType) {
+			return errors.Wrap(utils.ErrEventTypeNotSupported(eventType))
+		}
+	}
+
+	return nil
+}
+
+func isSupportedEventType(eventType string) bool {
+	for _, x := range constants.EventTypes {
+		if x == eventType {
+			return true
+		}
+	}
+
+	return false
+}
+
+func toWebhook(data models.Webhook) webhook.Webhook {
+	return webhook.Webhook{
+		ID:         data.WebhookID,
+		URL:        data.URL,
+		EventTypes: strings.Split(data.EventTypes, ","),
+		IsActive:   data.IsActive,
+		CreatedAt:  data.CreatedAt.UTC().Format(constants.DateTimeFormat),
+		UpdatedAt:  data.UpdatedAt.UTC().Format(constants.DateTimeFormat),
+	}
+}
+
+func toDelivery(data models.WebhookDelivery) webhook.Delivery {
+	return webhook.Delivery{
+		ID:             data.DeliveryID,
+		WebhookID:      data.WebhookID,
+		EventID:        data.EventID,
+		EventType:      data.EventType,
+		Status:         data.Status,
+		Attempts:       data.Attempts,
+		NextAttemptAt:  data.NextAttemptAt.UTC().Format(constants.DateTimeFormat),
+		LastStatusCode: data.LastStatusCode,
+		LastError:      data.LastError,
+		CreatedAt:      data.CreatedAt.UTC().Format(constants.DateTimeFormat),
+		UpdatedAt:      data.UpdatedAt.UTC().Format(constants.DateTimeFormat),
+	}
+}
@@ -0,0 +1,23 @@
+package webhook
+
+import (
+	"context"
+	"todolist-api/infra/context/repository"
+	"todolist-api/objects/webhook"
+)
+
+type WebhookServiceInterface interface {
+	CreateWebhook(ctx context.Context, req webhook.CreateWebhook) (webhook.Webhook, error)
+	GetAllWebhook(ctx context.Context) ([]webhook.Webhook, error)
+	GetOneWebhook(ctx context.Context, id int) (webhook.Webhook, error)
+	UpdateWebhook(ctx context.Context, id int, req webhook.UpdateWebhook) (webhook.Webhook, error)
+	DeleteWebhook(ctx context.Context, id int) error
+	GetDeadDelivery(ctx context.Context) ([]webhook.Delivery, error)
+	RedeliverDelivery(ctx context.Context, id int) (webhook.Delivery, error)
+}
+
+func NewWebhookService(ctx *repository.RepoCtx) WebhookServiceInterface {
+	return &webhookService{
+		ctx,
+	}
+}
@@ -1,49 +1,317 @@
 package config
 
 import (
-	"log"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
 
+	log "github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
 )
 
-// ServerConfig struct to handle server configuration
+// ServerConfig struct to handle server configuration, timeouts in seconds
 type ServerConfig struct {
-	Addr            string
-	WriteTimeout    int
-	ReadTimeout     int
-	GraceFulTimeout int
+	Addr              string
+	GRPCAddr          string
+	AdminAddr         string
+	WriteTimeout      int
+	ReadTimeout       int
+	ReadHeaderTimeout int
+	IdleTimeout       int
+	GraceFulTimeout   int
 }
 
-// DBConfig struct to handle database configuration
+// DBConfig struct to handle database configuration. Host is the DSN of the
+// master, Replicas the DSNs of its read replicas.
 type DBConfig struct {
 	Name            string
 	Host            string
+	Replicas        []string
 	MaxOpenConn     int
 	MaxIdleConn     int
 	ConnMaxLifetime int
+	ConnMaxIdleTime int
+}
+
+// LogConfig struct to handle logging configuration. Format is json or text.
+type LogConfig struct {
+	Level  string
+	Format string
+}
+
+// CORSConfig struct to handle the origins allowed to call the API from a
+// browser, also checked on websocket upgrades. MaxAge in seconds.
+type CORSConfig struct {
+	AllowedOrigins   []string
+	AllowCredentials bool
+	MaxAge           int
+}
+
+// WebhookConfig struct to handle webhook delivery configuration
+type WebhookConfig struct {
+	PollInterval int
+	BatchSize    int
+	MaxAttempts  int
+	BackoffBase  int
+	BackoffMax   int
+	Timeout      int
+}
+
+// APIKeyConfig struct to handle an API key and the user it belongs to
+type APIKeyConfig struct {
+	Key   string
+	Email string
+	Admin bool
+}
+
+// AuthConfig struct to handle authentication configuration
+type AuthConfig struct {
+	Enabled bool
+	APIKeys []APIKeyConfig
+}
+
+// EventsConfig struct to handle live change stream configuration
+type EventsConfig struct {
+	BufferSize int
+	Heartbeat  int
+}
+
+// GraphQLConfig struct to handle GraphQL query limits
+type GraphQLConfig struct {
+	MaxDepth      int
+	MaxComplexity int
+}
+
+// TracingConfig struct to handle OpenTelemetry tracing configuration.
+// Exporter is one of otlp, stdout or none.
+type TracingConfig struct {
+	Exporter    string
+	Endpoint    string
+	Insecure    bool
+	SampleRatio float64
+}
+
+// HealthConfig struct to handle the probes. Timeout bounds each readiness
+// check, DrainDelay is how long /readyz reports not ready before the server
+// shuts down, both in seconds.
+type HealthConfig struct {
+	Timeout    int
+	DrainDelay int
+}
+
+// FeaturesConfig struct to handle the endpoints that can be switched off
+// while serving
+type FeaturesConfig struct {
+	GraphQL   bool
+	Websocket bool
+}
+
+// LimitConfig struct to handle a token bucket refilled with Rate tokens per
+// second up to Burst, unlimited when Rate is 0
+type LimitConfig struct {
+	Rate  float64
+	Burst int
+}
+
+// RouteLimitConfig struct to handle the limits of one route, replacing the
+// limits per API key, user and IP. Path is the route template without the
+// version prefix, such as /todo-items/{id}.
+type RouteLimitConfig struct {
+	Method string
+	Path   string
+	Rate   float64
+	Burst  int
+}
+
+// RedisConfig struct to handle a Redis connection, Prefix namespacing its keys
+type RedisConfig struct {
+	Addr     string
+	Password string
+	DB       int
+	Prefix   string
+}
+
+// RateLimitConfig struct to handle rate limiting. Store is memory or redis.
+// TrustForwardedFor takes the IP of the caller from X-Forwarded-For, to be set
+// behind a proxy only. TodoDailyQuota caps the todos a user creates per UTC
+// day, 0 being unlimited.
+type RateLimitConfig struct {
+	Enabled           bool
+	Store             string
+	Redis             RedisConfig
+	TrustForwardedFor bool
+	APIKey            LimitConfig
+	User              LimitConfig
+	IP                LimitConfig
+	Routes            []RouteLimitConfig
+	TodoDailyQuota    int
+}
+
+// IdempotencyConfig struct to handle the Idempotency-Key of the creations.
+// TTL is how long a key replays its response, CleanupInterval how often the
+// expired keys are deleted, both in seconds.
+type IdempotencyConfig struct {
+	TTL             int
+	CleanupInterval int
+}
+
+// BatchConfig struct to handle POST /batch, MaxRequests being the most
+// requests of a batch
+type BatchConfig struct {
+	MaxRequests int
+}
+
+// SyncConfig struct to handle the offline sync. ConflictPolicy settles the
+// fields changed by both a client and the server, last_writer_wins or
+// server_wins, PageSize is the most entities of a pull and MaxMutations the
+// most mutations of a push.
+type SyncConfig struct {
+	ConflictPolicy string
+	PageSize       int
+	MaxMutations   int
+}
+
+// ImportConfig struct to handle the imports, MaxSize being the largest file
+// in bytes
+type ImportConfig struct {
+	MaxSize int64
+}
+
+// CalendarConfig struct to handle the calendar feeds. Secret signs the tokens
+// of their URLs, the feeds being off without it.
+type CalendarConfig struct {
+	Secret string
+}
+
+// LegacyConfig struct to handle the retirement of the unversioned aliases of
+// /v1. Since and Sunset are dates such as 2023-06-01, the Sunset header being
+// left out while Sunset is empty.
+type LegacyConfig struct {
+	Since  string
+	Sunset string
+}
+
+// Dates return Since and Sunset, Sunset being zero while empty
+func (c LegacyConfig) Dates() (since, sunset time.Time, err error) {
+	if since, err = time.Parse(DateFormat, c.Since); err != nil {
+		return since, sunset, err
+	}
+	if c.Sunset != "" {
+		sunset, err = time.Parse(DateFormat, c.Sunset)
+	}
+
+	return since, sunset, err
+}
+
+// ClientConfig struct to handle the command line client. BaseURL is the
+// address of the server the commands talk to, APIKey the key they send and
+// Timeout the seconds a request may take.
+type ClientConfig struct {
+	BaseURL string
+	APIKey  string
+	Timeout int
 }
 
 // Config struct for .env.yml
 type Config struct {
-	Server ServerConfig
-	DB     DBConfig
+	Environment string
+	Server      ServerConfig
+	DB          DBConfig
+	Log         LogConfig
+	CORS        CORSConfig
+	Webhook     WebhookConfig
+	Auth        AuthConfig
+	Events      EventsConfig
+	GraphQL     GraphQLConfig
+	Tracing     TracingConfig
+	Health      HealthConfig
+	Features    FeaturesConfig
+	RateLimit   RateLimitConfig
+	Idempotency IdempotencyConfig
+	Batch       BatchConfig
+	Sync        SyncConfig
+	Import      ImportConfig
+	Calendar    CalendarConfig
+	Legacy      LegacyConfig
+	Client      ClientConfig
+}
+
+// IsProduction tell whether the config is the one of the production environment
+func (c Config) IsProduction() bool {
+	return c.Environment == EnvProduction
 }
 
-// InitConfig function to init configuration, returns Config struct
+// InitConfig function to init configuration, returns Config struct. It exits
+// listing every problem when the configuration can't be loaded or is invalid.
 func InitConfig() Config {
-	viper.SetConfigName(".env")
+	configuration, err := Load()
+	if err != nil {
+		log.Fatalf("invalid configuration: %v", err)
+	}
 
-	viper.AddConfigPath(".")
+	return configuration
+}
 
+// Load layer the defaults, the config file, the TODOLIST_* environment
+// variables then the bound flags, and validate the result
+func Load() (Config, error) {
 	var configuration Config
 
-	if err := viper.ReadInConfig(); err != nil {
-		log.Fatalf("Error reading config file, %s", err)
+	// every load starts from a viper of its own, the reloads running from the
+	// file watcher and the SIGHUP handler at the same time
+	v := viper.New()
+	setDefaults(v)
+
+	v.SetEnvPrefix(envPrefix)
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+
+	if err := bindFlags(v); err != nil {
+		return configuration, err
 	}
-	err := viper.Unmarshal(&configuration)
-	if err != nil {
-		log.Fatalf("unable to decode into struct, %v", err)
+
+	if file != "" {
+		v.SetConfigFile(file)
+	} else {
+		v.SetConfigName(".env")
+		v.AddConfigPath(".")
 	}
 
-	return configuration
+	if err := v.ReadInConfig(); err != nil {
+		// the file is optional unless named with --config
+		var notFound viper.ConfigFileNotFoundError
+		if file != "" || !errors.As(err, &notFound) {
+			return configuration, fmt.Errorf("reading config file: %w", err)
+		}
+	}
+	setFileUsed(v.ConfigFileUsed())
+
+	// db.conLifeTime was the documented key of db.connMaxLifetime. It only
+	// replaces the default, so the environment and the flags still win.
+	if v.InConfig("db.conLifeTime") && !v.InConfig("db.connMaxLifetime") {
+		log.Warn("config: db.conLifeTime is deprecated, use db.connMaxLifetime")
+		v.SetDefault("db.connMaxLifetime", v.Get("db.conLifeTime"))
+	}
+
+	if err := v.Unmarshal(&configuration); err != nil {
+		return configuration, fmt.Errorf("unable to decode into struct: %w", err)
+	}
+
+	return configuration, configuration.Validate()
+}
+
+// File return the config file read by Load, empty when none was found
+func File() string {
+	mtx.Lock()
+	defer mtx.Unlock()
+
+	return fileUsed
+}
+
+func setFileUsed(path string) {
+	mtx.Lock()
+	defer mtx.Unlock()
+
+	fileUsed = path
 }
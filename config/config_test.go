@@ -0,0 +1,90 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// useFile make Load read content from a config file for the rest of the test
+func useFile(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.yml")
+	writeFile(t, path, content)
+
+	previous := file
+	file = path
+	t.Cleanup(func() {
+		file = previous
+	})
+
+	return path
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadDeprecatedConnLifetime(t *testing.T) {
+	path := useFile(t, "db:\n  host: todolist@/todolist\n  conLifeTime: 30\n")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.DB.ConnMaxLifetime != 30 {
+		t.Fatalf("connMaxLifetime %d, expected the 30 of conLifeTime", cfg.DB.ConnMaxLifetime)
+	}
+
+	// the environment overrides the deprecated key
+	t.Setenv("TODOLIST_DB_CONNMAXLIFETIME", "60")
+	if cfg, _ := Load(); cfg.DB.ConnMaxLifetime != 60 {
+		t.Fatalf("connMaxLifetime %d, expected the 60 of the environment", cfg.DB.ConnMaxLifetime)
+	}
+	os.Unsetenv("TODOLIST_DB_CONNMAXLIFETIME")
+
+	// the new key wins over the old one
+	writeFile(t, path, "db:\n  host: todolist@/todolist\n  conLifeTime: 30\n  connMaxLifetime: 20\n")
+	if cfg, _ := Load(); cfg.DB.ConnMaxLifetime != 20 {
+		t.Fatalf("connMaxLifetime %d, expected 20", cfg.DB.ConnMaxLifetime)
+	}
+
+	// dropped from the file, the default is back
+	writeFile(t, path, "db:\n  host: todolist@/todolist\n")
+	if cfg, _ := Load(); cfg.DB.ConnMaxLifetime != defaults["db.connMaxLifetime"] {
+		t.Fatalf("connMaxLifetime %d, expected the default", cfg.DB.ConnMaxLifetime)
+	}
+}
+
+func TestLoadLegacy(t *testing.T) {
+	path := useFile(t, "db:\n  host: todolist@/todolist\nlegacy:\n  sunset: \"2024-01-31\"\n")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	since, sunset, err := cfg.Legacy.Dates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if since != time.Date(2023, time.June, 1, 0, 0, 0, 0, time.UTC) || sunset != time.Date(2024, time.January, 31, 0, 0, 0, 0, time.UTC) {
+		t.Fatalf("since %s and sunset %s", since, sunset)
+	}
+
+	for _, content := range []string{
+		"legacy:\n  since: 01/06/2023\n",
+		"legacy:\n  since: \"2023-06-01\"\n  sunset: \"2023-05-01\"\n",
+	} {
+		writeFile(t, path, "db:\n  host: todolist@/todolist\n"+content)
+		if _, err := Load(); err == nil || !strings.Contains(err.Error(), "legacy") {
+			t.Errorf("%q loaded with error %v", content, err)
+		}
+	}
+}
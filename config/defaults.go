@@ -0,0 +1,164 @@
+package config
+
+import (
+	"sync"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// envPrefix of the environment variables overriding the config file, such as
+// TODOLIST_SERVER_ADDR for server.addr
+const envPrefix = "TODOLIST"
+
+const (
+	// EnvDevelopment environment of a developer machine
+	EnvDevelopment = "development"
+	// EnvStaging environment mirroring production
+	EnvStaging = "staging"
+	// EnvProduction environment serving the users
+	EnvProduction = "production"
+)
+
+// DateFormat the layout of the dates of the config, such as 2023-06-01
+const DateFormat = "2006-01-02"
+
+// file the config file named with --config
+var file string
+
+var (
+	// mtx guard the flags and the file used, Load running concurrently
+	mtx sync.Mutex
+	// flags the command line flags overriding a key once set
+	flags = map[string]*pflag.Flag{}
+	// fileUsed the config file read by the last Load
+	fileUsed string
+)
+
+// defaults of every key, the environment variables only override known keys
+var defaults = map[string]interface{}{
+	"environment": EnvDevelopment,
+
+	"server.addr":              "localhost:3030",
+	"server.grpcAddr":          "",
+	"server.adminAddr":         "",
+	"server.writeTimeout":      30,
+	"server.readTimeout":       30,
+	"server.readHeaderTimeout": 10,
+	"server.idleTimeout":       60,
+	"server.gracefulTimeout":   30,
+
+	"db.name":            "mysql",
+	"db.host":            "",
+	"db.replicas":        []string{},
+	"db.maxOpenConn":     10,
+	"db.maxIdleConn":     10,
+	"db.connMaxLifetime": 10,
+	"db.connMaxIdleTime": 0,
+
+	"log.level":  "info",
+	"log.format": "text",
+
+	"cors.allowedOrigins":   []string{"http://localhost:3030"},
+	"cors.allowCredentials": true,
+	"cors.maxAge":           0,
+
+	"webhook.pollInterval": 5,
+	"webhook.batchSize":    50,
+	"webhook.maxAttempts":  8,
+	"webhook.backoffBase":  10,
+	"webhook.backoffMax":   3600,
+	"webhook.timeout":      10,
+
+	"auth.enabled": false,
+	"auth.apiKeys": []APIKeyConfig{},
+
+	"events.bufferSize": 1024,
+	"events.heartbeat":  15,
+
+	"graphql.maxDepth":      8,
+	"graphql.maxComplexity": 1000,
+
+	"tracing.exporter":    "none",
+	"tracing.endpoint":    "localhost:4317",
+	"tracing.insecure":    true,
+	"tracing.sampleRatio": 1,
+
+	"health.timeout":    2,
+	"health.drainDelay": 0,
+
+	"features.graphql":   true,
+	"features.websocket": true,
+
+	"rateLimit.enabled":           false,
+	"rateLimit.store":             "memory",
+	"rateLimit.redis.addr":        "localhost:6379",
+	"rateLimit.redis.password":    "",
+	"rateLimit.redis.db":          0,
+	"rateLimit.redis.prefix":      "todolist:ratelimit:",
+	"rateLimit.trustForwardedFor": false,
+	"rateLimit.apiKey.rate":       20,
+	"rateLimit.apiKey.burst":      40,
+	"rateLimit.user.rate":         50,
+	"rateLimit.user.burst":        100,
+	"rateLimit.ip.rate":           20,
+	"rateLimit.ip.burst":          40,
+	"rateLimit.routes":            []RouteLimitConfig{},
+	"rateLimit.todoDailyQuota":    0,
+
+	"idempotency.ttl":             86400,
+	"idempotency.cleanupInterval": 3600,
+
+	"batch.maxRequests": 50,
+
+	"sync.conflictPolicy": "last_writer_wins",
+	"sync.pageSize":       500,
+	"sync.maxMutations":   500,
+
+	"import.maxSize": 10485760,
+
+	"calendar.secret": "",
+
+	"legacy.since":  "2023-06-01",
+	"legacy.sunset": "",
+
+	"client.baseURL": "http://localhost:3030",
+	"client.apiKey":  "",
+	"client.timeout": 30,
+}
+
+func setDefaults(v *viper.Viper) {
+	for key, value := range defaults {
+		v.SetDefault(key, value)
+	}
+}
+
+// AddFlags register --config, the config file replacing ./.env.yml
+func AddFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&file, "config", "", "config file, ./.env.yml by default")
+}
+
+// BindFlag let flag override key once it is set on the command line
+func BindFlag(key string, flag *pflag.Flag) {
+	if flag == nil {
+		panic("config: no flag bound to " + key)
+	}
+
+	mtx.Lock()
+	defer mtx.Unlock()
+
+	flags[key] = flag
+}
+
+func bindFlags(v *viper.Viper) error {
+	mtx.Lock()
+	defer mtx.Unlock()
+
+	for key, flag := range flags {
+		if err := v.BindPFlag(key, flag); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
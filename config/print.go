@@ -0,0 +1,124 @@
+package config
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// redacted replace the secrets of a printed config
+const redacted = "******"
+
+// Redacted return a copy of c without its secrets: the API keys, the
+// passwords of the DSNs and of Redis, the secret of the calendar feeds and
+// the API key of the client
+func (c Config) Redacted() Config {
+	c.DB.Host = redactDSN(c.DB.Host)
+	c.DB.Replicas = append([]string(nil), c.DB.Replicas...)
+	for idx := range c.DB.Replicas {
+		c.DB.Replicas[idx] = redactDSN(c.DB.Replicas[idx])
+	}
+
+	if c.RateLimit.Redis.Password != "" {
+		c.RateLimit.Redis.Password = redacted
+	}
+
+	if c.Calendar.Secret != "" {
+		c.Calendar.Secret = redacted
+	}
+
+	if c.Client.APIKey != "" {
+		c.Client.APIKey = redacted
+	}
+
+	c.Auth.APIKeys = append([]APIKeyConfig(nil), c.Auth.APIKeys...)
+	for idx := range c.Auth.APIKeys {
+		c.Auth.APIKeys[idx].Key = redacted
+	}
+
+	return c
+}
+
+// redactDSN hide the password of a user:password@address DSN
+func redactDSN(dsn string) string {
+	at := strings.LastIndex(dsn, "@")
+	if at < 0 {
+		return dsn
+	}
+
+	colon := strings.Index(dsn[:at], ":")
+	if colon < 0 {
+		return dsn
+	}
+
+	return dsn[:colon+1] + redacted + dsn[at:]
+}
+
+// Print write c as YAML, with the keys of the config file
+func (c Config) Print(w io.Writer) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(node(reflect.ValueOf(c), "")); err != nil {
+		return err
+	}
+
+	return enc.Close()
+}
+
+// node build the YAML node of v found at path, naming the fields of structs
+// like the keys of the config file
+func node(v reflect.Value, path string) *yaml.Node {
+	switch v.Kind() {
+	case reflect.Struct:
+		n := &yaml.Node{Kind: yaml.MappingNode}
+		for i := 0; i < v.NumField(); i++ {
+			name := key(path, v.Type().Field(i).Name)
+			n.Content = append(n.Content,
+				&yaml.Node{Kind: yaml.ScalarNode, Value: name},
+				node(v.Field(i), strings.TrimPrefix(path+"."+name, ".")),
+			)
+		}
+		return n
+	case reflect.Slice:
+		n := &yaml.Node{Kind: yaml.SequenceNode}
+		for i := 0; i < v.Len(); i++ {
+			n.Content = append(n.Content, node(v.Index(i), ""))
+		}
+		return n
+	default:
+		n := &yaml.Node{}
+		_ = n.Encode(v.Interface())
+		return n
+	}
+}
+
+// key return the name in the defaults of the field found under path, or
+// lower its leading capitals, the acronym included: GRPCAddr is grpcAddr
+func key(path, field string) string {
+	full := strings.TrimPrefix(path+"."+field, ".")
+	for k := range defaults {
+		parts := strings.Split(k, ".")
+		for i := range parts {
+			if strings.EqualFold(strings.Join(parts[:i+1], "."), full) {
+				return parts[i]
+			}
+		}
+	}
+
+	runes := []rune(field)
+	for i := range runes {
+		if !unicode.IsUpper(runes[i]) {
+			break
+		}
+		// the last capital of an acronym starts the next word
+		if i > 0 && i+1 < len(runes) && unicode.IsLower(runes[i+1]) {
+			break
+		}
+		runes[i] = unicode.ToLower(runes[i])
+	}
+
+	return string(runes)
+}
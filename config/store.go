@@ -0,0 +1,172 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+
+	"github.com/fsnotify/fsnotify"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// liveKeys the settings applied without restart, a key covers the keys it
+// prefixes
+var liveKeys = []string{
+	"log",
+	"cors",
+	"db.maxOpenConn",
+	"db.maxIdleConn",
+	"db.connMaxLifetime",
+	"db.connMaxIdleTime",
+	"features",
+	"rateLimit.enabled",
+	"rateLimit.trustForwardedFor",
+	"rateLimit.apiKey",
+	"rateLimit.user",
+	"rateLimit.ip",
+	"rateLimit.routes",
+	"rateLimit.todoDailyQuota",
+	"idempotency.ttl",
+	"batch",
+	"sync",
+	"import",
+	"calendar",
+	"client",
+}
+
+// Store hold the current config, replaced as a whole on reload
+type Store struct {
+	mtx       sync.Mutex
+	current   atomic.Pointer[Config]
+	listeners []func(old, cfg Config)
+}
+
+// NewStore return a store of cfg, the config loaded at startup
+func NewStore(cfg Config) *Store {
+	s := &Store{}
+	s.current.Store(&cfg)
+
+	return s
+}
+
+// Get return the current config
+func (s *Store) Get() Config {
+	return *s.current.Load()
+}
+
+// OnChange call fn with the previous and the new config after each reload
+// changing a setting, to be registered before Watch
+func (s *Store) OnChange(fn func(old, cfg Config)) {
+	s.listeners = append(s.listeners, fn)
+}
+
+// Reload load the config again and replace the current one. The new config
+// is rejected when invalid or when it changes a setting needing a restart.
+func (s *Store) Reload() error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	cfg, err := Load()
+	if err != nil {
+		return err
+	}
+
+	old := s.Get()
+	changed := Diff(old, cfg)
+	if len(changed) == 0 {
+		return nil
+	}
+
+	var restart []string
+	for _, key := range changed {
+		if !live(key) {
+			restart = append(restart, key)
+		}
+	}
+	if len(restart) > 0 {
+		return fmt.Errorf("restart required to change %s", strings.Join(restart, ", "))
+	}
+
+	s.current.Store(&cfg)
+	for _, fn := range s.listeners {
+		fn(old, cfg)
+	}
+	log.Infof("config reloaded, changed %s", strings.Join(changed, ", "))
+
+	return nil
+}
+
+// Watch reload the config when the file changes or on SIGHUP, until ctx is
+// done
+func (s *Store) Watch(ctx context.Context) {
+	reload := func(trigger string) {
+		if err := s.Reload(); err != nil {
+			log.Errorf("config reload on %s rejected: %v", trigger, err)
+		}
+	}
+
+	// the watcher reads the file on its own viper, every reload loading a
+	// fresh one
+	if path := File(); path != "" {
+		w := viper.New()
+		w.SetConfigFile(path)
+		w.OnConfigChange(func(e fsnotify.Event) {
+			reload(e.Name + " " + strings.ToLower(e.Op.String()))
+		})
+		w.WatchConfig()
+	}
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			reload("SIGHUP")
+		}
+	}
+}
+
+// live tell whether key is applied without restart
+func live(key string) bool {
+	for _, k := range liveKeys {
+		if key == k || strings.HasPrefix(key, k+".") || strings.HasPrefix(key, k+"[") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Diff list the keys whose setting differs between a and b, lists being
+// compared as a whole
+func Diff(a, b Config) []string {
+	return diff(reflect.ValueOf(a), reflect.ValueOf(b), "")
+}
+
+func diff(a, b reflect.Value, path string) []string {
+	if a.Kind() != reflect.Struct {
+		if reflect.DeepEqual(a.Interface(), b.Interface()) {
+			return nil
+		}
+		return []string{path}
+	}
+
+	var changed []string
+	for i := 0; i < a.NumField(); i++ {
+		name := strings.TrimPrefix(path+"."+key(path, a.Type().Field(i).Name), ".")
+		changed = append(changed, diff(a.Field(i), b.Field(i), name)...)
+	}
+
+	return changed
+}
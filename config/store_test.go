@@ -0,0 +1,68 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+)
+
+// replaceFile write content to path in one go, a concurrent load reading
+// either the previous or the new content
+func replaceFile(t *testing.T, path, content string) {
+	t.Helper()
+
+	writeFile(t, path+".tmp", content)
+	if err := os.Rename(path+".tmp", path); err != nil {
+		t.Error(err)
+	}
+}
+
+func withLevel(level string) string {
+	return fmt.Sprintf("db:\n  host: todolist@/todolist\nlog:\n  level: %s\n", level)
+}
+
+func TestReloadConcurrently(t *testing.T) {
+	path := useFile(t, withLevel("info"))
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewStore(cfg)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 25; j++ {
+				if err := s.Reload(); err != nil {
+					t.Error(err)
+				}
+				if _, err := Load(); err != nil {
+					t.Error(err)
+				}
+				_ = File()
+			}
+		}()
+	}
+
+	levels := []string{"debug", "warn", "error"}
+	for i := 0; i < 30; i++ {
+		replaceFile(t, path, withLevel(levels[i%len(levels)]))
+	}
+	wg.Wait()
+
+	replaceFile(t, path, withLevel("warn"))
+	if err := s.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if level := s.Get().Log.Level; level != "warn" {
+		t.Fatalf("level %q, expected the one of the last file", level)
+	}
+	if File() != path {
+		t.Fatalf("file %q, expected %q", File(), path)
+	}
+}
@@ -0,0 +1,168 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// ValidationError list every invalid setting of a config
+type ValidationError []string
+
+func (e ValidationError) Error() string {
+	return "\n  - " + strings.Join(e, "\n  - ")
+}
+
+// Validate check the settings, reporting all the invalid ones at once
+func (c Config) Validate() error {
+	var errs ValidationError
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Sprintf(format, args...))
+		}
+	}
+
+	check(oneOf(c.Environment, EnvDevelopment, EnvStaging, EnvProduction),
+		"environment: %q is not one of %s, %s or %s", c.Environment, EnvDevelopment, EnvStaging, EnvProduction)
+
+	check(c.Server.Addr != "", "server.addr: required")
+	check(c.Server.WriteTimeout >= 0, "server.writeTimeout: must not be negative")
+	check(c.Server.ReadTimeout >= 0, "server.readTimeout: must not be negative")
+	check(c.Server.ReadHeaderTimeout >= 0, "server.readHeaderTimeout: must not be negative")
+	check(c.Server.IdleTimeout >= 0, "server.idleTimeout: must not be negative")
+	check(c.Server.GraceFulTimeout > 0, "server.gracefulTimeout: must be positive")
+
+	check(c.DB.Name != "", "db.name: required")
+	check(c.DB.Host != "", "db.host: the DSN of the master is required")
+	for idx, dsn := range c.DB.Replicas {
+		check(dsn != "", "db.replicas[%d]: empty DSN", idx)
+	}
+	check(c.DB.MaxOpenConn >= 0, "db.maxOpenConn: must not be negative, 0 is unlimited")
+	check(c.DB.MaxOpenConn == 0 || c.DB.MaxIdleConn <= c.DB.MaxOpenConn,
+		"db.maxIdleConn: %d exceeds db.maxOpenConn %d", c.DB.MaxIdleConn, c.DB.MaxOpenConn)
+	check(c.DB.ConnMaxLifetime >= 0, "db.connMaxLifetime: must not be negative")
+	check(c.DB.ConnMaxIdleTime >= 0, "db.connMaxIdleTime: must not be negative")
+
+	_, err := log.ParseLevel(c.Log.Level)
+	check(err == nil, "log.level: %q is not a level, such as debug, info, warn or error", c.Log.Level)
+	check(oneOf(c.Log.Format, "json", "text"), "log.format: %q is not one of json or text", c.Log.Format)
+
+	for idx, origin := range c.CORS.AllowedOrigins {
+		check(validOrigin(origin), "cors.allowedOrigins[%d]: %q is not * or a scheme://host[:port] origin", idx, origin)
+	}
+	check(c.CORS.MaxAge >= 0, "cors.maxAge: must not be negative")
+
+	check(c.Webhook.PollInterval > 0, "webhook.pollInterval: must be positive")
+	check(c.Webhook.BatchSize > 0, "webhook.batchSize: must be positive")
+	check(c.Webhook.MaxAttempts > 0, "webhook.maxAttempts: must be positive")
+	check(c.Webhook.BackoffBase > 0, "webhook.backoffBase: must be positive")
+	check(c.Webhook.BackoffMax >= c.Webhook.BackoffBase, "webhook.backoffMax: must be at least webhook.backoffBase")
+	check(c.Webhook.Timeout > 0, "webhook.timeout: must be positive")
+
+	check(!c.Auth.Enabled || len(c.Auth.APIKeys) > 0, "auth.apiKeys: at least one key is required when auth is enabled")
+	keys := map[string]bool{}
+	for idx, k := range c.Auth.APIKeys {
+		check(k.Key != "", "auth.apiKeys[%d].key: required", idx)
+		check(!keys[k.Key], "auth.apiKeys[%d].key: duplicated", idx)
+		check(k.Email != "", "auth.apiKeys[%d].email: required", idx)
+		keys[k.Key] = true
+	}
+
+	check(c.Events.BufferSize > 0, "events.bufferSize: must be positive")
+	check(c.Events.Heartbeat >= 0, "events.heartbeat: must not be negative")
+
+	check(c.GraphQL.MaxDepth >= 0, "graphql.maxDepth: must not be negative, 0 is unlimited")
+	check(c.GraphQL.MaxComplexity >= 0, "graphql.maxComplexity: must not be negative, 0 is unlimited")
+
+	check(oneOf(c.Tracing.Exporter, "", "none", "stdout", "otlp"), "tracing.exporter: %q is not one of none, stdout or otlp", c.Tracing.Exporter)
+	check(c.Tracing.Exporter != "otlp" || c.Tracing.Endpoint != "", "tracing.endpoint: required by the otlp exporter")
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sampleRatio: must be between 0 and 1")
+
+	check(oneOf(c.RateLimit.Store, "memory", "redis"), "rateLimit.store: %q is not one of memory or redis", c.RateLimit.Store)
+	check(c.RateLimit.Store != "redis" || c.RateLimit.Redis.Addr != "", "rateLimit.redis.addr: required by the redis store")
+	for name, l := range map[string]LimitConfig{"apiKey": c.RateLimit.APIKey, "user": c.RateLimit.User, "ip": c.RateLimit.IP} {
+		check(validLimit(l.Rate, l.Burst), "rateLimit.%s: rate and burst must not be negative, burst must be positive with a rate", name)
+	}
+	for idx, r := range c.RateLimit.Routes {
+		check(r.Method != "" && strings.HasPrefix(r.Path, "/"), "rateLimit.routes[%d]: method and path are required", idx)
+		check(validLimit(r.Rate, r.Burst), "rateLimit.routes[%d]: rate and burst must not be negative, burst must be positive with a rate", idx)
+	}
+	check(c.RateLimit.TodoDailyQuota >= 0, "rateLimit.todoDailyQuota: must not be negative, 0 is unlimited")
+
+	check(c.Health.Timeout >= 0, "health.timeout: must not be negative")
+	check(c.Health.DrainDelay >= 0, "health.drainDelay: must not be negative")
+
+	check(c.Idempotency.TTL > 0, "idempotency.ttl: must be positive")
+	check(c.Idempotency.CleanupInterval > 0, "idempotency.cleanupInterval: must be positive")
+
+	check(c.Batch.MaxRequests > 0, "batch.maxRequests: must be positive")
+
+	check(oneOf(c.Sync.ConflictPolicy, "last_writer_wins", "server_wins"),
+		"sync.conflictPolicy: %q is not one of last_writer_wins or server_wins", c.Sync.ConflictPolicy)
+	check(c.Sync.PageSize > 0, "sync.pageSize: must be positive")
+	check(c.Sync.MaxMutations > 0, "sync.maxMutations: must be positive")
+	check(c.Import.MaxSize > 0, "import.maxSize: must be positive")
+	check(c.Calendar.Secret == "" || len(c.Calendar.Secret) >= 32, "calendar.secret: must be at least 32 characters")
+
+	since, sunset, err := c.Legacy.Dates()
+	check(err == nil, "legacy: since and sunset must be dates such as 2023-06-01: %v", err)
+	check(err != nil || sunset.IsZero() || sunset.After(since), "legacy.sunset: must be after legacy.since")
+
+	if err := c.Client.Validate(); err != nil {
+		errs = append(errs, err.(ValidationError)...)
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// Validate check the settings of the client, the only ones the commands
+// talking to a server need
+func (c ClientConfig) Validate() error {
+	var errs ValidationError
+	if !validURL(c.BaseURL) {
+		errs = append(errs, fmt.Sprintf("client.baseURL: %q is not a http or https URL", c.BaseURL))
+	}
+	if c.Timeout <= 0 {
+		errs = append(errs, "client.timeout: must be positive")
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+func oneOf(value string, allowed ...string) bool {
+	for _, a := range allowed {
+		if value == a {
+			return true
+		}
+	}
+
+	return false
+}
+
+func validLimit(rate float64, burst int) bool {
+	return rate >= 0 && burst >= 0 && (rate == 0 || burst > 0)
+}
+
+func validOrigin(origin string) bool {
+	if origin == "*" {
+		return true
+	}
+
+	u, err := url.Parse(origin)
+	return err == nil && u.Scheme != "" && u.Host != "" && (u.Path == "" || u.Path == "/")
+}
+
+func validURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
@@ -0,0 +1,22 @@
+package constants
+
+const (
+	// FormatICS the iCalendar files of the calendar feeds
+	FormatICS = "ics"
+
+	// CalendarName the activity group of an iCalendar without X-WR-CALNAME
+	CalendarName = "Calendar"
+
+	// MaxRRuleLength the longest recurrence rule, the size of its column
+	MaxRRuleLength = 255
+)
+
+// CalendarPriorities the PRIORITY of the calendars, from 1 the highest to 9,
+// of each priority
+var CalendarPriorities = map[string]int{
+	"very-high": 1,
+	"high":      3,
+	"normal":    5,
+	"low":       7,
+	"very-low":  9,
+}
@@ -4,3 +4,21 @@ const (
 	DateTimeFormat = "2006-01-02T15:04:05.000Z"
 	Priority       = "very-high"
 )
+
+const (
+	BulkCreate        = "create"
+	BulkUpdate        = "update"
+	BulkDelete        = "delete"
+	BulkCompleteGroup = "complete_group"
+
+	// BulkModeAtomic apply every item or none
+	BulkModeAtomic = "atomic"
+	// BulkModeBestEffort apply the valid items and report the others
+	BulkModeBestEffort = "best_effort"
+
+	BulkItemOK    = "ok"
+	BulkItemError = "error"
+
+	// BulkMaxItems the most items of a bulk operation
+	BulkMaxItems = 1000
+)
@@ -3,10 +3,52 @@ package constants
 import "errors"
 
 const (
-	ErrDataNotFound = "Activity with ID %v Not Found"
+	ErrDataNotFound          = "Activity with ID %v Not Found"
+	ErrWebhookNotFound       = "Webhook with ID %v Not Found"
+	ErrDeliveryNotFound      = "Delivery with ID %v Not Found"
+	ErrEventTypeNotSupported = "event type %s is not supported"
 )
 
 var (
-	ErrTitleCannotBeNull = errors.New("title cannot be null")
-	ErrBeginTransaction  = errors.New("Failed To Begin Transaction")
+	ErrNotFound               = errors.New("not found")
+	ErrTitleCannotBeNull      = errors.New("title cannot be null")
+	ErrBeginTransaction       = errors.New("Failed To Begin Transaction")
+	ErrURLCannotBeNull        = errors.New("url cannot be null")
+	ErrURLInvalid             = errors.New("url must be an absolute http or https url")
+	ErrSecretCannotBeNull     = errors.New("secret cannot be null")
+	ErrEventTypesCannotBeNull = errors.New("event types cannot be null")
+	ErrDeliveryNotDead        = errors.New("only a dead delivery can be redelivered")
+	ErrQuotaExceeded          = errors.New("daily quota exceeded")
+	ErrIdempotencyKeyReused   = errors.New("idempotency key already used with a different request")
+	ErrIdempotencyKeyInUse    = errors.New("idempotency key in use by a request in progress")
+	ErrBulkOperationInvalid   = errors.New("operation must be one of create, update, delete or complete_group")
+	ErrBulkModeInvalid        = errors.New("mode must be atomic or best_effort")
+	ErrBulkEmpty              = errors.New("bulk operation has no item")
+	ErrBulkTooManyItems       = errors.New("bulk operation has too many items")
+	ErrBulkNothingToSet       = errors.New("set has no field to update")
+	ErrActivityGroupRequired  = errors.New("activity group id is required")
+	ErrSyncTokenInvalid       = errors.New("sync token is invalid")
+	ErrSyncEntityInvalid      = errors.New("entity must be activity or todo")
+	ErrSyncOperationInvalid   = errors.New("op must be one of create, update or delete")
+	ErrSyncFieldInvalid       = errors.New("field is unknown or has the wrong type")
+	ErrSyncTimestampRequired  = errors.New("timestamp is required")
+	ErrSyncClientIDUnknown    = errors.New("client id does not match an earlier create")
+	ErrSyncEmpty              = errors.New("sync has no mutation")
+	ErrSyncTooManyMutations   = errors.New("sync has too many mutations")
+	ErrFormatInvalid          = errors.New("format must be one of json, csv, md, todoist, trello or ics")
+	ErrExportFormatInvalid    = errors.New("format must be one of json, csv or md")
+	ErrImportTooLarge         = errors.New("import file is too large")
+	ErrImportInvalid          = errors.New("import file is invalid")
+	ErrDueAtInvalid           = errors.New("due_at must be a RFC 3339 date time, such as 2023-07-01T09:00:00Z")
+	ErrRRuleInvalid           = errors.New("rrule must be a recurrence rule, such as FREQ=WEEKLY;BYDAY=MO")
+	ErrRRuleWithoutDueAt      = errors.New("rrule requires due_at, the first occurrence")
+	ErrCalendarTokenInvalid   = errors.New("calendar token is invalid")
+	ErrCalendarDisabled       = errors.New("calendar feeds are not configured")
+	ErrArchiveFormat          = errors.New("file is not a todolist backup")
+	ErrArchiveVersion         = errors.New("backup version is not supported")
+	ErrArchiveCorrupt         = errors.New("backup is corrupt")
+	ErrArchiveTruncated       = errors.New("backup is truncated")
+	ErrArchiveSchema          = errors.New("backup schema differs from the database")
+	ErrRestoreNotEmpty        = errors.New("database is not empty")
+	ErrResetProduction        = errors.New("refusing to reset the database of the production environment")
 )
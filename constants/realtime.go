@@ -0,0 +1,16 @@
+package constants
+
+const (
+	CommandSubscribe   = "subscribe"
+	CommandUnsubscribe = "unsubscribe"
+	CommandCreate      = "create"
+	CommandUpdate      = "update"
+	CommandMove        = "move"
+	CommandPing        = "ping"
+
+	MessageAck      = "ack"
+	MessageError    = "error"
+	MessageEvent    = "event"
+	MessagePresence = "presence"
+	MessagePong     = "pong"
+)
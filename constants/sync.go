@@ -0,0 +1,47 @@
+package constants
+
+const (
+	// SyncActivity the entity of the activity groups in the change feed
+	SyncActivity = "activity"
+	// SyncTodo the entity of the todos in the change feed
+	SyncTodo = "todo"
+
+	// ChangeUpsert a change creating or updating an entity
+	ChangeUpsert = "upsert"
+	// ChangeDelete a change deleting an entity, kept as its tombstone
+	ChangeDelete = "delete"
+)
+
+// fields tracked per entity for the conflicts of the sync
+const (
+	FieldTitle           = "title"
+	FieldEmail           = "email"
+	FieldIsActive        = "is_active"
+	FieldPriority        = "priority"
+	FieldActivityGroupID = "activity_group_id"
+	FieldDueAt           = "due_at"
+	FieldRRule           = "rrule"
+)
+
+const (
+	SyncCreate = "create"
+	SyncUpdate = "update"
+	SyncDelete = "delete"
+
+	// SyncLastWriterWins keep the value written last, by the time of the
+	// client for its mutations
+	SyncLastWriterWins = "last_writer_wins"
+	// SyncServerWins keep the value of the server whenever it changed since
+	// the last pull of the client
+	SyncServerWins = "server_wins"
+
+	// SyncApplied every field of the mutation was written
+	SyncApplied = "applied"
+	// SyncConflict the server kept its value for some fields of the mutation
+	SyncConflict = "conflict"
+	// SyncRejected the mutation is invalid, nothing was written
+	SyncRejected = "rejected"
+
+	SyncResolutionClient = "client"
+	SyncResolutionServer = "server"
+)
@@ -0,0 +1,26 @@
+package constants
+
+const (
+	FormatJSON     = "json"
+	FormatCSV      = "csv"
+	FormatMarkdown = "md"
+	// FormatTodoist the CSV of a Todoist project, its sections being the
+	// activity groups
+	FormatTodoist = "todoist"
+	// FormatTrello the CSV of a Trello board, its lists being the activity
+	// groups
+	FormatTrello = "trello"
+
+	// ExportVersion the version of the JSON export
+	ExportVersion = 1
+
+	// TodoistInbox the activity group of the Todoist tasks outside of a section
+	TodoistInbox = "Inbox"
+
+	// MaxTitleLength the longest title of an activity group or a todo, the
+	// size of the columns
+	MaxTitleLength = 100
+)
+
+// Priorities the priorities of the todos, from the highest
+var Priorities = []string{"very-high", "high", "normal", "low", "very-low"}
@@ -0,0 +1,36 @@
+package constants
+
+const (
+	EventActivityCreated = "activity.created"
+	EventActivityUpdated = "activity.updated"
+	EventActivityDeleted = "activity.deleted"
+	EventTodoCreated     = "todo.created"
+	EventTodoUpdated     = "todo.updated"
+	EventTodoCompleted   = "todo.completed"
+	EventTodoMoved       = "todo.moved"
+	EventTodoDeleted     = "todo.deleted"
+
+	EventPresenceJoined = "presence.joined"
+	EventPresenceLeft   = "presence.left"
+
+	DeliveryStatusPending   = "pending"
+	DeliveryStatusDelivered = "delivered"
+	DeliveryStatusDead      = "dead"
+
+	WebhookSignatureHeader = "X-Todolist-Signature"
+	WebhookEventHeader     = "X-Todolist-Event"
+	WebhookDeliveryHeader  = "X-Todolist-Delivery"
+	WebhookTimestampHeader = "X-Todolist-Timestamp"
+)
+
+// EventTypes list every event type a webhook can subscribe to
+var EventTypes = []string{
+	EventActivityCreated,
+	EventActivityUpdated,
+	EventActivityDeleted,
+	EventTodoCreated,
+	EventTodoUpdated,
+	EventTodoCompleted,
+	EventTodoMoved,
+	EventTodoDeleted,
+}
@@ -9,3 +9,7 @@ type Activity struct {
 	UpdatedAt  time.Time `db:"updated_at"`
 	CreatedAt  time.Time `db:"created_at"`
 }
+
+type ActivityFilter struct {
+	Email string
+}
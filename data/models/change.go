@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// Change a write to an activity group or a todo in the change feed. Fields
+// are the fields it set, ChangedAt when they were set, the time of the client
+// for the mutations it uploads.
+type Change struct {
+	Seq       int64     `db:"seq"`
+	Entity    string    `db:"entity"`
+	EntityID  int       `db:"entity_id"`
+	Operation string    `db:"operation"`
+	Fields    []string  `db:"-"`
+	ChangedAt time.Time `db:"-"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// ChangeField the last change of a field of an entity
+type ChangeField struct {
+	Entity    string    `db:"entity"`
+	EntityID  int       `db:"entity_id"`
+	Field     string    `db:"field"`
+	Seq       int64     `db:"seq"`
+	ChangedAt time.Time `db:"changed_at"`
+}
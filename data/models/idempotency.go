@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+type IdempotencyKey struct {
+	IdempotencyKeyID int       `db:"id"`
+	Scope            string    `db:"scope"`
+	Key              string    `db:"idem_key"`
+	Fingerprint      string    `db:"fingerprint"`
+	Response         string    `db:"response"`
+	CreatedAt        time.Time `db:"created_at"`
+	ExpiresAt        time.Time `db:"expires_at"`
+}
@@ -3,11 +3,31 @@ package models
 import "time"
 
 type Todo struct {
-	TodoID          int       `db:"id"`
-	Title           string    `db:"title"`
-	ActivityGroupID int       `db:"activity_group_id"`
-	IsActive        bool      `db:"is_active"`
-	Priority        string    `db:"priority"`
-	UpdatedAt       time.Time `db:"updated_at"`
-	CreatedAt       time.Time `db:"created_at"`
+	TodoID          int        `db:"id"`
+	Title           string     `db:"title"`
+	ActivityGroupID int        `db:"activity_group_id"`
+	IsActive        bool       `db:"is_active"`
+	Priority        string     `db:"priority"`
+	DueAt           *time.Time `db:"due_at"`
+	RRule           *string    `db:"rrule"`
+	UpdatedAt       time.Time  `db:"updated_at"`
+	CreatedAt       time.Time  `db:"created_at"`
+}
+
+type TodoFilter struct {
+	ActivityGroupID int
+	IsActive        *bool
+	Priority        string
+}
+
+// TodoPatch the fields set on several todos at once, nil fields are kept
+type TodoPatch struct {
+	IsActive        *bool
+	Priority        *string
+	ActivityGroupID *int
+}
+
+type TodoStateCount struct {
+	IsActive bool `db:"is_active"`
+	Count    int  `db:"count"`
 }
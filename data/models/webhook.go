@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+type Webhook struct {
+	WebhookID  int       `db:"id"`
+	URL        string    `db:"url"`
+	Secret     string    `db:"secret"`
+	EventTypes string    `db:"event_types"`
+	IsActive   bool      `db:"is_active"`
+	UpdatedAt  time.Time `db:"updated_at"`
+	CreatedAt  time.Time `db:"created_at"`
+}
+
+type WebhookEvent struct {
+	EventID   int       `db:"id"`
+	EventType string    `db:"event_type"`
+	Payload   string    `db:"payload"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+type WebhookDelivery struct {
+	DeliveryID     int       `db:"id"`
+	WebhookID      int       `db:"webhook_id"`
+	EventID        int       `db:"event_id"`
+	EventType      string    `db:"event_type"`
+	Payload        string    `db:"payload"`
+	Status         string    `db:"status"`
+	Attempts       int       `db:"attempts"`
+	NextAttemptAt  time.Time `db:"next_attempt_at"`
+	LastStatusCode int       `db:"last_status_code"`
+	LastError      string    `db:"last_error"`
+	UpdatedAt      time.Time `db:"updated_at"`
+	CreatedAt      time.Time `db:"created_at"`
+}
@@ -37,12 +37,45 @@ func (a activityRepository) CreateActivity(ctx context.Context, tx *sqlx.Tx, dat
 	return data, nil
 }
 
-func (a activityRepository) GetAllActivity(ctx context.Context) ([]models.Activity, error) {
+func (a activityRepository) GetAllActivity(ctx context.Context, filter models.ActivityFilter) ([]models.Activity, error) {
+	query := queryGetAllActivity
+	args := []interface{}{}
+
+	if filter.Email != "" {
+		query += " WHERE email = ?"
+		args = append(args, filter.Email)
+	}
+
 	results := []models.Activity{}
 	err := a.db.Slave().SelectContext(
 		ctx,
 		&results,
-		queryGetAllActivity,
+		query,
+		args...,
+	)
+	if err != nil {
+		return results, err
+	}
+
+	return results, nil
+}
+
+func (a activityRepository) GetActivityByIDs(ctx context.Context, ids []int) ([]models.Activity, error) {
+	results := []models.Activity{}
+	if len(ids) == 0 {
+		return results, nil
+	}
+
+	query, args, err := sqlx.In(queryGetActivityByIDs, ids)
+	if err != nil {
+		return results, err
+	}
+
+	err = a.db.Slave().SelectContext(
+		ctx,
+		&results,
+		query,
+		args...,
 	)
 	if err != nil {
 		return results, err
@@ -70,6 +103,26 @@ func (a activityRepository) GetOneActivity(ctx context.Context, tx *sqlx.Tx, id
 	return results[0], nil
 }
 
+// GetOneActivityForUpdate load the activity group, locked until the end of tx
+func (a activityRepository) GetOneActivityForUpdate(ctx context.Context, tx *sqlx.Tx, id int) (models.Activity, error) {
+	results := []models.Activity{}
+	err := tx.SelectContext(
+		ctx,
+		&results,
+		queryGetOneActivityForUpdate,
+		id,
+	)
+	if err != nil {
+		return models.Activity{}, err
+	}
+
+	if len(results) == 0 {
+		return models.Activity{}, errors.Wrap(utils.ErrDataNotFound(id))
+	}
+
+	return results[0], nil
+}
+
 func (a activityRepository) UpdateActivity(ctx context.Context, tx *sqlx.Tx, id int, data models.Activity) error {
 	result, err := tx.ExecContext(
 		ctx,
@@ -102,3 +155,33 @@ func (a activityRepository) DeleteActivity(ctx context.Context, tx *sqlx.Tx, id
 
 	return nil
 }
+
+// SeedActivities insert the activities one by one with their own
+// timestamps, returning their ids in order. Like CreateTodos, each id is read
+// from its own statement, the rows of a multi-row INSERT not always getting
+// consecutive ones.
+func (a activityRepository) SeedActivities(ctx context.Context, tx *sqlx.Tx, data []models.Activity) ([]int, error) {
+	ids := make([]int, 0, len(data))
+	for _, x := range data {
+		result, err := tx.ExecContext(
+			ctx,
+			querySeedActivity,
+			x.Title,
+			x.Email,
+			x.CreatedAt,
+			x.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		id, err := result.LastInsertId()
+		if err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, int(id))
+	}
+
+	return ids, nil
+}
@@ -10,13 +10,19 @@ import (
 
 type ActivityRepositoryInterface interface {
 	CreateActivity(ctx context.Context, tx *sqlx.Tx, data models.Activity) (models.Activity, error)
-	GetAllActivity(ctx context.Context) ([]models.Activity, error)
+	GetAllActivity(ctx context.Context, filter models.ActivityFilter) ([]models.Activity, error)
+	GetActivityByIDs(ctx context.Context, ids []int) ([]models.Activity, error)
 	GetOneActivity(ctx context.Context, tx *sqlx.Tx, id int) (models.Activity, error)
+	GetOneActivityForUpdate(ctx context.Context, tx *sqlx.Tx, id int) (models.Activity, error)
 	UpdateActivity(ctx context.Context, tx *sqlx.Tx, id int, data models.Activity) error
 	DeleteActivity(ctx context.Context, tx *sqlx.Tx, id int) error
+
+	SeedActivities(ctx context.Context, tx *sqlx.Tx, data []models.Activity) ([]int, error)
 }
 
 func NewActivityRepository(db *db.DB) ActivityRepositoryInterface {
+	db.NameStatements("activity", statements)
+
 	return &activityRepository{
 		db,
 	}
@@ -0,0 +1,54 @@
+package activity_test
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	"todolist-api/config"
+	"todolist-api/data/models"
+	"todolist-api/data/repositories/activity"
+	"todolist-api/infra/db"
+	"todolist-api/infra/db/dbtest"
+)
+
+func TestSeedActivities(t *testing.T) {
+	// IDs given two by two, as with auto_increment_increment = 2
+	d := &dbtest.Driver{LastInsertID: 21, AutoIncrement: 2, RowsAffected: 1}
+	conn, err := db.Open(&config.DBConfig{Name: dbtest.Register(d), Host: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	ctx := context.Background()
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+
+	at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	ids, err := activity.NewActivityRepository(conn).SeedActivities(ctx, tx, []models.Activity{
+		{Title: "Home chores", Email: "alice@example.com", CreatedAt: at, UpdatedAt: at},
+		{Title: "Garden ideas", Email: "bruno@example.com", CreatedAt: at, UpdatedAt: at},
+		{Title: "Office backlog", Email: "chloe@example.com", CreatedAt: at, UpdatedAt: at},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ids, []int{21, 23, 25}) {
+		t.Fatalf("ids %v, expected the ones of each insert", ids)
+	}
+
+	inserts := 0
+	for _, query := range d.Queries() {
+		if strings.Contains(query, "INSERT INTO activities") {
+			inserts++
+		}
+	}
+	if inserts != 3 {
+		t.Fatalf("%d inserts, expected one per activity", inserts)
+	}
+}
@@ -15,6 +15,17 @@ const (
 	FROM activities
 	`
 
+	queryGetActivityByIDs = `
+	SELECT
+		activity_id as id,
+		title,
+		email,
+		updated_at,
+		created_at
+	FROM activities
+	WHERE activity_id IN (?)
+	`
+
 	queryGetOneActivity = `
 	SELECT
 		activity_id as id,
@@ -26,6 +37,18 @@ const (
 	WHERE activity_id = ?
 	`
 
+	queryGetOneActivityForUpdate = `
+	SELECT
+		activity_id as id,
+		title,
+		email,
+		updated_at,
+		created_at
+	FROM activities
+	WHERE activity_id = ?
+	FOR UPDATE
+	`
+
 	queryUpdateActivity = `
 	UPDATE activities
 	SET
@@ -37,4 +60,20 @@ const (
 	queryDeleteActivity = `
 	DELETE FROM activities WHERE activity_id = ?
 	`
+
+	querySeedActivity = `
+	INSERT INTO activities (title, email, created_at, updated_at) VALUES (?, ?, ?, ?)
+	`
 )
+
+// statements name the queries in the traces
+var statements = map[string]string{
+	"CreateActivity":          queryCreateActivity,
+	"GetAllActivity":          queryGetAllActivity,
+	"GetActivityByIDs":        queryGetActivityByIDs,
+	"GetOneActivity":          queryGetOneActivity,
+	"GetOneActivityForUpdate": queryGetOneActivityForUpdate,
+	"UpdateActivity":          queryUpdateActivity,
+	"DeleteActivity":          queryDeleteActivity,
+	"SeedActivity":            querySeedActivity,
+}
@@ -0,0 +1,254 @@
+package backup
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+	"todolist-api/constants"
+	"todolist-api/infra/archive"
+	"todolist-api/infra/db"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// restoreBatch the rows inserted by a statement
+const restoreBatch = 500
+
+type backupRepository struct {
+	db *db.DB
+}
+
+func (b backupRepository) Tables() []string {
+	names := make([]string, 0, len(tables))
+	for _, t := range tables {
+		names = append(names, t.name)
+	}
+
+	return names
+}
+
+// GetSchemaVersion return the version of the last migration applied, the
+// latest row of a version telling whether it is applied
+func (b backupRepository) GetSchemaVersion(ctx context.Context, tx *sqlx.Tx) (int64, error) {
+	rows, err := tx.QueryContext(ctx, queryGetMigrations)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	seen := map[int64]bool{}
+	var current int64
+	for rows.Next() {
+		var version int64
+		var isApplied bool
+		if err := rows.Scan(&version, &isApplied); err != nil {
+			return 0, err
+		}
+		if seen[version] {
+			continue
+		}
+		seen[version] = true
+		if isApplied && version > current {
+			current = version
+		}
+	}
+
+	return current, rows.Err()
+}
+
+func (b backupRepository) DumpTable(ctx context.Context, tx *sqlx.Tx, name string, fn func(row archive.Row) error) error {
+	t, err := lookup(name)
+	if err != nil {
+		return err
+	}
+
+	rows, err := tx.QueryContext(ctx, t.queryDump())
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	dest := make([]interface{}, len(t.columns))
+	for rows.Next() {
+		for i, c := range t.columns {
+			dest[i] = scanner(c.kind)
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return err
+		}
+
+		row := make(archive.Row, len(t.columns))
+		for i, c := range t.columns {
+			row[c.name] = scanned(dest[i])
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+func (b backupRepository) CountRows(ctx context.Context, tx *sqlx.Tx, name string) (int, error) {
+	t, err := lookup(name)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int
+	err = tx.GetContext(ctx, &count, t.queryCount())
+
+	return count, err
+}
+
+// DeleteSeeds delete the rows the migrations insert, for the ones of the
+// backup to replace them
+func (b backupRepository) DeleteSeeds(ctx context.Context, tx *sqlx.Tx) error {
+	for _, t := range tables {
+		if t.seed == "" {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, t.queryDelete()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RestoreRows insert the rows of a backup as they are, ids and timestamps
+// included, the columns missing from a row being NULL
+func (b backupRepository) RestoreRows(ctx context.Context, tx *sqlx.Tx, name string, rows []archive.Row) error {
+	t, err := lookup(name)
+	if err != nil {
+		return err
+	}
+
+	for start := 0; start < len(rows); start += restoreBatch {
+		end := start + restoreBatch
+		if end > len(rows) {
+			end = len(rows)
+		}
+
+		args := make([]interface{}, 0, (end-start)*len(t.columns))
+		for _, row := range rows[start:end] {
+			for _, c := range t.columns {
+				value, err := restored(c.kind, row[c.name])
+				if err != nil {
+					return fmt.Errorf("%w: %s.%s %v", constants.ErrArchiveCorrupt, t.name, c.name, err)
+				}
+				args = append(args, value)
+			}
+		}
+
+		if _, err := tx.ExecContext(ctx, t.queryInsert(end-start), args...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Truncate empty every table backed up and the idempotency keys, the ids
+// starting over, then insert the rows of the migrations again. TRUNCATE
+// commits on its own so it can't be part of a transaction.
+func (b backupRepository) Truncate(ctx context.Context) error {
+	for _, name := range truncated {
+		if _, err := b.db.ExecContext(ctx, queryTruncate(name)); err != nil {
+			return err
+		}
+	}
+
+	for _, t := range tables {
+		if _, err := b.db.ExecContext(ctx, queryTruncate(t.name)); err != nil {
+			return err
+		}
+		if t.seed != "" {
+			if _, err := b.db.ExecContext(ctx, t.seed); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// lookup the table backed up named name
+func lookup(name string) (table, error) {
+	for _, t := range tables {
+		if t.name == name {
+			return t, nil
+		}
+	}
+
+	return table{}, fmt.Errorf("%w: unknown table %s", constants.ErrArchiveCorrupt, name)
+}
+
+// scanner the destination of a column of kind, NULL allowed
+func scanner(kind string) interface{} {
+	switch kind {
+	case kindInt:
+		return &sql.NullInt64{}
+	case kindBool:
+		return &sql.NullBool{}
+	case kindTime:
+		return &sql.NullTime{}
+	default:
+		return &sql.NullString{}
+	}
+}
+
+// scanned the value of a destination of scanner, nil for NULL and UTC for
+// the timestamps
+func scanned(dest interface{}) interface{} {
+	switch v := dest.(type) {
+	case *sql.NullInt64:
+		if v.Valid {
+			return v.Int64
+		}
+	case *sql.NullBool:
+		if v.Valid {
+			return v.Bool
+		}
+	case *sql.NullTime:
+		if v.Valid {
+			return v.Time.UTC()
+		}
+	case *sql.NullString:
+		if v.Valid {
+			return v.String
+		}
+	}
+
+	return nil
+}
+
+// restored the argument of a column of kind from its value in a backup
+func restored(kind string, value interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	switch kind {
+	case kindInt:
+		if n, ok := value.(json.Number); ok {
+			return n.Int64()
+		}
+	case kindBool:
+		if b, ok := value.(bool); ok {
+			return b, nil
+		}
+	case kindTime:
+		if s, ok := value.(string); ok {
+			return time.Parse(time.RFC3339Nano, s)
+		}
+	default:
+		if s, ok := value.(string); ok {
+			return s, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%v is not a %s", value, kind)
+}
@@ -0,0 +1,29 @@
+package backup
+
+import (
+	"context"
+	"todolist-api/infra/archive"
+	"todolist-api/infra/db"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// BackupRepositoryInterface the rows of every table backed up, read and
+// written by column name so the backups don't depend on the SQL dialect
+type BackupRepositoryInterface interface {
+	Tables() []string
+	GetSchemaVersion(ctx context.Context, tx *sqlx.Tx) (int64, error)
+	DumpTable(ctx context.Context, tx *sqlx.Tx, table string, fn func(row archive.Row) error) error
+	CountRows(ctx context.Context, tx *sqlx.Tx, table string) (int, error)
+	DeleteSeeds(ctx context.Context, tx *sqlx.Tx) error
+	RestoreRows(ctx context.Context, tx *sqlx.Tx, table string, rows []archive.Row) error
+	Truncate(ctx context.Context) error
+}
+
+func NewBackupRepository(db *db.DB) BackupRepositoryInterface {
+	db.NameStatements("backup", statements)
+
+	return &backupRepository{
+		db,
+	}
+}
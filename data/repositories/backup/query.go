@@ -0,0 +1,209 @@
+package backup
+
+import (
+	"fmt"
+	"strings"
+)
+
+// kinds of the columns, how their values are read and written
+const (
+	kindInt    = "int"
+	kindString = "string"
+	kindBool   = "bool"
+	kindTime   = "time"
+)
+
+// column a column of a table backed up
+type column struct {
+	name string
+	kind string
+}
+
+// table a table backed up, in the order of restore. The rows of a table with
+// a seed are inserted by the migrations, replaced on restore and inserted
+// again by the seed once truncated.
+type table struct {
+	name    string
+	order   string
+	columns []column
+	seed    string
+}
+
+// tables every table backed up, the idempotency keys being left out as they
+// expire within a day
+var tables = []table{
+	{
+		name:  "activities",
+		order: "activity_id",
+		columns: []column{
+			{"activity_id", kindInt},
+			{"title", kindString},
+			{"email", kindString},
+			{"created_at", kindTime},
+			{"updated_at", kindTime},
+		},
+	},
+	{
+		name:  "todos",
+		order: "todo_id",
+		columns: []column{
+			{"todo_id", kindInt},
+			{"activity_group_id", kindInt},
+			{"title", kindString},
+			{"priority", kindString},
+			{"due_at", kindTime},
+			{"rrule", kindString},
+			{"is_active", kindBool},
+			{"created_at", kindTime},
+			{"updated_at", kindTime},
+		},
+	},
+	{
+		name:  "webhooks",
+		order: "webhook_id",
+		columns: []column{
+			{"webhook_id", kindInt},
+			{"url", kindString},
+			{"secret", kindString},
+			{"event_types", kindString},
+			{"is_active", kindBool},
+			{"created_at", kindTime},
+			{"updated_at", kindTime},
+		},
+	},
+	{
+		name:  "webhook_events",
+		order: "event_id",
+		columns: []column{
+			{"event_id", kindInt},
+			{"event_type", kindString},
+			{"payload", kindString},
+			{"processed_at", kindTime},
+			{"created_at", kindTime},
+		},
+	},
+	{
+		name:  "webhook_deliveries",
+		order: "delivery_id",
+		columns: []column{
+			{"delivery_id", kindInt},
+			{"webhook_id", kindInt},
+			{"event_id", kindInt},
+			{"event_type", kindString},
+			{"payload", kindString},
+			{"status", kindString},
+			{"attempts", kindInt},
+			{"next_attempt_at", kindTime},
+			{"last_status_code", kindInt},
+			{"last_error", kindString},
+			{"created_at", kindTime},
+			{"updated_at", kindTime},
+		},
+	},
+	{
+		name:  "changes",
+		order: "seq",
+		columns: []column{
+			{"seq", kindInt},
+			{"entity", kindString},
+			{"entity_id", kindInt},
+			{"operation", kindString},
+			{"created_at", kindTime},
+		},
+	},
+	{
+		name:  "change_fields",
+		order: "entity, entity_id, field",
+		columns: []column{
+			{"entity", kindString},
+			{"entity_id", kindInt},
+			{"field", kindString},
+			{"seq", kindInt},
+			{"changed_at", kindTime},
+		},
+	},
+	{
+		name:  "change_sequence",
+		order: "id",
+		columns: []column{
+			{"id", kindInt},
+			{"seq", kindInt},
+		},
+		seed: querySeedChangeSequence,
+	},
+}
+
+// truncated the tables emptied along with the ones backed up, as their rows
+// refer to them
+var truncated = []string{"idempotency_keys"}
+
+// queryGetMigrations list the goose history, latest first
+const queryGetMigrations = `
+	SELECT version_id, is_applied FROM goose_db_version ORDER BY id DESC
+	`
+
+// querySeedChangeSequence the row the migration of change_sequence inserts
+const querySeedChangeSequence = `
+	INSERT INTO change_sequence (id, seq) VALUES (1, 0)
+	`
+
+// queryTruncate empty the table named name, resetting its AUTO_INCREMENT
+func queryTruncate(name string) string {
+	return fmt.Sprintf("TRUNCATE TABLE %s", name)
+}
+
+// names the names of the columns of t
+func (t table) names() string {
+	names := make([]string, 0, len(t.columns))
+	for _, c := range t.columns {
+		names = append(names, c.name)
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// queryDump read every row of t in the order of its key
+func (t table) queryDump() string {
+	return fmt.Sprintf("SELECT %s FROM %s ORDER BY %s", t.names(), t.name, t.order)
+}
+
+// queryCount count the rows of t
+func (t table) queryCount() string {
+	return fmt.Sprintf("SELECT COUNT(*) FROM %s", t.name)
+}
+
+// queryDelete delete every row of t
+func (t table) queryDelete() string {
+	return fmt.Sprintf("DELETE FROM %s", t.name)
+}
+
+// queryInsert insert n rows into t
+func (t table) queryInsert(n int) string {
+	row := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(t.columns)), ", ") + ")"
+	rows := make([]string, n)
+	for i := range rows {
+		rows[i] = row
+	}
+
+	return fmt.Sprintf("INSERT INTO %s (%s) VALUES %s", t.name, t.names(), strings.Join(rows, ", "))
+}
+
+// statements name the queries in the traces
+var statements = func() map[string]string {
+	queries := map[string]string{
+		"GetMigrations":      queryGetMigrations,
+		"SeedChangeSequence": querySeedChangeSequence,
+	}
+	for _, name := range truncated {
+		queries["Truncate."+name] = queryTruncate(name)
+	}
+	for _, t := range tables {
+		queries["Dump."+t.name] = t.queryDump()
+		queries["Count."+t.name] = t.queryCount()
+		queries["Delete."+t.name] = t.queryDelete()
+		queries["Insert."+t.name] = t.queryInsert(1)
+		queries["Truncate."+t.name] = queryTruncate(t.name)
+	}
+
+	return queries
+}()
@@ -0,0 +1,257 @@
+package change
+
+import (
+	"context"
+	"strings"
+	"time"
+	"todolist-api/constants"
+	"todolist-api/data/models"
+	"todolist-api/infra/db"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type changeRepository struct {
+	db *db.DB
+}
+
+// RecordChange append the changes to the feed in order and move the clocks of
+// their fields, the fields of a deleted entity being dropped. The sequence is
+// locked until the end of tx, see Commit.
+func (c changeRepository) RecordChange(ctx context.Context, tx *sqlx.Tx, data ...models.Change) error {
+	if len(data) == 0 {
+		return nil
+	}
+
+	result, err := tx.ExecContext(
+		ctx,
+		queryNextSeq,
+		len(data),
+	)
+	if err != nil {
+		return err
+	}
+
+	// LAST_INSERT_ID(expr) hands back the new value of the sequence
+	last, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	rows := make([]string, 0, len(data))
+	args := make([]interface{}, 0, len(data)*5)
+	fields := []models.ChangeField{}
+	for i, x := range data {
+		seq := last - int64(len(data)) + int64(i) + 1
+		rows = append(rows, "(?, ?, ?, ?, ?)")
+		args = append(args, seq, x.Entity, x.EntityID, x.Operation, now)
+
+		if x.Operation == constants.ChangeDelete {
+			_, err = tx.ExecContext(
+				ctx,
+				queryDeleteChangeFields,
+				x.Entity,
+				x.EntityID,
+			)
+			if err != nil {
+				return err
+			}
+
+			// nor the fields set earlier in data
+			kept := fields[:0]
+			for _, f := range fields {
+				if f.Entity != x.Entity || f.EntityID != x.EntityID {
+					kept = append(kept, f)
+				}
+			}
+			fields = kept
+			continue
+		}
+
+		changedAt := x.ChangedAt
+		if changedAt.IsZero() {
+			changedAt = now
+		}
+		for _, f := range x.Fields {
+			fields = append(fields, models.ChangeField{
+				Entity:    x.Entity,
+				EntityID:  x.EntityID,
+				Field:     f,
+				Seq:       seq,
+				ChangedAt: changedAt,
+			})
+		}
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		queryCreateChanges+strings.Join(rows, ", "),
+		args...,
+	)
+	if err != nil {
+		return err
+	}
+
+	if len(fields) == 0 {
+		return nil
+	}
+
+	// the last change of a field listed twice wins
+	fieldRows := make([]string, 0, len(fields))
+	fieldArgs := make([]interface{}, 0, len(fields)*5)
+	for _, f := range fields {
+		fieldRows = append(fieldRows, "(?, ?, ?, ?, ?)")
+		fieldArgs = append(fieldArgs, f.Entity, f.EntityID, f.Field, f.Seq, f.ChangedAt)
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		queryUpsertChangeFields+strings.Join(fieldRows, ", ")+queryUpsertChangeFieldsOnDuplicate,
+		fieldArgs...,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Commit record the changes then commit tx. The sequence of the feed stays
+// locked from the allocation of the changes until the commit, so they are
+// recorded by the last statements of tx: the writes of concurrent
+// transactions only wait for each other's commit.
+func Commit(ctx context.Context, repo ChangeRepositoryInterface, tx *sqlx.Tx, data ...models.Change) error {
+	err := repo.RecordChange(ctx, tx, data...)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (c changeRepository) GetLastSeq(ctx context.Context, tx *sqlx.Tx) (int64, error) {
+	var seq int64
+	err := tx.GetContext(
+		ctx,
+		&seq,
+		queryGetLastSeq,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return seq, nil
+}
+
+func (c changeRepository) GetChangeSince(ctx context.Context, tx *sqlx.Tx, seq int64, limit int) ([]models.Change, error) {
+	results := []models.Change{}
+	err := tx.SelectContext(
+		ctx,
+		&results,
+		queryGetChangeSince,
+		seq,
+		limit,
+	)
+	if err != nil {
+		return results, err
+	}
+
+	return results, nil
+}
+
+func (c changeRepository) GetChangeField(ctx context.Context, tx *sqlx.Tx, entity string, id int) ([]models.ChangeField, error) {
+	results := []models.ChangeField{}
+	err := tx.SelectContext(
+		ctx,
+		&results,
+		queryGetChangeField,
+		entity,
+		id,
+	)
+	if err != nil {
+		return results, err
+	}
+
+	return results, nil
+}
+
+func (c changeRepository) GetActivityPage(ctx context.Context, tx *sqlx.Tx, afterID int, limit int) ([]models.Activity, error) {
+	results := []models.Activity{}
+	err := tx.SelectContext(
+		ctx,
+		&results,
+		queryGetActivityPage,
+		afterID,
+		limit,
+	)
+	if err != nil {
+		return results, err
+	}
+
+	return results, nil
+}
+
+func (c changeRepository) GetActivityByIDs(ctx context.Context, tx *sqlx.Tx, ids []int) ([]models.Activity, error) {
+	results := []models.Activity{}
+	if len(ids) == 0 {
+		return results, nil
+	}
+
+	query, args, err := sqlx.In(queryGetActivityByIDs, ids)
+	if err != nil {
+		return results, err
+	}
+
+	err = tx.SelectContext(
+		ctx,
+		&results,
+		query,
+		args...,
+	)
+	if err != nil {
+		return results, err
+	}
+
+	return results, nil
+}
+
+func (c changeRepository) GetTodoPage(ctx context.Context, tx *sqlx.Tx, afterID int, limit int) ([]models.Todo, error) {
+	results := []models.Todo{}
+	err := tx.SelectContext(
+		ctx,
+		&results,
+		queryGetTodoPage,
+		afterID,
+		limit,
+	)
+	if err != nil {
+		return results, err
+	}
+
+	return results, nil
+}
+
+func (c changeRepository) GetTodoByIDs(ctx context.Context, tx *sqlx.Tx, ids []int) ([]models.Todo, error) {
+	results := []models.Todo{}
+	if len(ids) == 0 {
+		return results, nil
+	}
+
+	query, args, err := sqlx.In(queryGetTodoByIDs, ids)
+	if err != nil {
+		return results, err
+	}
+
+	err = tx.SelectContext(
+		ctx,
+		&results,
+		query,
+		args...,
+	)
+	if err != nil {
+		return results, err
+	}
+
+	return results, nil
+}
@@ -0,0 +1,33 @@
+package change
+
+import (
+	"context"
+	"todolist-api/data/models"
+	"todolist-api/infra/db"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ChangeRepositoryInterface the change feed of the activity groups and the
+// todos. Its reads run in tx so the feed and the entities it lists come from
+// the same snapshot, but for GetChangeField whose clocks are locked until the
+// end of tx.
+type ChangeRepositoryInterface interface {
+	RecordChange(ctx context.Context, tx *sqlx.Tx, data ...models.Change) error
+	GetLastSeq(ctx context.Context, tx *sqlx.Tx) (int64, error)
+	GetChangeSince(ctx context.Context, tx *sqlx.Tx, seq int64, limit int) ([]models.Change, error)
+	GetChangeField(ctx context.Context, tx *sqlx.Tx, entity string, id int) ([]models.ChangeField, error)
+
+	GetActivityPage(ctx context.Context, tx *sqlx.Tx, afterID int, limit int) ([]models.Activity, error)
+	GetActivityByIDs(ctx context.Context, tx *sqlx.Tx, ids []int) ([]models.Activity, error)
+	GetTodoPage(ctx context.Context, tx *sqlx.Tx, afterID int, limit int) ([]models.Todo, error)
+	GetTodoByIDs(ctx context.Context, tx *sqlx.Tx, ids []int) ([]models.Todo, error)
+}
+
+func NewChangeRepository(db *db.DB) ChangeRepositoryInterface {
+	db.NameStatements("change", statements)
+
+	return &changeRepository{
+		db,
+	}
+}
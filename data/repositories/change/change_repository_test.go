@@ -0,0 +1,84 @@
+package change_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"todolist-api/config"
+	"todolist-api/constants"
+	"todolist-api/data/models"
+	"todolist-api/data/repositories/change"
+	"todolist-api/infra/db"
+	"todolist-api/infra/db/dbtest"
+)
+
+func TestCommit(t *testing.T) {
+	d := &dbtest.Driver{LastInsertID: 5, RowsAffected: 1}
+	conn, err := db.Open(&config.DBConfig{Name: dbtest.Register(d), Host: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	ctx := context.Background()
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a write of the transaction, running before the sequence is locked
+	if _, err := tx.ExecContext(ctx, "UPDATE todos SET title = ? WHERE todo_id = ?", "Buy milk", 7); err != nil {
+		t.Fatal(err)
+	}
+
+	err = change.Commit(ctx, change.NewChangeRepository(conn), tx,
+		models.Change{Entity: constants.SyncTodo, EntityID: 7, Operation: constants.ChangeUpsert, Fields: []string{constants.FieldTitle}},
+		models.Change{Entity: constants.SyncTodo, EntityID: 8, Operation: constants.ChangeDelete},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the sequence is taken by the last statements, right before the commit
+	expected := []string{
+		"UPDATE todos",
+		"UPDATE change_sequence",
+		"DELETE FROM change_fields",
+		"INSERT INTO changes",
+		"INSERT INTO change_fields",
+		"COMMIT",
+	}
+	queries := d.Queries()
+	if len(queries) != len(expected) {
+		t.Fatalf("%d statements, expected %d: %q", len(queries), len(expected), queries)
+	}
+	for i, query := range queries {
+		if !strings.Contains(query, expected[i]) {
+			t.Fatalf("statement %d: %q, expected %s", i, strings.TrimSpace(query), expected[i])
+		}
+	}
+}
+
+func TestCommitWithoutChange(t *testing.T) {
+	d := &dbtest.Driver{}
+	conn, err := db.Open(&config.DBConfig{Name: dbtest.Register(d), Host: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	ctx := context.Background()
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := change.Commit(ctx, change.NewChangeRepository(conn), tx); err != nil {
+		t.Fatal(err)
+	}
+
+	// the sequence is left alone
+	if queries := d.Queries(); len(queries) != 1 || queries[0] != "COMMIT" {
+		t.Fatalf("statements %q, expected the commit only", queries)
+	}
+}
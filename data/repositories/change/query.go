@@ -0,0 +1,130 @@
+package change
+
+const (
+	// the lock of the row stays until the end of the transaction, so the
+	// sequences are committed in order. It is taken right before the commit,
+	// see Commit.
+	queryNextSeq = `
+	UPDATE change_sequence SET seq = LAST_INSERT_ID(seq + ?) WHERE id = 1
+	`
+
+	queryGetLastSeq = `
+	SELECT seq FROM change_sequence WHERE id = 1
+	`
+
+	queryCreateChanges = `
+	INSERT INTO changes (seq, entity, entity_id, operation, created_at) VALUES
+	`
+
+	queryUpsertChangeFields = `
+	INSERT INTO change_fields (entity, entity_id, field, seq, changed_at) VALUES
+	`
+
+	queryUpsertChangeFieldsOnDuplicate = `
+	ON DUPLICATE KEY UPDATE seq = VALUES(seq), changed_at = VALUES(changed_at)
+	`
+
+	queryDeleteChangeFields = `
+	DELETE FROM change_fields WHERE entity = ? AND entity_id = ?
+	`
+
+	// the last change of each entity changed past the sequence
+	queryGetChangeSince = `
+	SELECT
+		c.seq,
+		c.entity,
+		c.entity_id,
+		c.operation,
+		c.created_at
+	FROM changes c
+	WHERE c.seq > ?
+	AND c.seq = (
+		SELECT MAX(l.seq) FROM changes l WHERE l.entity = c.entity AND l.entity_id = c.entity_id
+	)
+	ORDER BY c.seq
+	LIMIT ?
+	`
+
+	queryGetChangeField = `
+	SELECT
+		entity,
+		entity_id,
+		field,
+		seq,
+		changed_at
+	FROM change_fields
+	WHERE entity = ? AND entity_id = ?
+	FOR UPDATE
+	`
+
+	queryGetActivityPage = `
+	SELECT
+		activity_id as id,
+		title,
+		email,
+		updated_at,
+		created_at
+	FROM activities
+	WHERE activity_id > ?
+	ORDER BY activity_id
+	LIMIT ?
+	`
+
+	queryGetActivityByIDs = `
+	SELECT
+		activity_id as id,
+		title,
+		email,
+		updated_at,
+		created_at
+	FROM activities
+	WHERE activity_id IN (?)
+	`
+
+	queryGetTodoPage = `
+	SELECT
+		todo_id as id,
+		title,
+		activity_group_id,
+		is_active,
+		priority,
+		due_at,
+		rrule,
+		updated_at,
+		created_at
+	FROM todos
+	WHERE todo_id > ?
+	ORDER BY todo_id
+	LIMIT ?
+	`
+
+	queryGetTodoByIDs = `
+	SELECT
+		todo_id as id,
+		title,
+		activity_group_id,
+		is_active,
+		priority,
+		due_at,
+		rrule,
+		updated_at,
+		created_at
+	FROM todos
+	WHERE todo_id IN (?)
+	`
+)
+
+// statements name the queries in the traces
+var statements = map[string]string{
+	"NextSeq":            queryNextSeq,
+	"GetLastSeq":         queryGetLastSeq,
+	"CreateChanges":      queryCreateChanges,
+	"UpsertChangeFields": queryUpsertChangeFields,
+	"DeleteChangeFields": queryDeleteChangeFields,
+	"GetChangeSince":     queryGetChangeSince,
+	"GetChangeField":     queryGetChangeField,
+	"GetActivityPage":    queryGetActivityPage,
+	"GetActivityByIDs":   queryGetActivityByIDs,
+	"GetTodoPage":        queryGetTodoPage,
+	"GetTodoByIDs":       queryGetTodoByIDs,
+}
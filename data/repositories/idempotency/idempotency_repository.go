@@ -0,0 +1,93 @@
+package idempotency
+
+import (
+	"context"
+	"time"
+	"todolist-api/constants"
+	"todolist-api/data/models"
+	"todolist-api/infra/db"
+	"todolist-api/infra/errors"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
+)
+
+// errDuplicateEntry code of MySQL for a duplicated unique key
+const errDuplicateEntry = 1062
+
+type idempotencyRepository struct {
+	db *db.DB
+}
+
+func (i idempotencyRepository) CreateKey(ctx context.Context, tx *sqlx.Tx, data models.IdempotencyKey) error {
+	_, err := tx.ExecContext(
+		ctx,
+		queryCreateKey,
+		data.Scope,
+		data.Key,
+		data.Fingerprint,
+		data.Response,
+		data.ExpiresAt,
+	)
+	if err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == errDuplicateEntry {
+			return errors.Wrap(constants.ErrIdempotencyKeyInUse)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (i idempotencyRepository) GetOneKey(ctx context.Context, tx *sqlx.Tx, scope, key string) (models.IdempotencyKey, bool, error) {
+	results := []models.IdempotencyKey{}
+	err := tx.SelectContext(
+		ctx,
+		&results,
+		queryGetOneKey,
+		scope,
+		key,
+	)
+	if err != nil {
+		return models.IdempotencyKey{}, false, err
+	}
+
+	if len(results) == 0 {
+		return models.IdempotencyKey{}, false, nil
+	}
+
+	return results[0], true, nil
+}
+
+func (i idempotencyRepository) DeleteKey(ctx context.Context, tx *sqlx.Tx, id int) error {
+	_, err := tx.ExecContext(
+		ctx,
+		queryDeleteKey,
+		id,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (i idempotencyRepository) DeleteExpiredKey(ctx context.Context, now time.Time, limit int) (int, error) {
+	result, err := i.db.Master().ExecContext(
+		ctx,
+		queryDeleteExpiredKey,
+		now,
+		limit,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(n), nil
+}
@@ -0,0 +1,25 @@
+package idempotency
+
+import (
+	"context"
+	"time"
+	"todolist-api/data/models"
+	"todolist-api/infra/db"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type IdempotencyRepositoryInterface interface {
+	CreateKey(ctx context.Context, tx *sqlx.Tx, data models.IdempotencyKey) error
+	GetOneKey(ctx context.Context, tx *sqlx.Tx, scope, key string) (models.IdempotencyKey, bool, error)
+	DeleteKey(ctx context.Context, tx *sqlx.Tx, id int) error
+	DeleteExpiredKey(ctx context.Context, now time.Time, limit int) (int, error)
+}
+
+func NewIdempotencyRepository(db *db.DB) IdempotencyRepositoryInterface {
+	db.NameStatements("idempotency", statements)
+
+	return &idempotencyRepository{
+		db,
+	}
+}
@@ -0,0 +1,60 @@
+package idempotency_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"todolist-api/config"
+	"todolist-api/constants"
+	"todolist-api/data/models"
+	"todolist-api/data/repositories/idempotency"
+	"todolist-api/infra/db"
+	"todolist-api/infra/db/dbtest"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+func TestCreateKeyDuplicate(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		// expected the error of CreateKey
+		expected error
+	}{
+		{"duplicate entry", &mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'k1' for key 'uniq_scope_key'"}, constants.ErrIdempotencyKeyInUse},
+		{"other error of MySQL", &mysql.MySQLError{Number: 1406, Message: "Data too long for column 'idem_key'"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &dbtest.Driver{ExecErr: tt.err}
+			conn, err := db.Open(&config.DBConfig{Name: dbtest.Register(d), Host: "test"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+
+			ctx := context.Background()
+			tx, err := conn.Begin(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer tx.Rollback()
+
+			err = idempotency.NewIdempotencyRepository(conn).CreateKey(ctx, tx, models.IdempotencyKey{
+				Scope:     "POST /todo-items anonymous",
+				Key:       "k1",
+				ExpiresAt: time.Now().Add(time.Hour),
+			})
+
+			expected := tt.expected
+			if expected == nil {
+				expected = tt.err
+			}
+			if !errors.Is(err, expected) {
+				t.Fatalf("error %v, expected %v", err, expected)
+			}
+		})
+	}
+}
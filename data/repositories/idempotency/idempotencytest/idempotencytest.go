@@ -0,0 +1,101 @@
+// Package idempotencytest provide an idempotency repository kept in memory,
+// so the replay of the requests can be tested without a database.
+package idempotencytest
+
+import (
+	"context"
+	"sync"
+	"time"
+	"todolist-api/constants"
+	"todolist-api/data/models"
+	"todolist-api/data/repositories/idempotency"
+	"todolist-api/infra/errors"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Repository an in-memory idempotency.IdempotencyRepositoryInterface, the
+// transactions it is given being ignored. A key is unique within its scope,
+// like the unique index of the table.
+type Repository struct {
+	mtx    sync.Mutex
+	keys   map[int]models.IdempotencyKey
+	lastID int
+}
+
+var _ idempotency.IdempotencyRepositoryInterface = (*Repository)(nil)
+
+// New an empty repository
+func New() *Repository {
+	return &Repository{
+		keys: map[int]models.IdempotencyKey{},
+	}
+}
+
+// Keys the keys stored, by ID
+func (r *Repository) Keys() map[int]models.IdempotencyKey {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	res := make(map[int]models.IdempotencyKey, len(r.keys))
+	for id, x := range r.keys {
+		res[id] = x
+	}
+
+	return res
+}
+
+func (r *Repository) CreateKey(_ context.Context, _ *sqlx.Tx, data models.IdempotencyKey) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	for _, x := range r.keys {
+		if x.Scope == data.Scope && x.Key == data.Key {
+			return errors.Wrap(constants.ErrIdempotencyKeyInUse)
+		}
+	}
+
+	r.lastID++
+	data.IdempotencyKeyID = r.lastID
+	data.CreatedAt = time.Now()
+	r.keys[data.IdempotencyKeyID] = data
+
+	return nil
+}
+
+func (r *Repository) GetOneKey(_ context.Context, _ *sqlx.Tx, scope, key string) (models.IdempotencyKey, bool, error) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	for _, x := range r.keys {
+		if x.Scope == scope && x.Key == key {
+			return x, true, nil
+		}
+	}
+
+	return models.IdempotencyKey{}, false, nil
+}
+
+func (r *Repository) DeleteKey(_ context.Context, _ *sqlx.Tx, id int) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	delete(r.keys, id)
+
+	return nil
+}
+
+func (r *Repository) DeleteExpiredKey(_ context.Context, now time.Time, limit int) (int, error) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	n := 0
+	for id, x := range r.keys {
+		if n < limit && x.ExpiresAt.Before(now) {
+			delete(r.keys, id)
+			n++
+		}
+	}
+
+	return n, nil
+}
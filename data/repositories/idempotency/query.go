@@ -0,0 +1,36 @@
+package idempotency
+
+const (
+	queryCreateKey = `
+	INSERT INTO idempotency_keys (scope, idem_key, fingerprint, response, expires_at) VALUES (?, ?, ?, ?, ?)
+	`
+
+	queryGetOneKey = `
+	SELECT
+		idempotency_key_id as id,
+		scope,
+		idem_key,
+		fingerprint,
+		response,
+		created_at,
+		expires_at
+	FROM idempotency_keys
+	WHERE scope = ? AND idem_key = ?
+	`
+
+	queryDeleteKey = `
+	DELETE FROM idempotency_keys WHERE idempotency_key_id = ?
+	`
+
+	queryDeleteExpiredKey = `
+	DELETE FROM idempotency_keys WHERE expires_at <= ? LIMIT ?
+	`
+)
+
+// statements name the queries in the traces
+var statements = map[string]string{
+	"CreateKey":        queryCreateKey,
+	"GetOneKey":        queryGetOneKey,
+	"DeleteKey":        queryDeleteKey,
+	"DeleteExpiredKey": queryDeleteExpiredKey,
+}
@@ -2,7 +2,7 @@ package todo
 
 const (
 	queryCreateTodo = `
-	INSERT INTO todos (title, activity_group_id, is_active, priority, updated_at) VALUES (?, ?, ?, ?, ?)
+	INSERT INTO todos (title, activity_group_id, is_active, priority, due_at, rrule, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?)
 	`
 
 	queryGetAllTodo = `
@@ -12,11 +12,28 @@ const (
 		activity_group_id,
 		is_active,
 		priority,
+		due_at,
+		rrule,
 		updated_at,
 		created_at
 	FROM todos
 	`
 
+	queryGetTodoByActivityGroupIDs = `
+	SELECT
+		todo_id as id,
+		title,
+		activity_group_id,
+		is_active,
+		priority,
+		due_at,
+		rrule,
+		updated_at,
+		created_at
+	FROM todos
+	WHERE activity_group_id IN (?)
+	`
+
 	queryGetOneTodo = `
 	SELECT
 		todo_id as id,
@@ -24,6 +41,8 @@ const (
 		activity_group_id,
 		is_active,
 		priority,
+		due_at,
+		rrule,
 		updated_at,
 		created_at
 	FROM todos
@@ -36,6 +55,16 @@ const (
 		title = ?,
 		is_active = ?,
 		priority = ?,
+		due_at = ?,
+		rrule = ?,
+		updated_at = ?
+	WHERE todo_id = ?
+	`
+
+	queryMoveTodo = `
+	UPDATE todos
+	SET
+		activity_group_id = ?,
 		updated_at = ?
 	WHERE todo_id = ?
 	`
@@ -43,4 +72,86 @@ const (
 	queryDeleteTodo = `
 	DELETE FROM todos WHERE todo_id = ?
 	`
+
+	// queryCreateTodos is completed with a row of placeholders per todo
+	queryCreateTodos = `
+	INSERT INTO todos (title, activity_group_id, is_active, priority, due_at, rrule, batch_token, updated_at) VALUES
+	`
+
+	queryGetBatchTodoIDs = `
+	SELECT todo_id FROM todos WHERE batch_token = ? ORDER BY todo_id
+	`
+
+	// querySeedTodos is completed with a row of placeholders per todo
+	querySeedTodos = `
+	INSERT INTO todos (title, activity_group_id, is_active, priority, due_at, rrule, created_at, updated_at) VALUES
+	`
+
+	queryGetTodoByIDs = `
+	SELECT
+		todo_id as id,
+		title,
+		activity_group_id,
+		is_active,
+		priority,
+		due_at,
+		rrule,
+		updated_at,
+		created_at
+	FROM todos
+	WHERE todo_id IN (?)
+	ORDER BY todo_id
+	FOR UPDATE
+	`
+
+	queryGetActiveTodoByActivityGroupID = `
+	SELECT
+		todo_id as id,
+		title,
+		activity_group_id,
+		is_active,
+		priority,
+		due_at,
+		rrule,
+		updated_at,
+		created_at
+	FROM todos
+	WHERE activity_group_id = ? AND is_active = true
+	ORDER BY todo_id
+	FOR UPDATE
+	`
+
+	// queryUpdateTodos is completed with the fields to set and the WHERE clause
+	queryUpdateTodos = `
+	UPDATE todos
+	SET
+		updated_at = ?
+	`
+
+	queryDeleteTodos = `
+	DELETE FROM todos WHERE todo_id IN (?)
+	`
+
+	queryCountTodoByState = `
+	SELECT is_active, COUNT(*) AS count FROM todos GROUP BY is_active
+	`
 )
+
+// statements name the queries in the traces
+var statements = map[string]string{
+	"CreateTodo":                     queryCreateTodo,
+	"GetAllTodo":                     queryGetAllTodo,
+	"GetTodoByActivityGroupIDs":      queryGetTodoByActivityGroupIDs,
+	"GetOneTodo":                     queryGetOneTodo,
+	"UpdateTodo":                     queryUpdateTodo,
+	"MoveTodo":                       queryMoveTodo,
+	"DeleteTodo":                     queryDeleteTodo,
+	"CountTodoByState":               queryCountTodoByState,
+	"CreateTodos":                    queryCreateTodos,
+	"GetBatchTodoIDs":                queryGetBatchTodoIDs,
+	"GetTodoByIDs":                   queryGetTodoByIDs,
+	"GetActiveTodoByActivityGroupID": queryGetActiveTodoByActivityGroupID,
+	"UpdateTodos":                    queryUpdateTodos,
+	"DeleteTodos":                    queryDeleteTodos,
+	"SeedTodos":                      querySeedTodos,
+}
@@ -2,6 +2,10 @@ package todo
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
 	"time"
 	"todolist-api/data/models"
 	"todolist-api/infra/db"
@@ -23,6 +27,8 @@ func (t todoRepository) CreateTodo(ctx context.Context, tx *sqlx.Tx, data models
 		data.ActivityGroupID,
 		data.IsActive,
 		data.Priority,
+		data.DueAt,
+		data.RRule,
 		time.Now(),
 	)
 	if err != nil {
@@ -39,12 +45,60 @@ func (t todoRepository) CreateTodo(ctx context.Context, tx *sqlx.Tx, data models
 	return data, nil
 }
 
-func (t todoRepository) GetAllTodo(ctx context.Context) ([]models.Todo, error) {
+func (t todoRepository) GetAllTodo(ctx context.Context, filter models.TodoFilter) ([]models.Todo, error) {
+	conditions := []string{}
+	args := []interface{}{}
+
+	if filter.ActivityGroupID != 0 {
+		conditions = append(conditions, "activity_group_id = ?")
+		args = append(args, filter.ActivityGroupID)
+	}
+
+	if filter.IsActive != nil {
+		conditions = append(conditions, "is_active = ?")
+		args = append(args, *filter.IsActive)
+	}
+
+	if filter.Priority != "" {
+		conditions = append(conditions, "priority = ?")
+		args = append(args, filter.Priority)
+	}
+
+	query := queryGetAllTodo
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
 	results := []models.Todo{}
 	err := t.db.Slave().SelectContext(
 		ctx,
 		&results,
-		queryGetAllTodo,
+		query,
+		args...,
+	)
+	if err != nil {
+		return results, err
+	}
+
+	return results, nil
+}
+
+func (t todoRepository) GetTodoByActivityGroupIDs(ctx context.Context, ids []int) ([]models.Todo, error) {
+	results := []models.Todo{}
+	if len(ids) == 0 {
+		return results, nil
+	}
+
+	query, args, err := sqlx.In(queryGetTodoByActivityGroupIDs, ids)
+	if err != nil {
+		return results, err
+	}
+
+	err = t.db.Slave().SelectContext(
+		ctx,
+		&results,
+		query,
+		args...,
 	)
 	if err != nil {
 		return results, err
@@ -79,6 +133,8 @@ func (t todoRepository) UpdateTodo(ctx context.Context, tx *sqlx.Tx, id int, dat
 		data.Title,
 		data.IsActive,
 		data.Priority,
+		data.DueAt,
+		data.RRule,
 		time.Now(),
 		id,
 	)
@@ -94,6 +150,21 @@ func (t todoRepository) UpdateTodo(ctx context.Context, tx *sqlx.Tx, id int, dat
 	return nil
 }
 
+func (t todoRepository) MoveTodo(ctx context.Context, tx *sqlx.Tx, id int, activityGroupID int) error {
+	_, err := tx.ExecContext(
+		ctx,
+		queryMoveTodo,
+		activityGroupID,
+		time.Now(),
+		id,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (t todoRepository) DeleteTodo(ctx context.Context, tx *sqlx.Tx, id int) error {
 	_, err := tx.ExecContext(
 		ctx,
@@ -106,3 +177,203 @@ func (t todoRepository) DeleteTodo(ctx context.Context, tx *sqlx.Tx, id int) err
 
 	return nil
 }
+
+func (t todoRepository) CountTodoByState(ctx context.Context) ([]models.TodoStateCount, error) {
+	results := []models.TodoStateCount{}
+	err := t.db.Slave().SelectContext(ctx, &results, queryCountTodoByState)
+	if err != nil {
+		return results, err
+	}
+
+	return results, nil
+}
+
+// CreateTodos insert the todos with a single statement and return their IDs,
+// in order. The rows of a multi-row INSERT aren't given consecutive IDs with
+// interleaved auto-increment locks, Galera or an auto_increment_increment
+// above one, so they carry a token of the batch their IDs are read back by
+// within tx, rising in the order of the rows.
+func (t todoRepository) CreateTodos(ctx context.Context, tx *sqlx.Tx, data []models.Todo) ([]int, error) {
+	if len(data) == 0 {
+		return []int{}, nil
+	}
+
+	token, err := newBatchToken()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	rows := make([]string, 0, len(data))
+	args := make([]interface{}, 0, len(data)*8)
+	for _, x := range data {
+		rows = append(rows, "(?, ?, ?, ?, ?, ?, ?, ?)")
+		args = append(args, x.Title, x.ActivityGroupID, x.IsActive, x.Priority, x.DueAt, x.RRule, token, now)
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		queryCreateTodos+strings.Join(rows, ", "),
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := []int{}
+	err = tx.SelectContext(ctx, &ids, queryGetBatchTodoIDs, token)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ids) != len(data) {
+		return nil, fmt.Errorf("%d todos read back of the %d inserted", len(ids), len(data))
+	}
+
+	return ids, nil
+}
+
+// newBatchToken a random token telling the rows of one insert apart
+func newBatchToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+func (t todoRepository) GetTodoByIDs(ctx context.Context, tx *sqlx.Tx, ids []int) ([]models.Todo, error) {
+	results := []models.Todo{}
+	if len(ids) == 0 {
+		return results, nil
+	}
+
+	query, args, err := sqlx.In(queryGetTodoByIDs, ids)
+	if err != nil {
+		return results, err
+	}
+
+	err = tx.SelectContext(
+		ctx,
+		&results,
+		query,
+		args...,
+	)
+	if err != nil {
+		return results, err
+	}
+
+	return results, nil
+}
+
+func (t todoRepository) GetActiveTodoByActivityGroupID(ctx context.Context, tx *sqlx.Tx, activityGroupID int) ([]models.Todo, error) {
+	results := []models.Todo{}
+	err := tx.SelectContext(
+		ctx,
+		&results,
+		queryGetActiveTodoByActivityGroupID,
+		activityGroupID,
+	)
+	if err != nil {
+		return results, err
+	}
+
+	return results, nil
+}
+
+// UpdateTodos set the fields of the patch on the todos with a single
+// statement and return how many changed
+func (t todoRepository) UpdateTodos(ctx context.Context, tx *sqlx.Tx, ids []int, patch models.TodoPatch) (int, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	query := queryUpdateTodos
+	args := []interface{}{time.Now()}
+
+	if patch.IsActive != nil {
+		query += ", is_active = ?"
+		args = append(args, *patch.IsActive)
+	}
+
+	if patch.Priority != nil {
+		query += ", priority = ?"
+		args = append(args, *patch.Priority)
+	}
+
+	if patch.ActivityGroupID != nil {
+		query += ", activity_group_id = ?"
+		args = append(args, *patch.ActivityGroupID)
+	}
+
+	query, args, err := sqlx.In(query+" WHERE todo_id IN (?)", append(args, ids)...)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := tx.ExecContext(
+		ctx,
+		query,
+		args...,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(n), nil
+}
+
+func (t todoRepository) DeleteTodos(ctx context.Context, tx *sqlx.Tx, ids []int) (int, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	query, args, err := sqlx.In(queryDeleteTodos, ids)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := tx.ExecContext(
+		ctx,
+		query,
+		args...,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(n), nil
+}
+
+// SeedTodos insert the todos with their own timestamps
+func (t todoRepository) SeedTodos(ctx context.Context, tx *sqlx.Tx, data []models.Todo) error {
+	if len(data) == 0 {
+		return nil
+	}
+
+	rows := make([]string, 0, len(data))
+	args := make([]interface{}, 0, len(data)*8)
+	for _, x := range data {
+		rows = append(rows, "(?, ?, ?, ?, ?, ?, ?, ?)")
+		args = append(args, x.Title, x.ActivityGroupID, x.IsActive, x.Priority, x.DueAt, x.RRule, x.CreatedAt, x.UpdatedAt)
+	}
+
+	_, err := tx.ExecContext(
+		ctx,
+		querySeedTodos+strings.Join(rows, ", "),
+		args...,
+	)
+
+	return err
+}
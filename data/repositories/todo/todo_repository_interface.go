@@ -10,13 +10,26 @@ import (
 
 type TodoRepositoryInterface interface {
 	CreateTodo(ctx context.Context, tx *sqlx.Tx, data models.Todo) (models.Todo, error)
-	GetAllTodo(ctx context.Context) ([]models.Todo, error)
+	GetAllTodo(ctx context.Context, filter models.TodoFilter) ([]models.Todo, error)
+	GetTodoByActivityGroupIDs(ctx context.Context, ids []int) ([]models.Todo, error)
 	GetOneTodo(ctx context.Context, tx *sqlx.Tx, id int) (models.Todo, error)
 	UpdateTodo(ctx context.Context, tx *sqlx.Tx, id int, data models.Todo) error
+	MoveTodo(ctx context.Context, tx *sqlx.Tx, id int, activityGroupID int) error
 	DeleteTodo(ctx context.Context, tx *sqlx.Tx, id int) error
+	CountTodoByState(ctx context.Context) ([]models.TodoStateCount, error)
+
+	CreateTodos(ctx context.Context, tx *sqlx.Tx, data []models.Todo) ([]int, error)
+	GetTodoByIDs(ctx context.Context, tx *sqlx.Tx, ids []int) ([]models.Todo, error)
+	GetActiveTodoByActivityGroupID(ctx context.Context, tx *sqlx.Tx, activityGroupID int) ([]models.Todo, error)
+	UpdateTodos(ctx context.Context, tx *sqlx.Tx, ids []int, patch models.TodoPatch) (int, error)
+	DeleteTodos(ctx context.Context, tx *sqlx.Tx, ids []int) (int, error)
+
+	SeedTodos(ctx context.Context, tx *sqlx.Tx, data []models.Todo) error
 }
 
 func NewTodoRepository(db *db.DB) TodoRepositoryInterface {
+	db.NameStatements("todo", statements)
+
 	return &todoRepository{
 		db,
 	}
@@ -0,0 +1,70 @@
+package todo_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"reflect"
+	"strings"
+	"testing"
+	"todolist-api/config"
+	"todolist-api/data/models"
+	"todolist-api/data/repositories/todo"
+	"todolist-api/infra/db"
+	"todolist-api/infra/db/dbtest"
+)
+
+func TestCreateTodos(t *testing.T) {
+	// IDs given two by two, as with auto_increment_increment = 2, and read
+	// back by the token of the batch
+	d := &dbtest.Driver{
+		RowsAffected: 3,
+		Columns:      []string{"todo_id"},
+		Rows:         [][]driver.Value{{int64(11)}, {int64(13)}, {int64(15)}},
+	}
+	conn, err := db.Open(&config.DBConfig{Name: dbtest.Register(d), Host: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	ctx := context.Background()
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+
+	repo := todo.NewTodoRepository(conn)
+	ids, err := repo.CreateTodos(ctx, tx, []models.Todo{
+		{Title: "Water the plants", ActivityGroupID: 1, IsActive: true},
+		{Title: "Buy milk", ActivityGroupID: 1, IsActive: true},
+		{Title: "Call the plumber", ActivityGroupID: 2, IsActive: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ids, []int{11, 13, 15}) {
+		t.Fatalf("ids %v, expected the ones read back", ids)
+	}
+
+	// one insert of every row, then the read of their IDs
+	queries := d.Queries()
+	if len(queries) != 2 {
+		t.Fatalf("%d statements, expected 2: %q", len(queries), queries)
+	}
+	if !strings.Contains(queries[0], "INSERT INTO todos") || strings.Count(queries[0], "(?, ?, ?, ?, ?, ?, ?, ?)") != 3 {
+		t.Fatalf("statement %q, expected a row per todo", queries[0])
+	}
+	if !strings.Contains(queries[1], "WHERE batch_token = ? ORDER BY todo_id") {
+		t.Fatalf("statement %q, expected the read of the batch", queries[1])
+	}
+
+	// a row missing from the batch fails it
+	if _, err := repo.CreateTodos(ctx, tx, make([]models.Todo, 4)); err == nil {
+		t.Fatal("expected 3 IDs read back for 4 todos to fail")
+	}
+
+	if ids, err := repo.CreateTodos(ctx, tx, nil); err != nil || len(ids) != 0 {
+		t.Fatalf("no todo: %v, %v", ids, err)
+	}
+}
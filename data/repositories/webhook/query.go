@@ -0,0 +1,148 @@
+package webhook
+
+const (
+	queryCreateWebhook = `
+	INSERT INTO webhooks (url, secret, event_types, is_active, updated_at) VALUES (?, ?, ?, ?, ?)
+	`
+
+	queryGetAllWebhook = `
+	SELECT
+		webhook_id as id,
+		url,
+		secret,
+		event_types,
+		is_active,
+		updated_at,
+		created_at
+	FROM webhooks
+	`
+
+	queryGetActiveWebhook = `
+	SELECT
+		webhook_id as id,
+		url,
+		secret,
+		event_types,
+		is_active,
+		updated_at,
+		created_at
+	FROM webhooks
+	WHERE is_active = true
+	`
+
+	queryGetOneWebhook = `
+	SELECT
+		webhook_id as id,
+		url,
+		secret,
+		event_types,
+		is_active,
+		updated_at,
+		created_at
+	FROM webhooks
+	WHERE webhook_id = ?
+	`
+
+	queryUpdateWebhook = `
+	UPDATE webhooks
+	SET
+		url = ?,
+		secret = ?,
+		event_types = ?,
+		is_active = ?,
+		updated_at = ?
+	WHERE webhook_id = ?
+	`
+
+	queryDeleteWebhook = `
+	DELETE FROM webhooks WHERE webhook_id = ?
+	`
+
+	queryCreateEvent = `
+	INSERT INTO webhook_events (event_type, payload) VALUES (?, ?)
+	`
+
+	queryGetPendingEvent = `
+	SELECT
+		event_id as id,
+		event_type,
+		payload,
+		created_at
+	FROM webhook_events
+	WHERE processed_at IS NULL
+	ORDER BY event_id
+	LIMIT ?
+	FOR UPDATE SKIP LOCKED
+	`
+
+	queryMarkEventProcessed = `
+	UPDATE webhook_events SET processed_at = ? WHERE event_id = ?
+	`
+
+	queryCreateDelivery = `
+	INSERT INTO webhook_deliveries (webhook_id, event_id, event_type, payload, status, next_attempt_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+
+	selectDelivery = `
+	SELECT
+		delivery_id as id,
+		webhook_id,
+		event_id,
+		event_type,
+		payload,
+		status,
+		attempts,
+		next_attempt_at,
+		last_status_code,
+		last_error,
+		updated_at,
+		created_at
+	FROM webhook_deliveries
+	`
+
+	queryGetDueDelivery = selectDelivery + `
+	WHERE status = ? AND next_attempt_at <= ?
+	ORDER BY next_attempt_at
+	LIMIT ?
+	FOR UPDATE SKIP LOCKED
+	`
+
+	queryGetDeliveryByStatus = selectDelivery + `
+	WHERE status = ?
+	ORDER BY delivery_id DESC
+	`
+
+	queryGetOneDelivery = selectDelivery + `
+	WHERE delivery_id = ?
+	`
+
+	queryUpdateDelivery = `
+	UPDATE webhook_deliveries
+	SET
+		status = ?,
+		attempts = ?,
+		next_attempt_at = ?,
+		last_status_code = ?,
+		last_error = ?,
+		updated_at = ?
+	WHERE delivery_id = ?
+	`
+)
+
+// statements name the queries in the traces
+var statements = map[string]string{
+	"CreateWebhook":       queryCreateWebhook,
+	"GetAllWebhook":       queryGetAllWebhook,
+	"GetActiveWebhook":    queryGetActiveWebhook,
+	"GetOneWebhook":       queryGetOneWebhook,
+	"UpdateWebhook":       queryUpdateWebhook,
+	"DeleteWebhook":       queryDeleteWebhook,
+	"CreateEvent":         queryCreateEvent,
+	"GetPendingEvent":     queryGetPendingEvent,
+	"MarkEventProcessed":  queryMarkEventProcessed,
+	"CreateDelivery":      queryCreateDelivery,
+	"GetDueDelivery":      queryGetDueDelivery,
+	"GetDeliveryByStatus": queryGetDeliveryByStatus,
+	"GetOneDelivery":      queryGetOneDelivery,
+	"UpdateDelivery":      queryUpdateDelivery,
+}
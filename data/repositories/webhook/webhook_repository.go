@@ -0,0 +1,266 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+	"todolist-api/constants"
+	"todolist-api/data/models"
+	"todolist-api/infra/db"
+	"todolist-api/infra/errors"
+	"todolist-api/utils"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type webhookRepository struct {
+	db *db.DB
+}
+
+func (w webhookRepository) CreateWebhook(ctx context.Context, tx *sqlx.Tx, data models.Webhook) (models.Webhook, error) {
+	result, err := tx.ExecContext(
+		ctx,
+		queryCreateWebhook,
+		data.URL,
+		data.Secret,
+		data.EventTypes,
+		data.IsActive,
+		time.Now(),
+	)
+	if err != nil {
+		return models.Webhook{}, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return models.Webhook{}, err
+	}
+
+	data.WebhookID = int(id)
+
+	return data, nil
+}
+
+func (w webhookRepository) GetAllWebhook(ctx context.Context) ([]models.Webhook, error) {
+	results := []models.Webhook{}
+	err := w.db.Slave().SelectContext(
+		ctx,
+		&results,
+		queryGetAllWebhook,
+	)
+	if err != nil {
+		return results, err
+	}
+
+	return results, nil
+}
+
+func (w webhookRepository) GetActiveWebhook(ctx context.Context, tx *sqlx.Tx) ([]models.Webhook, error) {
+	results := []models.Webhook{}
+	err := tx.SelectContext(
+		ctx,
+		&results,
+		queryGetActiveWebhook,
+	)
+	if err != nil {
+		return results, err
+	}
+
+	return results, nil
+}
+
+func (w webhookRepository) GetOneWebhook(ctx context.Context, tx *sqlx.Tx, id int) (models.Webhook, error) {
+	results := []models.Webhook{}
+	err := tx.SelectContext(
+		ctx,
+		&results,
+		queryGetOneWebhook,
+		id,
+	)
+	if err != nil {
+		return models.Webhook{}, err
+	}
+
+	if len(results) == 0 {
+		return models.Webhook{}, errors.Wrap(utils.ErrWebhookNotFound(id))
+	}
+
+	return results[0], nil
+}
+
+func (w webhookRepository) UpdateWebhook(ctx context.Context, tx *sqlx.Tx, id int, data models.Webhook) error {
+	_, err := tx.ExecContext(
+		ctx,
+		queryUpdateWebhook,
+		data.URL,
+		data.Secret,
+		data.EventTypes,
+		data.IsActive,
+		time.Now(),
+		id,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (w webhookRepository) DeleteWebhook(ctx context.Context, tx *sqlx.Tx, id int) error {
+	_, err := tx.ExecContext(
+		ctx,
+		queryDeleteWebhook,
+		id,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// EnqueueEvent stores the event in the outbox using the caller transaction,
+// so the event is only visible to the delivery worker once the mutation commits.
+func (w webhookRepository) EnqueueEvent(ctx context.Context, tx *sqlx.Tx, eventType string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		queryCreateEvent,
+		eventType,
+		string(payload),
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (w webhookRepository) GetPendingEvent(ctx context.Context, tx *sqlx.Tx, limit int) ([]models.WebhookEvent, error) {
+	results := []models.WebhookEvent{}
+	err := tx.SelectContext(
+		ctx,
+		&results,
+		queryGetPendingEvent,
+		limit,
+	)
+	if err != nil {
+		return results, err
+	}
+
+	return results, nil
+}
+
+func (w webhookRepository) MarkEventProcessed(ctx context.Context, tx *sqlx.Tx, id int) error {
+	_, err := tx.ExecContext(
+		ctx,
+		queryMarkEventProcessed,
+		time.Now(),
+		id,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (w webhookRepository) CreateDelivery(ctx context.Context, tx *sqlx.Tx, data models.WebhookDelivery) (models.WebhookDelivery, error) {
+	result, err := tx.ExecContext(
+		ctx,
+		queryCreateDelivery,
+		data.WebhookID,
+		data.EventID,
+		data.EventType,
+		data.Payload,
+		data.Status,
+		data.NextAttemptAt,
+		time.Now(),
+	)
+	if err != nil {
+		return models.WebhookDelivery{}, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return models.WebhookDelivery{}, err
+	}
+
+	data.DeliveryID = int(id)
+
+	return data, nil
+}
+
+func (w webhookRepository) GetDueDelivery(ctx context.Context, tx *sqlx.Tx, now time.Time, limit int) ([]models.WebhookDelivery, error) {
+	results := []models.WebhookDelivery{}
+	err := tx.SelectContext(
+		ctx,
+		&results,
+		queryGetDueDelivery,
+		constants.DeliveryStatusPending,
+		now,
+		limit,
+	)
+	if err != nil {
+		return results, err
+	}
+
+	return results, nil
+}
+
+func (w webhookRepository) GetDeliveryByStatus(ctx context.Context, status string) ([]models.WebhookDelivery, error) {
+	results := []models.WebhookDelivery{}
+	err := w.db.Slave().SelectContext(
+		ctx,
+		&results,
+		queryGetDeliveryByStatus,
+		status,
+	)
+	if err != nil {
+		return results, err
+	}
+
+	return results, nil
+}
+
+func (w webhookRepository) GetOneDelivery(ctx context.Context, tx *sqlx.Tx, id int) (models.WebhookDelivery, error) {
+	results := []models.WebhookDelivery{}
+	err := tx.SelectContext(
+		ctx,
+		&results,
+		queryGetOneDelivery,
+		id,
+	)
+	if err != nil {
+		return models.WebhookDelivery{}, err
+	}
+
+	if len(results) == 0 {
+		return models.WebhookDelivery{}, errors.Wrap(utils.ErrDeliveryNotFound(id))
+	}
+
+	return results[0], nil
+}
+
+func (w webhookRepository) UpdateDelivery(ctx context.Context, tx *sqlx.Tx, data models.WebhookDelivery) error {
+	_, err := tx.ExecContext(
+		ctx,
+		queryUpdateDelivery,
+		data.Status,
+		data.Attempts,
+		data.NextAttemptAt,
+		data.LastStatusCode,
+		data.LastError,
+		time.Now(),
+		data.DeliveryID,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
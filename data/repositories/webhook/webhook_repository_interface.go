@@ -0,0 +1,37 @@
+package webhook
+
+import (
+	"context"
+	"time"
+	"todolist-api/data/models"
+	"todolist-api/infra/db"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type WebhookRepositoryInterface interface {
+	CreateWebhook(ctx context.Context, tx *sqlx.Tx, data models.Webhook) (models.Webhook, error)
+	GetAllWebhook(ctx context.Context) ([]models.Webhook, error)
+	GetActiveWebhook(ctx context.Context, tx *sqlx.Tx) ([]models.Webhook, error)
+	GetOneWebhook(ctx context.Context, tx *sqlx.Tx, id int) (models.Webhook, error)
+	UpdateWebhook(ctx context.Context, tx *sqlx.Tx, id int, data models.Webhook) error
+	DeleteWebhook(ctx context.Context, tx *sqlx.Tx, id int) error
+
+	EnqueueEvent(ctx context.Context, tx *sqlx.Tx, eventType string, data interface{}) error
+	GetPendingEvent(ctx context.Context, tx *sqlx.Tx, limit int) ([]models.WebhookEvent, error)
+	MarkEventProcessed(ctx context.Context, tx *sqlx.Tx, id int) error
+
+	CreateDelivery(ctx context.Context, tx *sqlx.Tx, data models.WebhookDelivery) (models.WebhookDelivery, error)
+	GetDueDelivery(ctx context.Context, tx *sqlx.Tx, now time.Time, limit int) ([]models.WebhookDelivery, error)
+	GetDeliveryByStatus(ctx context.Context, status string) ([]models.WebhookDelivery, error)
+	GetOneDelivery(ctx context.Context, tx *sqlx.Tx, id int) (models.WebhookDelivery, error)
+	UpdateDelivery(ctx context.Context, tx *sqlx.Tx, data models.WebhookDelivery) error
+}
+
+func NewWebhookRepository(db *db.DB) WebhookRepositoryInterface {
+	db.NameStatements("webhook", statements)
+
+	return &webhookRepository{
+		db,
+	}
+}
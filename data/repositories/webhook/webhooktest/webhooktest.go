@@ -0,0 +1,264 @@
+// Package webhooktest provide a webhook repository kept in memory, so the
+// outbox, the dispatcher and the delivery endpoints can be tested without a
+// database.
+package webhooktest
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+	"todolist-api/constants"
+	"todolist-api/data/models"
+	"todolist-api/data/repositories/webhook"
+	"todolist-api/infra/errors"
+	"todolist-api/utils"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Repository an in-memory webhook.WebhookRepositoryInterface, the
+// transactions it is given being ignored
+type Repository struct {
+	mtx        sync.Mutex
+	webhooks   map[int]models.Webhook
+	events     map[int]models.WebhookEvent
+	processed  map[int]bool
+	deliveries map[int]models.WebhookDelivery
+	lastID     int
+}
+
+var _ webhook.WebhookRepositoryInterface = (*Repository)(nil)
+
+// New an empty repository
+func New() *Repository {
+	return &Repository{
+		webhooks:   map[int]models.Webhook{},
+		events:     map[int]models.WebhookEvent{},
+		processed:  map[int]bool{},
+		deliveries: map[int]models.WebhookDelivery{},
+	}
+}
+
+func (r *Repository) nextID() int {
+	r.lastID++
+	return r.lastID
+}
+
+func (r *Repository) CreateWebhook(_ context.Context, _ *sqlx.Tx, data models.Webhook) (models.Webhook, error) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	data.WebhookID = r.nextID()
+	data.CreatedAt = time.Now()
+	data.UpdatedAt = data.CreatedAt
+	r.webhooks[data.WebhookID] = data
+
+	return data, nil
+}
+
+func (r *Repository) GetAllWebhook(context.Context) ([]models.Webhook, error) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	results := []models.Webhook{}
+	for _, id := range sortedKeys(r.webhooks) {
+		results = append(results, r.webhooks[id])
+	}
+
+	return results, nil
+}
+
+func (r *Repository) GetActiveWebhook(context.Context, *sqlx.Tx) ([]models.Webhook, error) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	results := []models.Webhook{}
+	for _, id := range sortedKeys(r.webhooks) {
+		if r.webhooks[id].IsActive {
+			results = append(results, r.webhooks[id])
+		}
+	}
+
+	return results, nil
+}
+
+func (r *Repository) GetOneWebhook(_ context.Context, _ *sqlx.Tx, id int) (models.Webhook, error) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	data, ok := r.webhooks[id]
+	if !ok {
+		return models.Webhook{}, errors.Wrap(utils.ErrWebhookNotFound(id))
+	}
+
+	return data, nil
+}
+
+func (r *Repository) UpdateWebhook(_ context.Context, _ *sqlx.Tx, id int, data models.Webhook) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	previous, ok := r.webhooks[id]
+	if !ok {
+		return errors.Wrap(utils.ErrWebhookNotFound(id))
+	}
+
+	data.WebhookID = id
+	data.CreatedAt = previous.CreatedAt
+	data.UpdatedAt = time.Now()
+	r.webhooks[id] = data
+
+	return nil
+}
+
+func (r *Repository) DeleteWebhook(_ context.Context, _ *sqlx.Tx, id int) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if _, ok := r.webhooks[id]; !ok {
+		return errors.Wrap(utils.ErrWebhookNotFound(id))
+	}
+	delete(r.webhooks, id)
+
+	return nil
+}
+
+func (r *Repository) EnqueueEvent(_ context.Context, _ *sqlx.Tx, eventType string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	id := r.nextID()
+	r.events[id] = models.WebhookEvent{
+		EventID:   id,
+		EventType: eventType,
+		Payload:   string(payload),
+		CreatedAt: time.Now(),
+	}
+
+	return nil
+}
+
+func (r *Repository) GetPendingEvent(_ context.Context, _ *sqlx.Tx, limit int) ([]models.WebhookEvent, error) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	results := []models.WebhookEvent{}
+	for _, id := range sortedKeys(r.events) {
+		if !r.processed[id] && len(results) < limit {
+			results = append(results, r.events[id])
+		}
+	}
+
+	return results, nil
+}
+
+func (r *Repository) MarkEventProcessed(_ context.Context, _ *sqlx.Tx, id int) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	r.processed[id] = true
+
+	return nil
+}
+
+func (r *Repository) CreateDelivery(_ context.Context, _ *sqlx.Tx, data models.WebhookDelivery) (models.WebhookDelivery, error) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	data.DeliveryID = r.nextID()
+	data.CreatedAt = time.Now()
+	data.UpdatedAt = data.CreatedAt
+	r.deliveries[data.DeliveryID] = data
+
+	return data, nil
+}
+
+func (r *Repository) GetDueDelivery(_ context.Context, _ *sqlx.Tx, now time.Time, limit int) ([]models.WebhookDelivery, error) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	results := []models.WebhookDelivery{}
+	for _, id := range sortedKeys(r.deliveries) {
+		data := r.deliveries[id]
+		if data.Status == constants.DeliveryStatusPending && !data.NextAttemptAt.After(now) && len(results) < limit {
+			results = append(results, data)
+		}
+	}
+
+	return results, nil
+}
+
+func (r *Repository) GetDeliveryByStatus(_ context.Context, status string) ([]models.WebhookDelivery, error) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	results := []models.WebhookDelivery{}
+	for _, id := range sortedKeys(r.deliveries) {
+		if status == "" || r.deliveries[id].Status == status {
+			results = append(results, r.deliveries[id])
+		}
+	}
+
+	return results, nil
+}
+
+func (r *Repository) GetOneDelivery(_ context.Context, _ *sqlx.Tx, id int) (models.WebhookDelivery, error) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	data, ok := r.deliveries[id]
+	if !ok {
+		return models.WebhookDelivery{}, errors.Wrap(utils.ErrDeliveryNotFound(id))
+	}
+
+	return data, nil
+}
+
+func (r *Repository) UpdateDelivery(_ context.Context, _ *sqlx.Tx, data models.WebhookDelivery) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	previous, ok := r.deliveries[data.DeliveryID]
+	if !ok {
+		return errors.Wrap(utils.ErrDeliveryNotFound(data.DeliveryID))
+	}
+
+	data.CreatedAt = previous.CreatedAt
+	data.UpdatedAt = time.Now()
+	r.deliveries[data.DeliveryID] = data
+
+	return nil
+}
+
+// Delivery the delivery id as stored, the zero value when missing
+func (r *Repository) Delivery(id int) models.WebhookDelivery {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	return r.deliveries[id]
+}
+
+// SetDelivery store data as is, to put a delivery in a given state
+func (r *Repository) SetDelivery(data models.WebhookDelivery) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	r.deliveries[data.DeliveryID] = data
+}
+
+func sortedKeys[T any](m map[int]T) []int {
+	keys := make([]int, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+
+	return keys
+}
@@ -0,0 +1,249 @@
+// Package archive read and write the backups of the database, independent of
+// its SQL dialect.
+//
+// A backup is a gzip compressed stream of JSON lines. The first line is the
+// Header, every following line holds a row of a table, and the last line is
+// the Trailer counting the rows of each table along with the SHA-256 of every
+// line before it. Timestamps are RFC 3339 strings and NULL is null.
+package archive
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"time"
+	"todolist-api/constants"
+)
+
+const (
+	// Format tell a backup from any other gzip file
+	Format = "todolist-backup"
+	// Version of the layout of the backups written
+	Version = 1
+)
+
+// Header the first line of a backup. Schema is the version of the last
+// migration applied to the database backed up.
+type Header struct {
+	Format    string    `json:"format"`
+	Version   int       `json:"version"`
+	Schema    int64     `json:"schema"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Row the columns of a row by name
+type Row map[string]interface{}
+
+// Trailer the last line of a backup, Rows being the number of rows of each
+// table and SHA256 the hex checksum of the lines before it
+type Trailer struct {
+	Rows   map[string]int `json:"rows"`
+	SHA256 string         `json:"sha256"`
+}
+
+// line a row of a table, or the trailer
+type line struct {
+	Table   string   `json:"table,omitempty"`
+	Row     Row      `json:"row,omitempty"`
+	Trailer *Trailer `json:"trailer,omitempty"`
+}
+
+// Writer write a backup, Close writing its trailer
+type Writer struct {
+	gz   *gzip.Writer
+	hash hash.Hash
+	enc  *json.Encoder
+	rows map[string]int
+}
+
+// NewWriter start a backup on w with its header, stamped with the format and
+// the version
+func NewWriter(w io.Writer, header Header) (*Writer, error) {
+	header.Format = Format
+	header.Version = Version
+
+	gz := gzip.NewWriter(w)
+	sum := sha256.New()
+	aw := &Writer{
+		gz:   gz,
+		hash: sum,
+		enc:  json.NewEncoder(io.MultiWriter(gz, sum)),
+		rows: map[string]int{},
+	}
+
+	if err := aw.enc.Encode(header); err != nil {
+		return nil, err
+	}
+
+	return aw, nil
+}
+
+// Write add a row of table
+func (w *Writer) Write(table string, row Row) error {
+	if err := w.enc.Encode(line{Table: table, Row: row}); err != nil {
+		return err
+	}
+	w.rows[table]++
+
+	return nil
+}
+
+// Close write the trailer and flush the compressed stream, leaving the
+// underlying writer open
+func (w *Writer) Close() (Trailer, error) {
+	trailer := Trailer{
+		Rows:   w.rows,
+		SHA256: hex.EncodeToString(w.hash.Sum(nil)),
+	}
+
+	if err := json.NewEncoder(w.gz).Encode(line{Trailer: &trailer}); err != nil {
+		return Trailer{}, err
+	}
+
+	return trailer, w.gz.Close()
+}
+
+// Reader read a backup row by row, Next checking the trailer once every row
+// is read
+type Reader struct {
+	Header Header
+
+	buf     *bufio.Reader
+	hash    hash.Hash
+	rows    map[string]int
+	trailer *Trailer
+	err     error
+}
+
+// NewReader open a backup, reading its header
+func NewReader(r io.Reader) (*Reader, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, constants.ErrArchiveFormat
+	}
+
+	ar := &Reader{
+		buf:  bufio.NewReader(gz),
+		hash: sha256.New(),
+		rows: map[string]int{},
+	}
+
+	data, err := ar.line()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &ar.Header); err != nil || ar.Header.Format != Format {
+		return nil, constants.ErrArchiveFormat
+	}
+	if ar.Header.Version != Version {
+		return nil, fmt.Errorf("%w: version %d, expected %d", constants.ErrArchiveVersion, ar.Header.Version, Version)
+	}
+
+	return ar, nil
+}
+
+// Next return the table and the row of the next line, io.EOF once the
+// trailer matched the rows read. Numbers are json.Number.
+func (r *Reader) Next() (string, Row, error) {
+	if r.err != nil {
+		return "", nil, r.err
+	}
+
+	table, row, err := r.next()
+	if err != nil {
+		r.err = err
+	}
+
+	return table, row, err
+}
+
+func (r *Reader) next() (string, Row, error) {
+	data, err := r.line()
+	if err != nil {
+		return "", nil, err
+	}
+
+	var l line
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&l); err != nil {
+		return "", nil, fmt.Errorf("%w: %v", constants.ErrArchiveCorrupt, err)
+	}
+
+	if l.Trailer != nil {
+		r.trailer = l.Trailer
+		return "", nil, r.check()
+	}
+
+	if l.Table == "" {
+		return "", nil, fmt.Errorf("%w: line without table", constants.ErrArchiveCorrupt)
+	}
+
+	r.hash.Write(data)
+	r.rows[l.Table]++
+
+	return l.Table, l.Row, nil
+}
+
+// Rows the number of rows of each table read so far
+func (r *Reader) Rows() map[string]int {
+	return r.rows
+}
+
+// Trailer the trailer of the backup, nil until Next read it
+func (r *Reader) Trailer() *Trailer {
+	return r.trailer
+}
+
+// line read the next line, checksummed for the header
+func (r *Reader) line() ([]byte, error) {
+	data, err := r.buf.ReadBytes('\n')
+	if errors.Is(err, io.EOF) {
+		return nil, constants.ErrArchiveTruncated
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", constants.ErrArchiveCorrupt, err)
+	}
+
+	if r.Header.Format == "" {
+		r.hash.Write(data)
+	}
+
+	return data, nil
+}
+
+// check compare the trailer with the rows read and their checksum, and make
+// sure nothing follows it
+func (r *Reader) check() error {
+	if sum := hex.EncodeToString(r.hash.Sum(nil)); sum != r.trailer.SHA256 {
+		return fmt.Errorf("%w: checksum %s, expected %s", constants.ErrArchiveCorrupt, sum, r.trailer.SHA256)
+	}
+
+	for table, n := range r.trailer.Rows {
+		if r.rows[table] != n {
+			return fmt.Errorf("%w: %d rows of %s, expected %d", constants.ErrArchiveCorrupt, r.rows[table], table, n)
+		}
+	}
+	for table, n := range r.rows {
+		if _, ok := r.trailer.Rows[table]; !ok {
+			return fmt.Errorf("%w: %d rows of %s, expected none", constants.ErrArchiveCorrupt, n, table)
+		}
+	}
+
+	// reading up to the end checks the CRC of the gzip stream
+	if _, err := r.buf.ReadByte(); err == nil {
+		return fmt.Errorf("%w: data after the trailer", constants.ErrArchiveCorrupt)
+	} else if !errors.Is(err, io.EOF) {
+		return fmt.Errorf("%w: %v", constants.ErrArchiveCorrupt, err)
+	}
+
+	return io.EOF
+}
@@ -0,0 +1,183 @@
+package archive_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	"todolist-api/constants"
+	"todolist-api/infra/archive"
+)
+
+var createdAt = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// rows the rows of the test backups, by table in order
+var rows = []struct {
+	table string
+	row   archive.Row
+}{
+	{"activities", archive.Row{"id": json.Number("1"), "title": "Home", "email": nil}},
+	{"activities", archive.Row{"id": json.Number("2"), "title": "Work, \"Q3\"", "email": "me@example.com"}},
+	{"todos", archive.Row{"id": json.Number("1"), "activity_group_id": json.Number("1"), "title": "Buy milk", "is_active": true}},
+}
+
+// backup write the test backup, returning its bytes and its trailer
+func backup(t *testing.T) ([]byte, archive.Trailer) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w, err := archive.NewWriter(&buf, archive.Header{Schema: 20240101, CreatedAt: createdAt})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, x := range rows {
+		if err := w.Write(x.table, x.row); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	trailer, err := w.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes(), trailer
+}
+
+// lines the uncompressed lines of a backup
+func lines(t *testing.T, data []byte) []string {
+	t.Helper()
+
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res := strings.SplitAfter(string(raw), "\n")
+
+	return res[:len(res)-1]
+}
+
+// compress a backup of the lines
+func compress(t *testing.T, lines []string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write([]byte(strings.Join(lines, ""))); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+// readAll read every row of the backup, returning the error ending it
+func readAll(data []byte) error {
+	r, err := archive.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+
+	for {
+		if _, _, err := r.Next(); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	data, written := backup(t)
+
+	if written.Rows["activities"] != 2 || written.Rows["todos"] != 1 || len(written.SHA256) != 64 {
+		t.Fatalf("unexpected trailer %+v", written)
+	}
+
+	r, err := archive.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Header.Format != archive.Format || r.Header.Version != archive.Version || r.Header.Schema != 20240101 || !r.Header.CreatedAt.Equal(createdAt) {
+		t.Fatalf("unexpected header %+v", r.Header)
+	}
+
+	for i, x := range rows {
+		table, row, err := r.Next()
+		if err != nil {
+			t.Fatalf("row %d: %v", i, err)
+		}
+		if table != x.table || !reflect.DeepEqual(row, x.row) {
+			t.Fatalf("row %d: %s %v, expected %s %v", i, table, row, x.table, x.row)
+		}
+	}
+
+	// the trailer matched, reading on keeps the end
+	for i := 0; i < 2; i++ {
+		if _, _, err := r.Next(); err != io.EOF {
+			t.Fatalf("expected io.EOF after the rows, got %v", err)
+		}
+	}
+	if read := r.Trailer(); read == nil || !reflect.DeepEqual(*read, written) {
+		t.Fatalf("trailer read %+v, written %+v", read, written)
+	}
+	if !reflect.DeepEqual(r.Rows(), written.Rows) {
+		t.Fatalf("rows read %v, written %v", r.Rows(), written.Rows)
+	}
+}
+
+func TestReaderRejects(t *testing.T) {
+	data, _ := backup(t)
+	valid := lines(t, data)
+	last := len(valid) - 1
+
+	// replace swap a line of the valid backup
+	replace := func(idx int, old, new string) []byte {
+		changed := append([]string{}, valid...)
+		if !strings.Contains(changed[idx], old) {
+			t.Fatalf("line %d %q has no %q", idx, changed[idx], old)
+		}
+		changed[idx] = strings.Replace(changed[idx], old, new, 1)
+		return compress(t, changed)
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+		err  error
+	}{
+		{"not gzip", []byte("id,title\n1,Home\n"), constants.ErrArchiveFormat},
+		{"other format", compress(t, []string{`{"format":"other","version":1}` + "\n"}), constants.ErrArchiveFormat},
+		{"other version", replace(0, `"version":1`, `"version":2`), constants.ErrArchiveVersion},
+		{"without trailer", compress(t, valid[:last]), constants.ErrArchiveTruncated},
+		{"cut in a line", compress(t, append(append([]string{}, valid[:2]...), valid[2][:10])), constants.ErrArchiveTruncated},
+		{"cut compressed stream", data[:len(data)-12], constants.ErrArchiveCorrupt},
+		{"corrupted checksum", replace(last, `"sha256":"`, `"sha256":"0`), constants.ErrArchiveCorrupt},
+		{"changed row", replace(1, `"Home"`, `"Hone"`), constants.ErrArchiveCorrupt},
+		{"changed header", replace(0, `"schema":20240101`, `"schema":20240102`), constants.ErrArchiveCorrupt},
+		{"row count", replace(last, `"todos":1`, `"todos":2`), constants.ErrArchiveCorrupt},
+		{"after the trailer", compress(t, append(append([]string{}, valid...), valid[1])), constants.ErrArchiveCorrupt},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := readAll(tt.data)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("error %v, expected %v", err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"regexp"
+	"strings"
+	"todolist-api/config"
+	"todolist-api/utils"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	// HeaderAPIKey request header carrying the API key
+	HeaderAPIKey = "API-KEY"
+	// QueryAPIKey query parameter carrying the API key, for clients such as
+	// EventSource that can't set request headers
+	QueryAPIKey = "api_key"
+)
+
+// versionPrefix the version the route templates are mounted under
+var versionPrefix = regexp.MustCompile(`^/v[0-9]+`)
+
+type contextKey struct{}
+
+// User the authenticated caller
+type User struct {
+	Email  string
+	APIKey string
+	Admin  bool
+}
+
+// CanAccess report whether the user may see data owned by email
+func (u User) CanAccess(email string) bool {
+	return u.Admin || (u.Email != "" && strings.EqualFold(u.Email, email))
+}
+
+// WithUser return a copy of ctx carrying the user
+func WithUser(ctx context.Context, user User) context.Context {
+	return context.WithValue(ctx, contextKey{}, user)
+}
+
+// FromContext return the user stored in ctx
+func FromContext(ctx context.Context) (User, bool) {
+	user, ok := ctx.Value(contextKey{}).(User)
+	return user, ok
+}
+
+// Middleware authenticate every request with its API key, except the requests
+// to the public paths, given as paths or as route templates without their
+// version such as /activity-groups/{id}/calendar.ics. When authentication is
+// disabled every caller is an anonymous admin.
+func Middleware(cfg config.AuthConfig, public ...string) func(http.Handler) http.Handler {
+	skip := map[string]bool{}
+	for _, x := range public {
+		skip[x] = true
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if skip[r.URL.Path] || skip[routeTemplate(r)] {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			user, ok := Authenticate(cfg, apiKey(r))
+			if !ok {
+				res := utils.SetResponseErrJSON(utils.MESSAGE_UNAUTHORIZED, "invalid or missing API key")
+				res.JSONErrUnauthorized(w)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(WithUser(r.Context(), user)))
+		})
+	}
+}
+
+// Admin let only the admins through, authenticated by their API key, for the
+// endpoints such as /metrics served next to the API. When authentication is
+// disabled every caller is an anonymous admin.
+func Admin(cfg config.AuthConfig) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, ok := Authenticate(cfg, apiKey(r))
+			if !ok {
+				res := utils.SetResponseErrJSON(utils.MESSAGE_UNAUTHORIZED, "invalid or missing API key")
+				res.JSONErrUnauthorized(w)
+				return
+			}
+			if !user.Admin {
+				res := utils.SetResponseErrJSON(utils.MESSAGE_FORBIDDEN, "admin API key required")
+				res.JSONErrForbidden(w)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(WithUser(r.Context(), user)))
+		})
+	}
+}
+
+// apiKey the API key of r, from its header or else from its query
+func apiKey(r *http.Request) string {
+	if key := r.Header.Get(HeaderAPIKey); key != "" {
+		return key
+	}
+
+	return r.URL.Query().Get(QueryAPIKey)
+}
+
+// routeTemplate the template of the route serving r without its version,
+// empty when no route matched
+func routeTemplate(r *http.Request) string {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return ""
+	}
+
+	template, err := route.GetPathTemplate()
+	if err != nil {
+		return ""
+	}
+
+	return versionPrefix.ReplaceAllString(template, "")
+}
+
+// Authenticate resolve the user owning an API key. When authentication is
+// disabled every caller is an anonymous admin.
+func Authenticate(cfg config.AuthConfig, key string) (User, bool) {
+	if !cfg.Enabled {
+		return User{Admin: true}, true
+	}
+
+	if key == "" {
+		return User{}, false
+	}
+
+	for _, x := range cfg.APIKeys {
+		if subtle.ConstantTimeCompare([]byte(x.Key), []byte(key)) == 1 {
+			return User{Email: x.Email, APIKey: x.Key, Admin: x.Admin}, true
+		}
+	}
+
+	return User{}, false
+}
@@ -0,0 +1,47 @@
+package auth_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"todolist-api/config"
+	"todolist-api/infra/auth"
+)
+
+func TestAdmin(t *testing.T) {
+	cfg := config.AuthConfig{Enabled: true, APIKeys: []config.APIKeyConfig{
+		{Key: "admin-key", Admin: true},
+		{Key: "user-key", Email: "user@example.com"},
+	}}
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	tests := []struct {
+		name   string
+		cfg    config.AuthConfig
+		key    string
+		status int
+	}{
+		{"admin", cfg, "admin-key", http.StatusOK},
+		{"user", cfg, "user-key", http.StatusForbidden},
+		{"unknown key", cfg, "other", http.StatusUnauthorized},
+		{"no key", cfg, "", http.StatusUnauthorized},
+		{"auth disabled", config.AuthConfig{}, "", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+			if tt.key != "" {
+				req.Header.Set(auth.HeaderAPIKey, tt.key)
+			}
+			w := httptest.NewRecorder()
+			auth.Admin(tt.cfg)(ok).ServeHTTP(w, req)
+
+			if w.Code != tt.status {
+				t.Fatalf("status %d, expected %d", w.Code, tt.status)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package auth
+
+import (
+	"todolist-api/infra/events"
+	"todolist-api/objects/activity"
+	"todolist-api/objects/todo"
+)
+
+// OwnerLookup resolve the owner email of an activity group
+type OwnerLookup func(groupID int) (string, error)
+
+// EventFilter decide which broker events a user may see. Activity events carry
+// their owner, the owner of a todo event is resolved once per group and cached
+// for the lifetime of the filter, which is meant to live as long as one stream.
+type EventFilter struct {
+	user   User
+	lookup OwnerLookup
+	owners map[int]bool
+}
+
+// NewEventFilter create the event filter of a single stream
+func NewEventFilter(user User, lookup OwnerLookup) *EventFilter {
+	return &EventFilter{
+		user:   user,
+		lookup: lookup,
+		owners: map[int]bool{},
+	}
+}
+
+// Visible report whether the user owns the activity group of the event
+func (f *EventFilter) Visible(event events.Event) bool {
+	if f.user.Admin {
+		return true
+	}
+
+	switch data := event.Data.(type) {
+	case activity.Activity:
+		f.owners[data.ID] = f.user.CanAccess(data.Email)
+		return f.owners[data.ID]
+	case todo.Todo:
+		if allowed, ok := f.owners[data.ActivityGroupID]; ok {
+			return allowed
+		}
+
+		email, err := f.lookup(data.ActivityGroupID)
+		f.owners[data.ActivityGroupID] = err == nil && f.user.CanAccess(email)
+		return f.owners[data.ActivityGroupID]
+	}
+
+	return false
+}
@@ -0,0 +1,72 @@
+// Package codec read and write the activity groups and their todos as files,
+// the JSON export, a CSV row per todo, a Markdown checklist and an iCalendar
+// of VTODO, and read the CSV exports of Todoist and Trello.
+package codec
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"todolist-api/constants"
+	"todolist-api/objects/transfer"
+)
+
+// utf8BOM the byte order mark the spreadsheets put in front of their CSV
+var utf8BOM = []byte("\xef\xbb\xbf")
+
+// Encode write the export in format, json, csv or md
+func Encode(w io.Writer, format string, data transfer.Export) error {
+	switch format {
+	case constants.FormatJSON:
+		return encodeJSON(w, data)
+	case constants.FormatCSV:
+		return encodeCSV(w, data)
+	case constants.FormatMarkdown:
+		return encodeMarkdown(w, data)
+	default:
+		return constants.ErrExportFormatInvalid
+	}
+}
+
+// Decode read the activity groups of a file in format, reporting where the
+// file can't be read. A csv file in the layout of Todoist or Trello is read
+// as such.
+func Decode(format string, data []byte) ([]transfer.Group, []transfer.ImportError, error) {
+	data = bytes.TrimPrefix(data, utf8BOM)
+
+	switch format {
+	case constants.FormatJSON:
+		groups, errs := decodeJSON(data)
+		return groups, errs, nil
+	case constants.FormatCSV, constants.FormatTodoist, constants.FormatTrello:
+		groups, errs := decodeCSV(format, data)
+		return groups, errs, nil
+	case constants.FormatMarkdown:
+		groups, errs := decodeMarkdown(data)
+		return groups, errs, nil
+	case constants.FormatICS:
+		groups, errs := decodeICS(data)
+		return groups, errs, nil
+	default:
+		return nil, nil, constants.ErrFormatInvalid
+	}
+}
+
+// ContentType the media type of the files in format
+func ContentType(format string) string {
+	switch format {
+	case constants.FormatJSON:
+		return "application/json"
+	case constants.FormatMarkdown:
+		return "text/markdown; charset=utf-8"
+	case constants.FormatICS:
+		return "text/calendar; charset=utf-8"
+	default:
+		return "text/csv; charset=utf-8"
+	}
+}
+
+// line the location of the line n of a file
+func line(n int) string {
+	return fmt.Sprintf("line %d", n)
+}
@@ -0,0 +1,176 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"todolist-api/constants"
+	"todolist-api/objects/transfer"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files of testdata")
+
+// golden compare got with the golden file name of testdata, rewriting it
+// with -update
+func golden(t *testing.T, name string, got []byte) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	expected, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, expected) {
+		t.Fatalf("%s differs, run go test -update to accept:\n%s", path, got)
+	}
+}
+
+// decoded the groups and the errors of a file as compared with the golden
+// files, along with where they were read from
+type decoded struct {
+	Groups []decodedGroup         `json:"groups"`
+	Errors []transfer.ImportError `json:"errors"`
+}
+
+type decodedGroup struct {
+	transfer.Group
+	Location string        `json:"location"`
+	Todos    []decodedTodo `json:"todos"`
+}
+
+type decodedTodo struct {
+	transfer.Todo
+	Location string `json:"location"`
+}
+
+func snapshot(t *testing.T, groups []transfer.Group, errs []transfer.ImportError) []byte {
+	t.Helper()
+
+	res := decoded{Groups: []decodedGroup{}, Errors: errs}
+	for _, g := range groups {
+		x := decodedGroup{Group: g, Location: g.Location, Todos: []decodedTodo{}}
+		for _, todo := range g.Todos {
+			x.Todos = append(x.Todos, decodedTodo{Todo: todo, Location: todo.Location})
+		}
+		res.Groups = append(res.Groups, x)
+	}
+
+	data, err := json.MarshalIndent(res, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return append(data, '\n')
+}
+
+// export the activity groups of the export golden files
+var export = transfer.Export{
+	Version:    constants.ExportVersion,
+	ExportedAt: "2024-01-01T00:00:00Z",
+	ActivityGroups: []transfer.Group{
+		{
+			Title: "Groceries, weekly",
+			Email: "me@example.com",
+			Todos: []transfer.Todo{
+				{Title: "Buy milk", Priority: "high", IsActive: true},
+				{Title: "Buy \"free range\" eggs", Priority: "very-low", IsActive: false},
+				{Title: "Standup\nnotes", Priority: "normal", IsActive: true, DueAt: "2024-01-02T09:00:00Z", RRule: "FREQ=DAILY"},
+			},
+		},
+		{Title: "Empty", Todos: []transfer.Todo{}},
+	},
+}
+
+func TestEncode(t *testing.T) {
+	for _, format := range []string{constants.FormatJSON, constants.FormatCSV, constants.FormatMarkdown} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Encode(&buf, format, export); err != nil {
+				t.Fatal(err)
+			}
+			golden(t, "export.golden."+format, buf.Bytes())
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		format string
+		file   string
+	}{
+		{constants.FormatTodoist, "todoist.csv"},
+		{constants.FormatTrello, "trello.csv"},
+		{constants.FormatCSV, "import.csv"},
+		{constants.FormatMarkdown, "import.md"},
+		// the layout of a CSV is told by its header
+		{constants.FormatCSV, "todoist.csv"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format+" "+tt.file, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join("testdata", tt.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			groups, errs, err := Decode(tt.format, data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			golden(t, tt.file+".golden.json", snapshot(t, groups, errs))
+		})
+	}
+}
+
+// TestRoundTrip read the exports back into the groups exported
+func TestRoundTrip(t *testing.T) {
+	for _, format := range []string{constants.FormatJSON, constants.FormatCSV, constants.FormatMarkdown} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Encode(&buf, format, export); err != nil {
+				t.Fatal(err)
+			}
+
+			groups, errs, err := Decode(format, buf.Bytes())
+			if err != nil || len(errs) > 0 {
+				t.Fatalf("%v, %v", err, errs)
+			}
+			if len(groups) != len(export.ActivityGroups) {
+				t.Fatalf("%d groups read back, %d exported", len(groups), len(export.ActivityGroups))
+			}
+			for i, g := range groups {
+				exported := export.ActivityGroups[i]
+				if g.Title != exported.Title || len(g.Todos) != len(exported.Todos) {
+					t.Fatalf("group %d read back as %+v", i, g)
+				}
+				for j, x := range g.Todos {
+					if x.Priority != exported.Todos[j].Priority || x.IsActive != exported.Todos[j].IsActive {
+						t.Fatalf("todo %d of group %d read back as %+v", j, i, x)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestDecodeWrongLayout(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "trello.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	groups, errs, err := Decode(constants.FormatTodoist, data)
+	if err != nil || groups != nil || len(errs) != 1 || errs[0].Location != "line 1" {
+		t.Fatalf("a Trello export read as Todoist: %v, %v, %v", groups, errs, err)
+	}
+}
@@ -0,0 +1,291 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"todolist-api/constants"
+	"todolist-api/objects/transfer"
+)
+
+// csvHeader the columns of the CSV export, a row per todo. A row without todo
+// is an activity group without todo.
+var csvHeader = []string{"activity_group", "email", "title", "priority", "is_active"}
+
+// todoistPriorities the priorities of Todoist, p1 the highest
+var todoistPriorities = map[string]string{
+	"1": "very-high",
+	"2": "high",
+	"3": "normal",
+	"4": "low",
+}
+
+func encodeCSV(w io.Writer, data transfer.Export) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, g := range data.ActivityGroups {
+		if len(g.Todos) == 0 {
+			if err := cw.Write([]string{g.Title, g.Email, "", "", ""}); err != nil {
+				return err
+			}
+			continue
+		}
+
+		for _, x := range g.Todos {
+			if err := cw.Write([]string{g.Title, g.Email, x.Title, x.Priority, strconv.FormatBool(x.IsActive)}); err != nil {
+				return err
+			}
+		}
+	}
+
+	cw.Flush()
+
+	return cw.Error()
+}
+
+// csvRow a row of a CSV file, its cells by lower case column name
+type csvRow struct {
+	line    int
+	columns map[string]int
+	record  []string
+}
+
+func (r csvRow) cell(name string) string {
+	i, ok := r.columns[name]
+	if !ok || i >= len(r.record) {
+		return ""
+	}
+
+	return strings.TrimSpace(r.record[i])
+}
+
+// groupSet the activity groups of a file in the order they appear, the rows
+// of a group joining it wherever they are
+type groupSet struct {
+	groups []transfer.Group
+	index  map[string]int
+}
+
+func (s *groupSet) get(title, email, location string) int {
+	key := title + "\x00" + email
+	if i, ok := s.index[key]; ok {
+		return i
+	}
+
+	s.groups = append(s.groups, transfer.Group{
+		Title:    title,
+		Email:    email,
+		Todos:    []transfer.Todo{},
+		Location: location,
+	})
+	s.index[key] = len(s.groups) - 1
+
+	return len(s.groups) - 1
+}
+
+// decodeCSV read a CSV export, the layout being told by the columns of the
+// header
+func decodeCSV(format string, data []byte) ([]transfer.Group, []transfer.ImportError) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+
+	header, err := r.Read()
+	if err == io.EOF {
+		return nil, []transfer.ImportError{{Location: line(1), Message: "the file is empty"}}
+	}
+	if err != nil {
+		return nil, []transfer.ImportError{{Location: line(1), Message: err.Error()}}
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, x := range header {
+		columns[strings.ToLower(strings.TrimSpace(x))] = i
+	}
+
+	layout := csvLayout(columns)
+	if layout == "" || (format != constants.FormatCSV && format != layout) {
+		return nil, []transfer.ImportError{{Location: line(1), Message: expectedColumns(format)}}
+	}
+
+	set := &groupSet{index: map[string]int{}}
+	current := -1
+	errs := []transfer.ImportError{}
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			// a broken quote throws the rest of the file off
+			n, _ := r.FieldPos(0)
+			errs = append(errs, transfer.ImportError{Location: line(n), Message: err.Error()})
+			break
+		}
+
+		n, _ := r.FieldPos(0)
+		row := csvRow{line: n, columns: columns, record: record}
+		if strings.TrimSpace(strings.Join(record, "")) == "" {
+			continue
+		}
+
+		var msg string
+		switch layout {
+		case constants.FormatTodoist:
+			current, msg = todoistRow(set, current, row)
+		case constants.FormatTrello:
+			msg = trelloRow(set, row)
+		default:
+			msg = csvExportRow(set, row)
+		}
+		if msg != "" {
+			errs = append(errs, transfer.ImportError{Location: line(n), Message: msg})
+		}
+	}
+
+	return set.groups, errs
+}
+
+// csvLayout tell the layout of a CSV by its columns
+func csvLayout(columns map[string]int) string {
+	has := func(names ...string) bool {
+		for _, x := range names {
+			if _, ok := columns[x]; !ok {
+				return false
+			}
+		}
+		return true
+	}
+
+	switch {
+	case has("activity_group", "title"):
+		return constants.FormatCSV
+	case has("type", "content"):
+		return constants.FormatTodoist
+	case has("card name", "list name"):
+		return constants.FormatTrello
+	default:
+		return ""
+	}
+}
+
+func expectedColumns(format string) string {
+	switch format {
+	case constants.FormatTodoist:
+		return "not a Todoist export, the TYPE and CONTENT columns are required"
+	case constants.FormatTrello:
+		return "not a Trello export, the Card Name and List Name columns are required"
+	default:
+		return "unknown columns, activity_group and title are required, or the columns of a Todoist or Trello export"
+	}
+}
+
+// csvExportRow read a row of the CSV export
+func csvExportRow(set *groupSet, row csvRow) string {
+	title := row.cell("activity_group")
+	if title == "" {
+		return "activity_group is required"
+	}
+
+	g := set.get(title, row.cell("email"), line(row.line))
+	if row.cell("title") == "" && row.cell("priority") == "" && row.cell("is_active") == "" {
+		return ""
+	}
+
+	isActive := true
+	if value := row.cell("is_active"); value != "" {
+		var err error
+		isActive, err = strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Sprintf("is_active: %q is not true or false", value)
+		}
+	}
+
+	set.groups[g].Todos = append(set.groups[g].Todos, transfer.Todo{
+		Title:    row.cell("title"),
+		Priority: row.cell("priority"),
+		IsActive: isActive,
+		Location: line(row.line),
+	})
+
+	return ""
+}
+
+// todoistRow read a row of a Todoist export, a section starting an activity
+// group for the tasks after it. The notes and the settings are left out.
+func todoistRow(set *groupSet, current int, row csvRow) (int, string) {
+	switch strings.ToLower(row.cell("type")) {
+	case "section":
+		if row.cell("content") == "" {
+			return current, "the section has no CONTENT"
+		}
+		return set.get(row.cell("content"), "", line(row.line)), ""
+	case "task":
+		if current < 0 {
+			current = set.get(constants.TodoistInbox, "", line(row.line))
+		}
+
+		priority := ""
+		if value := row.cell("priority"); value != "" {
+			var ok bool
+			priority, ok = todoistPriorities[value]
+			if !ok {
+				return current, fmt.Sprintf("PRIORITY: %q is not 1, 2, 3 or 4", value)
+			}
+		}
+
+		set.groups[current].Todos = append(set.groups[current].Todos, transfer.Todo{
+			Title:    row.cell("content"),
+			Priority: priority,
+			IsActive: true,
+			Location: line(row.line),
+		})
+		return current, ""
+	default:
+		return current, ""
+	}
+}
+
+// trelloRow read a row of a Trello export, a card of a list. A label named
+// after a priority, such as High (red), sets it. The archived cards are left
+// out.
+func trelloRow(set *groupSet, row csvRow) string {
+	if archived, _ := strconv.ParseBool(row.cell("archived")); archived {
+		return ""
+	}
+
+	if row.cell("list name") == "" {
+		return "List Name is required"
+	}
+
+	g := set.get(row.cell("list name"), "", line(row.line))
+	done, _ := strconv.ParseBool(row.cell("due complete"))
+
+	set.groups[g].Todos = append(set.groups[g].Todos, transfer.Todo{
+		Title:    row.cell("card name"),
+		Priority: trelloPriority(row.cell("labels")),
+		IsActive: !done,
+		Location: line(row.line),
+	})
+
+	return ""
+}
+
+func trelloPriority(labels string) string {
+	for _, label := range strings.Split(labels, ",") {
+		name, _, _ := strings.Cut(strings.TrimSpace(label), " (")
+		name = strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), " ", "-")
+		for _, p := range constants.Priorities {
+			if name == p {
+				return p
+			}
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,94 @@
+package codec
+
+import (
+	"fmt"
+	"io"
+	"time"
+	"todolist-api/constants"
+	"todolist-api/infra/ical"
+	"todolist-api/objects/transfer"
+)
+
+// EncodeCalendar write the todos of the calendar as VTODO, the title of their
+// activity group as category
+func EncodeCalendar(w io.Writer, cal transfer.Calendar) error {
+	data := ical.Calendar{
+		Name:  cal.Name,
+		Todos: make([]ical.Todo, 0, len(cal.Todos)),
+	}
+
+	for _, x := range cal.Todos {
+		data.Todos = append(data.Todos, ical.Todo{
+			UID:          fmt.Sprintf("todo-%d@todolist-api", x.ID),
+			Summary:      x.Title,
+			Categories:   []string{x.Group},
+			Due:          x.DueAt,
+			Priority:     constants.CalendarPriorities[x.Priority],
+			Completed:    !x.IsActive,
+			RRule:        x.RRule,
+			Created:      x.CreatedAt,
+			LastModified: x.UpdatedAt,
+		})
+	}
+
+	return ical.Encode(w, data)
+}
+
+// decodeICS read the VTODO of a calendar as the todos of an activity group
+// named after the calendar
+func decodeICS(data []byte) ([]transfer.Group, []transfer.ImportError) {
+	cal, icalErrs := ical.Decode(data)
+
+	errs := make([]transfer.ImportError, 0, len(icalErrs))
+	for _, x := range icalErrs {
+		errs = append(errs, transfer.ImportError{Location: line(x.Line), Message: x.Message})
+	}
+
+	if len(errs) > 0 && cal.Todos == nil {
+		return nil, errs
+	}
+
+	group := transfer.Group{
+		Title:    cal.Name,
+		Todos:    make([]transfer.Todo, 0, len(cal.Todos)),
+		Location: line(1),
+	}
+	if group.Title == "" {
+		group.Title = constants.CalendarName
+	}
+
+	for _, x := range cal.Todos {
+		todo := transfer.Todo{
+			Title:    x.Summary,
+			Priority: priority(x.Priority),
+			IsActive: !x.Completed,
+			RRule:    x.RRule,
+			Location: line(x.Line),
+		}
+		if x.Due != nil {
+			todo.DueAt = x.Due.UTC().Format(time.RFC3339)
+		}
+
+		group.Todos = append(group.Todos, todo)
+	}
+
+	return []transfer.Group{group}, errs
+}
+
+// priority the priority of a PRIORITY of iCalendar, none for 0
+func priority(n int) string {
+	switch {
+	case n <= 0:
+		return ""
+	case n <= 2:
+		return "very-high"
+	case n <= 4:
+		return "high"
+	case n == 5:
+		return "normal"
+	case n <= 7:
+		return "low"
+	default:
+		return "very-low"
+	}
+}
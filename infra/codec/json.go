@@ -0,0 +1,73 @@
+package codec
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"todolist-api/constants"
+	"todolist-api/objects/transfer"
+)
+
+func encodeJSON(w io.Writer, data transfer.Export) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(data)
+}
+
+// jsonExport the JSON export as read, a missing is_active being an active todo
+type jsonExport struct {
+	Version        int `json:"version"`
+	ActivityGroups []struct {
+		Title string `json:"title"`
+		Email string `json:"email"`
+		Todos []struct {
+			Title    string `json:"title"`
+			Priority string `json:"priority"`
+			IsActive *bool  `json:"is_active"`
+			DueAt    string `json:"due_at"`
+			RRule    string `json:"rrule"`
+		} `json:"todos"`
+	} `json:"activity_groups"`
+}
+
+func decodeJSON(data []byte) ([]transfer.Group, []transfer.ImportError) {
+	var doc jsonExport
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, []transfer.ImportError{{Location: "document", Message: err.Error()}}
+	}
+
+	if doc.Version > constants.ExportVersion {
+		return nil, []transfer.ImportError{{Location: "version", Message: fmt.Sprintf("version %d is newer than %d", doc.Version, constants.ExportVersion)}}
+	}
+
+	groups := make([]transfer.Group, 0, len(doc.ActivityGroups))
+	for i, g := range doc.ActivityGroups {
+		group := transfer.Group{
+			Title:    g.Title,
+			Email:    g.Email,
+			Todos:    make([]transfer.Todo, 0, len(g.Todos)),
+			Location: fmt.Sprintf("activity_groups[%d]", i),
+		}
+
+		for j, x := range g.Todos {
+			isActive := true
+			if x.IsActive != nil {
+				isActive = *x.IsActive
+			}
+
+			group.Todos = append(group.Todos, transfer.Todo{
+				Title:    x.Title,
+				Priority: x.Priority,
+				IsActive: isActive,
+				DueAt:    x.DueAt,
+				RRule:    x.RRule,
+				Location: fmt.Sprintf("activity_groups[%d].todos[%d]", i, j),
+			})
+		}
+
+		groups = append(groups, group)
+	}
+
+	return groups, nil
+}
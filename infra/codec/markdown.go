@@ -0,0 +1,107 @@
+package codec
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"todolist-api/objects/transfer"
+)
+
+var (
+	// markdownItem a list item, checked or not, such as - [x] Buy milk
+	markdownItem = regexp.MustCompile(`^\s*[-*+]\s+(?:\[([ xX])\]\s*)?(.*)$`)
+	// markdownPriority the priority at the end of an item, such as `high`
+	markdownPriority = regexp.MustCompile("\\s*`([^`]+)`$")
+)
+
+// encodeMarkdown write a heading per activity group and a checklist of its
+// todos, the completed ones checked, the priority in code at the end
+func encodeMarkdown(w io.Writer, data transfer.Export) error {
+	bw := bufio.NewWriter(w)
+	for i, g := range data.ActivityGroups {
+		if i > 0 {
+			fmt.Fprintln(bw)
+		}
+		fmt.Fprintf(bw, "# %s\n", oneLine(g.Title))
+
+		if len(g.Todos) > 0 {
+			fmt.Fprintln(bw)
+		}
+		for _, x := range g.Todos {
+			check := " "
+			if !x.IsActive {
+				check = "x"
+			}
+			fmt.Fprintf(bw, "- [%s] %s", check, oneLine(x.Title))
+			if x.Priority != "" {
+				fmt.Fprintf(bw, " `%s`", x.Priority)
+			}
+			fmt.Fprintln(bw)
+		}
+	}
+
+	return bw.Flush()
+}
+
+// decodeMarkdown read the headings as activity groups and the list items
+// under them as todos, the other lines being left out
+func decodeMarkdown(data []byte) ([]transfer.Group, []transfer.ImportError) {
+	groups := []transfer.Group{}
+	errs := []transfer.ImportError{}
+
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	n := 0
+	for sc.Scan() {
+		n++
+		text := strings.TrimRight(sc.Text(), " \t\r")
+
+		if strings.HasPrefix(text, "#") {
+			title := strings.TrimSpace(strings.TrimLeft(text, "#"))
+			groups = append(groups, transfer.Group{
+				Title:    title,
+				Todos:    []transfer.Todo{},
+				Location: line(n),
+			})
+			continue
+		}
+
+		m := markdownItem.FindStringSubmatch(text)
+		if m == nil {
+			continue
+		}
+
+		if len(groups) == 0 {
+			errs = append(errs, transfer.ImportError{Location: line(n), Message: "todo outside of an activity group, a # heading has to come first"})
+			continue
+		}
+
+		todo := transfer.Todo{
+			Title:    m[2],
+			IsActive: strings.TrimSpace(m[1]) == "",
+			Location: line(n),
+		}
+		if p := markdownPriority.FindStringSubmatch(todo.Title); p != nil {
+			todo.Priority = p[1]
+			todo.Title = strings.TrimSuffix(todo.Title, p[0])
+		}
+		todo.Title = strings.TrimSpace(todo.Title)
+
+		g := &groups[len(groups)-1]
+		g.Todos = append(g.Todos, todo)
+	}
+
+	if err := sc.Err(); err != nil {
+		errs = append(errs, transfer.ImportError{Location: line(n + 1), Message: err.Error()})
+	}
+
+	return groups, errs
+}
+
+// oneLine keep a title on its line
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
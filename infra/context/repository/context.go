@@ -2,13 +2,37 @@ package repository
 
 import (
 	"todolist-api/data/repositories/activity"
+	"todolist-api/data/repositories/backup"
+	"todolist-api/data/repositories/change"
+	"todolist-api/data/repositories/idempotency"
 	"todolist-api/data/repositories/todo"
+	"todolist-api/data/repositories/webhook"
 	"todolist-api/infra/db"
+	"todolist-api/infra/events"
 )
 
 // RepoCtx struct for repository context
 type RepoCtx struct {
-	DB                 *db.DB
-	ActivityRepository activity.ActivityRepositoryInterface
-	TodoRepository     todo.TodoRepositoryInterface
+	DB                    *db.DB
+	ActivityRepository    activity.ActivityRepositoryInterface
+	TodoRepository        todo.TodoRepositoryInterface
+	WebhookRepository     webhook.WebhookRepositoryInterface
+	IdempotencyRepository idempotency.IdempotencyRepositoryInterface
+	ChangeRepository      change.ChangeRepositoryInterface
+	BackupRepository      backup.BackupRepositoryInterface
+	Publisher             events.Publisher
+}
+
+// NewRepoCtx initialize every repository on top of db
+func NewRepoCtx(db *db.DB, publisher events.Publisher) *RepoCtx {
+	return &RepoCtx{
+		DB:                    db,
+		ActivityRepository:    activity.NewActivityRepository(db),
+		TodoRepository:        todo.NewTodoRepository(db),
+		WebhookRepository:     webhook.NewWebhookRepository(db),
+		IdempotencyRepository: idempotency.NewIdempotencyRepository(db),
+		ChangeRepository:      change.NewChangeRepository(db),
+		BackupRepository:      backup.NewBackupRepository(db),
+		Publisher:             publisher,
+	}
 }
@@ -2,11 +2,68 @@ package service
 
 import (
 	"todolist-api/cmd/services/activity"
+	"todolist-api/cmd/services/sync"
 	"todolist-api/cmd/services/todo"
+	"todolist-api/cmd/services/transfer"
+	"todolist-api/cmd/services/webhook"
+	"todolist-api/infra/context/repository"
+	"todolist-api/infra/decorator"
+	"todolist-api/infra/metrics"
+	"todolist-api/infra/ratelimit"
 )
 
 // Ctx service context
 type Ctx struct {
 	ActivityService activity.ActivityServiceInterface
 	TodoService     todo.TodoServiceInterface
+	WebhookService  webhook.WebhookServiceInterface
+	SyncService     sync.SyncServiceInterface
+	TransferService transfer.TransferServiceInterface
+}
+
+// NewCtx initialize every service on top of the repository context
+func NewCtx(ctx *repository.RepoCtx) *Ctx {
+	return &Ctx{
+		ActivityService: activity.NewActivityService(ctx),
+		TodoService:     todo.NewTodoService(ctx),
+		WebhookService:  webhook.NewWebhookService(ctx),
+		SyncService:     sync.NewSyncService(ctx),
+		TransferService: transfer.NewTransferService(ctx),
+	}
+}
+
+// Limit return a copy of ctx whose todo, sync and transfer services count
+// the todo creations against the daily quota of each user
+func Limit(ctx *Ctx, todoQuota *ratelimit.Quota) *Ctx {
+	return &Ctx{
+		ActivityService: ctx.ActivityService,
+		TodoService:     todo.NewTodoServiceQuota(ctx.TodoService, todoQuota),
+		WebhookService:  ctx.WebhookService,
+		SyncService:     sync.NewSyncServiceQuota(ctx.SyncService, todoQuota),
+		TransferService: transfer.NewTransferServiceQuota(ctx.TransferService, todoQuota),
+	}
+}
+
+// Instrument return a copy of ctx whose activity, todo, sync and transfer
+// services report their timings to m
+func Instrument(ctx *Ctx, m *metrics.Metrics) *Ctx {
+	return &Ctx{
+		ActivityService: activity.NewActivityServiceDecorator(ctx.ActivityService, decorator.Metrics("activity", m)),
+		TodoService:     todo.NewTodoServiceDecorator(ctx.TodoService, decorator.Metrics("todo", m)),
+		WebhookService:  ctx.WebhookService,
+		SyncService:     sync.NewSyncServiceDecorator(ctx.SyncService, decorator.Metrics("sync", m)),
+		TransferService: transfer.NewTransferServiceDecorator(ctx.TransferService, decorator.Metrics("transfer", m)),
+	}
+}
+
+// Trace return a copy of ctx whose activity, todo, sync and transfer
+// services open a span per method call
+func Trace(ctx *Ctx) *Ctx {
+	return &Ctx{
+		ActivityService: activity.NewActivityServiceDecorator(ctx.ActivityService, decorator.Tracing("ActivityService")),
+		TodoService:     todo.NewTodoServiceDecorator(ctx.TodoService, decorator.Tracing("TodoService")),
+		WebhookService:  ctx.WebhookService,
+		SyncService:     sync.NewSyncServiceDecorator(ctx.SyncService, decorator.Tracing("SyncService")),
+		TransferService: transfer.NewTransferServiceDecorator(ctx.TransferService, decorator.Tracing("TransferService")),
+	}
 }
@@ -17,10 +17,11 @@ import (
 
 // DB logical wrapper for database object
 type DB struct {
-	mtx    sync.RWMutex
-	driver string
-	dbs    []*sql.DB
-	count  uint64
+	mtx        sync.RWMutex
+	driver     string
+	dbs        []*sql.DB
+	count      uint64
+	statements *statements
 }
 
 func scatter(n int, fn func(idx int) error) error {
@@ -41,8 +42,10 @@ func scatter(n int, fn func(idx int) error) error {
 	return err
 }
 
-// Open concurrently opens each underlying physical db
-func Open(dbSetting *config.DBConfig) (*DB, error) {
+// Open concurrently opens each underlying physical db. Statements and
+// transactions are traced, and the hooks observe the transactions of every
+// physical db.
+func Open(dbSetting *config.DBConfig, hooks ...Hooks) (*DB, error) {
 	if dbSetting == nil {
 		return nil, errors.New("database setting is required")
 	}
@@ -51,11 +54,17 @@ func Open(dbSetting *config.DBConfig) (*DB, error) {
 		return nil, errors.New("database driver name should not empty")
 	}
 
-	dsns := []string{dbSetting.Host}
+	dsns := append([]string{dbSetting.Host}, dbSetting.Replicas...)
 
 	db := &DB{
-		driver: dbSetting.Name,
-		dbs:    make([]*sql.DB, len(dsns)),
+		driver:     dbSetting.Name,
+		dbs:        make([]*sql.DB, len(dsns)),
+		statements: newStatements(),
+	}
+	in := &instrumentation{
+		system:     dbSetting.Name,
+		hooks:      hooks,
+		statements: db.statements,
 	}
 
 	err := scatter(len(db.dbs), func(idx int) error {
@@ -64,9 +73,18 @@ func Open(dbSetting *config.DBConfig) (*DB, error) {
 			return err
 		}
 
+		// reopen through the instrumented connector of the same driver
+		c, err := newConnector(dbConn.Driver(), dsns[idx], in)
+		if err != nil {
+			return err
+		}
+		_ = dbConn.Close()
+		dbConn = sql.OpenDB(c)
+
 		dbConn.SetMaxOpenConns(dbSetting.MaxOpenConn)
 		dbConn.SetMaxIdleConns(dbSetting.MaxIdleConn)
 		dbConn.SetConnMaxLifetime(time.Duration(dbSetting.ConnMaxLifetime) * time.Second)
+		dbConn.SetConnMaxIdleTime(time.Duration(dbSetting.ConnMaxIdleTime) * time.Second)
 		db.dbs[idx] = dbConn
 
 		return nil
@@ -88,6 +106,24 @@ func (db *DB) Close() error {
 	})
 }
 
+// NameStatements names the queries of a repository in the traces, as prefix
+// followed by their key in queries. Queries completed at run time, such as
+// with a WHERE clause or an IN list, keep the name of the query they start with.
+func (db *DB) NameStatements(prefix string, queries map[string]string) {
+	db.statements.register(prefix, queries)
+}
+
+// Stats returns the connection pool statistics of each physical database,
+// the master first.
+func (db *DB) Stats() []sql.DBStats {
+	stats := make([]sql.DBStats, len(db.dbs))
+	for idx := range db.dbs {
+		stats[idx] = db.dbs[idx].Stats()
+	}
+
+	return stats
+}
+
 // SetMaxIdleConns sets the maximum number of connections in the idle
 // connection pool for each underlying physical db.
 // If MaxOpenConns is greater than 0 but less than the new MaxIdleConns then the
@@ -120,6 +156,15 @@ func (db *DB) SetConnMaxLifetime(d time.Duration) {
 	}
 }
 
+// SetConnMaxIdleTime sets the maximum amount of time a connection may be idle.
+// Expired connections may be closed lazily before reuse.
+// If d <= 0, connections are not closed due to a connection's idle time.
+func (db *DB) SetConnMaxIdleTime(d time.Duration) {
+	for idx := range db.dbs {
+		db.dbs[idx].SetConnMaxIdleTime(d)
+	}
+}
+
 // Master returns the master physical database
 func (db *DB) Master() *sqlx.DB {
 	db.mtx.RLock()
@@ -145,18 +190,30 @@ func (db *DB) Slave() *sqlx.DB {
 	return sqlx.NewDb(db.dbs[db.slave(len(db.dbs))], db.driver)
 }
 
-// Begin starts a transaction on the master. The isolation level is dependent on the driver.
+// Begin starts a transaction on the master, or a savepoint within the Scope
+// of ctx. The isolation level is dependent on the driver.
 func (db *DB) Begin(ctx context.Context) (*sqlx.Tx, error) {
-	return db.Master().BeginTxx(ctx, nil)
+	conn, err := db.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return conn.BeginTxx(ctx, nil)
 }
 
-// BeginTx starts a transaction with the provided context on the master.
+// BeginTx starts a transaction with the provided context on the master, or
+// a savepoint within the Scope of ctx.
 //
 // The provided TxOptions is optional and may be nil if defaults should be used.
 // If a non-default isolation level is used that the driver doesn't support,
 // an error will be returned.
 func (db *DB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
-	return db.Master().BeginTx(ctx, opts)
+	conn, err := db.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return conn.BeginTx(ctx, opts)
 }
 
 // Exec executes a query without returning any rows.
@@ -189,6 +246,22 @@ func (db *DB) PingContext(ctx context.Context) error {
 	})
 }
 
+// PingEach verifies each physical database concurrently like PingContext,
+// returning the latency and the error of each, the master first.
+func (db *DB) PingEach(ctx context.Context) ([]time.Duration, []error) {
+	latencies := make([]time.Duration, len(db.dbs))
+	errs := make([]error, len(db.dbs))
+
+	_ = scatter(len(db.dbs), func(idx int) error {
+		start := time.Now()
+		errs[idx] = db.dbs[idx].PingContext(ctx)
+		latencies[idx] = time.Since(start)
+		return nil
+	})
+
+	return latencies, errs
+}
+
 // Prepare creates a prepared statement for later queries or executions
 // on each physical database, concurrently.
 func (db *DB) Prepare(query string) (Stmt, error) {
@@ -0,0 +1,183 @@
+// Package dbtest provide a database/sql driver answering from memory, so the
+// code running statements through infra/db can be tested without a database.
+package dbtest
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// registered number the drivers, database/sql refusing a name twice
+var registered uint64
+
+// Driver answer every query with Rows of Columns and every other statement
+// with RowsAffected and LastInsertID, the later moving by AutoIncrement after
+// each statement when set. ExecErr fails every statement other than a query
+// when set. Queries records the statements run, and the commits and
+// rollbacks as COMMIT and ROLLBACK.
+type Driver struct {
+	Columns       []string
+	Rows          [][]driver.Value
+	RowsAffected  int64
+	LastInsertID  int64
+	AutoIncrement int64
+	ExecErr       error
+
+	mtx     sync.Mutex
+	queries []string
+}
+
+// Register the driver with database/sql, returning the name to open it with
+func Register(d *Driver) string {
+	name := fmt.Sprintf("dbtest-%d", atomic.AddUint64(&registered, 1))
+	sql.Register(name, d)
+
+	return name
+}
+
+// Queries the statements run so far, in order
+func (d *Driver) Queries() []string {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	return append([]string{}, d.queries...)
+}
+
+func (d *Driver) record(query string) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	d.queries = append(d.queries, query)
+}
+
+// result the result of a statement other than a query
+func (d *Driver) result() (driver.Result, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	if d.ExecErr != nil {
+		return nil, d.ExecErr
+	}
+
+	res := result{d.LastInsertID, d.RowsAffected}
+	d.LastInsertID += d.AutoIncrement
+
+	return res, nil
+}
+
+func (d *Driver) Open(string) (driver.Conn, error) {
+	return &conn{d: d}, nil
+}
+
+// conn a connection of the driver, running the statements without preparing
+// them
+type conn struct {
+	d *Driver
+}
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return &stmt{d: c.d, query: query}, nil
+}
+
+func (c *conn) Close() error {
+	return nil
+}
+
+func (c *conn) Begin() (driver.Tx, error) {
+	return tx{d: c.d}, nil
+}
+
+func (c *conn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) {
+	return tx{d: c.d}, nil
+}
+
+func (c *conn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	c.d.record(query)
+	return c.d.result()
+}
+
+func (c *conn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	c.d.record(query)
+	return &rows{columns: c.d.Columns, values: c.d.Rows}, nil
+}
+
+// stmt a statement prepared by database/sql, such as the ones of sqlx.Preparex
+type stmt struct {
+	d     *Driver
+	query string
+}
+
+func (s *stmt) Close() error {
+	return nil
+}
+
+func (s *stmt) NumInput() int {
+	return -1
+}
+
+func (s *stmt) Exec([]driver.Value) (driver.Result, error) {
+	s.d.record(s.query)
+	return s.d.result()
+}
+
+func (s *stmt) Query([]driver.Value) (driver.Rows, error) {
+	s.d.record(s.query)
+	return &rows{columns: s.d.Columns, values: s.d.Rows}, nil
+}
+
+type tx struct {
+	d *Driver
+}
+
+func (t tx) Commit() error {
+	t.d.record("COMMIT")
+	return nil
+}
+
+func (t tx) Rollback() error {
+	t.d.record("ROLLBACK")
+	return nil
+}
+
+type result struct {
+	lastInsertID int64
+	rowsAffected int64
+}
+
+func (r result) LastInsertId() (int64, error) {
+	return r.lastInsertID, nil
+}
+
+func (r result) RowsAffected() (int64, error) {
+	return r.rowsAffected, nil
+}
+
+// rows the rows of a query, read once
+type rows struct {
+	columns []string
+	values  [][]driver.Value
+	next    int
+}
+
+func (r *rows) Columns() []string {
+	return r.columns
+}
+
+func (r *rows) Close() error {
+	return nil
+}
+
+func (r *rows) Next(dest []driver.Value) error {
+	if r.next == len(r.values) {
+		return io.EOF
+	}
+	copy(dest, r.values[r.next])
+	r.next++
+
+	return nil
+}
@@ -0,0 +1,375 @@
+package db
+
+import (
+	"context"
+	"database/sql/driver"
+	"io"
+	"time"
+	"todolist-api/infra/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	attrStatementName = attribute.Key("db.statement.name")
+	attrRowsAffected  = attribute.Key("db.rows_affected")
+	attrRowsReturned  = attribute.Key("db.rows_returned")
+)
+
+// Hooks observe the transactions of the physical databases. Every field is optional.
+type Hooks struct {
+	OnBegin    func(ctx context.Context, err error)
+	OnCommit   func(ctx context.Context, err error)
+	OnRollback func(ctx context.Context, err error)
+}
+
+type hookList []Hooks
+
+func (h hookList) begin(ctx context.Context, err error) {
+	for _, x := range h {
+		if x.OnBegin != nil {
+			x.OnBegin(ctx, err)
+		}
+	}
+}
+
+func (h hookList) commit(ctx context.Context, err error) {
+	for _, x := range h {
+		if x.OnCommit != nil {
+			x.OnCommit(ctx, err)
+		}
+	}
+}
+
+func (h hookList) rollback(ctx context.Context, err error) {
+	for _, x := range h {
+		if x.OnRollback != nil {
+			x.OnRollback(ctx, err)
+		}
+	}
+}
+
+// instrumentation shared by the connections of a physical database
+type instrumentation struct {
+	system     string
+	hooks      hookList
+	statements *statements
+}
+
+// span start a span of a statement that already ran since start
+func (in *instrumentation) span(ctx context.Context, query string, start time.Time) (context.Context, trace.Span) {
+	name := in.statements.name(query)
+	spanName := "db." + name
+	if name == "" {
+		spanName = "db." + operation(query)
+	}
+
+	return tracing.Tracer().Start(ctx, spanName,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithTimestamp(start),
+		trace.WithAttributes(
+			semconv.DBSystemKey.String(in.system),
+			semconv.DBStatement(query),
+			semconv.DBOperation(operation(query)),
+			attrStatementName.String(name),
+		),
+	)
+}
+
+// endResult end the span of a statement that doesn't return rows
+func endResult(span trace.Span, res driver.Result, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	} else if n, err := res.RowsAffected(); err == nil {
+		span.SetAttributes(attrRowsAffected.Int64(n))
+	}
+	span.End()
+}
+
+// connector open the connections of a physical database through the driver
+// and wrap them, so transactions and statements are traced and hooked
+type connector struct {
+	dsn  string
+	drv  driver.Driver
+	base driver.Connector
+	in   *instrumentation
+}
+
+func newConnector(drv driver.Driver, dsn string, in *instrumentation) (*connector, error) {
+	c := &connector{dsn: dsn, drv: drv, in: in}
+	if dc, ok := drv.(driver.DriverContext); ok {
+		base, err := dc.OpenConnector(dsn)
+		if err != nil {
+			return nil, err
+		}
+		c.base = base
+	}
+
+	return c, nil
+}
+
+func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
+	var cn driver.Conn
+	var err error
+	if c.base != nil {
+		cn, err = c.base.Connect(ctx)
+	} else {
+		cn, err = c.drv.Open(c.dsn)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &driverConn{Conn: cn, in: c.in}, nil
+}
+
+func (c *connector) Driver() driver.Driver {
+	return c.drv
+}
+
+// driverConn forward the optional interfaces of the driver connection, so
+// database/sql keeps its fast paths
+type driverConn struct {
+	driver.Conn
+	in *instrumentation
+}
+
+func (c *driverConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "db.Begin", trace.WithSpanKind(trace.SpanKindClient))
+	defer span.End()
+
+	var tx driver.Tx
+	var err error
+	if b, ok := c.Conn.(driver.ConnBeginTx); ok {
+		tx, err = b.BeginTx(ctx, opts)
+	} else {
+		// drivers without BeginTx only support the default options
+		tx, err = c.Conn.Begin()
+	}
+
+	c.in.hooks.begin(ctx, err)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return &driverTx{Tx: tx, ctx: ctx, in: c.in}, nil
+}
+
+func (c *driverConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var st driver.Stmt
+	var err error
+	if p, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		st, err = p.PrepareContext(ctx, query)
+	} else {
+		st, err = c.Conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &driverStmt{Stmt: st, query: query, in: c.in}, nil
+}
+
+func (c *driverConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	e, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	start := time.Now()
+	res, err := e.ExecContext(ctx, query, args)
+	// the statement is prepared and run again through driverStmt
+	if err == driver.ErrSkip {
+		return nil, err
+	}
+
+	_, span := c.in.span(ctx, query, start)
+	endResult(span, res, err)
+
+	return res, err
+}
+
+func (c *driverConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	q, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	start := time.Now()
+	rows, err := q.QueryContext(ctx, query, args)
+	if err == driver.ErrSkip {
+		return nil, err
+	}
+
+	return traceRows(ctx, c.in, query, start, rows, err)
+}
+
+func (c *driverConn) Ping(ctx context.Context) error {
+	if p, ok := c.Conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+
+	return nil
+}
+
+func (c *driverConn) ResetSession(ctx context.Context) error {
+	if r, ok := c.Conn.(driver.SessionResetter); ok {
+		return r.ResetSession(ctx)
+	}
+
+	return nil
+}
+
+func (c *driverConn) IsValid() bool {
+	if v, ok := c.Conn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+
+	return true
+}
+
+func (c *driverConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if ch, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return ch.CheckNamedValue(nv)
+	}
+
+	return driver.ErrSkip
+}
+
+// driverStmt trace the executions of a prepared statement
+type driverStmt struct {
+	driver.Stmt
+	query string
+	in    *instrumentation
+}
+
+func (s *driverStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	start := time.Now()
+
+	var res driver.Result
+	var err error
+	if e, ok := s.Stmt.(driver.StmtExecContext); ok {
+		res, err = e.ExecContext(ctx, args)
+	} else {
+		res, err = s.Stmt.Exec(values(args))
+	}
+
+	_, span := s.in.span(ctx, s.query, start)
+	endResult(span, res, err)
+
+	return res, err
+}
+
+func (s *driverStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	start := time.Now()
+
+	var rows driver.Rows
+	var err error
+	if q, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		rows, err = q.QueryContext(ctx, args)
+	} else {
+		rows, err = s.Stmt.Query(values(args))
+	}
+
+	return traceRows(ctx, s.in, s.query, start, rows, err)
+}
+
+func (s *driverStmt) CheckNamedValue(nv *driver.NamedValue) error {
+	if ch, ok := s.Stmt.(driver.NamedValueChecker); ok {
+		return ch.CheckNamedValue(nv)
+	}
+
+	return driver.ErrSkip
+}
+
+// values convert the arguments for drivers without context support
+func values(args []driver.NamedValue) []driver.Value {
+	res := make([]driver.Value, len(args))
+	for i, x := range args {
+		res[i] = x.Value
+	}
+
+	return res
+}
+
+// traceRows start the span of a query, ended once its rows are closed
+func traceRows(ctx context.Context, in *instrumentation, query string, start time.Time, rows driver.Rows, err error) (driver.Rows, error) {
+	_, span := in.span(ctx, query, start)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		span.End()
+		return nil, err
+	}
+
+	return &driverRows{Rows: rows, span: span}, nil
+}
+
+// driverRows count the rows read
+type driverRows struct {
+	driver.Rows
+	span  trace.Span
+	count int64
+}
+
+func (r *driverRows) Next(dest []driver.Value) error {
+	err := r.Rows.Next(dest)
+	switch {
+	case err == nil:
+		r.count++
+	case err != io.EOF:
+		r.span.RecordError(err)
+		r.span.SetStatus(codes.Error, err.Error())
+	}
+
+	return err
+}
+
+func (r *driverRows) Close() error {
+	err := r.Rows.Close()
+	r.span.SetAttributes(attrRowsReturned.Int64(r.count))
+	r.span.End()
+
+	return err
+}
+
+// driverTx trace and hook the end of a transaction
+type driverTx struct {
+	driver.Tx
+	ctx context.Context
+	in  *instrumentation
+}
+
+func (t *driverTx) Commit() error {
+	_, span := tracing.Tracer().Start(t.ctx, "db.Commit", trace.WithSpanKind(trace.SpanKindClient))
+	defer span.End()
+
+	err := t.Tx.Commit()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	t.in.hooks.commit(t.ctx, err)
+
+	return err
+}
+
+func (t *driverTx) Rollback() error {
+	_, span := tracing.Tracer().Start(t.ctx, "db.Rollback", trace.WithSpanKind(trace.SpanKindClient))
+	defer span.End()
+
+	err := t.Tx.Rollback()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	t.in.hooks.rollback(t.ctx, err)
+
+	return err
+}
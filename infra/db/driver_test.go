@@ -0,0 +1,152 @@
+package db
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+	"todolist-api/config"
+	"todolist-api/infra/db/dbtest"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// record install a tracer provider recording the spans ended
+func record(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+	})
+
+	return recorder
+}
+
+// attr the value of the attribute key of span, false when missing
+func attr(span sdktrace.ReadOnlySpan, key attribute.Key) (attribute.Value, bool) {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value, true
+		}
+	}
+
+	return attribute.Value{}, false
+}
+
+// spanNamed the span named name, failing the test when missing
+func spanNamed(t *testing.T, spans []sdktrace.ReadOnlySpan, name string) sdktrace.ReadOnlySpan {
+	t.Helper()
+
+	for _, span := range spans {
+		if span.Name() == name {
+			return span
+		}
+	}
+
+	names := make([]string, 0, len(spans))
+	for _, span := range spans {
+		names = append(names, span.Name())
+	}
+	t.Fatalf("no span %s in %v", name, names)
+
+	return nil
+}
+
+func open(t *testing.T, d *dbtest.Driver) *DB {
+	t.Helper()
+
+	conn, err := Open(&config.DBConfig{Name: dbtest.Register(d), Host: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+
+	return conn
+}
+
+func TestTraceQuery(t *testing.T) {
+	recorder := record(t)
+	conn := open(t, &dbtest.Driver{
+		Columns: []string{"id", "title"},
+		Rows:    [][]driver.Value{{int64(1), "a"}, {int64(2), "b"}, {int64(3), "c"}},
+	})
+	conn.NameStatements("todo", map[string]string{
+		"GetAllTodo": "SELECT id, title FROM todos",
+	})
+
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "parent")
+	var ids []struct {
+		ID    int    `db:"id"`
+		Title string `db:"title"`
+	}
+	// completed with a WHERE clause, the name of the query it starts with is kept
+	if err := conn.Slave().SelectContext(ctx, &ids, "SELECT id, title\n\tFROM todos WHERE is_active = ?", true); err != nil {
+		t.Fatal(err)
+	}
+	parent.End()
+
+	span := spanNamed(t, recorder.Ended(), "db.todo.GetAllTodo")
+	if span.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Fatalf("parent %s, expected %s", span.Parent().SpanID(), parent.SpanContext().SpanID())
+	}
+	if v, _ := attr(span, attrStatementName); v.AsString() != "todo.GetAllTodo" {
+		t.Fatalf("statement name %q", v.AsString())
+	}
+	if v, ok := attr(span, attrRowsReturned); !ok || v.AsInt64() != 3 {
+		t.Fatalf("rows returned %v", v.AsInt64())
+	}
+	if v, _ := attr(span, "db.operation"); v.AsString() != "SELECT" {
+		t.Fatalf("operation %q", v.AsString())
+	}
+}
+
+func TestTraceTransaction(t *testing.T) {
+	recorder := record(t)
+	conn := open(t, &dbtest.Driver{RowsAffected: 4})
+	conn.NameStatements("todo", map[string]string{
+		"UpdateTodos": "UPDATE todos SET is_active = ?",
+	})
+
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "parent")
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.ExecContext(ctx, "UPDATE todos SET is_active = ? WHERE todo_id IN (?, ?, ?, ?)", false, 1, 2, 3, 4); err != nil {
+		t.Fatal(err)
+	}
+	// unnamed statements are named after their verb
+	if _, err := tx.ExecContext(ctx, "DELETE FROM changes"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	parent.End()
+
+	spans := recorder.Ended()
+	for _, name := range []string{"db.Begin", "db.todo.UpdateTodos", "db.DELETE"} {
+		if span := spanNamed(t, spans, name); span.Parent().SpanID() != parent.SpanContext().SpanID() {
+			t.Fatalf("%s is not a child of the parent", name)
+		}
+	}
+
+	update := spanNamed(t, spans, "db.todo.UpdateTodos")
+	if v, ok := attr(update, attrRowsAffected); !ok || v.AsInt64() != 4 {
+		t.Fatalf("rows affected %v", v.AsInt64())
+	}
+
+	// the commit belongs to the span of the transaction
+	commit := spanNamed(t, spans, "db.Commit")
+	if commit.Parent().SpanID() != spanNamed(t, spans, "db.Begin").SpanContext().SpanID() {
+		t.Fatal("commit is not a child of db.Begin")
+	}
+}
@@ -0,0 +1,160 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/jmoiron/sqlx"
+	log "github.com/sirupsen/logrus"
+)
+
+// ErrScopeBusy a transaction begun within a Scope while another one of the
+// scope is still open, which would wait forever for the single connection of
+// the scope
+var ErrScopeBusy = errors.New("scope: a transaction of the scope is still open")
+
+// scopeKey the context key of the scope of a Scope
+type scopeKey struct{}
+
+// scope the database of a Scope and its connection
+type scope struct {
+	db   *sqlx.DB
+	conn *scopeConn
+}
+
+// Scope run fn within one transaction of the master. The transactions begun
+// with Begin or BeginTx on the context given to fn are savepoints of it,
+// committed or rolled back along with it once fn returns, rolled back when fn
+// fails. They run one at a time on a single connection, and the reads made
+// outside of them don't see their writes until the end of the scope, and
+// beginning one while another is open fails with ErrScopeBusy. A scope within
+// a scope joins it.
+func (db *DB) Scope(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(scopeKey{}).(*scope); ok {
+		return fn(ctx)
+	}
+
+	conn, err := db.Master().Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return conn.Raw(func(dc interface{}) error {
+		c, ok := dc.(*driverConn)
+		if !ok {
+			return errors.New("scope: connection not opened through db.Open")
+		}
+
+		tx, err := c.BeginTx(ctx, driver.TxOptions{})
+		if err != nil {
+			return err
+		}
+
+		sc := &scopeConn{driverConn: c}
+		scoped := sql.OpenDB(&scopeConnector{conn: sc, drv: db.Master().Driver()})
+		scoped.SetMaxOpenConns(1)
+
+		err = fn(context.WithValue(ctx, scopeKey{}, &scope{db: sqlx.NewDb(scoped, db.driver), conn: sc}))
+		_ = scoped.Close()
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				log.Errorf("scope rollback: %v", rbErr)
+			}
+			return err
+		}
+
+		return tx.Commit()
+	})
+}
+
+// begin return the database of the scope of ctx, the master without scope
+func (db *DB) begin(ctx context.Context) (*sqlx.DB, error) {
+	s, ok := ctx.Value(scopeKey{}).(*scope)
+	if !ok {
+		return db.Master(), nil
+	}
+
+	// the open savepoint holds the only connection until it ends
+	if atomic.LoadInt32(&s.conn.open) > 0 {
+		return nil, ErrScopeBusy
+	}
+
+	return s.db, nil
+}
+
+// scopeConnector hand the connection of the scope to its database
+type scopeConnector struct {
+	conn *scopeConn
+	drv  driver.Driver
+}
+
+func (c *scopeConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.conn, nil
+}
+
+func (c *scopeConnector) Driver() driver.Driver {
+	return c.drv
+}
+
+// scopeConn turn the transactions into savepoints of the transaction of the
+// scope, the connection itself belonging to the pool of the master
+type scopeConn struct {
+	*driverConn
+	savepoints int
+	open       int32
+}
+
+func (c *scopeConn) BeginTx(ctx context.Context, _ driver.TxOptions) (driver.Tx, error) {
+	c.savepoints++
+	name := fmt.Sprintf("scope_%d", c.savepoints)
+	if err := c.exec(ctx, "SAVEPOINT "+name); err != nil {
+		return nil, err
+	}
+	atomic.StoreInt32(&c.open, 1)
+
+	return &savepoint{conn: c, name: name}, nil
+}
+
+// Close leave the connection open, it goes back to the pool of the master
+func (c *scopeConn) Close() error {
+	return nil
+}
+
+func (c *scopeConn) exec(ctx context.Context, query string) error {
+	_, err := c.driverConn.ExecContext(ctx, query, nil)
+	if err != driver.ErrSkip {
+		return err
+	}
+
+	st, err := c.driverConn.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+
+	_, err = st.(driver.StmtExecContext).ExecContext(ctx, nil)
+
+	return err
+}
+
+// savepoint a transaction within the transaction of a scope. It ends
+// without context, the one it began with may be cancelled by then.
+type savepoint struct {
+	conn *scopeConn
+	name string
+}
+
+func (s *savepoint) Commit() error {
+	defer atomic.StoreInt32(&s.conn.open, 0)
+	return s.conn.exec(context.Background(), "RELEASE SAVEPOINT "+s.name)
+}
+
+func (s *savepoint) Rollback() error {
+	defer atomic.StoreInt32(&s.conn.open, 0)
+	return s.conn.exec(context.Background(), "ROLLBACK TO SAVEPOINT "+s.name)
+}
@@ -0,0 +1,74 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+	"todolist-api/config"
+	"todolist-api/infra/db/dbtest"
+)
+
+func TestScopeNestedBegin(t *testing.T) {
+	d := &dbtest.Driver{}
+	conn, err := Open(&config.DBConfig{Name: dbtest.Register(d), Host: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- conn.Scope(context.Background(), func(ctx context.Context) error {
+			outer, err := conn.Begin(ctx)
+			if err != nil {
+				return err
+			}
+
+			// the outer savepoint holds the connection of the scope
+			if _, err := conn.Begin(ctx); !errors.Is(err, ErrScopeBusy) {
+				return errors.New("nested begin: " + errString(err))
+			}
+
+			if err := outer.Commit(); err != nil {
+				return err
+			}
+
+			// the connection is free again once the savepoint ended
+			next, err := conn.Begin(ctx)
+			if err != nil {
+				return err
+			}
+			return next.Rollback()
+		})
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the nested begin waits for the connection of the scope")
+	}
+
+	expected := []string{
+		"SAVEPOINT scope_1",
+		"RELEASE SAVEPOINT scope_1",
+		"SAVEPOINT scope_2",
+		"ROLLBACK TO SAVEPOINT scope_2",
+		"COMMIT",
+	}
+	if queries := d.Queries(); strings.Join(queries, "; ") != strings.Join(expected, "; ") {
+		t.Fatalf("statements %q, expected %q", queries, expected)
+	}
+}
+
+func errString(err error) string {
+	if err == nil {
+		return "no error"
+	}
+
+	return err.Error()
+}
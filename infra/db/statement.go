@@ -0,0 +1,68 @@
+package db
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// placeholders of an IN list expanded by sqlx.In, such as (?, ?, ?)
+var inList = regexp.MustCompile(`\(\s*\?(\s*,\s*\?)*\s*\)`)
+
+// statements name the queries of the repositories, so traces tell which query
+// ran without parsing SQL
+type statements struct {
+	mtx   sync.RWMutex
+	names map[string]string
+}
+
+func newStatements() *statements {
+	return &statements{names: map[string]string{}}
+}
+
+// register name each query of the map, prefixed with prefix and a dot
+func (s *statements) register(prefix string, queries map[string]string) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	for name, query := range queries {
+		s.names[normalize(query)] = prefix + "." + name
+	}
+}
+
+// name return the name of the query, matching the longest registered query it
+// starts with, so queries completed with a WHERE clause keep their name
+func (s *statements) name(query string) string {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	query = normalize(query)
+	if name, ok := s.names[query]; ok {
+		return name
+	}
+
+	var name string
+	var longest int
+	for registered, n := range s.names {
+		if len(registered) > longest && strings.HasPrefix(query, registered) {
+			name, longest = n, len(registered)
+		}
+	}
+
+	return name
+}
+
+// normalize collapse whitespace and IN lists
+func normalize(query string) string {
+	return inList.ReplaceAllString(strings.Join(strings.Fields(query), " "), "(?)")
+}
+
+// operation return the SQL verb of the query, such as SELECT
+func operation(query string) string {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return ""
+	}
+
+	return strings.ToUpper(fields[0])
+}
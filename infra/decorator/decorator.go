@@ -0,0 +1,66 @@
+// Package decorator observe the calls of the services, shared by the
+// decorator each service package wraps its interface with.
+package decorator
+
+import (
+	"context"
+	"time"
+	"todolist-api/infra/metrics"
+	"todolist-api/infra/tracing"
+
+	"go.opentelemetry.io/otel/codes"
+)
+
+// Decorator observe the calls of the methods of a service, as timings when
+// built by Metrics and as spans when built by Tracing
+type Decorator struct {
+	start func(ctx context.Context, method string) (context.Context, func(err error))
+}
+
+// Metrics time the calls of service, such as todo, with m
+func Metrics(service string, m *metrics.Metrics) *Decorator {
+	return &Decorator{
+		start: func(ctx context.Context, method string) (context.Context, func(error)) {
+			start := time.Now()
+			return ctx, func(err error) {
+				m.ObserveService(service, method, time.Since(start), err)
+			}
+		},
+	}
+}
+
+// Tracing open a span around the calls, named after service and the method
+// such as TodoService.CreateTodo
+func Tracing(service string) *Decorator {
+	return &Decorator{
+		start: func(ctx context.Context, method string) (context.Context, func(error)) {
+			ctx, span := tracing.Tracer().Start(ctx, service+"."+method)
+			return ctx, func(err error) {
+				if err != nil {
+					span.RecordError(err)
+					span.SetStatus(codes.Error, err.Error())
+				}
+				span.End()
+			}
+		},
+	}
+}
+
+// Call observe the call of method made by fn
+func Call[T any](ctx context.Context, d *Decorator, method string, fn func(ctx context.Context) (T, error)) (T, error) {
+	ctx, done := d.start(ctx, method)
+	data, err := fn(ctx)
+	done(err)
+
+	return data, err
+}
+
+// Exec observe the call of method made by fn, for the methods returning an
+// error alone
+func Exec(ctx context.Context, d *Decorator, method string, fn func(ctx context.Context) error) error {
+	ctx, done := d.start(ctx, method)
+	err := fn(ctx)
+	done(err)
+
+	return err
+}
@@ -0,0 +1,80 @@
+package decorator_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"todolist-api/infra/decorator"
+	"todolist-api/infra/metrics"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var errFailed = errors.New("failed")
+
+func TestTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+	})
+
+	d := decorator.Tracing("TodoService")
+	ctx := context.Background()
+
+	// the wrapped method runs in the span
+	data, err := decorator.Call(ctx, d, "GetOneTodo", func(ctx context.Context) (int, error) {
+		if !trace.SpanFromContext(ctx).SpanContext().IsValid() {
+			t.Error("expected the call to run in the span")
+		}
+		return 7, nil
+	})
+	if data != 7 || err != nil {
+		t.Fatalf("%d, %v", data, err)
+	}
+
+	if err := decorator.Exec(ctx, d, "DeleteTodo", func(context.Context) error { return errFailed }); err != errFailed {
+		t.Fatalf("error %v, expected the one of the call", err)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 2 || spans[0].Name() != "TodoService.GetOneTodo" || spans[1].Name() != "TodoService.DeleteTodo" {
+		t.Fatalf("unexpected spans %v", spans)
+	}
+	if spans[0].Status().Code == codes.Error {
+		t.Fatal("span of a successful call marked as an error")
+	}
+	if spans[1].Status().Code != codes.Error || spans[1].Status().Description != "failed" || len(spans[1].Events()) != 1 {
+		t.Fatalf("span of a failed call: %+v, %d events", spans[1].Status(), len(spans[1].Events()))
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	m := metrics.New()
+	d := decorator.Metrics("todo", m)
+	ctx := context.Background()
+
+	_, _ = decorator.Call(ctx, d, "GetOneTodo", func(context.Context) (int, error) { return 7, nil })
+	_ = decorator.Exec(ctx, d, "DeleteTodo", func(context.Context) error { return errFailed })
+
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := io.ReadAll(w.Body)
+
+	for _, series := range []string{
+		`method="GetOneTodo",outcome="ok",service="todo"`,
+		`method="DeleteTodo",outcome="error",service="todo"`,
+	} {
+		if !strings.Contains(string(body), "call_duration_seconds_count{"+series+"} 1") {
+			t.Fatalf("no call counted for %s", series)
+		}
+	}
+}
@@ -15,6 +15,11 @@ type errWrapper struct {
 	stack *stack
 }
 
+// Unwrap return the wrapped error, for errors.Is and errors.As
+func (w *errWrapper) Unwrap() error {
+	return w.error
+}
+
 func callers(pos int) *stack {
 	var pcs [depth]uintptr
 	n := runtime.Callers(3, pcs[:])
@@ -47,3 +52,14 @@ func Wrap(err error) error {
 func New(message string) error {
 	return wrapErr(errors.New(message), 1)
 }
+
+// Is report whether any error in the chain of err matches target
+func Is(err, target error) bool {
+	return errors.Is(err, target)
+}
+
+// As find the first error in the chain of err matching target, setting
+// target to it
+func As(err error, target interface{}) bool {
+	return errors.As(err, target)
+}
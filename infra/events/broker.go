@@ -0,0 +1,155 @@
+package events
+
+import (
+	"context"
+	"sync"
+	"time"
+	"todolist-api/infra/logger"
+)
+
+const (
+	defaultBufferSize       = 1024
+	subscriptionChannelSize = 64
+)
+
+// Broker publish/subscribe hub. Published events go through the fan-out and
+// come back to every broker sharing it; the most recent events are kept in a
+// ring buffer so a reconnecting subscriber can resume from its last event ID.
+type Broker struct {
+	mtx         sync.RWMutex
+	fanOut      FanOut
+	lastID      uint64
+	ring        []Event
+	next        int
+	full        bool
+	subscribers map[*Subscription]struct{}
+}
+
+// Subscription receive events published after it was created
+type Subscription struct {
+	C      <-chan Event
+	ch     chan Event
+	broker *Broker
+	once   sync.Once
+}
+
+// NewBroker create a broker keeping the last size events for resume, a nil
+// fan-out keeps the events within the process
+func NewBroker(size int, fanOut FanOut) *Broker {
+	if size <= 0 {
+		size = defaultBufferSize
+	}
+
+	if fanOut == nil {
+		fanOut = NewLocalFanOut()
+	}
+
+	b := &Broker{
+		fanOut:      fanOut,
+		ring:        make([]Event, size),
+		subscribers: make(map[*Subscription]struct{}),
+	}
+	fanOut.Subscribe(b.deliver)
+
+	return b
+}
+
+// Publish hand the event to the fan-out, or hold it until the commit when ctx
+// comes from Defer
+func (b *Broker) Publish(ctx context.Context, event Event) {
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+
+	if d, ok := deferred(ctx); ok {
+		d.add(b, event)
+		return
+	}
+
+	if err := b.fanOut.Publish(ctx, event); err != nil {
+		logger.FromContext(ctx).Error(err)
+	}
+}
+
+// deliver assign the next event ID and pass the event to every subscriber.
+// A subscriber that can't keep up is dropped, it resumes through Last-Event-ID.
+func (b *Broker) deliver(event Event) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	b.lastID++
+	event.ID = b.lastID
+
+	b.ring[b.next] = event
+	b.next = (b.next + 1) % len(b.ring)
+	if b.next == 0 {
+		b.full = true
+	}
+
+	for sub := range b.subscribers {
+		select {
+		case sub.ch <- event:
+		default:
+			b.drop(sub)
+		}
+	}
+}
+
+// Subscribe register a new subscription. When lastID is not zero the events
+// published after it are returned for replay; ok is false when they are no
+// longer buffered and the subscriber has to reload its state.
+func (b *Broker) Subscribe(lastID uint64) (sub *Subscription, missed []Event, ok bool) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	ch := make(chan Event, subscriptionChannelSize)
+	sub = &Subscription{C: ch, ch: ch, broker: b}
+	b.subscribers[sub] = struct{}{}
+
+	if lastID == 0 {
+		return sub, nil, true
+	}
+
+	if lastID > b.lastID {
+		// the ID belongs to a previous process
+		return sub, nil, false
+	}
+
+	buffered := b.buffered()
+	if lastID < b.lastID && (len(buffered) == 0 || buffered[0].ID > lastID+1) {
+		return sub, nil, false
+	}
+
+	for _, event := range buffered {
+		if event.ID > lastID {
+			missed = append(missed, event)
+		}
+	}
+
+	return sub, missed, true
+}
+
+// Close unregister the subscription and close its channel
+func (s *Subscription) Close() {
+	s.broker.mtx.Lock()
+	defer s.broker.mtx.Unlock()
+
+	s.broker.drop(s)
+}
+
+// drop must be called with the lock held
+func (b *Broker) drop(sub *Subscription) {
+	sub.once.Do(func() {
+		delete(b.subscribers, sub)
+		close(sub.ch)
+	})
+}
+
+// buffered return the ring buffer content oldest first, must be called with the lock held
+func (b *Broker) buffered() []Event {
+	if !b.full {
+		return append([]Event(nil), b.ring[:b.next]...)
+	}
+
+	return append(append([]Event(nil), b.ring[b.next:]...), b.ring[:b.next]...)
+}
@@ -0,0 +1,88 @@
+package events_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"todolist-api/infra/events"
+)
+
+// publish n events on b
+func publish(b *events.Broker, n int) {
+	for i := 0; i < n; i++ {
+		b.Publish(context.Background(), events.Event{Type: "todo.created"})
+	}
+}
+
+func ids(list []events.Event) []uint64 {
+	var res []uint64
+	for _, x := range list {
+		res = append(res, x.ID)
+	}
+
+	return res
+}
+
+func TestSubscribeResume(t *testing.T) {
+	tests := []struct {
+		name      string
+		published int
+		lastID    uint64
+		missed    []uint64
+		resumed   bool
+	}{
+		{"new subscriber", 3, 0, nil, true},
+		{"behind", 3, 1, []uint64{2, 3}, true},
+		{"up to date", 3, 3, nil, true},
+		// the ring of 4 holds the events 3 to 6
+		{"behind past the wrap", 6, 2, []uint64{3, 4, 5, 6}, true},
+		{"older than the ring", 6, 1, nil, false},
+		{"from a previous process", 3, 7, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := events.NewBroker(4, events.NewLocalFanOut())
+			publish(b, tt.published)
+
+			sub, missed, resumed := b.Subscribe(tt.lastID)
+			defer sub.Close()
+
+			if resumed != tt.resumed {
+				t.Fatalf("resumed %t, expected %t", resumed, tt.resumed)
+			}
+			if got := ids(missed); !reflect.DeepEqual(got, tt.missed) {
+				t.Fatalf("missed %v, expected %v", got, tt.missed)
+			}
+
+			// the next event follows the replay
+			publish(b, 1)
+			if event := <-sub.C; event.ID != uint64(tt.published+1) {
+				t.Fatalf("next event %d, expected %d", event.ID, tt.published+1)
+			}
+		})
+	}
+}
+
+func TestSlowSubscriberDropped(t *testing.T) {
+	b := events.NewBroker(256, events.NewLocalFanOut())
+	sub, _, _ := b.Subscribe(0)
+
+	// more events than the subscription holds, none read
+	publish(b, 100)
+
+	var last uint64
+	for event := range sub.C {
+		last = event.ID
+	}
+	if last == 0 || last == 100 {
+		t.Fatalf("subscription not dropped, last event %d", last)
+	}
+
+	// it resumes from the last event it got
+	resumed, missed, ok := b.Subscribe(last)
+	defer resumed.Close()
+	if !ok || len(missed) != int(100-last) || missed[0].ID != last+1 {
+		t.Fatalf("resumed %t with %v after %d", ok, ids(missed), last)
+	}
+}
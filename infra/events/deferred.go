@@ -0,0 +1,64 @@
+package events
+
+import (
+	"context"
+	"sync"
+)
+
+// deferredKey the context key of the events held until a commit
+type deferredKey struct{}
+
+// Deferred the events published with a context of Defer, held until the
+// changes they announce are committed
+type Deferred struct {
+	mtx    sync.Mutex
+	events []deferredEvent
+}
+
+type deferredEvent struct {
+	publisher Publisher
+	event     Event
+}
+
+// Defer return a copy of ctx whose published events are held by the returned
+// Deferred instead, to be flushed once committed or dropped on rollback
+func Defer(ctx context.Context) (context.Context, *Deferred) {
+	d := &Deferred{}
+
+	return context.WithValue(ctx, deferredKey{}, d), d
+}
+
+// deferred return the holder of the events published with ctx, if any
+func deferred(ctx context.Context) (*Deferred, bool) {
+	d, ok := ctx.Value(deferredKey{}).(*Deferred)
+	return d, ok && d != nil
+}
+
+func (d *Deferred) add(p Publisher, event Event) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	d.events = append(d.events, deferredEvent{publisher: p, event: event})
+}
+
+// Flush publish the held events in order
+func (d *Deferred) Flush(ctx context.Context) {
+	d.mtx.Lock()
+	held := d.events
+	d.events = nil
+	d.mtx.Unlock()
+
+	// the events are published for good this time
+	ctx = context.WithValue(ctx, deferredKey{}, (*Deferred)(nil))
+	for _, x := range held {
+		x.publisher.Publish(ctx, x.event)
+	}
+}
+
+// Discard drop the held events
+func (d *Deferred) Discard() {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	d.events = nil
+}
@@ -0,0 +1,20 @@
+package events
+
+import (
+	"context"
+	"time"
+)
+
+// Event a change committed by the service layer
+type Event struct {
+	ID              uint64      `json:"id"`
+	Type            string      `json:"type"`
+	ActivityGroupID int         `json:"activity_group_id"`
+	Data            interface{} `json:"data"`
+	CreatedAt       time.Time   `json:"created_at"`
+}
+
+// Publisher publish committed changes to interested subscribers
+type Publisher interface {
+	Publish(ctx context.Context, event Event)
+}
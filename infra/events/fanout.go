@@ -0,0 +1,54 @@
+package events
+
+import (
+	"context"
+	"sync"
+)
+
+// FanOut carry published events to every broker sharing it. The in-process
+// implementation serves a single instance; a message bus backed implementation
+// lets several serve-http instances share their events.
+type FanOut interface {
+	Publish(ctx context.Context, event Event) error
+	Subscribe(handler func(event Event)) (cancel func())
+}
+
+type localFanOut struct {
+	mtx      sync.RWMutex
+	seq      int
+	handlers map[int]func(event Event)
+}
+
+// NewLocalFanOut create a fan-out delivering events within the current process
+func NewLocalFanOut() FanOut {
+	return &localFanOut{
+		handlers: make(map[int]func(event Event)),
+	}
+}
+
+func (l *localFanOut) Publish(ctx context.Context, event Event) error {
+	l.mtx.RLock()
+	defer l.mtx.RUnlock()
+
+	for _, handler := range l.handlers {
+		handler(event)
+	}
+
+	return nil
+}
+
+func (l *localFanOut) Subscribe(handler func(event Event)) func() {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	l.seq++
+	id := l.seq
+	l.handlers[id] = handler
+
+	return func() {
+		l.mtx.Lock()
+		defer l.mtx.Unlock()
+
+		delete(l.handlers, id)
+	}
+}
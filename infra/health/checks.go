@@ -0,0 +1,127 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"todolist-api/infra/db"
+)
+
+// queryMigrations list the goose history, latest first
+const queryMigrations = `SELECT version_id, is_applied FROM goose_db_version ORDER BY id DESC`
+
+// MigrationStatus the schema version of the database against the migrations
+// of the build
+type MigrationStatus struct {
+	Current int64   `json:"current"`
+	Latest  int64   `json:"latest"`
+	Pending []int64 `json:"pending,omitempty"`
+}
+
+// Database ping the master and each replica, reported as db.master and
+// db.replica.<n>
+func Database(database *db.DB) Checker {
+	return func(ctx context.Context) []Check {
+		latencies, errs := database.PingEach(ctx)
+
+		checks := make([]Check, len(errs))
+		for idx := range errs {
+			name := "db.master"
+			if idx > 0 {
+				name = fmt.Sprintf("db.replica.%d", idx)
+			}
+			checks[idx] = result(name, latencies[idx], errs[idx])
+		}
+
+		return checks
+	}
+}
+
+// Migrations compare the versions applied to the master with the goose
+// migrations of migrationsFS, the schema is down while any is pending
+func Migrations(database *db.DB, migrationsFS fs.FS) Checker {
+	return func(ctx context.Context) []Check {
+		start := time.Now()
+		status, err := migrationStatus(ctx, database, migrationsFS)
+		if err == nil && len(status.Pending) > 0 {
+			err = fmt.Errorf("%d pending migrations", len(status.Pending))
+		}
+
+		c := result("migrations", time.Since(start), err)
+		c.Details = status
+
+		return []Check{c}
+	}
+}
+
+func migrationStatus(ctx context.Context, database *db.DB, migrationsFS fs.FS) (*MigrationStatus, error) {
+	known, err := migrationVersions(migrationsFS)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := database.Master().QueryContext(ctx, queryMigrations)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// the latest row of a version tells whether it is applied
+	applied := map[int64]bool{}
+	for rows.Next() {
+		var version int64
+		var isApplied bool
+		if err := rows.Scan(&version, &isApplied); err != nil {
+			return nil, err
+		}
+		if _, seen := applied[version]; !seen {
+			applied[version] = isApplied
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	status := &MigrationStatus{}
+	for version, ok := range applied {
+		if ok && version > status.Current {
+			status.Current = version
+		}
+	}
+	for _, version := range known {
+		if version > status.Latest {
+			status.Latest = version
+		}
+		if !applied[version] {
+			status.Pending = append(status.Pending, version)
+		}
+	}
+
+	return status, nil
+}
+
+// migrationVersions return the sorted versions of the migrations of migrationsFS
+func migrationVersions(migrationsFS fs.FS) ([]int64, error) {
+	files, err := fs.Glob(migrationsFS, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]int64, 0, len(files))
+	for _, file := range files {
+		prefix, _, _ := strings.Cut(path.Base(file), "_")
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("migration %s: no version prefix", file)
+		}
+		versions = append(versions, version)
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i] < versions[j] })
+
+	return versions, nil
+}
@@ -0,0 +1,152 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// defaultTimeout bound each readiness check when no timeout is configured
+const defaultTimeout = 2 * time.Second
+
+// Status of the process or of one of its dependencies
+type Status string
+
+const (
+	// StatusUp the dependency answers
+	StatusUp Status = "up"
+	// StatusDown the dependency fails or the process is draining
+	StatusDown Status = "down"
+)
+
+// BuildInfo the build of the running binary
+type BuildInfo struct {
+	Name      string `json:"name"`
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	GoVersion string `json:"go_version"`
+	Arch      string `json:"arch"`
+	Date      string `json:"date"`
+}
+
+// Check the outcome of probing one dependency
+type Check struct {
+	Name      string      `json:"name"`
+	Status    Status      `json:"status"`
+	LatencyMs float64     `json:"latency_ms"`
+	Error     string      `json:"error,omitempty"`
+	Details   interface{} `json:"details,omitempty"`
+}
+
+// Checker probe one or more dependencies, reporting a check for each
+type Checker func(ctx context.Context) []Check
+
+// Report the state of the process and, for readiness, of its dependencies
+type Report struct {
+	Status        Status    `json:"status"`
+	Draining      bool      `json:"draining,omitempty"`
+	UptimeSeconds float64   `json:"uptime_seconds"`
+	Build         BuildInfo `json:"build"`
+	Checks        []Check   `json:"checks,omitempty"`
+}
+
+// Health hold the readiness checks of the process and whether it is draining
+type Health struct {
+	build    BuildInfo
+	timeout  time.Duration
+	started  time.Time
+	checkers []Checker
+	draining int32
+}
+
+// New return the health of the running build, each readiness check being
+// bounded by timeout
+func New(build BuildInfo, timeout time.Duration) *Health {
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	return &Health{
+		build:   build,
+		timeout: timeout,
+		started: time.Now(),
+	}
+}
+
+// Register add readiness checks, to be registered before serving
+func (h *Health) Register(checkers ...Checker) {
+	h.checkers = append(h.checkers, checkers...)
+}
+
+// Drain report the process as not ready from now on, so the load balancer
+// stops routing to it before the server shuts down
+func (h *Health) Drain() {
+	atomic.StoreInt32(&h.draining, 1)
+}
+
+// Draining tell whether Drain was called
+func (h *Health) Draining() bool {
+	return atomic.LoadInt32(&h.draining) == 1
+}
+
+// Live report the process as up, it answers as long as it serves requests
+func (h *Health) Live() Report {
+	return Report{
+		Status:        StatusUp,
+		Draining:      h.Draining(),
+		UptimeSeconds: time.Since(h.started).Seconds(),
+		Build:         h.build,
+	}
+}
+
+// Ready run the checks concurrently and report the process as up when every
+// dependency is up and it isn't draining
+func (h *Health) Ready(ctx context.Context) Report {
+	report := h.Live()
+
+	results := make([][]Check, len(h.checkers))
+	var wg sync.WaitGroup
+	for idx, checker := range h.checkers {
+		wg.Add(1)
+		go func(idx int, checker Checker) {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(ctx, h.timeout)
+			defer cancel()
+			results[idx] = checker(ctx)
+		}(idx, checker)
+	}
+	wg.Wait()
+
+	report.Checks = []Check{}
+	for _, checks := range results {
+		for _, c := range checks {
+			if c.Status != StatusUp {
+				report.Status = StatusDown
+			}
+			report.Checks = append(report.Checks, c)
+		}
+	}
+
+	if report.Draining {
+		report.Status = StatusDown
+	}
+
+	return report
+}
+
+// result build the check of a dependency answering after latency
+func result(name string, latency time.Duration, err error) Check {
+	c := Check{
+		Name:      name,
+		Status:    StatusUp,
+		LatencyMs: float64(latency.Microseconds()) / 1000,
+	}
+	if err != nil {
+		c.Status = StatusDown
+		c.Error = err.Error()
+	}
+
+	return c
+}
@@ -0,0 +1,224 @@
+package ical
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// property a content line, such as DUE;TZID=Europe/Paris:20230701T090000
+type property struct {
+	line   int
+	name   string
+	params map[string]string
+	value  string
+}
+
+// Decode read the name and the VTODO of the calendar, the other components
+// being left out, and report the lines that can't be read
+func Decode(data []byte) (Calendar, []Error) {
+	props, errs := unfold(string(data))
+	if len(props) == 0 || props[0].name != "BEGIN" || !strings.EqualFold(props[0].value, "VCALENDAR") {
+		return Calendar{}, append(errs, Error{Line: 1, Message: "not an iCalendar file, BEGIN:VCALENDAR is missing"})
+	}
+
+	cal := Calendar{Todos: []Todo{}}
+	var current *Todo
+	// components nested in a VTODO, such as VALARM
+	nested := 0
+	for _, p := range props {
+		switch {
+		case p.name == "BEGIN" && current == nil && strings.EqualFold(p.value, "VTODO"):
+			current = &Todo{Line: p.line}
+		case p.name == "BEGIN" && current != nil:
+			nested++
+		case p.name == "END" && current != nil && nested > 0:
+			nested--
+		case p.name == "END" && current != nil && strings.EqualFold(p.value, "VTODO"):
+			// a recurrence is computed from its first occurrence
+			if current.RRule != "" && current.Due == nil {
+				errs = append(errs, Error{Line: current.Line, Message: "RRULE requires DTSTART or DUE, the first occurrence"})
+			} else {
+				cal.Todos = append(cal.Todos, *current)
+			}
+			current = nil
+		case p.name == "X-WR-CALNAME" && current == nil:
+			cal.Name = Unescape(p.value)
+		case current != nil && nested == 0:
+			if msg := current.set(p); msg != "" {
+				errs = append(errs, Error{Line: p.line, Message: msg})
+			}
+		}
+	}
+
+	if current != nil {
+		errs = append(errs, Error{Line: current.Line, Message: "VTODO is not closed, END:VTODO is missing"})
+	}
+
+	return cal, errs
+}
+
+// set read the property of the todo, telling why it can't
+func (t *Todo) set(p property) string {
+	switch p.name {
+	case "UID":
+		t.UID = p.value
+	case "SUMMARY":
+		t.Summary = Unescape(p.value)
+	case "CATEGORIES":
+		for _, c := range splitText(p.value) {
+			if c != "" {
+				t.Categories = append(t.Categories, c)
+			}
+		}
+	case "DUE":
+		due, err := parseTime(p)
+		if err != nil {
+			return "DUE: " + err.Error()
+		}
+		t.Due = &due
+	case "DTSTART":
+		// the start stands for the due date of the todos without one
+		start, err := parseTime(p)
+		if err != nil {
+			return "DTSTART: " + err.Error()
+		}
+		if t.Due == nil {
+			t.Due = &start
+		}
+	case "PRIORITY":
+		priority, err := strconv.Atoi(p.value)
+		if err != nil || priority < 0 || priority > 9 {
+			return fmt.Sprintf("PRIORITY: %q is not a number from 0 to 9", p.value)
+		}
+		t.Priority = priority
+	case "STATUS":
+		t.Completed = strings.EqualFold(p.value, "COMPLETED")
+	case "COMPLETED":
+		t.Completed = true
+	case "RRULE":
+		rule, err := ParseRRule(p.value)
+		if err != nil {
+			return "RRULE: " + err.Error()
+		}
+		t.RRule = rule
+	}
+
+	return ""
+}
+
+// unfold join the folded lines and split them into properties
+func unfold(data string) ([]property, []Error) {
+	lines := strings.Split(strings.TrimPrefix(data, "\xef\xbb\xbf"), "\n")
+
+	props := []property{}
+	errs := []Error{}
+	var text strings.Builder
+	start := 0
+	flush := func() {
+		if text.Len() == 0 {
+			return
+		}
+		p, ok := parseProperty(start, text.String())
+		if ok {
+			props = append(props, p)
+		} else {
+			errs = append(errs, Error{Line: start, Message: "not a content line, NAME:value is expected"})
+		}
+		text.Reset()
+	}
+
+	for i, x := range lines {
+		x = strings.TrimSuffix(x, "\r")
+		if len(x) > 0 && (x[0] == ' ' || x[0] == '\t') && text.Len() > 0 {
+			text.WriteString(x[1:])
+			continue
+		}
+
+		flush()
+		if strings.TrimSpace(x) == "" {
+			continue
+		}
+		start = i + 1
+		text.WriteString(x)
+	}
+	flush()
+
+	return props, errs
+}
+
+// parseProperty split a content line into its name, its parameters and its
+// value, the quoted parameter values holding : and ;
+func parseProperty(line int, text string) (property, bool) {
+	p := property{line: line, params: map[string]string{}}
+
+	quoted := false
+	parts := []string{}
+	from := 0
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; {
+		case c == '"':
+			quoted = !quoted
+		case c == ';' && !quoted:
+			parts = append(parts, text[from:i])
+			from = i + 1
+		case c == ':' && !quoted:
+			parts = append(parts, text[from:i])
+			p.value = text[i+1:]
+			p.name = strings.ToUpper(strings.TrimSpace(parts[0]))
+			for _, x := range parts[1:] {
+				key, value, _ := strings.Cut(x, "=")
+				p.params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+			}
+			return p, p.name != ""
+		}
+	}
+
+	return property{}, false
+}
+
+// parseTime read a DATE or a DATE-TIME, in UTC, in the time zone of its TZID
+// or else floating, read as UTC
+func parseTime(p property) (time.Time, error) {
+	if strings.EqualFold(p.params["VALUE"], "DATE") || len(p.value) == len(dateFormat) {
+		return time.Parse(dateFormat, p.value)
+	}
+
+	if strings.HasSuffix(p.value, "Z") {
+		return time.Parse(utcFormat, p.value)
+	}
+
+	loc := time.UTC
+	if tzid := p.params["TZID"]; tzid != "" {
+		var err error
+		loc, err = time.LoadLocation(tzid)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("unknown TZID %q", tzid)
+		}
+	}
+
+	t, err := time.ParseInLocation(localFormat, p.value, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is not a date or a date time", p.value)
+	}
+
+	return t.UTC(), nil
+}
+
+// splitText split a list of TEXT values on the commas that aren't escaped
+func splitText(s string) []string {
+	res := []string{}
+	from := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case ',':
+			res = append(res, Unescape(s[from:i]))
+			from = i + 1
+		}
+	}
+
+	return append(res, Unescape(s[from:]))
+}
@@ -0,0 +1,94 @@
+package ical
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Encode write the calendar, a VTODO per todo
+func Encode(w io.Writer, cal Calendar) error {
+	bw := bufio.NewWriter(w)
+	write := func(name, value string) {
+		_, _ = bw.WriteString(fold(name + ":" + value))
+	}
+
+	write("BEGIN", "VCALENDAR")
+	write("VERSION", "2.0")
+	write("PRODID", ProdID)
+	write("CALSCALE", "GREGORIAN")
+	write("METHOD", "PUBLISH")
+	if cal.Name != "" {
+		write("X-WR-CALNAME", Escape(cal.Name))
+	}
+
+	for _, x := range cal.Todos {
+		write("BEGIN", "VTODO")
+		write("UID", x.UID)
+		write("DTSTAMP", formatTime(x.LastModified))
+		write("CREATED", formatTime(x.Created))
+		write("LAST-MODIFIED", formatTime(x.LastModified))
+		write("SUMMARY", Escape(x.Summary))
+
+		if len(x.Categories) > 0 {
+			categories := make([]string, 0, len(x.Categories))
+			for _, c := range x.Categories {
+				categories = append(categories, Escape(c))
+			}
+			write("CATEGORIES", strings.Join(categories, ","))
+		}
+
+		if x.Due != nil {
+			// a recurrence is computed from the start
+			if x.RRule != "" {
+				write("DTSTART", formatTime(*x.Due))
+			}
+			write("DUE", formatTime(*x.Due))
+		}
+		if x.RRule != "" {
+			write("RRULE", x.RRule)
+		}
+
+		if x.Priority > 0 {
+			write("PRIORITY", strconv.Itoa(x.Priority))
+		}
+
+		if x.Completed {
+			write("STATUS", "COMPLETED")
+			write("COMPLETED", formatTime(x.LastModified))
+			write("PERCENT-COMPLETE", "100")
+		} else {
+			write("STATUS", "NEEDS-ACTION")
+		}
+		write("END", "VTODO")
+	}
+
+	write("END", "VCALENDAR")
+
+	return bw.Flush()
+}
+
+// fold break the content line every 75 octets, between two characters, the
+// next lines starting with a space
+func fold(line string) string {
+	var b strings.Builder
+	limit := maxLine
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		limit = maxLine - 1
+	}
+
+	b.WriteString(line)
+	b.WriteString("\r\n")
+
+	return b.String()
+}
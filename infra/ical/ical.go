@@ -0,0 +1,97 @@
+// Package ical write and read the todos of iCalendar files (RFC 5545) as
+// VTODO components, folding the long lines and escaping the text values.
+package ical
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// ProdID the product writing the calendars
+	ProdID = "-//todolist-api//Todolist API//EN"
+
+	// maxLine the most octets of a content line, the line break left out
+	maxLine = 75
+
+	utcFormat   = "20060102T150405Z"
+	localFormat = "20060102T150405"
+	dateFormat  = "20060102"
+)
+
+// Calendar a calendar of todos, Name being shown by the calendar apps
+type Calendar struct {
+	Name  string
+	Todos []Todo
+}
+
+// Todo a VTODO. Priority goes from 1, the highest, to 9, 0 being undefined.
+// Line is where it starts in the file it was read from.
+type Todo struct {
+	UID          string
+	Summary      string
+	Categories   []string
+	Due          *time.Time
+	Priority     int
+	Completed    bool
+	RRule        string
+	Created      time.Time
+	LastModified time.Time
+	Line         int
+}
+
+// Error a problem of the file at Line
+type Error struct {
+	Line    int
+	Message string
+}
+
+func (e Error) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+}
+
+// escaper escape the TEXT values
+var escaper = strings.NewReplacer(
+	`\`, `\\`,
+	";", `\;`,
+	",", `\,`,
+	"\r\n", `\n`,
+	"\n", `\n`,
+	"\r", "",
+)
+
+// Escape a TEXT value
+func Escape(s string) string {
+	return escaper.Replace(s)
+}
+
+// Unescape a TEXT value
+func Unescape(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i == len(s)-1 {
+			b.WriteByte(s[i])
+			continue
+		}
+
+		i++
+		switch s[i] {
+		case 'n', 'N':
+			b.WriteByte('\n')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+
+	return b.String()
+}
+
+// formatTime a DATE-TIME in UTC
+func formatTime(t time.Time) string {
+	return t.UTC().Format(utcFormat)
+}
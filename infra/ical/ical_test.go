@@ -0,0 +1,183 @@
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestFold(t *testing.T) {
+	tests := []struct {
+		name  string
+		line  string
+		lines int
+	}{
+		{"short", "SUMMARY:Buy milk", 1},
+		{"75 octets", "SUMMARY:" + strings.Repeat("a", 67), 1},
+		{"76 octets", "SUMMARY:" + strings.Repeat("a", 68), 2},
+		// é takes the 75th and the 76th octets, the line is cut before it
+		{"rune across the limit", "SUMMARY:" + strings.Repeat("a", 66) + "é" + strings.Repeat("b", 10), 2},
+		{"runes only", "SUMMARY:" + strings.Repeat("日本語", 40), 6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			folded := fold(tt.line)
+			if !strings.HasSuffix(folded, "\r\n") {
+				t.Fatalf("%q doesn't end with CRLF", folded)
+			}
+
+			lines := strings.Split(strings.TrimSuffix(folded, "\r\n"), "\r\n")
+			if len(lines) != tt.lines {
+				t.Fatalf("%d lines, expected %d: %q", len(lines), tt.lines, lines)
+			}
+			for i, x := range lines {
+				if len(x) > maxLine {
+					t.Fatalf("line %d has %d octets", i, len(x))
+				}
+				if !utf8.ValidString(x) {
+					t.Fatalf("line %d splits a rune: %q", i, x)
+				}
+				if i > 0 && x[0] != ' ' {
+					t.Fatalf("line %d doesn't start with a space: %q", i, x)
+				}
+			}
+
+			props, errs := unfold(folded)
+			if len(errs) > 0 || len(props) != 1 || props[0].name+":"+props[0].value != tt.line {
+				t.Fatalf("unfolded into %+v, %v", props, errs)
+			}
+		})
+	}
+}
+
+func TestEscape(t *testing.T) {
+	tests := []struct {
+		text    string
+		escaped string
+	}{
+		{"Buy milk", "Buy milk"},
+		{"milk, eggs", `milk\, eggs`},
+		{"milk; eggs", `milk\; eggs`},
+		{`C:\todo`, `C:\\todo`},
+		{"milk\neggs", `milk\neggs`},
+		{`a\,b;c`, `a\\\,b\;c`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if escaped := Escape(tt.text); escaped != tt.escaped {
+				t.Fatalf("escaped into %q, expected %q", escaped, tt.escaped)
+			}
+			if text := Unescape(tt.escaped); text != tt.text {
+				t.Fatalf("unescaped into %q, expected %q", text, tt.text)
+			}
+		})
+	}
+
+	// CRLF is a single line break, an uppercase N one too
+	if escaped := Escape("milk\r\neggs"); escaped != `milk\neggs` {
+		t.Fatalf("CRLF escaped into %q", escaped)
+	}
+	if text := Unescape(`milk\Neggs`); text != "milk\neggs" {
+		t.Fatalf(`\N unescaped into %q`, text)
+	}
+}
+
+func TestRRuleRoundTrip(t *testing.T) {
+	due := time.Date(2023, 7, 1, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		rule     string
+		expected string
+	}{
+		{"FREQ=WEEKLY;BYDAY=MO", "FREQ=WEEKLY;BYDAY=MO"},
+		{"freq=daily;interval=2", "FREQ=DAILY;INTERVAL=2"},
+		{"RRULE:FREQ=MONTHLY;COUNT=3", "FREQ=MONTHLY;COUNT=3"},
+		{"FREQ=YEARLY;UNTIL=20301231T000000Z", "FREQ=YEARLY;UNTIL=20301231T000000Z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rule, func(t *testing.T) {
+			rule, err := ParseRRule(tt.rule)
+			if err != nil || rule != tt.expected {
+				t.Fatalf("parsed into %q, %v, expected %q", rule, err, tt.expected)
+			}
+
+			var buf bytes.Buffer
+			err = Encode(&buf, Calendar{Todos: []Todo{{UID: "todo-1@todolist-api", Summary: "Standup", Due: &due, RRule: rule}}})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			cal, errs := Decode(buf.Bytes())
+			if len(errs) > 0 || len(cal.Todos) != 1 {
+				t.Fatalf("decoded into %+v, %v", cal, errs)
+			}
+			if x := cal.Todos[0]; x.RRule != tt.expected || x.Due == nil || !x.Due.Equal(due) {
+				t.Fatalf("read back the rule %q due %v", x.RRule, x.Due)
+			}
+		})
+	}
+}
+
+func TestParseRRuleInvalid(t *testing.T) {
+	for _, rule := range []string{
+		"",
+		"BYDAY=MO",
+		"FREQ=FORTNIGHTLY",
+		"FREQ=DAILY;FREQ=WEEKLY",
+		"FREQ=DAILY;COUNT=0",
+		"FREQ=DAILY;COUNT=2;UNTIL=20301231",
+		"FREQ=DAILY;UNTIL=tomorrow",
+		"FREQ=DAILY;EVERY=2",
+		"FREQ",
+	} {
+		t.Run(rule, func(t *testing.T) {
+			if parsed, err := ParseRRule(rule); err == nil {
+				t.Fatalf("%q accepted as %q", rule, parsed)
+			}
+		})
+	}
+}
+
+func TestDecodeRRuleStart(t *testing.T) {
+	tests := []struct {
+		name string
+		todo string
+		due  string
+	}{
+		{"due", "DUE:20230701T090000Z", "2023-07-01T09:00:00Z"},
+		{"start", "DTSTART:20230701T090000Z", "2023-07-01T09:00:00Z"},
+		{"due over start", "DTSTART:20230701T090000Z\r\nDUE:20230702T090000Z", "2023-07-02T09:00:00Z"},
+		{"start after due", "DUE:20230702T090000Z\r\nDTSTART:20230701T090000Z", "2023-07-02T09:00:00Z"},
+		{"neither", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := "BEGIN:VCALENDAR\r\nBEGIN:VTODO\r\nSUMMARY:Standup\r\nRRULE:FREQ=DAILY\r\n"
+			if tt.todo != "" {
+				data += tt.todo + "\r\n"
+			}
+			data += "END:VTODO\r\nEND:VCALENDAR\r\n"
+
+			cal, errs := Decode([]byte(data))
+			if tt.due == "" {
+				if len(cal.Todos) != 0 || len(errs) != 1 || errs[0].Line != 2 || !strings.Contains(errs[0].Message, "RRULE requires") {
+					t.Fatalf("the rule without a start was read into %+v, %v", cal.Todos, errs)
+				}
+				return
+			}
+
+			if len(errs) > 0 || len(cal.Todos) != 1 {
+				t.Fatalf("decoded into %+v, %v", cal, errs)
+			}
+			if due := cal.Todos[0].Due; due == nil || due.Format(time.RFC3339) != tt.due {
+				t.Fatalf("due %v, expected %s", due, tt.due)
+			}
+		})
+	}
+}
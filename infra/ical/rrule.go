@@ -0,0 +1,82 @@
+package ical
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// rruleParts the parts of a recurrence rule
+var rruleParts = map[string]bool{
+	"FREQ": true, "UNTIL": true, "COUNT": true, "INTERVAL": true,
+	"BYSECOND": true, "BYMINUTE": true, "BYHOUR": true, "BYDAY": true,
+	"BYMONTHDAY": true, "BYYEARDAY": true, "BYWEEKNO": true, "BYMONTH": true,
+	"BYSETPOS": true, "WKST": true,
+}
+
+// frequencies the values of FREQ
+var frequencies = map[string]bool{
+	"SECONDLY": true, "MINUTELY": true, "HOURLY": true, "DAILY": true,
+	"WEEKLY": true, "MONTHLY": true, "YEARLY": true,
+}
+
+// ParseRRule check a recurrence rule, such as FREQ=WEEKLY;BYDAY=MO, and
+// return it in upper case without the RRULE: prefix
+func ParseRRule(s string) (string, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	s = strings.TrimPrefix(s, "RRULE:")
+	if s == "" {
+		return "", errors.New("the rule is empty")
+	}
+
+	seen := map[string]bool{}
+	for _, part := range strings.Split(s, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok || value == "" {
+			return "", fmt.Errorf("%q is not a NAME=value part", part)
+		}
+		if !rruleParts[key] {
+			return "", fmt.Errorf("%s is not a part of a rule", key)
+		}
+		if seen[key] {
+			return "", fmt.Errorf("%s is given twice", key)
+		}
+		seen[key] = true
+
+		switch key {
+		case "FREQ":
+			if !frequencies[value] {
+				return "", fmt.Errorf("FREQ %s is not one of SECONDLY, MINUTELY, HOURLY, DAILY, WEEKLY, MONTHLY or YEARLY", value)
+			}
+		case "COUNT", "INTERVAL":
+			if n, err := strconv.Atoi(value); err != nil || n <= 0 {
+				return "", fmt.Errorf("%s %s is not a positive number", key, value)
+			}
+		case "UNTIL":
+			if !validUntil(value) {
+				return "", fmt.Errorf("UNTIL %s is not a date or a date time", value)
+			}
+		}
+	}
+
+	if !seen["FREQ"] {
+		return "", errors.New("FREQ is required")
+	}
+	if seen["COUNT"] && seen["UNTIL"] {
+		return "", errors.New("COUNT and UNTIL can't both be given")
+	}
+
+	return s, nil
+}
+
+func validUntil(value string) bool {
+	for _, layout := range []string{utcFormat, localFormat, dateFormat} {
+		if _, err := time.Parse(layout, value); err == nil {
+			return true
+		}
+	}
+
+	return false
+}
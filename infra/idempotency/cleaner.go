@@ -0,0 +1,63 @@
+package idempotency
+
+import (
+	"context"
+	"time"
+	"todolist-api/data/repositories/idempotency"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// batchSize the expired keys deleted per statement, keeping the locks short
+const batchSize = 500
+
+// Cleaner delete the expired keys periodically
+type Cleaner struct {
+	repo     idempotency.IdempotencyRepositoryInterface
+	interval time.Duration
+}
+
+// NewCleaner return a cleaner running every interval
+func NewCleaner(repo idempotency.IdempotencyRepositoryInterface, interval time.Duration) *Cleaner {
+	return &Cleaner{
+		repo:     repo,
+		interval: interval,
+	}
+}
+
+// Run delete the expired keys until ctx is done
+func (c *Cleaner) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.clean(ctx)
+		}
+	}
+}
+
+func (c *Cleaner) clean(ctx context.Context) {
+	total := 0
+	for {
+		n, err := c.repo.DeleteExpiredKey(ctx, time.Now(), batchSize)
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Errorf("idempotency cleaner: %v", err)
+			}
+			return
+		}
+
+		total += n
+		if n < batchSize {
+			break
+		}
+	}
+
+	if total > 0 {
+		log.Debugf("idempotency cleaner: deleted %d expired keys", total)
+	}
+}
@@ -0,0 +1,123 @@
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+	"todolist-api/constants"
+	"todolist-api/data/models"
+	"todolist-api/data/repositories/idempotency"
+	"todolist-api/infra/errors"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// HeaderKey the header of the key chosen by the client for a request it may
+// retry
+const HeaderKey = "Idempotency-Key"
+
+// HeaderReplayed the header set on a response replayed from a previous
+// request with the same key
+const HeaderReplayed = "Idempotent-Replayed"
+
+type contextKey struct{}
+
+// Request the idempotency key of a request. Scope keeps the keys of each
+// caller and route apart, Fingerprint tells whether a retry is the same
+// request.
+type Request struct {
+	Scope       string
+	Key         string
+	Fingerprint string
+	TTL         time.Duration
+
+	// Replayed set once the response is taken from the previous request
+	Replayed bool
+}
+
+// WithRequest return a copy of ctx holding req
+func WithRequest(ctx context.Context, req *Request) context.Context {
+	return context.WithValue(ctx, contextKey{}, req)
+}
+
+// FromContext return the idempotency key of the request, if any
+func FromContext(ctx context.Context) (*Request, bool) {
+	req, ok := ctx.Value(contextKey{}).(*Request)
+	return req, ok && req != nil
+}
+
+// Replayed tell whether the response of the request was replayed
+func Replayed(ctx context.Context) bool {
+	req, ok := FromContext(ctx)
+	return ok && req.Replayed
+}
+
+// Fingerprint return the hash identifying a request from its parts
+func Fingerprint(parts ...[]byte) string {
+	h := sha256.New()
+	for _, p := range parts {
+		// the length keeps the parts from running into each other
+		fmt.Fprintf(h, "%d:", len(p))
+		h.Write(p)
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Replay look for the key of the request in tx and decode the response it
+// stored into v, telling whether it did. It fails with
+// constants.ErrIdempotencyKeyReused when the key was used by a different
+// request. An expired key is deleted so the request runs again.
+func Replay(ctx context.Context, repo idempotency.IdempotencyRepositoryInterface, tx *sqlx.Tx, v interface{}) (bool, error) {
+	req, ok := FromContext(ctx)
+	if !ok {
+		return false, nil
+	}
+
+	data, found, err := repo.GetOneKey(ctx, tx, req.Scope, req.Key)
+	if err != nil || !found {
+		return false, err
+	}
+
+	if !data.ExpiresAt.After(time.Now()) {
+		return false, repo.DeleteKey(ctx, tx, data.IdempotencyKeyID)
+	}
+
+	if data.Fingerprint != req.Fingerprint {
+		return false, errors.Wrap(constants.ErrIdempotencyKeyReused)
+	}
+
+	if err := json.Unmarshal([]byte(data.Response), v); err != nil {
+		return false, err
+	}
+	req.Replayed = true
+
+	return true, nil
+}
+
+// Save store the key of the request with v, its response, in tx so the key
+// is only kept when the request commits. It fails with
+// constants.ErrIdempotencyKeyInUse when a concurrent request committed the
+// key first.
+func Save(ctx context.Context, repo idempotency.IdempotencyRepositoryInterface, tx *sqlx.Tx, v interface{}) error {
+	req, ok := FromContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	response, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	return repo.CreateKey(ctx, tx, models.IdempotencyKey{
+		Scope:       req.Scope,
+		Key:         req.Key,
+		Fingerprint: req.Fingerprint,
+		Response:    string(response),
+		ExpiresAt:   time.Now().Add(req.TTL),
+	})
+}
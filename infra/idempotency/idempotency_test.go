@@ -0,0 +1,131 @@
+package idempotency_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"todolist-api/constants"
+	"todolist-api/data/repositories/idempotency/idempotencytest"
+	"todolist-api/infra/idempotency"
+)
+
+type response struct {
+	ID int `json:"id"`
+}
+
+// request the context of a request with key, sent with body
+func request(key, body string) context.Context {
+	return idempotency.WithRequest(context.Background(), &idempotency.Request{
+		Scope:       "POST /todo-items anonymous",
+		Key:         key,
+		Fingerprint: idempotency.Fingerprint([]byte("POST /todo-items"), []byte(body)),
+		TTL:         time.Hour,
+	})
+}
+
+func TestReplay(t *testing.T) {
+	repo := idempotencytest.New()
+
+	// the first request runs and stores its response
+	ctx := request("k1", `{"title":"milk"}`)
+	var res response
+	if ok, err := idempotency.Replay(ctx, repo, nil, &res); ok || err != nil {
+		t.Fatalf("first request replayed: %t, %v", ok, err)
+	}
+	if err := idempotency.Save(ctx, repo, nil, response{ID: 7}); err != nil {
+		t.Fatal(err)
+	}
+	if idempotency.Replayed(ctx) {
+		t.Fatal("first request reported as replayed")
+	}
+
+	// a retry gets the response of the first request
+	retry := request("k1", `{"title":"milk"}`)
+	if ok, err := idempotency.Replay(retry, repo, nil, &res); !ok || err != nil {
+		t.Fatalf("retry not replayed: %t, %v", ok, err)
+	}
+	if res.ID != 7 || !idempotency.Replayed(retry) {
+		t.Fatalf("retry replayed %+v", res)
+	}
+
+	// the key with another body is a mistake of the client
+	_, err := idempotency.Replay(request("k1", `{"title":"eggs"}`), repo, nil, &res)
+	if !errors.Is(err, constants.ErrIdempotencyKeyReused) {
+		t.Fatalf("error %v, expected %v", err, constants.ErrIdempotencyKeyReused)
+	}
+
+	// another key is another request
+	if ok, err := idempotency.Replay(request("k2", `{"title":"milk"}`), repo, nil, &res); ok || err != nil {
+		t.Fatalf("other key replayed: %t, %v", ok, err)
+	}
+}
+
+func TestSaveInUse(t *testing.T) {
+	repo := idempotencytest.New()
+
+	// both requests found no key, the second one to save loses
+	first, second := request("k1", `{}`), request("k1", `{}`)
+	var res response
+	for _, ctx := range []context.Context{first, second} {
+		if ok, err := idempotency.Replay(ctx, repo, nil, &res); ok || err != nil {
+			t.Fatalf("replayed: %t, %v", ok, err)
+		}
+	}
+
+	if err := idempotency.Save(first, repo, nil, response{ID: 1}); err != nil {
+		t.Fatal(err)
+	}
+	err := idempotency.Save(second, repo, nil, response{ID: 2})
+	if !errors.Is(err, constants.ErrIdempotencyKeyInUse) {
+		t.Fatalf("error %v, expected %v", err, constants.ErrIdempotencyKeyInUse)
+	}
+}
+
+func TestReplayExpired(t *testing.T) {
+	repo := idempotencytest.New()
+
+	ctx := idempotency.WithRequest(context.Background(), &idempotency.Request{
+		Scope: "POST /sync anonymous",
+		Key:   "k1",
+		TTL:   -time.Second,
+	})
+	if err := idempotency.Save(ctx, repo, nil, response{ID: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	// an expired key is deleted, the request runs again
+	var res response
+	if ok, err := idempotency.Replay(ctx, repo, nil, &res); ok || err != nil {
+		t.Fatalf("expired key replayed: %t, %v", ok, err)
+	}
+	if keys := repo.Keys(); len(keys) != 0 {
+		t.Fatalf("expired key kept: %v", keys)
+	}
+}
+
+func TestWithoutKey(t *testing.T) {
+	repo := idempotencytest.New()
+
+	var res response
+	ctx := context.Background()
+	if ok, err := idempotency.Replay(ctx, repo, nil, &res); ok || err != nil {
+		t.Fatalf("replayed without key: %t, %v", ok, err)
+	}
+	if err := idempotency.Save(ctx, repo, nil, response{ID: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if keys := repo.Keys(); len(keys) != 0 {
+		t.Fatalf("key stored without key: %v", keys)
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	// the length of each part keeps them from running into each other
+	if idempotency.Fingerprint([]byte("ab"), []byte("c")) == idempotency.Fingerprint([]byte("a"), []byte("bc")) {
+		t.Fatal("parts split differently share a fingerprint")
+	}
+	if idempotency.Fingerprint([]byte("a")) != idempotency.Fingerprint([]byte("a")) {
+		t.Fatal("fingerprint not stable")
+	}
+}
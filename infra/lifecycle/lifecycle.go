@@ -0,0 +1,210 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// hook a named step of the shutdown
+type hook struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+// Manager run the listeners and background workers of the process until
+// SIGINT or SIGTERM, or until a listener fails, then shut them down in order:
+//
+//  1. the drain functions, before the graceful timeout starts
+//  2. the stop hooks, newest first, such as the servers waiting for the
+//     requests in flight
+//  3. the workers, cancelled then waited for
+//  4. the close hooks, newest first, such as the database pool
+//
+// Steps 2 to 4 share the graceful timeout. A second signal cuts it short.
+type Manager struct {
+	timeout time.Duration
+
+	ctx     context.Context
+	cancel  context.CancelFunc
+	workers sync.WaitGroup
+
+	drains []func()
+	stops  []hook
+	closes []hook
+
+	failed chan error
+}
+
+// New return a manager giving the shutdown timeout to complete
+func New(timeout time.Duration) *Manager {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Manager{
+		timeout: timeout,
+		ctx:     ctx,
+		cancel:  cancel,
+		failed:  make(chan error, 1),
+	}
+}
+
+// Context return the context of the workers, cancelled once the stop hooks
+// have run
+func (m *Manager) Context() context.Context {
+	return m.ctx
+}
+
+// Go run a background worker until its context is cancelled, the shutdown
+// waits for it to return
+func (m *Manager) Go(name string, fn func(ctx context.Context)) {
+	m.workers.Add(1)
+	go func() {
+		defer m.workers.Done()
+		fn(m.ctx)
+		log.Debugf("%s stopped", name)
+	}()
+}
+
+// Serve run a listener, such as http.Server.ListenAndServe. Returning
+// anything but http.ErrServerClosed or nil starts the shutdown and makes Run
+// fail.
+func (m *Manager) Serve(name string, fn func() error) {
+	go func() {
+		if err := fn(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			select {
+			case m.failed <- fmt.Errorf("%s: %w", name, err):
+			default:
+			}
+		}
+	}()
+}
+
+// OnDrain run fn as soon as the shutdown starts, such as flipping the
+// readiness probe and waiting for the load balancer to notice
+func (m *Manager) OnDrain(fn func()) {
+	m.drains = append(m.drains, fn)
+}
+
+// OnStop run fn before the workers are stopped, newest first
+func (m *Manager) OnStop(name string, fn func(ctx context.Context) error) {
+	m.stops = append(m.stops, hook{name: name, fn: fn})
+}
+
+// OnClose run fn once the workers returned, newest first
+func (m *Manager) OnClose(name string, fn func(ctx context.Context) error) {
+	m.closes = append(m.closes, hook{name: name, fn: fn})
+}
+
+// Run block until SIGINT, SIGTERM or a listener failure, then shut down. It
+// return the failure of the listener, or of the shutdown when a step failed
+// or didn't complete in time.
+func (m *Manager) Run() error {
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	var cause error
+	select {
+	case sig := <-signals:
+		log.Infof("received %s, shutting down", sig)
+	case cause = <-m.failed:
+		log.Errorf("%v, shutting down", cause)
+	}
+
+	for _, fn := range m.drains {
+		fn()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), m.timeout)
+	defer cancel()
+
+	// a second signal gives up waiting
+	go func() {
+		select {
+		case sig := <-signals:
+			log.Warnf("received %s, forcing shutdown", sig)
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return m.shutdown(ctx, cause)
+}
+
+// Fail shut down what was registered so far because the startup failed with
+// cause, skipping the drain functions, and return cause along with the
+// failures of the shutdown
+func (m *Manager) Fail(cause error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), m.timeout)
+	defer cancel()
+
+	return m.shutdown(ctx, cause)
+}
+
+// shutdown run the stop hooks, stop the workers then run the close hooks
+func (m *Manager) shutdown(ctx context.Context, cause error) error {
+	errs := []error{cause}
+	errs = append(errs, m.run(ctx, m.stops)...)
+
+	m.cancel()
+	if err := m.wait(ctx); err != nil {
+		errs = append(errs, err)
+	}
+
+	errs = append(errs, m.run(ctx, m.closes)...)
+
+	return join(errs)
+}
+
+// join the errors that aren't nil, the first one stays wrapped
+func join(errs []error) error {
+	var err error
+	for _, e := range errs {
+		switch {
+		case e == nil:
+		case err == nil:
+			err = e
+		default:
+			err = fmt.Errorf("%w; %v", err, e)
+		}
+	}
+
+	return err
+}
+
+// run the hooks newest first, logging and returning their errors
+func (m *Manager) run(ctx context.Context, hooks []hook) []error {
+	var errs []error
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if err := hooks[i].fn(ctx); err != nil {
+			log.Errorf("%s: %v", hooks[i].name, err)
+			errs = append(errs, fmt.Errorf("%s: %w", hooks[i].name, err))
+		}
+	}
+
+	return errs
+}
+
+// wait for the workers until ctx is done
+func (m *Manager) wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		m.workers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("workers: %w", ctx.Err())
+	}
+}
@@ -0,0 +1,48 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFail(t *testing.T) {
+	m := New(time.Second)
+
+	var order []string
+	m.OnDrain(func() {
+		order = append(order, "drain")
+	})
+	m.OnStop("server", func(context.Context) error {
+		order = append(order, "stop")
+		return nil
+	})
+	m.Go("worker", func(ctx context.Context) {
+		<-ctx.Done()
+		order = append(order, "worker")
+	})
+	m.OnClose("tracing", func(context.Context) error {
+		order = append(order, "close tracing")
+		return nil
+	})
+	m.OnClose("database", func(context.Context) error {
+		order = append(order, "close database")
+		return errors.New("already closed")
+	})
+
+	cause := errors.New("listen tcp: address already in use")
+	err := m.Fail(cause)
+	if !errors.Is(err, cause) {
+		t.Fatalf("expected the cause to be returned, got %v", err)
+	}
+	if err.Error() != "listen tcp: address already in use; database: already closed" {
+		t.Fatalf("unexpected error %q", err)
+	}
+
+	expected := []string{"stop", "worker", "close database", "close tracing"}
+	if !reflect.DeepEqual(order, expected) {
+		t.Fatalf("expected %v, got %v", expected, order)
+	}
+}
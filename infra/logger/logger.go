@@ -0,0 +1,42 @@
+package logger
+
+import (
+	"context"
+	"todolist-api/config"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type contextKey struct{}
+
+// WithEntry return a copy of ctx carrying the log entry
+func WithEntry(ctx context.Context, entry *log.Entry) context.Context {
+	return context.WithValue(ctx, contextKey{}, entry)
+}
+
+// FromContext return the log entry of the request ctx belongs to, or an entry
+// of the standard logger outside of a request
+func FromContext(ctx context.Context) *log.Entry {
+	if entry, ok := ctx.Value(contextKey{}).(*log.Entry); ok {
+		return entry
+	}
+
+	return log.NewEntry(log.StandardLogger())
+}
+
+// Configure set the level and the format of the standard logger
+func Configure(cfg config.LogConfig) error {
+	level, err := log.ParseLevel(cfg.Level)
+	if err != nil {
+		return err
+	}
+	log.SetLevel(level)
+
+	if cfg.Format == "json" {
+		log.SetFormatter(&log.JSONFormatter{})
+	} else {
+		log.SetFormatter(&log.TextFormatter{})
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+)
+
+// scrapeTimeout bound the queries run while collecting
+const scrapeTimeout = 5 * time.Second
+
+var (
+	dbOpenDesc = prometheus.NewDesc(
+		namespace+"_db_open_connections", "Established connections, in use or idle.", []string{"db"}, nil)
+	dbInUseDesc = prometheus.NewDesc(
+		namespace+"_db_in_use_connections", "Connections currently in use.", []string{"db"}, nil)
+	dbIdleDesc = prometheus.NewDesc(
+		namespace+"_db_idle_connections", "Idle connections.", []string{"db"}, nil)
+	dbMaxOpenDesc = prometheus.NewDesc(
+		namespace+"_db_max_open_connections", "Maximum number of open connections.", []string{"db"}, nil)
+	dbWaitCountDesc = prometheus.NewDesc(
+		namespace+"_db_wait_count_total", "Connections waited for.", []string{"db"}, nil)
+	dbWaitDurationDesc = prometheus.NewDesc(
+		namespace+"_db_wait_duration_seconds_total", "Time blocked waiting for a connection.", []string{"db"}, nil)
+
+	todosDesc = prometheus.NewDesc(
+		namespace+"_todos", "Todos by state.", []string{"state"}, nil)
+)
+
+// DBStatsCollector expose the pool statistics of every physical database,
+// labelled by their index, 0 being the master
+type DBStatsCollector struct {
+	stats func() []sql.DBStats
+}
+
+// NewDBStatsCollector collect the statistics returned by stats, such as db.DB.Stats
+func NewDBStatsCollector(stats func() []sql.DBStats) *DBStatsCollector {
+	return &DBStatsCollector{stats: stats}
+}
+
+func (c *DBStatsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- dbOpenDesc
+	ch <- dbInUseDesc
+	ch <- dbIdleDesc
+	ch <- dbMaxOpenDesc
+	ch <- dbWaitCountDesc
+	ch <- dbWaitDurationDesc
+}
+
+func (c *DBStatsCollector) Collect(ch chan<- prometheus.Metric) {
+	for idx, s := range c.stats() {
+		label := strconv.Itoa(idx)
+		ch <- prometheus.MustNewConstMetric(dbOpenDesc, prometheus.GaugeValue, float64(s.OpenConnections), label)
+		ch <- prometheus.MustNewConstMetric(dbInUseDesc, prometheus.GaugeValue, float64(s.InUse), label)
+		ch <- prometheus.MustNewConstMetric(dbIdleDesc, prometheus.GaugeValue, float64(s.Idle), label)
+		ch <- prometheus.MustNewConstMetric(dbMaxOpenDesc, prometheus.GaugeValue, float64(s.MaxOpenConnections), label)
+		ch <- prometheus.MustNewConstMetric(dbWaitCountDesc, prometheus.CounterValue, float64(s.WaitCount), label)
+		ch <- prometheus.MustNewConstMetric(dbWaitDurationDesc, prometheus.CounterValue, s.WaitDuration.Seconds(), label)
+	}
+}
+
+// TodoCounter count the todos by state, such as active and done
+type TodoCounter func(ctx context.Context) (map[string]int, error)
+
+// TodoCollector expose the number of todos per state, counted at scrape time
+type TodoCollector struct {
+	count TodoCounter
+}
+
+func NewTodoCollector(count TodoCounter) *TodoCollector {
+	return &TodoCollector{count: count}
+}
+
+func (c *TodoCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- todosDesc
+}
+
+func (c *TodoCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), scrapeTimeout)
+	defer cancel()
+
+	counts, err := c.count(ctx)
+	if err != nil {
+		log.Error(err)
+		ch <- prometheus.NewInvalidMetric(todosDesc, err)
+		return
+	}
+
+	for state, n := range counts {
+		ch <- prometheus.MustNewConstMetric(todosDesc, prometheus.GaugeValue, float64(n), state)
+	}
+}
@@ -0,0 +1,130 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+	"todolist-api/infra/db"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "todolist"
+
+// Metrics the Prometheus collectors of the application
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests *prometheus.CounterVec
+	httpDuration *prometheus.HistogramVec
+	httpInFlight prometheus.Gauge
+
+	serviceDuration *prometheus.HistogramVec
+
+	txOperations *prometheus.CounterVec
+}
+
+// New create the collectors and register them, along with the Go runtime and
+// process collectors
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "HTTP requests by method, route template and status code.",
+		}, []string{"method", "route", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "HTTP request latency by method and route template.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+		httpInFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_in_flight",
+			Help:      "HTTP requests being served.",
+		}),
+		serviceDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "service",
+			Name:      "call_duration_seconds",
+			Help:      "Service method latency by service, method and outcome.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"service", "method", "outcome"}),
+		txOperations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "db",
+			Name:      "transactions_total",
+			Help:      "Transaction begin, commit and rollback calls by outcome.",
+		}, []string{"operation", "outcome"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests,
+		m.httpDuration,
+		m.httpInFlight,
+		m.serviceDuration,
+		m.txOperations,
+	)
+
+	return m
+}
+
+// Handler serve the metrics in the Prometheus exposition format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// Register add application specific collectors
+func (m *Metrics) Register(cs ...prometheus.Collector) {
+	m.registry.MustRegister(cs...)
+}
+
+// ObserveRequest record a served HTTP request, route is the path template
+func (m *Metrics) ObserveRequest(method, route string, status int, duration time.Duration) {
+	m.httpRequests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+	m.httpDuration.WithLabelValues(method, route).Observe(duration.Seconds())
+}
+
+// InFlight track a request being served, call the returned func once it's done
+func (m *Metrics) InFlight() func() {
+	m.httpInFlight.Inc()
+	return m.httpInFlight.Dec
+}
+
+// ObserveService record a service method call
+func (m *Metrics) ObserveService(service, method string, duration time.Duration, err error) {
+	m.serviceDuration.WithLabelValues(service, method, outcome(err)).Observe(duration.Seconds())
+}
+
+// DBHooks count the transactions of the physical databases
+func (m *Metrics) DBHooks() db.Hooks {
+	return db.Hooks{
+		OnBegin: func(_ context.Context, err error) {
+			m.txOperations.WithLabelValues("begin", outcome(err)).Inc()
+		},
+		OnCommit: func(_ context.Context, err error) {
+			m.txOperations.WithLabelValues("commit", outcome(err)).Inc()
+		},
+		OnRollback: func(_ context.Context, err error) {
+			m.txOperations.WithLabelValues("rollback", outcome(err)).Inc()
+		},
+	}
+}
+
+func outcome(err error) string {
+	if err != nil {
+		return "error"
+	}
+
+	return "ok"
+}
@@ -0,0 +1,79 @@
+package ratelimit
+
+import (
+	"context"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Bucket a bucket to take a token from
+type Bucket struct {
+	Key   string
+	Limit Limit
+}
+
+// Limiter take a token from several buckets at once
+type Limiter struct {
+	store Store
+}
+
+// NewLimiter return a limiter keeping its buckets in store
+func NewLimiter(store Store) *Limiter {
+	return &Limiter{store: store}
+}
+
+// Allow take a token from each bucket that isn't unlimited. The result is the
+// one of the most restrictive bucket, it isn't allowed as soon as a bucket is
+// empty, the tokens taken from the other buckets being given back then. ok is
+// false when every bucket is unlimited.
+func (l *Limiter) Allow(ctx context.Context, buckets []Bucket) (res Result, ok bool, err error) {
+	taken := []Bucket{}
+	for _, b := range buckets {
+		if b.Limit.Unlimited() {
+			continue
+		}
+
+		r, err := l.store.Take(ctx, "bucket:"+b.Key, b.Limit)
+		if err != nil {
+			l.refund(ctx, taken)
+			return Result{}, false, err
+		}
+		if r.Allowed {
+			taken = append(taken, b)
+		}
+
+		if !ok || restrictive(r, res) {
+			res = r
+		}
+		ok = true
+	}
+
+	// a request refused doesn't use up the buckets that let it through
+	if ok && !res.Allowed {
+		l.refund(ctx, taken)
+	}
+
+	return res, ok, nil
+}
+
+// refund give back the tokens taken from buckets, a failure only costing a
+// token
+func (l *Limiter) refund(ctx context.Context, buckets []Bucket) {
+	for _, b := range buckets {
+		if err := l.store.Refund(ctx, "bucket:"+b.Key, b.Limit); err != nil {
+			log.Warnf("rate limit: refund of %s: %v", b.Key, err)
+		}
+	}
+}
+
+// restrictive tell whether a is more restrictive than b
+func restrictive(a, b Result) bool {
+	if a.Allowed != b.Allowed {
+		return !a.Allowed
+	}
+	if !a.Allowed {
+		return a.RetryAfter > b.RetryAfter
+	}
+
+	return a.Remaining < b.Remaining
+}
@@ -0,0 +1,114 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// sweepEvery number of calls between two removals of the idle entries
+const sweepEvery = 1024
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	limit  Limit
+}
+
+type counter struct {
+	value   int64
+	expires time.Time
+}
+
+// MemoryStore keep the buckets and the counters in the process
+type MemoryStore struct {
+	mtx      sync.Mutex
+	buckets  map[string]*bucket
+	counters map[string]*counter
+	calls    int
+	now      func() time.Time
+}
+
+// NewMemoryStore return an empty store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets:  map[string]*bucket{},
+		counters: map[string]*counter{},
+		now:      time.Now,
+	}
+}
+
+// Take take a token from the bucket key, full when first seen
+func (s *MemoryStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), last: now}
+		s.buckets[key] = b
+	}
+	b.tokens = refill(b.tokens, now.Sub(b.last), limit)
+	b.last = now
+	b.limit = limit
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+
+	return result(allowed, b.tokens, limit), nil
+}
+
+// Refund give back a token taken from the bucket key, up to its burst
+func (s *MemoryStore) Refund(ctx context.Context, key string, limit Limit) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if b, ok := s.buckets[key]; ok {
+		b.tokens = math.Min(float64(limit.Burst), b.tokens+1)
+	}
+
+	return nil
+}
+
+// Add add delta to the counter key, created to expire after ttl
+func (s *MemoryStore) Add(ctx context.Context, key string, delta int64, ttl time.Duration) (int64, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	c, ok := s.counters[key]
+	if !ok || !now.Before(c.expires) {
+		c = &counter{expires: now.Add(ttl)}
+		s.counters[key] = c
+	}
+	c.value += delta
+
+	return c.value, nil
+}
+
+// sweep remove the full buckets and the expired counters from time to time
+func (s *MemoryStore) sweep(now time.Time) {
+	s.calls++
+	if s.calls%sweepEvery != 0 {
+		return
+	}
+
+	for key, b := range s.buckets {
+		if refill(b.tokens, now.Sub(b.last), b.limit) >= float64(b.limit.Burst) {
+			delete(s.buckets, key)
+		}
+	}
+	for key, c := range s.counters {
+		if !now.Before(c.expires) {
+			delete(s.counters, key)
+		}
+	}
+}
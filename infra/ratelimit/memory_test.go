@@ -0,0 +1,187 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// clock a time moved by hand
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+func (c *clock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func newClock() *clock {
+	return &clock{now: time.Date(2023, time.July, 1, 12, 0, 0, 0, time.UTC)}
+}
+
+func TestMemoryStoreBurst(t *testing.T) {
+	c := newClock()
+	s := NewMemoryStore()
+	s.now = c.Now
+	limit := Limit{Rate: 2, Burst: 3}
+
+	for i := 2; i >= 0; i-- {
+		res, err := s.Take(context.Background(), "ip:1", limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !res.Allowed || res.Remaining != i || res.Limit != 3 || res.RetryAfter != 0 {
+			t.Fatalf("take %d: %+v", 3-i, res)
+		}
+	}
+
+	// an empty bucket refuses, a token coming back in half a second
+	res, _ := s.Take(context.Background(), "ip:1", limit)
+	if res.Allowed || res.Remaining != 0 {
+		t.Fatalf("expected a refusal, got %+v", res)
+	}
+	if res.RetryAfter != 500*time.Millisecond {
+		t.Fatalf("retry after %s, expected 500ms", res.RetryAfter)
+	}
+	if res.Reset != 1500*time.Millisecond {
+		t.Fatalf("reset %s, expected 1.5s", res.Reset)
+	}
+
+	// the other buckets are full
+	if res, _ := s.Take(context.Background(), "ip:2", limit); !res.Allowed || res.Remaining != 2 {
+		t.Fatalf("another bucket: %+v", res)
+	}
+}
+
+func TestMemoryStoreRefill(t *testing.T) {
+	c := newClock()
+	s := NewMemoryStore()
+	s.now = c.Now
+	limit := Limit{Rate: 2, Burst: 3}
+
+	for i := 0; i < 3; i++ {
+		_, _ = s.Take(context.Background(), "ip:1", limit)
+	}
+
+	// a token every half second
+	c.Advance(500 * time.Millisecond)
+	if res, _ := s.Take(context.Background(), "ip:1", limit); !res.Allowed || res.Remaining != 0 {
+		t.Fatalf("after 500ms: %+v", res)
+	}
+	if res, _ := s.Take(context.Background(), "ip:1", limit); res.Allowed {
+		t.Fatalf("expected a refusal, got %+v", res)
+	}
+
+	// never more than the burst
+	c.Advance(time.Hour)
+	res, _ := s.Take(context.Background(), "ip:1", limit)
+	if !res.Allowed || res.Remaining != 2 {
+		t.Fatalf("after an hour: %+v", res)
+	}
+}
+
+func TestMemoryStoreAdd(t *testing.T) {
+	c := newClock()
+	s := NewMemoryStore()
+	s.now = c.Now
+
+	for i, expected := range []int64{2, 5, 4} {
+		value, err := s.Add(context.Background(), "quota:a", []int64{2, 3, -1}[i], time.Minute)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if value != expected {
+			t.Fatalf("add %d: %d, expected %d", i, value, expected)
+		}
+	}
+
+	// the counter starts over once expired
+	c.Advance(time.Minute)
+	if value, _ := s.Add(context.Background(), "quota:a", 1, time.Minute); value != 1 {
+		t.Fatalf("after expiry: %d, expected 1", value)
+	}
+}
+
+func TestLimiterMostRestrictive(t *testing.T) {
+	c := newClock()
+	s := NewMemoryStore()
+	s.now = c.Now
+	l := NewLimiter(s)
+
+	buckets := []Bucket{
+		{Key: "key:a", Limit: Limit{Rate: 1, Burst: 10}},
+		{Key: "ip:1", Limit: Limit{Rate: 1, Burst: 2}},
+		{Key: "user:a", Limit: Limit{}},
+	}
+
+	res, ok, err := l.Allow(context.Background(), buckets)
+	if err != nil || !ok {
+		t.Fatal(ok, err)
+	}
+	if !res.Allowed || res.Limit != 2 || res.Remaining != 1 {
+		t.Fatalf("expected the state of the IP bucket, got %+v", res)
+	}
+
+	_, _, _ = l.Allow(context.Background(), buckets)
+	res, _, _ = l.Allow(context.Background(), buckets)
+	if res.Allowed || res.Limit != 2 {
+		t.Fatalf("expected the refusal of the IP bucket, got %+v", res)
+	}
+
+	// only unlimited buckets
+	if _, ok, _ := l.Allow(context.Background(), buckets[2:]); ok {
+		t.Fatal("expected no limit")
+	}
+}
+
+func TestLimiterRefusalKeepsTheOtherBuckets(t *testing.T) {
+	c := newClock()
+	s := NewMemoryStore()
+	s.now = c.Now
+	l := NewLimiter(s)
+
+	key := Bucket{Key: "key:a", Limit: Limit{Rate: 0.001, Burst: 3}}
+	ip := Bucket{Key: "ip:1", Limit: Limit{Rate: 0.001, Burst: 1}}
+
+	if res, _, _ := l.Allow(context.Background(), []Bucket{key, ip}); !res.Allowed {
+		t.Fatalf("first request refused: %+v", res)
+	}
+
+	// refused by the IP bucket, the key bucket gets its token back
+	for i := 0; i < 5; i++ {
+		if res, _, _ := l.Allow(context.Background(), []Bucket{key, ip}); res.Allowed || res.Limit != 1 {
+			t.Fatalf("request %d: expected the refusal of the IP bucket, got %+v", i, res)
+		}
+	}
+
+	// the key bucket only paid for the request let through
+	for i := 1; i >= 0; i-- {
+		res, _, _ := l.Allow(context.Background(), []Bucket{key})
+		if !res.Allowed || res.Remaining != i {
+			t.Fatalf("key bucket: %+v, expected %d remaining", res, i)
+		}
+	}
+}
+
+func TestMemoryStoreRefund(t *testing.T) {
+	s := NewMemoryStore()
+	limit := Limit{Rate: 0.001, Burst: 2}
+
+	_, _ = s.Take(context.Background(), "ip:1", limit)
+	_ = s.Refund(context.Background(), "ip:1", limit)
+	// never past the burst
+	_ = s.Refund(context.Background(), "ip:1", limit)
+
+	for i := 1; i >= 0; i-- {
+		if res, _ := s.Take(context.Background(), "ip:1", limit); !res.Allowed || res.Remaining != i {
+			t.Fatalf("after refund: %+v, expected %d remaining", res, i)
+		}
+	}
+	if res, _ := s.Take(context.Background(), "ip:1", limit); res.Allowed {
+		t.Fatalf("refund past the burst: %+v", res)
+	}
+}
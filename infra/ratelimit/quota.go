@@ -0,0 +1,115 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"todolist-api/constants"
+	"todolist-api/infra/auth"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// QuotaExceeded the error of a use past the daily quota
+type QuotaExceeded struct {
+	Name  string
+	Limit int
+	// Reset the start of the next day, UTC
+	Reset time.Time
+}
+
+func (e *QuotaExceeded) Error() string {
+	return fmt.Sprintf("%s: %s of %d per day", constants.ErrQuotaExceeded, e.Name, e.Limit)
+}
+
+// Is make errors.Is match constants.ErrQuotaExceeded
+func (e *QuotaExceeded) Is(target error) bool {
+	return target == constants.ErrQuotaExceeded
+}
+
+// Quota count what each user does per UTC day
+type Quota struct {
+	store Store
+	name  string
+	limit func() int
+	now   func() time.Time
+}
+
+// NewQuota return the quota of name, limit being read on each use so it can
+// change while serving, 0 being unlimited
+func NewQuota(store Store, name string, limit func() int) *Quota {
+	return &Quota{
+		store: store,
+		name:  name,
+		limit: limit,
+		now:   time.Now,
+	}
+}
+
+// Use count n uses by user, failing with *QuotaExceeded when they don't fit
+// in the quota of the day. The quota is ignored when the store fails.
+func (q *Quota) Use(ctx context.Context, user string, n int) error {
+	limit := q.limit()
+	if limit <= 0 || n <= 0 {
+		return nil
+	}
+
+	key, reset := q.key(user)
+	value, err := q.store.Add(ctx, key, int64(n), q.ttl(reset))
+	if err != nil {
+		log.Errorf("quota %s: %v", q.name, err)
+		return nil
+	}
+
+	if value > int64(limit) {
+		q.Release(ctx, user, n)
+		return &QuotaExceeded{Name: q.name, Limit: limit, Reset: reset}
+	}
+
+	return nil
+}
+
+// Take use n of the quota of the user of ctx, returning the function giving
+// some back. The anonymous callers, only there with the authentication
+// disabled, aren't counted.
+func (q *Quota) Take(ctx context.Context, n int) (func(n int), error) {
+	user, ok := auth.FromContext(ctx)
+	if !ok || user.Email == "" || n <= 0 {
+		return func(int) {}, nil
+	}
+
+	if err := q.Use(ctx, user.Email, n); err != nil {
+		return nil, err
+	}
+
+	return func(n int) {
+		q.Release(ctx, user.Email, n)
+	}, nil
+}
+
+// Release give back n uses of user, such as when the creation failed
+func (q *Quota) Release(ctx context.Context, user string, n int) {
+	if q.limit() <= 0 || n <= 0 {
+		return
+	}
+
+	key, reset := q.key(user)
+	if _, err := q.store.Add(ctx, key, -int64(n), q.ttl(reset)); err != nil {
+		log.Errorf("quota %s: %v", q.name, err)
+	}
+}
+
+// ttl keep the counter of the day an hour past its end, for the clock skew
+// between the instances
+func (q *Quota) ttl(reset time.Time) time.Duration {
+	return reset.Sub(q.now()) + time.Hour
+}
+
+// key return the counter of user for the current day and its end
+func (q *Quota) key(user string) (string, time.Time) {
+	now := q.now().UTC()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	return fmt.Sprintf("quota:%s:%s:%s", q.name, strings.ToLower(user), day.Format("2006-01-02")), day.AddDate(0, 0, 1)
+}
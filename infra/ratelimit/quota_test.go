@@ -0,0 +1,122 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"todolist-api/constants"
+	"todolist-api/infra/auth"
+)
+
+func TestQuota(t *testing.T) {
+	start := time.Date(2023, time.July, 1, 23, 0, 0, 0, time.UTC)
+	redisStore, m := newRedisStore(t, start)
+
+	stores := map[string]Store{
+		"memory": NewMemoryStore(),
+		"redis":  redisStore,
+	}
+
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			c := &clock{now: start}
+			if s, ok := store.(*MemoryStore); ok {
+				s.now = c.Now
+			}
+
+			limit := 5
+			q := NewQuota(store, "todo", func() int { return limit })
+			q.now = c.Now
+			ctx := context.Background()
+
+			if err := q.Use(ctx, "Alice@example.com", 3); err != nil {
+				t.Fatal(err)
+			}
+			if err := q.Use(ctx, "alice@example.com", 2); err != nil {
+				t.Fatal(err)
+			}
+
+			// past the quota, nothing is counted
+			err := q.Use(ctx, "alice@example.com", 1)
+			var exceeded *QuotaExceeded
+			if !errors.As(err, &exceeded) || !errors.Is(err, constants.ErrQuotaExceeded) {
+				t.Fatalf("expected the quota to be exceeded, got %v", err)
+			}
+			if exceeded.Limit != 5 || !exceeded.Reset.Equal(time.Date(2023, time.July, 2, 0, 0, 0, 0, time.UTC)) {
+				t.Fatalf("unexpected %+v", exceeded)
+			}
+
+			// a released use is given back, the others users have their own quota
+			q.Release(ctx, "alice@example.com", 1)
+			if err := q.Use(ctx, "alice@example.com", 1); err != nil {
+				t.Fatal(err)
+			}
+			if err := q.Use(ctx, "bruno@example.com", 5); err != nil {
+				t.Fatal(err)
+			}
+
+			// a limit raised while serving applies at once
+			limit = 6
+			if err := q.Use(ctx, "alice@example.com", 1); err != nil {
+				t.Fatal(err)
+			}
+
+			// the next UTC day starts over
+			c.Advance(time.Hour)
+			if err := q.Use(ctx, "alice@example.com", 6); err != nil {
+				t.Fatal(err)
+			}
+
+			// unlimited
+			limit = 0
+			if err := q.Use(ctx, "alice@example.com", 100); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+
+	// the counter of a day outlives it by an hour
+	if ttl := m.TTL("todolist:ratelimit:quota:todo:alice@example.com:2023-07-01"); ttl != 2*time.Hour {
+		t.Fatalf("ttl %s, expected 2h", ttl)
+	}
+}
+
+func TestQuotaStoreDown(t *testing.T) {
+	s, m := newRedisStore(t, time.Now())
+	m.Close()
+
+	// the quota is ignored rather than refusing every creation
+	q := NewQuota(s, "todo", func() int { return 1 })
+	if err := q.Use(context.Background(), "alice@example.com", 10); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestQuotaTake(t *testing.T) {
+	q := NewQuota(NewMemoryStore(), "todo", func() int { return 3 })
+	ctx := auth.WithUser(context.Background(), auth.User{Email: "alice@example.com"})
+
+	release, err := q.Take(ctx, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := q.Take(ctx, 1); !errors.Is(err, constants.ErrQuotaExceeded) {
+		t.Fatalf("error %v, expected the quota to be exceeded", err)
+	}
+
+	// given back, the uses fit again
+	release(2)
+	if _, err := q.Take(ctx, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	// the anonymous callers aren't counted
+	for i := 0; i < 5; i++ {
+		release, err := q.Take(context.Background(), 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		release(1)
+	}
+}
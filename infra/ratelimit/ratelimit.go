@@ -0,0 +1,91 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+	"todolist-api/config"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// StoreMemory keep the buckets in the process, each instance limiting on its own
+	StoreMemory = "memory"
+	// StoreRedis share the buckets between the instances through Redis
+	StoreRedis = "redis"
+)
+
+// Limit a token bucket refilled with Rate tokens per second up to Burst
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// Unlimited tell whether the limit lets everything through
+func (l Limit) Unlimited() bool {
+	return l.Rate <= 0 || l.Burst <= 0
+}
+
+// Result the state of a bucket after taking a token
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset until the bucket is full again
+	Reset time.Duration
+	// RetryAfter until the next token, zero when allowed
+	RetryAfter time.Duration
+}
+
+// Store keep the token buckets and the quota counters
+type Store interface {
+	// Take take a token from the bucket key
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+	// Refund give back a token taken from the bucket key
+	Refund(ctx context.Context, key string, limit Limit) error
+	// Add add delta to the counter key, created to expire after ttl, and
+	// return its new value
+	Add(ctx context.Context, key string, delta int64, ttl time.Duration) (int64, error)
+}
+
+// NewStore return the store named by cfg, in memory by default
+func NewStore(cfg config.RateLimitConfig) Store {
+	if cfg.Store == StoreRedis {
+		return NewRedisStore(redis.NewClient(&redis.Options{
+			Addr:     cfg.Redis.Addr,
+			Password: cfg.Redis.Password,
+			DB:       cfg.Redis.DB,
+		}), cfg.Redis.Prefix)
+	}
+
+	return NewMemoryStore()
+}
+
+// refill return the tokens of a bucket holding tokens elapsed ago
+func refill(tokens float64, elapsed time.Duration, limit Limit) float64 {
+	if elapsed < 0 {
+		elapsed = 0
+	}
+
+	return math.Min(float64(limit.Burst), tokens+elapsed.Seconds()*limit.Rate)
+}
+
+// result describe a bucket left with tokens once a token was taken, or not
+func result(allowed bool, tokens float64, limit Limit) Result {
+	res := Result{
+		Allowed:   allowed,
+		Limit:     limit.Burst,
+		Remaining: int(math.Floor(tokens)),
+		Reset:     seconds((float64(limit.Burst) - tokens) / limit.Rate),
+	}
+	if !allowed {
+		res.RetryAfter = seconds((1 - tokens) / limit.Rate)
+	}
+
+	return res
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(math.Ceil(s * float64(time.Second)))
+}
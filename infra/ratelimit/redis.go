@@ -0,0 +1,98 @@
+package ratelimit
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// takeScript refill the bucket KEYS[1] at ARGV[1] tokens per second up to
+// ARGV[2] using the clock of the server, then take a token. The bucket
+// expires once full again.
+var takeScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) + tonumber(t[2]) / 1000000
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+	tokens = burst
+	ts = now
+end
+tokens = math.min(burst, tokens + math.max(0, now - ts) * rate)
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', tostring(now))
+redis.call('PEXPIRE', KEYS[1], math.ceil((burst - tokens) / rate * 1000) + 1000)
+return {allowed, tostring(tokens)}
+`)
+
+// refundScript give back a token to the bucket KEYS[1], up to ARGV[1]. A
+// bucket already expired is full.
+var refundScript = redis.NewScript(`
+local burst = tonumber(ARGV[1])
+local tokens = tonumber(redis.call('HGET', KEYS[1], 'tokens'))
+if tokens == nil then
+	return 0
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(math.min(burst, tokens + 1)))
+return 1
+`)
+
+// addScript add ARGV[1] to the counter KEYS[1], expiring after ARGV[2]
+// milliseconds when created
+var addScript = redis.NewScript(`
+local value = redis.call('INCRBY', KEYS[1], ARGV[1])
+if redis.call('PTTL', KEYS[1]) < 0 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return value
+`)
+
+// RedisStore share the buckets and the counters between the instances
+// through a Redis server, or any server speaking its protocol and Lua scripts
+type RedisStore struct {
+	client redis.Scripter
+	prefix string
+}
+
+// NewRedisStore return a store keeping its keys under prefix
+func NewRedisStore(client redis.Scripter, prefix string) *RedisStore {
+	return &RedisStore{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+// Take take a token from the bucket key
+func (s *RedisStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	res, err := takeScript.Run(ctx, s.client, []string{s.prefix + key}, limit.Rate, limit.Burst).Slice()
+	if err != nil {
+		return Result{}, err
+	}
+
+	allowed, _ := res[0].(int64)
+	tokens, err := strconv.ParseFloat(res[1].(string), 64)
+	if err != nil {
+		return Result{}, err
+	}
+
+	return result(allowed == 1, tokens, limit), nil
+}
+
+// Refund give back a token taken from the bucket key
+func (s *RedisStore) Refund(ctx context.Context, key string, limit Limit) error {
+	return refundScript.Run(ctx, s.client, []string{s.prefix + key}, limit.Burst).Err()
+}
+
+// Add add delta to the counter key, created to expire after ttl
+func (s *RedisStore) Add(ctx context.Context, key string, delta int64, ttl time.Duration) (int64, error) {
+	return addScript.Run(ctx, s.client, []string{s.prefix + key}, delta, ttl.Milliseconds()).Int64()
+}
@@ -0,0 +1,123 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// newRedisStore a store on a local stand-in of Redis, its clock set to start
+func newRedisStore(t *testing.T, start time.Time) (*RedisStore, *miniredis.Miniredis) {
+	t.Helper()
+
+	m := miniredis.RunT(t)
+	m.SetTime(start)
+
+	client := redis.NewClient(&redis.Options{Addr: m.Addr()})
+	t.Cleanup(func() {
+		_ = client.Close()
+	})
+
+	return NewRedisStore(client, "todolist:ratelimit:"), m
+}
+
+func TestRedisStoreTake(t *testing.T) {
+	start := time.Date(2023, time.July, 1, 12, 0, 0, 0, time.UTC)
+	s, m := newRedisStore(t, start)
+	ctx := context.Background()
+	limit := Limit{Rate: 2, Burst: 3}
+
+	for i := 2; i >= 0; i-- {
+		res, err := s.Take(ctx, "bucket:ip:1", limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !res.Allowed || res.Remaining != i || res.Limit != 3 {
+			t.Fatalf("take %d: %+v", 3-i, res)
+		}
+	}
+
+	res, err := s.Take(ctx, "bucket:ip:1", limit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Allowed || res.RetryAfter != 500*time.Millisecond || res.Reset != 1500*time.Millisecond {
+		t.Fatalf("expected a refusal for 500ms, got %+v", res)
+	}
+
+	// the bucket is kept under the prefix until full again, plus a second
+	if !m.Exists("todolist:ratelimit:bucket:ip:1") {
+		t.Fatal("bucket missing from the store")
+	}
+	if ttl := m.TTL("todolist:ratelimit:bucket:ip:1"); ttl != 2500*time.Millisecond {
+		t.Fatalf("ttl %s, expected 2.5s", ttl)
+	}
+
+	// refilled by the clock of the server
+	m.SetTime(start.Add(500 * time.Millisecond))
+	if res, _ := s.Take(ctx, "bucket:ip:1", limit); !res.Allowed || res.Remaining != 0 {
+		t.Fatalf("after 500ms: %+v", res)
+	}
+	m.SetTime(start.Add(time.Hour))
+	if res, _ := s.Take(ctx, "bucket:ip:1", limit); !res.Allowed || res.Remaining != 2 {
+		t.Fatalf("after an hour: %+v", res)
+	}
+}
+
+func TestRedisStoreAdd(t *testing.T) {
+	s, m := newRedisStore(t, time.Date(2023, time.July, 1, 12, 0, 0, 0, time.UTC))
+	ctx := context.Background()
+
+	for i, expected := range []int64{2, 5, 4} {
+		value, err := s.Add(ctx, "quota:todo:a", []int64{2, 3, -1}[i], time.Minute)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if value != expected {
+			t.Fatalf("add %d: %d, expected %d", i, value, expected)
+		}
+	}
+
+	// the expiry is set on creation only
+	if ttl := m.TTL("todolist:ratelimit:quota:todo:a"); ttl != time.Minute {
+		t.Fatalf("ttl %s, expected 1m", ttl)
+	}
+
+	m.FastForward(time.Minute)
+	if value, _ := s.Add(ctx, "quota:todo:a", 1, time.Minute); value != 1 {
+		t.Fatalf("after expiry: %d, expected 1", value)
+	}
+}
+
+func TestRedisStoreDown(t *testing.T) {
+	s, m := newRedisStore(t, time.Now())
+	m.Close()
+
+	if _, err := s.Take(context.Background(), "bucket:ip:1", Limit{Rate: 1, Burst: 1}); err == nil {
+		t.Fatal("expected an error once the server is gone")
+	}
+}
+
+func TestRedisStoreRefund(t *testing.T) {
+	s, m := newRedisStore(t, time.Date(2023, time.July, 1, 12, 0, 0, 0, time.UTC))
+	ctx := context.Background()
+	limit := Limit{Rate: 0.001, Burst: 2}
+
+	_, _ = s.Take(ctx, "bucket:ip:1", limit)
+	if err := s.Refund(ctx, "bucket:ip:1", limit); err != nil {
+		t.Fatal(err)
+	}
+	// never past the burst
+	_ = s.Refund(ctx, "bucket:ip:1", limit)
+	if tokens := m.HGet("todolist:ratelimit:bucket:ip:1", "tokens"); tokens != "2" {
+		t.Fatalf("tokens %s, expected 2", tokens)
+	}
+
+	// a bucket gone is full, nothing to give back
+	if err := s.Refund(ctx, "bucket:ip:2", limit); err != nil || m.Exists("todolist:ratelimit:bucket:ip:2") {
+		t.Fatalf("refund of a missing bucket: %v", err)
+	}
+}
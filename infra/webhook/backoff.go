@@ -0,0 +1,21 @@
+package webhook
+
+import "time"
+
+// Backoff return the delay before the next attempt, doubling from base on
+// every failed attempt and never exceeding max
+func Backoff(attempts int, base, max time.Duration) time.Duration {
+	if attempts < 1 {
+		return base
+	}
+
+	delay := base
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= max {
+			return max
+		}
+	}
+
+	return delay
+}
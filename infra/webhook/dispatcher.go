@@ -0,0 +1,329 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"todolist-api/config"
+	"todolist-api/constants"
+	"todolist-api/data/models"
+	"todolist-api/infra/context/repository"
+	"todolist-api/infra/errors"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultPollInterval = 5 * time.Second
+	defaultBatchSize    = 50
+	defaultMaxAttempts  = 8
+	defaultBackoffBase  = 10 * time.Second
+	defaultBackoffMax   = time.Hour
+	defaultTimeout      = 10 * time.Second
+
+	maxErrorLength = 255
+)
+
+// envelope is the JSON body sent to every subscriber
+type envelope struct {
+	ID        int             `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt string          `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// Dispatcher moves events from the outbox into per subscription deliveries
+// and sends the due deliveries, retrying failures with exponential backoff
+// until they end up in the dead letter queue.
+type Dispatcher struct {
+	*repository.RepoCtx
+	client       *http.Client
+	pollInterval time.Duration
+	batchSize    int
+	maxAttempts  int
+	backoffBase  time.Duration
+	backoffMax   time.Duration
+	timeout      time.Duration
+}
+
+// NewDispatcher create a dispatcher, zero configuration values fall back to defaults
+func NewDispatcher(ctx *repository.RepoCtx, cfg config.WebhookConfig) *Dispatcher {
+	d := &Dispatcher{
+		RepoCtx:      ctx,
+		pollInterval: seconds(cfg.PollInterval, defaultPollInterval),
+		batchSize:    cfg.BatchSize,
+		maxAttempts:  cfg.MaxAttempts,
+		backoffBase:  seconds(cfg.BackoffBase, defaultBackoffBase),
+		backoffMax:   seconds(cfg.BackoffMax, defaultBackoffMax),
+		timeout:      seconds(cfg.Timeout, defaultTimeout),
+	}
+
+	if d.batchSize <= 0 {
+		d.batchSize = defaultBatchSize
+	}
+
+	if d.maxAttempts <= 0 {
+		d.maxAttempts = defaultMaxAttempts
+	}
+
+	d.client = &http.Client{Timeout: d.timeout}
+
+	return d
+}
+
+// Run poll the outbox until ctx is cancelled
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := d.Tick(ctx); err != nil {
+				log.Error(err)
+			}
+		}
+	}
+}
+
+// Tick run a single fan out and delivery round
+func (d *Dispatcher) Tick(ctx context.Context) error {
+	if err := d.fanOut(ctx); err != nil {
+		return err
+	}
+
+	return d.deliverDue(ctx)
+}
+
+// fanOut create one delivery per matching subscription for every pending outbox event
+func (d *Dispatcher) fanOut(ctx context.Context) error {
+	tx, err := d.DB.Begin(ctx)
+	if err != nil {
+		return errors.Wrap(constants.ErrBeginTransaction)
+	}
+
+	events, err := d.WebhookRepository.GetPendingEvent(ctx, tx, d.batchSize)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if len(events) == 0 {
+		return tx.Rollback()
+	}
+
+	hooks, err := d.WebhookRepository.GetActiveWebhook(ctx, tx)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	now := time.Now()
+	for _, event := range events {
+		body, err := json.Marshal(envelope{
+			ID:        event.EventID,
+			Type:      event.EventType,
+			CreatedAt: event.CreatedAt.UTC().Format(constants.DateTimeFormat),
+			Data:      json.RawMessage(event.Payload),
+		})
+		if err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+
+		for _, hook := range hooks {
+			if !subscribed(hook, event.EventType) {
+				continue
+			}
+
+			_, err = d.WebhookRepository.CreateDelivery(ctx, tx, models.WebhookDelivery{
+				WebhookID:     hook.WebhookID,
+				EventID:       event.EventID,
+				EventType:     event.EventType,
+				Payload:       string(body),
+				Status:        constants.DeliveryStatusPending,
+				NextAttemptAt: now,
+			})
+			if err != nil {
+				_ = tx.Rollback()
+				return err
+			}
+		}
+
+		err = d.WebhookRepository.MarkEventProcessed(ctx, tx, event.EventID)
+		if err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// deliverDue claim the due deliveries by pushing their next attempt past the
+// request timeout, so other instances skip them, then send them outside the transaction
+func (d *Dispatcher) deliverDue(ctx context.Context) error {
+	tx, err := d.DB.Begin(ctx)
+	if err != nil {
+		return errors.Wrap(constants.ErrBeginTransaction)
+	}
+
+	now := time.Now()
+	deliveries, err := d.WebhookRepository.GetDueDelivery(ctx, tx, now, d.batchSize)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if len(deliveries) == 0 {
+		return tx.Rollback()
+	}
+
+	hooks, err := d.WebhookRepository.GetActiveWebhook(ctx, tx)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	for i := range deliveries {
+		deliveries[i].NextAttemptAt = now.Add(2 * d.timeout)
+		err = d.WebhookRepository.UpdateDelivery(ctx, tx, deliveries[i])
+		if err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	hookByID := make(map[int]models.Webhook, len(hooks))
+	for _, hook := range hooks {
+		hookByID[hook.WebhookID] = hook
+	}
+
+	for _, delivery := range deliveries {
+		hook, ok := hookByID[delivery.WebhookID]
+		if !ok {
+			delivery.Status = constants.DeliveryStatusDead
+			delivery.LastError = fmt.Sprintf("webhook %d is no longer active", delivery.WebhookID)
+			if err := d.record(ctx, delivery); err != nil {
+				log.Error(err)
+			}
+			continue
+		}
+
+		statusCode, err := d.send(ctx, hook, delivery)
+		if err := d.record(ctx, d.result(delivery, statusCode, err)); err != nil {
+			log.Error(err)
+		}
+	}
+
+	return nil
+}
+
+// send POST the signed payload to the subscriber
+func (d *Dispatcher) send(ctx context.Context, hook models.Webhook, delivery models.WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	timestamp := time.Now().Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(constants.WebhookEventHeader, delivery.EventType)
+	req.Header.Set(constants.WebhookDeliveryHeader, strconv.Itoa(delivery.DeliveryID))
+	req.Header.Set(constants.WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(constants.WebhookSignatureHeader, Sign(hook.Secret, timestamp, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("receiver responded with status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// result apply the outcome of an attempt to the delivery
+func (d *Dispatcher) result(delivery models.WebhookDelivery, statusCode int, err error) models.WebhookDelivery {
+	delivery.Attempts++
+	delivery.LastStatusCode = statusCode
+
+	if err == nil {
+		delivery.Status = constants.DeliveryStatusDelivered
+		delivery.LastError = ""
+		return delivery
+	}
+
+	delivery.LastError = truncate(err.Error(), maxErrorLength)
+	if delivery.Attempts >= d.maxAttempts {
+		delivery.Status = constants.DeliveryStatusDead
+		return delivery
+	}
+
+	delivery.Status = constants.DeliveryStatusPending
+	delivery.NextAttemptAt = time.Now().Add(Backoff(delivery.Attempts, d.backoffBase, d.backoffMax))
+
+	return delivery
+}
+
+func (d *Dispatcher) record(ctx context.Context, delivery models.WebhookDelivery) error {
+	tx, err := d.DB.Begin(ctx)
+	if err != nil {
+		return errors.Wrap(constants.ErrBeginTransaction)
+	}
+
+	err = d.WebhookRepository.UpdateDelivery(ctx, tx, delivery)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func subscribed(hook models.Webhook, eventType string) bool {
+	for _, x := range strings.Split(hook.EventTypes, ",") {
+		if x == eventType {
+			return true
+		}
+	}
+
+	return false
+}
+
+func seconds(n int, fallback time.Duration) time.Duration {
+	if n <= 0 {
+		return fallback
+	}
+
+	return time.Duration(n) * time.Second
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+
+	return s[:n]
+}
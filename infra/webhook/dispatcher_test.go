@@ -0,0 +1,300 @@
+package webhook_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+	"todolist-api/config"
+	"todolist-api/constants"
+	"todolist-api/data/models"
+	"todolist-api/data/repositories/webhook/webhooktest"
+	"todolist-api/infra/context/repository"
+	"todolist-api/infra/db"
+	"todolist-api/infra/db/dbtest"
+	"todolist-api/infra/webhook"
+)
+
+// receiver a subscriber answering status, recording every request it gets
+type receiver struct {
+	*httptest.Server
+	status int
+
+	mtx      sync.Mutex
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func newReceiver(t *testing.T, status int) *receiver {
+	t.Helper()
+
+	rcv := &receiver{status: status}
+	rcv.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		rcv.mtx.Lock()
+		rcv.requests = append(rcv.requests, r)
+		rcv.bodies = append(rcv.bodies, body)
+		rcv.mtx.Unlock()
+
+		w.WriteHeader(rcv.status)
+	}))
+	t.Cleanup(rcv.Close)
+
+	return rcv
+}
+
+func (rcv *receiver) count() int {
+	rcv.mtx.Lock()
+	defer rcv.mtx.Unlock()
+
+	return len(rcv.requests)
+}
+
+// newDispatcher a dispatcher on top of repo, its transactions run against a
+// database answering from memory
+func newDispatcher(t *testing.T, repo *webhooktest.Repository, cfg config.WebhookConfig) *webhook.Dispatcher {
+	t.Helper()
+
+	conn, err := db.Open(&config.DBConfig{Name: dbtest.Register(&dbtest.Driver{}), Host: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+
+	return webhook.NewDispatcher(&repository.RepoCtx{DB: conn, WebhookRepository: repo}, cfg)
+}
+
+// subscribe an active webhook of url to todo.created, returning its id
+func subscribe(t *testing.T, repo *webhooktest.Repository, url, secret string) int {
+	t.Helper()
+
+	hook, err := repo.CreateWebhook(context.Background(), nil, models.Webhook{
+		URL:        url,
+		Secret:     secret,
+		EventTypes: constants.EventTodoCreated + "," + constants.EventTodoDeleted,
+		IsActive:   true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return hook.WebhookID
+}
+
+// enqueue a todo.created event in the outbox
+func enqueue(t *testing.T, repo *webhooktest.Repository) {
+	t.Helper()
+
+	err := repo.EnqueueEvent(context.Background(), nil, constants.EventTodoCreated, map[string]interface{}{"id": 7, "title": "Water the plants"})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// delivery the only delivery of the repository
+func delivery(t *testing.T, repo *webhooktest.Repository) models.WebhookDelivery {
+	t.Helper()
+
+	deliveries, _ := repo.GetDeliveryByStatus(context.Background(), "")
+	if len(deliveries) != 1 {
+		t.Fatalf("%d deliveries, expected 1", len(deliveries))
+	}
+
+	return deliveries[0]
+}
+
+func TestBackoff(t *testing.T) {
+	base, max := 10*time.Second, time.Hour
+	expected := []time.Duration{
+		10 * time.Second,
+		10 * time.Second,
+		20 * time.Second,
+		40 * time.Second,
+		80 * time.Second,
+		160 * time.Second,
+		320 * time.Second,
+		640 * time.Second,
+		1280 * time.Second,
+		2560 * time.Second,
+		time.Hour,
+		time.Hour,
+	}
+
+	for attempts, delay := range expected {
+		if got := webhook.Backoff(attempts, base, max); got != delay {
+			t.Fatalf("attempt %d: %s, expected %s", attempts, got, delay)
+		}
+	}
+}
+
+func TestDispatcherSignature(t *testing.T) {
+	rcv := newReceiver(t, http.StatusNoContent)
+	repo := webhooktest.New()
+	subscribe(t, repo, rcv.URL, "s3cret")
+	// not subscribed to the event
+	_, _ = repo.CreateWebhook(context.Background(), nil, models.Webhook{
+		URL:        rcv.URL,
+		Secret:     "other",
+		EventTypes: constants.EventTodoDeleted,
+		IsActive:   true,
+	})
+	enqueue(t, repo)
+
+	d := newDispatcher(t, repo, config.WebhookConfig{})
+	if err := d.Tick(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if rcv.count() != 1 {
+		t.Fatalf("%d requests, expected 1", rcv.count())
+	}
+	req, body := rcv.requests[0], rcv.bodies[0]
+	sent := delivery(t, repo)
+
+	if req.Method != http.MethodPost || req.Header.Get("Content-Type") != "application/json" {
+		t.Fatalf("%s with %q", req.Method, req.Header.Get("Content-Type"))
+	}
+	if got := req.Header.Get(constants.WebhookEventHeader); got != constants.EventTodoCreated {
+		t.Fatalf("event header %q", got)
+	}
+	if got := req.Header.Get(constants.WebhookDeliveryHeader); got != strconv.Itoa(sent.DeliveryID) {
+		t.Fatalf("delivery header %q, expected %d", got, sent.DeliveryID)
+	}
+
+	// the signature covers the timestamp and the body, with the secret of the webhook
+	timestamp, err := strconv.ParseInt(req.Header.Get(constants.WebhookTimestampHeader), 10, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if time.Since(time.Unix(timestamp, 0)) > time.Minute {
+		t.Fatalf("timestamp %d is not the time of sending", timestamp)
+	}
+	signature := req.Header.Get(constants.WebhookSignatureHeader)
+	if !webhook.Verify("s3cret", timestamp, body, signature) {
+		t.Fatalf("signature %q does not verify", signature)
+	}
+	if webhook.Verify("other", timestamp, body, signature) || webhook.Verify("s3cret", timestamp+1, body, signature) {
+		t.Fatal("signature verified with another secret or timestamp")
+	}
+
+	var payload struct {
+		ID   int                    `json:"id"`
+		Type string                 `json:"type"`
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		t.Fatal(err)
+	}
+	if payload.Type != constants.EventTodoCreated || payload.ID != sent.EventID || payload.Data["title"] != "Water the plants" {
+		t.Fatalf("unexpected payload %s", body)
+	}
+
+	if sent.Status != constants.DeliveryStatusDelivered || sent.Attempts != 1 || sent.LastStatusCode != http.StatusNoContent {
+		t.Fatalf("unexpected delivery %+v", sent)
+	}
+
+	// the event is not fanned out twice
+	if err := d.Tick(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if rcv.count() != 1 {
+		t.Fatalf("%d requests after a second tick, expected 1", rcv.count())
+	}
+}
+
+func TestDispatcherDeadLetter(t *testing.T) {
+	rcv := newReceiver(t, http.StatusInternalServerError)
+	repo := webhooktest.New()
+	subscribe(t, repo, rcv.URL, "s3cret")
+	enqueue(t, repo)
+
+	d := newDispatcher(t, repo, config.WebhookConfig{MaxAttempts: 4, BackoffBase: 10, BackoffMax: 30})
+	ctx := context.Background()
+
+	// every failure waits twice as long as the previous one, up to the maximum
+	for attempts, wait := range []time.Duration{10 * time.Second, 20 * time.Second, 30 * time.Second} {
+		before := time.Now()
+		if err := d.Tick(ctx); err != nil {
+			t.Fatal(err)
+		}
+		after := time.Now()
+
+		failed := delivery(t, repo)
+		if failed.Status != constants.DeliveryStatusPending || failed.Attempts != attempts+1 {
+			t.Fatalf("attempt %d: %+v", attempts+1, failed)
+		}
+		if failed.LastStatusCode != http.StatusInternalServerError || failed.LastError != "receiver responded with status 500" {
+			t.Fatalf("attempt %d: status %d, error %q", attempts+1, failed.LastStatusCode, failed.LastError)
+		}
+		if failed.NextAttemptAt.Before(before.Add(wait)) || failed.NextAttemptAt.After(after.Add(wait)) {
+			t.Fatalf("attempt %d: next attempt in %s, expected %s", attempts+1, failed.NextAttemptAt.Sub(before), wait)
+		}
+
+		// not due yet
+		if err := d.Tick(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if rcv.count() != attempts+1 {
+			t.Fatalf("attempt %d: retried before its time", attempts+1)
+		}
+
+		failed.NextAttemptAt = time.Now().Add(-time.Second)
+		repo.SetDelivery(failed)
+	}
+
+	// the last attempt moves it to the dead letter queue, where it stays
+	if err := d.Tick(ctx); err != nil {
+		t.Fatal(err)
+	}
+	dead := delivery(t, repo)
+	if dead.Status != constants.DeliveryStatusDead || dead.Attempts != 4 {
+		t.Fatalf("expected a dead delivery after 4 attempts, got %+v", dead)
+	}
+
+	dead.NextAttemptAt = time.Now().Add(-time.Second)
+	repo.SetDelivery(dead)
+	if err := d.Tick(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if rcv.count() != 4 {
+		t.Fatalf("%d requests, expected 4", rcv.count())
+	}
+}
+
+func TestDispatcherInactiveWebhook(t *testing.T) {
+	rcv := newReceiver(t, http.StatusNoContent)
+	repo := webhooktest.New()
+	id := subscribe(t, repo, rcv.URL, "s3cret")
+	ctx := context.Background()
+
+	// a delivery left pending while its webhook was disabled
+	_, _ = repo.CreateDelivery(ctx, nil, models.WebhookDelivery{
+		WebhookID:     id,
+		EventID:       1,
+		EventType:     constants.EventTodoCreated,
+		Payload:       "{}",
+		Status:        constants.DeliveryStatusPending,
+		NextAttemptAt: time.Now().Add(-time.Second),
+	})
+	_ = repo.UpdateWebhook(ctx, nil, id, models.Webhook{URL: rcv.URL, Secret: "s3cret", EventTypes: constants.EventTodoCreated})
+
+	if err := newDispatcher(t, repo, config.WebhookConfig{}).Tick(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	dead := delivery(t, repo)
+	if dead.Status != constants.DeliveryStatusDead || dead.LastError != "webhook "+strconv.Itoa(id)+" is no longer active" {
+		t.Fatalf("unexpected delivery %+v", dead)
+	}
+	if rcv.count() != 0 {
+		t.Fatalf("%d requests, expected none", rcv.count())
+	}
+}
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE change_sequence
+(
+    id TINYINT NOT NULL PRIMARY KEY,
+    seq BIGINT NOT NULL
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+INSERT INTO change_sequence (id, seq) VALUES (1, 0);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE change_sequence;
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE change_fields
+(
+    entity VARCHAR(20) NOT NULL,
+    entity_id INTEGER NOT NULL,
+    field VARCHAR(50) NOT NULL,
+    seq BIGINT NOT NULL,
+    changed_at TIMESTAMP(3) NOT NULL,
+    PRIMARY KEY (entity, entity_id, field)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE change_fields;
+-- +goose StatementEnd
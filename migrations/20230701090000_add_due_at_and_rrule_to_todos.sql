@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE todos
+    ADD COLUMN due_at DATETIME NULL DEFAULT NULL AFTER priority,
+    ADD COLUMN rrule VARCHAR(255) NULL DEFAULT NULL AFTER due_at;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE todos
+    DROP COLUMN rrule,
+    DROP COLUMN due_at;
+-- +goose StatementEnd
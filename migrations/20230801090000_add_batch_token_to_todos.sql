@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE todos
+    ADD COLUMN batch_token CHAR(32) NULL DEFAULT NULL AFTER rrule,
+    ADD INDEX idx_todos_batch_token (batch_token);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE todos
+    DROP INDEX idx_todos_batch_token,
+    DROP COLUMN batch_token;
+-- +goose StatementEnd
@@ -0,0 +1,10 @@
+// Package migrations embed the goose migrations of the schema, so the binary
+// can tell whether the database it serves is up to date.
+package migrations
+
+import "embed"
+
+// FS the SQL migrations, named <version>_<description>.sql
+//
+//go:embed *.sql
+var FS embed.FS
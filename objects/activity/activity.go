@@ -9,6 +9,10 @@ type UpdateActivity struct {
 	Title string `json:"title"`
 }
 
+type FilterActivity struct {
+	Email string
+}
+
 type Activity struct {
 	ID        int    `json:"id"`
 	Title     string `json:"title"`
@@ -0,0 +1,34 @@
+package batch
+
+import "encoding/json"
+
+// Batch API calls run in order in one round trip. A string of the body or the
+// path such as $1.id is replaced by the id field of the data of the response
+// to the first request.
+type Batch struct {
+	Atomic   bool      `json:"atomic"`
+	Requests []Request `json:"requests"`
+}
+
+// Request one API call of a batch, the path being relative to the root such
+// as /v1/todo-items
+type Request struct {
+	Method  string            `json:"method"`
+	Path    string            `json:"path"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    json.RawMessage   `json:"body,omitempty"`
+}
+
+// Result the responses to the requests of a batch, in order. RolledBack
+// tells that an atomic batch failed and none of its changes were kept.
+type Result struct {
+	Atomic     bool       `json:"atomic"`
+	RolledBack bool       `json:"rolled_back"`
+	Responses  []Response `json:"responses"`
+}
+
+// Response the answer to one request of a batch
+type Response struct {
+	Status int             `json:"status"`
+	Body   json.RawMessage `json:"body,omitempty"`
+}
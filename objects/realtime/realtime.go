@@ -0,0 +1,26 @@
+package realtime
+
+import "encoding/json"
+
+type Command struct {
+	ID              string          `json:"id"`
+	Type            string          `json:"type"`
+	ActivityGroupID int             `json:"activity_group_id"`
+	TodoID          int             `json:"todo_id"`
+	Data            json.RawMessage `json:"data"`
+}
+
+type Message struct {
+	Type            string      `json:"type"`
+	ID              string      `json:"id,omitempty"`
+	Event           string      `json:"event,omitempty"`
+	EventID         uint64      `json:"event_id,omitempty"`
+	ActivityGroupID int         `json:"activity_group_id,omitempty"`
+	Data            interface{} `json:"data,omitempty"`
+	Error           string      `json:"error,omitempty"`
+}
+
+type Viewer struct {
+	ConnectionID string `json:"connection_id"`
+	Email        string `json:"email"`
+}
@@ -0,0 +1,85 @@
+package sync
+
+import (
+	"encoding/json"
+	"time"
+	"todolist-api/objects/activity"
+	"todolist-api/objects/todo"
+)
+
+// Pull the changes past Since, the token of the last pull, everything when
+// it is empty. Limit is the most entities returned.
+type Pull struct {
+	Since string
+	Limit int
+}
+
+// Changes the activity groups and the todos created or updated since the
+// token of the pull, in their current state, and the ones deleted. Token is
+// the token of the next pull, HasMore telling that it has more to return.
+type Changes struct {
+	Token      string              `json:"token"`
+	HasMore    bool                `json:"has_more"`
+	Activities []activity.Activity `json:"activities"`
+	Todos      []todo.Todo         `json:"todos"`
+	Deleted    []Tombstone         `json:"deleted"`
+}
+
+// Tombstone an entity deleted since the token of the pull
+type Tombstone struct {
+	Entity    string `json:"entity"`
+	ID        int    `json:"id"`
+	DeletedAt string `json:"deleted_at"`
+}
+
+// Push the mutations made by a client offline, applied in order. Since is the
+// token of its last pull, the server changes past it being the conflicts.
+// Policy is the resolution of the conflicts, set by the server.
+type Push struct {
+	Since     string     `json:"since"`
+	Mutations []Mutation `json:"mutations"`
+	Policy    string     `json:"-"`
+}
+
+// Mutation the create, update or delete of an activity group or a todo at
+// Timestamp, the time of the client. A create may carry a ClientID, a string
+// activity_group_id of a later todo referring to the group it created.
+type Mutation struct {
+	ClientID  string                     `json:"client_id,omitempty"`
+	Entity    string                     `json:"entity"`
+	Operation string                     `json:"op"`
+	ID        int                        `json:"id,omitempty"`
+	Fields    map[string]json.RawMessage `json:"fields,omitempty"`
+	Timestamp time.Time                  `json:"timestamp"`
+}
+
+// PushResult the outcome of each mutation, in order, and the token the
+// client pulls from next
+type PushResult struct {
+	Token   string           `json:"token"`
+	Results []MutationResult `json:"results"`
+}
+
+// MutationResult the outcome of one mutation, Index being its position in the
+// push, and the entity once written
+type MutationResult struct {
+	Index     int                `json:"index"`
+	ClientID  string             `json:"client_id,omitempty"`
+	Entity    string             `json:"entity"`
+	ID        int                `json:"id,omitempty"`
+	Status    string             `json:"status"`
+	Error     string             `json:"error,omitempty"`
+	Conflicts []Conflict         `json:"conflicts,omitempty"`
+	Activity  *activity.Activity `json:"activity,omitempty"`
+	Todo      *todo.Todo         `json:"todo,omitempty"`
+}
+
+// Conflict a field the server changed since the last pull of the client and
+// the value kept, the one of the client or of the server
+type Conflict struct {
+	Field           string      `json:"field"`
+	ClientValue     interface{} `json:"client_value"`
+	ServerValue     interface{} `json:"server_value"`
+	ServerChangedAt string      `json:"server_changed_at"`
+	Resolution      string      `json:"resolution"`
+}
@@ -1,16 +1,35 @@
 package todo
 
+// CreateTodo the todo to create. DueAt is a RFC 3339 date time and RRule a
+// recurrence rule of iCalendar, such as FREQ=WEEKLY;BYDAY=MO, repeating the
+// todo from DueAt.
 type CreateTodo struct {
 	Title           string `json:"title"`
 	ActivityGroupID int    `json:"activity_group_id"`
 	IsActive        bool   `json:"is_active"`
 	Priority        string `json:"priority"`
+	DueAt           string `json:"due_at,omitempty"`
+	RRule           string `json:"rrule,omitempty"`
 }
 
+// UpdateTodo the fields of the todo. A nil DueAt or RRule is kept, an empty
+// one removed.
 type UpdateTodo struct {
-	Title    string `json:"title"`
-	IsActive bool   `json:"is_active"`
-	Priority string `json:"priority"`
+	Title    string  `json:"title"`
+	IsActive bool    `json:"is_active"`
+	Priority string  `json:"priority"`
+	DueAt    *string `json:"due_at,omitempty"`
+	RRule    *string `json:"rrule,omitempty"`
+}
+
+type MoveTodo struct {
+	ActivityGroupID int `json:"activity_group_id"`
+}
+
+type FilterTodo struct {
+	ActivityGroupID int
+	IsActive        *bool
+	Priority        string
 }
 
 type Todo struct {
@@ -19,6 +38,46 @@ type Todo struct {
 	ActivityGroupID int    `json:"activity_group_id"`
 	IsActive        bool   `json:"is_active"`
 	Priority        string `json:"priority"`
+	DueAt           string `json:"due_at,omitempty"`
+	RRule           string `json:"rrule,omitempty"`
 	UpdatedAt       string `json:"updatedAt"`
 	CreatedAt       string `json:"createdAt"`
 }
+
+// BulkTodo several todos created, updated or deleted at once. Items are the
+// todos to create, IDs the todos to update or delete, Set the fields to
+// update and ActivityGroupID the group whose todos are completed.
+type BulkTodo struct {
+	Operation       string       `json:"operation"`
+	Mode            string       `json:"mode"`
+	Items           []CreateTodo `json:"items,omitempty"`
+	IDs             []int        `json:"ids,omitempty"`
+	Set             *BulkTodoSet `json:"set,omitempty"`
+	ActivityGroupID int          `json:"activity_group_id,omitempty"`
+}
+
+// BulkTodoSet the fields updated on every todo, the missing ones are kept
+type BulkTodoSet struct {
+	IsActive        *bool   `json:"is_active,omitempty"`
+	Priority        *string `json:"priority,omitempty"`
+	ActivityGroupID *int    `json:"activity_group_id,omitempty"`
+}
+
+// BulkTodoResult the outcome of each item of a bulk operation
+type BulkTodoResult struct {
+	Operation string         `json:"operation"`
+	Mode      string         `json:"mode"`
+	Succeeded int            `json:"succeeded"`
+	Failed    int            `json:"failed"`
+	Items     []BulkTodoItem `json:"items"`
+}
+
+// BulkTodoItem the outcome of one item, Index being its position in the
+// request. Todo is the todo once created, updated or deleted.
+type BulkTodoItem struct {
+	Index  int    `json:"index"`
+	ID     int    `json:"id,omitempty"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+	Todo   *Todo  `json:"todo,omitempty"`
+}
@@ -0,0 +1,93 @@
+package transfer
+
+import (
+	"time"
+	"todolist-api/objects/activity"
+)
+
+// Export activity groups and their todos, the document of the JSON format
+type Export struct {
+	Version        int     `json:"version"`
+	ExportedAt     string  `json:"exported_at"`
+	ActivityGroups []Group `json:"activity_groups"`
+}
+
+// Group an activity group of an export or an import, Location telling where
+// it was read from in the file
+type Group struct {
+	Title    string `json:"title"`
+	Email    string `json:"email,omitempty"`
+	Todos    []Todo `json:"todos"`
+	Location string `json:"-"`
+}
+
+// Todo a todo of an activity group of an export or an import, DueAt being a
+// RFC 3339 date time
+type Todo struct {
+	Title    string `json:"title"`
+	Priority string `json:"priority,omitempty"`
+	IsActive bool   `json:"is_active"`
+	DueAt    string `json:"due_at,omitempty"`
+	RRule    string `json:"rrule,omitempty"`
+	Location string `json:"-"`
+}
+
+// Import the activity groups read from a file in Format, along with the
+// errors found reading it. Email owns the groups created, the owner of the
+// file keeping them when empty. With an ActivityGroupID, the todos of the
+// file are added to that group instead. A dry run checks the groups without
+// creating them.
+type Import struct {
+	Format          string
+	Groups          []Group
+	Errors          []ImportError
+	Email           string
+	ActivityGroupID int
+	DryRun          bool
+}
+
+// ImportReport the outcome of an import, the activity groups it created or
+// would create and the todos of them, or the errors keeping it from running
+type ImportReport struct {
+	Format         string              `json:"format"`
+	DryRun         bool                `json:"dry_run"`
+	Valid          bool                `json:"valid"`
+	Groups         int                 `json:"groups"`
+	Todos          int                 `json:"todos"`
+	Errors         []ImportError       `json:"errors"`
+	ActivityGroups []activity.Activity `json:"activity_groups,omitempty"`
+}
+
+// Calendar the todos of a calendar feed, of an activity group or of an account
+type Calendar struct {
+	Name  string
+	Todos []CalendarTodo
+}
+
+// CalendarTodo a todo of a calendar feed, Group being the title of its
+// activity group
+type CalendarTodo struct {
+	ID        int
+	Group     string
+	Title     string
+	Priority  string
+	IsActive  bool
+	DueAt     *time.Time
+	RRule     string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// CalendarLink the URL of a calendar feed, for a calendar app to subscribe
+// to, and the token in it
+type CalendarLink struct {
+	URL   string `json:"url"`
+	Token string `json:"token"`
+}
+
+// ImportError a problem of the file, Location being such as line 3 or
+// activity_groups[0].todos[2]
+type ImportError struct {
+	Location string `json:"location"`
+	Message  string `json:"message"`
+}
@@ -13,5 +13,47 @@ import (
 func ErrDataNotFound(m interface{}) error {
 	errs := fmt.Sprintf(constants.ErrDataNotFound, m)
 
+	return notFound(errs)
+}
+
+// ErrWebhookNotFound function to handle webhook not found
+// Params:
+// m: webhook id
+// Returns error
+func ErrWebhookNotFound(m interface{}) error {
+	errs := fmt.Sprintf(constants.ErrWebhookNotFound, m)
+
+	return notFound(errs)
+}
+
+// ErrDeliveryNotFound function to handle webhook delivery not found
+// Params:
+// m: delivery id
+// Returns error
+func ErrDeliveryNotFound(m interface{}) error {
+	errs := fmt.Sprintf(constants.ErrDeliveryNotFound, m)
+
+	return notFound(errs)
+}
+
+// ErrEventTypeNotSupported function to handle unknown webhook event type
+// Params:
+// m: event type
+// Returns error
+func ErrEventTypeNotSupported(m string) error {
+	errs := fmt.Sprintf(constants.ErrEventTypeNotSupported, m)
+
 	return errors.New(errs)
 }
+
+// notFound an error keeping its message, matched by errors.Is against
+// constants.ErrNotFound
+type notFound string
+
+func (e notFound) Error() string {
+	return string(e)
+}
+
+func (e notFound) Is(target error) bool {
+	return target == constants.ErrNotFound
+}
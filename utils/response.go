@@ -2,7 +2,11 @@ package utils
 
 import (
 	"encoding/json"
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 )
@@ -23,6 +27,13 @@ const (
 	MESSAGE_BAD_REQUEST         = "Bad Request"
 	MESSAGE_INTERNAL_SERVER_ERR = "Internal Server Error"
 	MESSAGE_NOT_FOUND           = "Not Found"
+	MESSAGE_UNAUTHORIZED        = "Unauthorized"
+	MESSAGE_FORBIDDEN           = "Forbidden"
+	MESSAGE_METHOD_NOT_ALLOWED  = "Method Not Allowed"
+	MESSAGE_TOO_MANY_REQUESTS   = "Too Many Requests"
+	MESSAGE_CONFLICT            = "Conflict"
+	MESSAGE_UNPROCESSABLE       = "Unprocessable Entity"
+	MESSAGE_TOO_LARGE           = "Request Entity Too Large"
 )
 
 type Response struct {
@@ -127,3 +138,75 @@ func (r *ResponseErr) JSONErrNotFound(w http.ResponseWriter) {
 		log.Error(err)
 	}
 }
+
+func (r *ResponseErr) JSONErrUnauthorized(w http.ResponseWriter) {
+	w.Header().Set(contentType, contentTypeValue)
+	w.Header().Set(xContentTypeOptions, xContentTypeOptionsValue)
+	w.WriteHeader(http.StatusUnauthorized)
+	err := json.NewEncoder(w).Encode(r)
+	if err != nil {
+		log.Error(err)
+	}
+}
+
+func (r *ResponseErr) JSONErrForbidden(w http.ResponseWriter) {
+	w.Header().Set(contentType, contentTypeValue)
+	w.Header().Set(xContentTypeOptions, xContentTypeOptionsValue)
+	w.WriteHeader(http.StatusForbidden)
+	err := json.NewEncoder(w).Encode(r)
+	if err != nil {
+		log.Error(err)
+	}
+}
+
+func (r *ResponseErr) JSONErrMethodNotAllowed(w http.ResponseWriter, allow []string) {
+	w.Header().Set("Allow", strings.Join(allow, ", "))
+	w.Header().Set(contentType, contentTypeValue)
+	w.Header().Set(xContentTypeOptions, xContentTypeOptionsValue)
+	w.WriteHeader(http.StatusMethodNotAllowed)
+	err := json.NewEncoder(w).Encode(r)
+	if err != nil {
+		log.Error(err)
+	}
+}
+
+func (r *ResponseErr) JSONErrTooManyRequests(w http.ResponseWriter, retryAfter time.Duration) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	w.Header().Set(contentType, contentTypeValue)
+	w.Header().Set(xContentTypeOptions, xContentTypeOptionsValue)
+	w.WriteHeader(http.StatusTooManyRequests)
+	err := json.NewEncoder(w).Encode(r)
+	if err != nil {
+		log.Error(err)
+	}
+}
+
+func (r *ResponseErr) JSONErrConflict(w http.ResponseWriter) {
+	w.Header().Set(contentType, contentTypeValue)
+	w.Header().Set(xContentTypeOptions, xContentTypeOptionsValue)
+	w.WriteHeader(http.StatusConflict)
+	err := json.NewEncoder(w).Encode(r)
+	if err != nil {
+		log.Error(err)
+	}
+}
+
+func (r *ResponseErr) JSONErrUnprocessableEntity(w http.ResponseWriter) {
+	w.Header().Set(contentType, contentTypeValue)
+	w.Header().Set(xContentTypeOptions, xContentTypeOptionsValue)
+	w.WriteHeader(http.StatusUnprocessableEntity)
+	err := json.NewEncoder(w).Encode(r)
+	if err != nil {
+		log.Error(err)
+	}
+}
+
+func (r *ResponseErr) JSONErrRequestEntityTooLarge(w http.ResponseWriter) {
+	w.Header().Set(contentType, contentTypeValue)
+	w.Header().Set(xContentTypeOptions, xContentTypeOptionsValue)
+	w.WriteHeader(http.StatusRequestEntityTooLarge)
+	err := json.NewEncoder(w).Encode(r)
+	if err != nil {
+		log.Error(err)
+	}
+}
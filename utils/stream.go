@@ -0,0 +1,33 @@
+package utils
+
+import (
+	"errors"
+	"net/http"
+	"time"
+)
+
+// ErrWriteDeadlineUnsupported returned when no writer in the chain controls its deadline
+var ErrWriteDeadlineUnsupported = errors.New("response writer does not support write deadlines")
+
+type writeDeadliner interface {
+	SetWriteDeadline(deadline time.Time) error
+}
+
+type responseUnwrapper interface {
+	Unwrap() http.ResponseWriter
+}
+
+// DisableWriteDeadline remove the server WriteTimeout for a long-lived response
+// such as an event stream. Wrapping writers are walked through their Unwrap method.
+func DisableWriteDeadline(w http.ResponseWriter) error {
+	for {
+		switch x := w.(type) {
+		case writeDeadliner:
+			return x.SetWriteDeadline(time.Time{})
+		case responseUnwrapper:
+			w = x.Unwrap()
+		default:
+			return ErrWriteDeadlineUnsupported
+		}
+	}
+}
@@ -3,6 +3,7 @@ package utils
 import (
 	"fmt"
 	"strings"
+	"unicode"
 )
 
 func QueryLog(query string, args ...interface{}) {
@@ -12,3 +13,31 @@ func QueryLog(query string, args ...interface{}) {
 	}
 	fmt.Println(query)
 }
+
+// ToSnakeCase convert a camelCase name to snake_case
+// Params:
+// s: camelCase name, such as createdAt
+// Returns the snake_case name, such as created_at
+func ToSnakeCase(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+// NullString return the value of s, empty when s is nil
+func NullString(s *string) string {
+	if s == nil {
+		return ""
+	}
+
+	return *s
+}
@@ -0,0 +1,12 @@
+package utils
+
+import "time"
+
+// FormatNullTime format t in UTC with layout, empty when t is nil
+func FormatNullTime(t *time.Time, layout string) string {
+	if t == nil {
+		return ""
+	}
+
+	return t.UTC().Format(layout)
+}